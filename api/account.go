@@ -6,13 +6,28 @@ import (
 	"fmt"
 	db "github.com/VL-037/go-bank/db/sqlc"
 	"github.com/VL-037/go-bank/token"
+	"github.com/VL-037/go-bank/util"
 	"github.com/gin-gonic/gin"
 	"github.com/lib/pq"
 	"net/http"
+	"strings"
+)
+
+const (
+	// ACCOUNT_NUMBER_ATTEMPTS is how many random account numbers are tried before giving up on a collision
+	ACCOUNT_NUMBER_ATTEMPTS = 5
+	// ACCOUNT_NUMBER_CONSTRAINT is the unique index on the account number, see 000010_add_account_numbers
+	ACCOUNT_NUMBER_CONSTRAINT = "accounts_number_idx"
+)
+
+var (
+	ErrAccountClosed   = db.ErrAccountClosed
+	ErrAccountNotEmpty = errors.New("account balance and available balance must be zero and no whole unit of interest accrued to close it")
 )
 
 type createAccountRequest struct {
 	Currency string `json:"currency" binding:"required,currency"`
+	Type     string `json:"type" binding:"omitempty,oneof=checking savings"`
 }
 
 func (server *Server) createAccount(ctx *gin.Context) {
@@ -22,16 +37,30 @@ func (server *Server) createAccount(ctx *gin.Context) {
 		return
 	}
 
+	if req.Type == "" {
+		req.Type = util.CHECKING_ACCOUNT_TYPE
+	}
+
 	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD_KEY).(*token.Payload)
 	arg := db.CreateAccountParams{
 		Owner:    authPayload.Username,
 		Currency: req.Currency,
 		Balance:  0,
+		Number:   util.NewAccountNumber(),
+		Type:     req.Type,
 	}
 
-	account, err := server.store.CreateAccount(ctx, arg)
+	var account db.Account
+	var err error
+	for attempt := 1; attempt <= ACCOUNT_NUMBER_ATTEMPTS; attempt++ {
+		account, err = server.store.CreateAccount(ctx, arg)
+		if !isAccountNumberCollision(err) {
+			break
+		}
+		arg.Number = util.NewAccountNumber()
+	}
 	if err != nil {
-		if pqErr, ok := err.(*pq.Error); ok {
+		if pqErr, ok := err.(*pq.Error); ok && !isAccountNumberCollision(err) {
 			switch pqErr.Code.Name() {
 			case "foreign_key_violation", "unique_violation":
 				ctx.JSON(http.StatusForbidden, errorResponse(err))
@@ -45,35 +74,66 @@ func (server *Server) createAccount(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, account)
 }
 
+// isAccountNumberCollision reports whether err is a randomly generated account number that is already taken
+func isAccountNumberCollision(err error) bool {
+	pqErr, ok := err.(*pq.Error)
+	return ok && pqErr.Code.Name() == "unique_violation" && pqErr.Constraint == ACCOUNT_NUMBER_CONSTRAINT
+}
+
 type getAccountRequest struct {
 	ID int64 `uri:"id" binding:"required,min=1"`
 }
 
+type getAccountByNumberRequest struct {
+	Number string `uri:"id" binding:"required,account_number"`
+}
+
 func (server *Server) getAccount(ctx *gin.Context) {
-	var req getAccountRequest
-	if err := ctx.ShouldBindUri(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+	account, valid := server.uriAccount(ctx)
+	if !valid {
 		return
 	}
 
-	account, err := server.store.GetAccount(ctx, req.ID)
+	ctx.JSON(http.StatusOK, account)
+}
+
+// uriAccount loads the account in the path, given either by id or by public account number,
+// and makes sure it belongs to the authenticated user
+func (server *Server) uriAccount(ctx *gin.Context) (db.Account, bool) {
+	var account db.Account
+	var err error
+	if strings.HasPrefix(ctx.Param("id"), util.ACCOUNT_NUMBER_PREFIX) {
+		var req getAccountByNumberRequest
+		if err := ctx.ShouldBindUri(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return account, false
+		}
+		account, err = server.store.GetAccountByNumber(ctx, req.Number)
+	} else {
+		var req getAccountRequest
+		if err := ctx.ShouldBindUri(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return account, false
+		}
+		account, err = server.store.GetAccount(ctx, req.ID)
+	}
 	if err != nil {
 		if err == sql.ErrNoRows {
 			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
+			return account, false
 		}
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
+		return account, false
 	}
 
 	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD_KEY).(*token.Payload)
 	if authPayload.Username != account.Owner {
 		err := errors.New("account doesn't belong to the authenticated user")
 		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
-		return
+		return account, false
 	}
 
-	ctx.JSON(http.StatusOK, account)
+	return account, true
 }
 
 type listAccountRequest struct {
@@ -104,3 +164,36 @@ func (server *Server) listAccounts(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, accounts)
 }
+
+// closeAccount closes an empty account of the authenticated user, the account and its entries are kept
+// but it cannot send or receive money anymore, so its standing orders, scheduled transfers and holds are cancelled
+func (server *Server) closeAccount(ctx *gin.Context) {
+	account, valid := server.uriAccount(ctx)
+	if !valid {
+		return
+	}
+
+	if account.MarkForDelete {
+		ctx.JSON(http.StatusBadRequest, errorResponse(ErrAccountClosed))
+		return
+	}
+
+	// interest below one minor unit is forfeited on close
+	if account.Balance != 0 || account.AvailableBalance != 0 || account.AccruedInterest >= util.INTEREST_SCALE {
+		ctx.JSON(http.StatusBadRequest, errorResponse(ErrAccountNotEmpty))
+		return
+	}
+
+	response, err := server.store.CloseAccountTx(ctx, account.ID)
+	if err != nil {
+		// money arrived or the account was closed in the meantime
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusBadRequest, errorResponse(ErrAccountNotEmpty))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response.Account)
+}
@@ -10,10 +10,12 @@ import (
 	"github.com/VL-037/go-bank/token"
 	"github.com/VL-037/go-bank/util"
 	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/require"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"testing"
 )
 
@@ -147,6 +149,105 @@ func TestGetAccountAPI(t *testing.T) {
 	}
 }
 
+type eqCreateAccountParamsMatcher struct {
+	arg db.CreateAccountParams
+}
+
+func (e eqCreateAccountParamsMatcher) Matches(x interface{}) bool {
+	arg, ok := x.(db.CreateAccountParams)
+	if !ok {
+		return false
+	}
+
+	if !util.IsValidAccountNumber(arg.Number) {
+		return false
+	}
+
+	e.arg.Number = arg.Number
+	return reflect.DeepEqual(e.arg, arg)
+}
+
+func (e eqCreateAccountParamsMatcher) String() string {
+	return fmt.Sprintf("matches arg %v with a valid account number", e.arg)
+}
+
+func EqCreateAccountParams(arg db.CreateAccountParams) gomock.Matcher {
+	return eqCreateAccountParamsMatcher{arg}
+}
+
+func TestGetAccountByNumberAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+
+	testCases := []struct {
+		name          string
+		number        string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "OK",
+			number: account.Number,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccountByNumber(gomock.Any(), gomock.Eq(account.Number)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchAccount(t, recorder.Body, account)
+			},
+		},
+		{
+			name:   "NOT_FOUND",
+			number: account.Number,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccountByNumber(gomock.Any(), gomock.Eq(account.Number)).
+					Times(1).
+					Return(db.Account{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "BAD_REQUEST - Invalid Check Digits",
+			number: "VL210000000001",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/%s", tc.number)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, AUTHORIZATION_TYPE_BEARER, user.Username, DURATION)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
 func TestCreateAccountAPI(t *testing.T) {
 	user, _ := randomUser(t)
 	account := randomAccount(user.Username)
@@ -171,9 +272,10 @@ func TestCreateAccountAPI(t *testing.T) {
 					Owner:    account.Owner,
 					Balance:  0,
 					Currency: account.Currency,
+					Type:     util.CHECKING_ACCOUNT_TYPE,
 				}
 				store.EXPECT().
-					CreateAccount(gomock.Any(), gomock.Eq(arg)).
+					CreateAccount(gomock.Any(), EqCreateAccountParams(arg)).
 					Times(1).
 					Return(account, nil)
 			},
@@ -182,6 +284,116 @@ func TestCreateAccountAPI(t *testing.T) {
 				requireBodyMatchAccount(t, recorder.Body, account)
 			},
 		},
+		{
+			name: "OK - Savings",
+			body: createAccountRequest{
+				Currency: account.Currency,
+				Type:     util.SAVINGS_ACCOUNT_TYPE,
+			},
+			setupAuth: func(t *testing.T, req *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, req, tokenMaker, AUTHORIZATION_TYPE_BEARER, user.Username, DURATION)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.CreateAccountParams{
+					Owner:    account.Owner,
+					Balance:  0,
+					Currency: account.Currency,
+					Type:     util.SAVINGS_ACCOUNT_TYPE,
+				}
+				store.EXPECT().
+					CreateAccount(gomock.Any(), EqCreateAccountParams(arg)).
+					Times(1).
+					Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "OK - Account Number Collision",
+			body: createAccountRequest{
+				Currency: account.Currency,
+			},
+			setupAuth: func(t *testing.T, req *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, req, tokenMaker, AUTHORIZATION_TYPE_BEARER, user.Username, DURATION)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.CreateAccountParams{
+					Owner:    account.Owner,
+					Balance:  0,
+					Currency: account.Currency,
+					Type:     util.CHECKING_ACCOUNT_TYPE,
+				}
+				gomock.InOrder(
+					store.EXPECT().
+						CreateAccount(gomock.Any(), EqCreateAccountParams(arg)).
+						Times(1).
+						Return(db.Account{}, &pq.Error{Code: "23505", Constraint: ACCOUNT_NUMBER_CONSTRAINT}),
+					store.EXPECT().
+						CreateAccount(gomock.Any(), EqCreateAccountParams(arg)).
+						Times(1).
+						Return(account, nil),
+				)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchAccount(t, recorder.Body, account)
+			},
+		},
+		{
+			name: "INTERNAL_SERVER_ERROR - Account Number Collisions",
+			body: createAccountRequest{
+				Currency: account.Currency,
+			},
+			setupAuth: func(t *testing.T, req *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, req, tokenMaker, AUTHORIZATION_TYPE_BEARER, user.Username, DURATION)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAccount(gomock.Any(), gomock.Any()).
+					Times(ACCOUNT_NUMBER_ATTEMPTS).
+					Return(db.Account{}, &pq.Error{Code: "23505", Constraint: ACCOUNT_NUMBER_CONSTRAINT})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name: "FORBIDDEN - Duplicate Currency",
+			body: createAccountRequest{
+				Currency: account.Currency,
+			},
+			setupAuth: func(t *testing.T, req *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, req, tokenMaker, AUTHORIZATION_TYPE_BEARER, user.Username, DURATION)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAccount(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Account{}, &pq.Error{Code: "23505", Constraint: "owner_currency_type_key"})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "BAD_REQUEST - Internal Account Type",
+			body: createAccountRequest{
+				Currency: account.Currency,
+				Type:     util.INTERNAL_ACCOUNT_TYPE,
+			},
+			setupAuth: func(t *testing.T, req *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, req, tokenMaker, AUTHORIZATION_TYPE_BEARER, user.Username, DURATION)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
 		{
 			name: "UNAUTHORIZATION",
 			body: createAccountRequest{
@@ -210,9 +422,10 @@ func TestCreateAccountAPI(t *testing.T) {
 					Owner:    account.Owner,
 					Balance:  0,
 					Currency: account.Currency,
+					Type:     util.CHECKING_ACCOUNT_TYPE,
 				}
 				store.EXPECT().
-					CreateAccount(gomock.Any(), gomock.Eq(arg)).
+					CreateAccount(gomock.Any(), EqCreateAccountParams(arg)).
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
@@ -232,9 +445,10 @@ func TestCreateAccountAPI(t *testing.T) {
 					Owner:    account.Owner,
 					Balance:  0,
 					Currency: account.Currency,
+					Type:     util.CHECKING_ACCOUNT_TYPE,
 				}
 				store.EXPECT().
-					CreateAccount(gomock.Any(), gomock.Eq(arg)).
+					CreateAccount(gomock.Any(), EqCreateAccountParams(arg)).
 					Times(1).
 					Return(db.Account{}, sql.ErrConnDone)
 			},
@@ -431,12 +645,177 @@ func TestListAccountAPI(t *testing.T) {
 	}
 }
 
+func TestCloseAccountAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	account.Balance = 0
+	closedAccount := account
+	closedAccount.MarkForDelete = true
+	fundedAccount := randomAccount(user.Username)
+	fundedAccount.AvailableBalance = fundedAccount.Balance
+	fractionAccount := randomAccount(user.Username)
+	fractionAccount.Balance = 0
+	fractionAccount.AccruedInterest = util.INTEREST_SCALE - 1
+	accruedAccount := randomAccount(user.Username)
+	accruedAccount.Balance = 0
+	accruedAccount.AccruedInterest = util.INTEREST_SCALE
+
+	testCases := []struct {
+		name          string
+		accountID     int64
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			accountID: account.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					CloseAccountTx(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(db.CloseAccountTxResponse{Account: closedAccount}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchAccount(t, recorder.Body, closedAccount)
+			},
+		},
+		{
+			name:      "OK - Interest Fraction Forfeited",
+			accountID: fractionAccount.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(fractionAccount.ID)).
+					Times(1).
+					Return(fractionAccount, nil)
+				store.EXPECT().
+					CloseAccountTx(gomock.Any(), gomock.Eq(fractionAccount.ID)).
+					Times(1).
+					Return(db.CloseAccountTxResponse{Account: fractionAccount}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:      "BAD_REQUEST - Interest Accrued",
+			accountID: accruedAccount.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(accruedAccount.ID)).
+					Times(1).
+					Return(accruedAccount, nil)
+				store.EXPECT().
+					CloseAccountTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "BAD_REQUEST - Already Closed",
+			accountID: account.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(closedAccount, nil)
+				store.EXPECT().
+					CloseAccountTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "BAD_REQUEST - Not Empty",
+			accountID: fundedAccount.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(fundedAccount.ID)).
+					Times(1).
+					Return(fundedAccount, nil)
+				store.EXPECT().
+					CloseAccountTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "BAD_REQUEST - Funded Meanwhile",
+			accountID: account.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					CloseAccountTx(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(db.CloseAccountTxResponse{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "INTERNAL_SERVER_ERROR",
+			accountID: account.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					CloseAccountTx(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(db.CloseAccountTxResponse{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/%d", tc.accountID)
+			request, err := http.NewRequest(http.MethodDelete, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, AUTHORIZATION_TYPE_BEARER, user.Username, DURATION)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
 func randomAccount(owner string) db.Account {
 	return db.Account{
 		ID:       util.RandomInt(1, 1000),
 		Owner:    owner,
 		Balance:  util.RandomMoney(),
 		Currency: util.RandomCurrency(),
+		Number:   util.NewAccountNumber(),
+		Type:     util.CHECKING_ACCOUNT_TYPE,
 	}
 }
 
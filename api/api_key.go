@@ -0,0 +1,191 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	db "github.com/VL-037/go-bank/db/sqlc"
+	"github.com/VL-037/go-bank/token"
+	"github.com/VL-037/go-bank/util"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	API_KEY_PREFIX = "gbk_"
+	// API_KEY_PREFIX_LENGTH characters of a key are stored in clear to tell keys apart
+	API_KEY_PREFIX_LENGTH = 12
+)
+
+var (
+	ErrInvalidAPIKey = errors.New("api key is invalid")
+	ErrRevokedAPIKey = errors.New("api key has been revoked")
+)
+
+type apiKeyResponse struct {
+	ID         int64        `json:"id"`
+	Name       string       `json:"name"`
+	KeyPrefix  string       `json:"key_prefix"`
+	Scopes     []string     `json:"scopes"`
+	ExpiredAt  time.Time    `json:"expired_at"`
+	LastUsedAt sql.NullTime `json:"last_used_at"`
+	IsRevoked  bool         `json:"is_revoked"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
+func newAPIKeyResponse(apiKey db.ApiKey) apiKeyResponse {
+	return apiKeyResponse{
+		ID:         apiKey.ID,
+		Name:       apiKey.Name,
+		KeyPrefix:  apiKey.KeyPrefix,
+		Scopes:     strings.Fields(apiKey.Scopes),
+		ExpiredAt:  apiKey.ExpiredAt,
+		LastUsedAt: apiKey.LastUsedAt,
+		IsRevoked:  apiKey.IsRevoked,
+		CreatedAt:  apiKey.CreatedAt,
+	}
+}
+
+type createAPIKeyRequest struct {
+	Name          string   `json:"name" binding:"required,max=64"`
+	Scopes        []string `json:"scopes" binding:"required,min=1,dive,oneof=read:accounts write:transfers"`
+	ExpiresInDays int      `json:"expires_in_days" binding:"required,min=1,max=365"`
+}
+
+type createAPIKeyResponse struct {
+	Key    string         `json:"key"`
+	APIKey apiKeyResponse `json:"api_key"`
+}
+
+// createAPIKey issues a named API key with the given scopes, the key is only shown in this response
+func (server *Server) createAPIKey(ctx *gin.Context) {
+	var req createAPIKeyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	secret, err := util.NewSecureToken()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	key := API_KEY_PREFIX + secret
+
+	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD_KEY).(*token.Payload)
+	apiKey, err := server.store.CreateAPIKey(ctx, db.CreateAPIKeyParams{
+		Username:  authPayload.Username,
+		Name:      req.Name,
+		KeyPrefix: key[:API_KEY_PREFIX_LENGTH],
+		KeyHash:   util.HashToken(key),
+		Scopes:    joinScopes(req.Scopes),
+		ExpiredAt: time.Now().AddDate(0, 0, req.ExpiresInDays),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, createAPIKeyResponse{
+		Key:    key,
+		APIKey: newAPIKeyResponse(apiKey),
+	})
+}
+
+func (server *Server) listAPIKeys(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD_KEY).(*token.Payload)
+
+	apiKeys, err := server.store.ListAPIKeys(ctx, authPayload.Username)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	response := make([]apiKeyResponse, len(apiKeys))
+	for i, apiKey := range apiKeys {
+		response[i] = newAPIKeyResponse(apiKey)
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+type revokeAPIKeyRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) revokeAPIKey(ctx *gin.Context) {
+	var req revokeAPIKeyRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD_KEY).(*token.Payload)
+	_, err := server.store.RevokeAPIKey(ctx, db.RevokeAPIKeyParams{
+		ID:       req.ID,
+		Username: authPayload.Username,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// apiKeyPayload authenticates an API key for authMiddleware and describes it as a scoped token payload
+func apiKeyPayload(ctx *gin.Context, store db.Store, key string) (*token.Payload, bool) {
+	apiKey, err := store.GetAPIKeyByHash(ctx, util.HashToken(key))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(ErrInvalidAPIKey))
+			return nil, false
+		}
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+		return nil, false
+	}
+
+	if apiKey.IsRevoked {
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(ErrRevokedAPIKey))
+		return nil, false
+	}
+
+	if time.Now().After(apiKey.ExpiredAt) {
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(token.ErrExpiredToken))
+		return nil, false
+	}
+
+	if err := store.UpdateAPIKeyLastUsed(ctx, apiKey.ID); err != nil {
+		log.Printf("cannot record use of api key %d: %v", apiKey.ID, err)
+	}
+
+	return &token.Payload{
+		Username:  apiKey.Username,
+		Purpose:   token.PURPOSE_ACCESS,
+		Scopes:    strings.Fields(apiKey.Scopes),
+		IssuedAt:  apiKey.CreatedAt,
+		ExpiredAt: apiKey.ExpiredAt,
+	}, true
+}
+
+// joinScopes stores scopes as a sorted, space separated list without duplicates
+func joinScopes(scopes []string) string {
+	unique := make(map[string]bool, len(scopes))
+	for _, scope := range scopes {
+		unique[scope] = true
+	}
+
+	sorted := make([]string, 0, len(unique))
+	for scope := range unique {
+		sorted = append(sorted, scope)
+	}
+	sort.Strings(sorted)
+
+	return strings.Join(sorted, " ")
+}
@@ -0,0 +1,490 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	mockdb "github.com/VL-037/go-bank/db/mock"
+	db "github.com/VL-037/go-bank/db/sqlc"
+	"github.com/VL-037/go-bank/token"
+	"github.com/VL-037/go-bank/util"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func randomAPIKey(t *testing.T, username string, scopes ...string) (apiKey db.ApiKey, key string) {
+	secret, err := util.NewSecureToken()
+	require.NoError(t, err)
+	key = API_KEY_PREFIX + secret
+
+	apiKey = db.ApiKey{
+		ID:        util.RandomInt(1, 1000),
+		Username:  username,
+		Name:      util.RandomOwner(),
+		KeyPrefix: key[:API_KEY_PREFIX_LENGTH],
+		KeyHash:   util.HashToken(key),
+		Scopes:    strings.Join(scopes, " "),
+		ExpiredAt: time.Now().Add(time.Hour),
+		CreatedAt: time.Now(),
+	}
+	return
+}
+
+func addAPIKeyAuthorization(req *http.Request, key string) {
+	req.Header.Set(AUTHORIZATION_HEADER_KEY, fmt.Sprintf("ApiKey %s", key))
+}
+
+func TestAPIKeyAuthMiddleware(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+
+	readKey, readSecret := randomAPIKey(t, user.Username, token.SCOPE_READ_ACCOUNTS)
+	transferKey, transferSecret := randomAPIKey(t, user.Username, token.SCOPE_WRITE_TRANSFERS)
+	revokedKey, revokedSecret := randomAPIKey(t, user.Username, token.SCOPE_READ_ACCOUNTS)
+	revokedKey.IsRevoked = true
+	expiredKey, expiredSecret := randomAPIKey(t, user.Username, token.SCOPE_READ_ACCOUNTS)
+	expiredKey.ExpiredAt = time.Now().Add(-time.Minute)
+
+	expectAPIKey := func(store *mockdb.MockStore, apiKey db.ApiKey) {
+		store.EXPECT().
+			GetAPIKeyByHash(gomock.Any(), gomock.Eq(apiKey.KeyHash)).
+			Times(1).
+			Return(apiKey, nil)
+	}
+
+	testCases := []struct {
+		name          string
+		method        string
+		url           string
+		key           string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "OK",
+			method: http.MethodGet,
+			url:    fmt.Sprintf("/accounts/%d", account.ID),
+			key:    readSecret,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAPIKey(store, readKey)
+				store.EXPECT().
+					UpdateAPIKeyLastUsed(gomock.Any(), gomock.Eq(readKey.ID)).
+					Times(1).
+					Return(nil)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchAccount(t, recorder.Body, account)
+			},
+		},
+		{
+			name:   "FORBIDDEN - Missing Scope",
+			method: http.MethodGet,
+			url:    fmt.Sprintf("/accounts/%d", account.ID),
+			key:    transferSecret,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAPIKey(store, transferKey)
+				store.EXPECT().
+					UpdateAPIKeyLastUsed(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:   "FORBIDDEN - Route Without Scope",
+			method: http.MethodGet,
+			url:    "/users/me/api_keys",
+			key:    readSecret,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAPIKey(store, readKey)
+				store.EXPECT().
+					UpdateAPIKeyLastUsed(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+				store.EXPECT().
+					ListAPIKeys(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:   "OK - Last Used Not Recorded",
+			method: http.MethodGet,
+			url:    fmt.Sprintf("/accounts/%d", account.ID),
+			key:    readSecret,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAPIKey(store, readKey)
+				store.EXPECT().
+					UpdateAPIKeyLastUsed(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(sql.ErrConnDone)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "UNAUTHORIZED - Unknown Key",
+			method: http.MethodGet,
+			url:    fmt.Sprintf("/accounts/%d", account.ID),
+			key:    API_KEY_PREFIX + util.RandomString(43),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAPIKeyByHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ApiKey{}, sql.ErrNoRows)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:   "UNAUTHORIZED - Revoked Key",
+			method: http.MethodGet,
+			url:    fmt.Sprintf("/accounts/%d", account.ID),
+			key:    revokedSecret,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAPIKey(store, revokedKey)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Contains(t, recorder.Body.String(), ErrRevokedAPIKey.Error())
+			},
+		},
+		{
+			name:   "UNAUTHORIZED - Expired Key",
+			method: http.MethodGet,
+			url:    fmt.Sprintf("/accounts/%d", account.ID),
+			key:    expiredSecret,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAPIKey(store, expiredKey)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:   "INTERNAL_SERVER_ERROR",
+			method: http.MethodGet,
+			url:    fmt.Sprintf("/accounts/%d", account.ID),
+			key:    readSecret,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAPIKeyByHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ApiKey{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(tc.method, tc.url, nil)
+			require.NoError(t, err)
+
+			addAPIKeyAuthorization(request, tc.key)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestCreateAPIKeyAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"name":            "bookkeeping",
+				"scopes":          []string{token.SCOPE_WRITE_TRANSFERS, token.SCOPE_READ_ACCOUNTS, token.SCOPE_READ_ACCOUNTS},
+				"expires_in_days": 30,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAPIKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateAPIKeyParams) (db.ApiKey, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.Equal(t, "bookkeeping", arg.Name)
+						require.Equal(t, "read:accounts write:transfers", arg.Scopes)
+						require.True(t, strings.HasPrefix(arg.KeyPrefix, API_KEY_PREFIX))
+						require.WithinDuration(t, time.Now().AddDate(0, 0, 30), arg.ExpiredAt, time.Minute)
+
+						return db.ApiKey{
+							ID:        1,
+							Username:  arg.Username,
+							Name:      arg.Name,
+							KeyPrefix: arg.KeyPrefix,
+							KeyHash:   arg.KeyHash,
+							Scopes:    arg.Scopes,
+							ExpiredAt: arg.ExpiredAt,
+							CreatedAt: time.Now(),
+						}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.NotContains(t, recorder.Body.String(), "key_hash")
+
+				var response createAPIKeyResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.True(t, strings.HasPrefix(response.Key, response.APIKey.KeyPrefix))
+				require.Equal(t, []string{token.SCOPE_READ_ACCOUNTS, token.SCOPE_WRITE_TRANSFERS}, response.APIKey.Scopes)
+			},
+		},
+		{
+			name: "BAD_REQUEST - Unknown Scope",
+			body: gin.H{
+				"name":            "bookkeeping",
+				"scopes":          []string{"admin"},
+				"expires_in_days": 30,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAPIKey(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "BAD_REQUEST - No Scopes",
+			body: gin.H{
+				"name":            "bookkeeping",
+				"scopes":          []string{},
+				"expires_in_days": 30,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAPIKey(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "BAD_REQUEST - Expiry Too Long",
+			body: gin.H{
+				"name":            "bookkeeping",
+				"scopes":          []string{token.SCOPE_READ_ACCOUNTS},
+				"expires_in_days": 366,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAPIKey(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "INTERNAL_SERVER_ERROR",
+			body: gin.H{
+				"name":            "bookkeeping",
+				"scopes":          []string{token.SCOPE_READ_ACCOUNTS},
+				"expires_in_days": 30,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAPIKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ApiKey{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := "/users/me/api_keys"
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, AUTHORIZATION_TYPE_BEARER, user.Username, DURATION)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListAPIKeysAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	apiKey, _ := randomAPIKey(t, user.Username, token.SCOPE_READ_ACCOUNTS)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		ListAPIKeys(gomock.Any(), gomock.Eq(user.Username)).
+		Times(1).
+		Return([]db.ApiKey{apiKey}, nil)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/users/me/api_keys", nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, AUTHORIZATION_TYPE_BEARER, user.Username, DURATION)
+	server.router.ServeHTTP(recorder, request)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.NotContains(t, recorder.Body.String(), apiKey.KeyHash)
+
+	var response []apiKeyResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	require.Len(t, response, 1)
+	require.Equal(t, apiKey.ID, response[0].ID)
+	require.Equal(t, apiKey.KeyPrefix, response[0].KeyPrefix)
+	require.Equal(t, []string{token.SCOPE_READ_ACCOUNTS}, response[0].Scopes)
+}
+
+func TestRevokeAPIKeyAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	apiKey, _ := randomAPIKey(t, user.Username, token.SCOPE_READ_ACCOUNTS)
+
+	testCases := []struct {
+		name          string
+		apiKeyID      int64
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "NO_CONTENT",
+			apiKeyID: apiKey.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					RevokeAPIKey(gomock.Any(), gomock.Eq(db.RevokeAPIKeyParams{
+						ID:       apiKey.ID,
+						Username: user.Username,
+					})).
+					Times(1).
+					Return(apiKey, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name:     "NOT_FOUND",
+			apiKeyID: apiKey.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					RevokeAPIKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ApiKey{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:     "BAD_REQUEST - Invalid ID",
+			apiKeyID: 0,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					RevokeAPIKey(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/users/me/api_keys/%d", tc.apiKeyID)
+			request, err := http.NewRequest(http.MethodDelete, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, AUTHORIZATION_TYPE_BEARER, user.Username, DURATION)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	db "github.com/VL-037/go-bank/db/sqlc"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+type getAccountBalanceRequest struct {
+	At time.Time `form:"at" binding:"required"`
+}
+
+type accountBalanceResponse struct {
+	AccountID int64     `json:"account_id"`
+	Number    string    `json:"number"`
+	Currency  string    `json:"currency"`
+	At        time.Time `json:"at"`
+	Balance   int64     `json:"balance"`
+}
+
+// getAccountBalance returns the balance an account had at a point in time
+func (server *Server) getAccountBalance(ctx *gin.Context) {
+	account, valid := server.uriAccount(ctx)
+	if !valid {
+		return
+	}
+
+	var req getAccountBalanceRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.At.After(time.Now()) {
+		err := errors.New("at must not be in the future")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	balance, err := server.balanceAt(ctx, account, req.At)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, accountBalanceResponse{
+		AccountID: account.ID,
+		Number:    account.Number,
+		Currency:  account.Currency,
+		At:        req.At,
+		Balance:   balance,
+	})
+}
+
+// balanceAt computes the balance of an account at a point in time from the latest snapshot taken before it
+// and the entries posted since the snapshot. Without a snapshot, the entries posted since that point in time
+// are taken off the current balance
+func (server *Server) balanceAt(ctx *gin.Context, account db.Account, at time.Time) (int64, error) {
+	snapshot, err := server.store.GetLatestBalanceSnapshot(ctx, db.GetLatestBalanceSnapshotParams{
+		AccountID:  account.ID,
+		BeforeDate: at.UTC().Truncate(24 * time.Hour),
+	})
+	if err != nil {
+		if err != sql.ErrNoRows {
+			return 0, err
+		}
+
+		sinceAt, err := server.store.SumAccountEntriesSince(ctx, db.SumAccountEntriesSinceParams{
+			AccountID: account.ID,
+			Since:     at,
+		})
+		if err != nil {
+			return 0, err
+		}
+		return account.Balance - sinceAt, nil
+	}
+
+	// a snapshot holds the balance at the end of its date
+	sinceSnapshot, err := server.store.SumAccountEntriesBetween(ctx, db.SumAccountEntriesBetweenParams{
+		AccountID: account.ID,
+		FromTime:  snapshot.SnapshotDate.AddDate(0, 0, 1),
+		ToTime:    at,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return snapshot.Balance + sinceSnapshot, nil
+}
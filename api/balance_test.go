@@ -0,0 +1,182 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	mockdb "github.com/VL-037/go-bank/db/mock"
+	db "github.com/VL-037/go-bank/db/sqlc"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestGetAccountBalanceAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	account.Balance = 15000
+
+	otherUser, _ := randomUser(t)
+	otherAccount := randomAccount(otherUser.Username)
+
+	at := time.Date(2023, time.March, 5, 10, 30, 0, 0, time.UTC)
+	snapshot := db.AccountBalanceSnapshot{
+		ID:           1,
+		AccountID:    account.ID,
+		SnapshotDate: time.Date(2023, time.March, 3, 0, 0, 0, 0, time.UTC),
+		Balance:      8000,
+	}
+
+	testCases := []struct {
+		name          string
+		accountID     int64
+		at            string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK - From Snapshot",
+			accountID: account.ID,
+			at:        at.Format(time.RFC3339),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					GetLatestBalanceSnapshot(gomock.Any(), gomock.Eq(db.GetLatestBalanceSnapshotParams{
+						AccountID:  account.ID,
+						BeforeDate: time.Date(2023, time.March, 5, 0, 0, 0, 0, time.UTC),
+					})).
+					Times(1).
+					Return(snapshot, nil)
+				store.EXPECT().
+					SumAccountEntriesBetween(gomock.Any(), gomock.Eq(db.SumAccountEntriesBetweenParams{
+						AccountID: account.ID,
+						FromTime:  time.Date(2023, time.March, 4, 0, 0, 0, 0, time.UTC),
+						ToTime:    at,
+					})).
+					Times(1).
+					Return(int64(-500), nil)
+				store.EXPECT().SumAccountEntriesSince(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchBalance(t, recorder.Body, account, 7500)
+			},
+		},
+		{
+			name:      "OK - Without Snapshot",
+			accountID: account.ID,
+			at:        at.Format(time.RFC3339),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					GetLatestBalanceSnapshot(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AccountBalanceSnapshot{}, sql.ErrNoRows)
+				store.EXPECT().
+					SumAccountEntriesSince(gomock.Any(), gomock.Eq(db.SumAccountEntriesSinceParams{
+						AccountID: account.ID,
+						Since:     at,
+					})).
+					Times(1).
+					Return(int64(4000), nil)
+				store.EXPECT().SumAccountEntriesBetween(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchBalance(t, recorder.Body, account, 11000)
+			},
+		},
+		{
+			name:      "UNAUTHORIZED - Other User's Account",
+			accountID: otherAccount.ID,
+			at:        at.Format(time.RFC3339),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(otherAccount.ID)).Times(1).Return(otherAccount, nil)
+				store.EXPECT().GetLatestBalanceSnapshot(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:      "BAD_REQUEST - Invalid At",
+			accountID: account.ID,
+			at:        "2023-03-05",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(1).Return(account, nil)
+				store.EXPECT().GetLatestBalanceSnapshot(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "BAD_REQUEST - Future At",
+			accountID: account.ID,
+			at:        time.Now().Add(time.Hour).Format(time.RFC3339),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(1).Return(account, nil)
+				store.EXPECT().GetLatestBalanceSnapshot(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "INTERNAL_SERVER_ERROR",
+			accountID: account.ID,
+			at:        at.Format(time.RFC3339),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(1).Return(account, nil)
+				store.EXPECT().
+					GetLatestBalanceSnapshot(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AccountBalanceSnapshot{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/%d/balance?at=%s", tc.accountID, url.QueryEscape(tc.at))
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, AUTHORIZATION_TYPE_BEARER, user.Username, DURATION)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func requireBodyMatchBalance(t *testing.T, body *bytes.Buffer, account db.Account, balance int64) {
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+
+	var gotBalance accountBalanceResponse
+	err = json.Unmarshal(data, &gotBalance)
+	require.NoError(t, err)
+
+	require.Equal(t, account.ID, gotBalance.AccountID)
+	require.Equal(t, account.Number, gotBalance.Number)
+	require.Equal(t, balance, gotBalance.Balance)
+}
@@ -0,0 +1,237 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	db "github.com/VL-037/go-bank/db/sqlc"
+	"github.com/VL-037/go-bank/token"
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	"net/http"
+	"time"
+)
+
+var (
+	ErrBeneficiaryRequired   = errors.New("account must be saved as a beneficiary to receive this amount")
+	ErrBeneficiaryCoolingOff = errors.New("beneficiary was added recently and cannot receive this amount yet")
+)
+
+// createBeneficiaryRequest addresses the saved payee the same way as a transfer does
+type createBeneficiaryRequest struct {
+	Nickname      string `json:"nickname" binding:"required,max=64"`
+	AccountID     int64  `json:"account_id" binding:"omitempty,min=1"`
+	AccountNumber string `json:"account_number" binding:"omitempty,account_number"`
+	Username      string `json:"username" binding:"omitempty,alphanum"`
+	Email         string `json:"email" binding:"omitempty,email"`
+	Currency      string `json:"currency" binding:"required,currency"`
+}
+
+func (server *Server) createBeneficiary(ctx *gin.Context) {
+	var req createBeneficiaryRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, valid := server.payeeAccount(ctx, payee{
+		AccountID:     req.AccountID,
+		AccountNumber: req.AccountNumber,
+		Username:      req.Username,
+		Email:         req.Email,
+	}, req.Currency)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD_KEY).(*token.Payload)
+	if account.Owner == authPayload.Username {
+		err := errors.New("cannot add your own account as a beneficiary")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg := db.CreateBeneficiaryParams{
+		Owner:     authPayload.Username,
+		Nickname:  req.Nickname,
+		AccountID: account.ID,
+		Currency:  account.Currency,
+	}
+
+	beneficiary, err := server.store.CreateBeneficiary(ctx, arg)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code.Name() {
+			case "foreign_key_violation", "unique_violation":
+				ctx.JSON(http.StatusForbidden, errorResponse(err))
+				return
+			}
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, beneficiary)
+}
+
+type listBeneficiaryRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+func (server *Server) listBeneficiaries(ctx *gin.Context) {
+	var req listBeneficiaryRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD_KEY).(*token.Payload)
+	arg := db.ListBeneficiariesParams{
+		Owner:  authPayload.Username,
+		Limit:  req.PageSize,
+		Offset: (req.PageID - 1) * req.PageSize,
+	}
+
+	beneficiaries, err := server.store.ListBeneficiaries(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, beneficiaries)
+}
+
+type beneficiaryRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) getBeneficiary(ctx *gin.Context) {
+	var req beneficiaryRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	beneficiary, valid := server.ownedBeneficiary(ctx, req.ID)
+	if !valid {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, beneficiary)
+}
+
+type updateBeneficiaryRequest struct {
+	Nickname string `json:"nickname" binding:"required,max=64"`
+}
+
+func (server *Server) updateBeneficiary(ctx *gin.Context) {
+	var uri beneficiaryRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req updateBeneficiaryRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, valid := server.ownedBeneficiary(ctx, uri.ID); !valid {
+		return
+	}
+
+	beneficiary, err := server.store.UpdateBeneficiary(ctx, db.UpdateBeneficiaryParams{
+		ID:       uri.ID,
+		Nickname: req.Nickname,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, beneficiary)
+}
+
+func (server *Server) deleteBeneficiary(ctx *gin.Context) {
+	var req beneficiaryRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, valid := server.ownedBeneficiary(ctx, req.ID); !valid {
+		return
+	}
+
+	err := server.store.DeleteBeneficiary(ctx, req.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// ownedBeneficiary loads a beneficiary and makes sure it was saved by the authenticated user
+func (server *Server) ownedBeneficiary(ctx *gin.Context, id int64) (db.Beneficiary, bool) {
+	beneficiary, err := server.store.GetBeneficiary(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return beneficiary, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return beneficiary, false
+	}
+
+	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD_KEY).(*token.Payload)
+	if authPayload.Username != beneficiary.Owner {
+		err := errors.New("beneficiary doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return beneficiary, false
+	}
+
+	return beneficiary, true
+}
+
+// validCoolingOff rejects amounts above the cooling-off threshold to an account the user has not saved as a
+// beneficiary, or saved too recently, whichever way the account is addressed. The user's own accounts are exempt
+func (server *Server) validCoolingOff(ctx *gin.Context, owner string, toAccount db.Account, amount int64) bool {
+	err := server.checkCoolingOff(ctx, owner, toAccount, amount)
+	if err != nil {
+		if err == ErrBeneficiaryRequired || err == ErrBeneficiaryCoolingOff {
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+
+	return true
+}
+
+// checkCoolingOff is validCoolingOff without the response, for callers that report several destinations at once
+func (server *Server) checkCoolingOff(ctx *gin.Context, owner string, toAccount db.Account, amount int64) error {
+	threshold, ok := server.coolingOffThresholds[toAccount.Currency]
+	if !ok || amount <= threshold || toAccount.Owner == owner {
+		return nil
+	}
+
+	beneficiary, err := server.store.GetBeneficiaryByAccount(ctx, db.GetBeneficiaryByAccountParams{
+		Owner:     owner,
+		AccountID: toAccount.ID,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrBeneficiaryRequired
+		}
+		return err
+	}
+
+	if time.Since(beneficiary.CreatedAt) < server.config.BeneficiaryCoolingOffPeriod {
+		return ErrBeneficiaryCoolingOff
+	}
+
+	return nil
+}
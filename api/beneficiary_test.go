@@ -0,0 +1,579 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	mockdb "github.com/VL-037/go-bank/db/mock"
+	db "github.com/VL-037/go-bank/db/sqlc"
+	"github.com/VL-037/go-bank/util"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCreateBeneficiaryAPI(t *testing.T) {
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+
+	account1 := randomAccount(user1.Username)
+	account2 := randomAccount(user2.Username)
+	account1.Currency = util.USD
+	account2.Currency = util.USD
+
+	beneficiary := randomBeneficiary(user1.Username, account2)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"nickname": beneficiary.Nickname,
+				"username": user2.Username,
+				"currency": util.USD,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccountByOwner(gomock.Any(), gomock.Eq(db.GetAccountByOwnerParams{Owner: user2.Username, Currency: util.USD, Type: util.CHECKING_ACCOUNT_TYPE})).
+					Times(1).
+					Return(account2, nil)
+				store.EXPECT().
+					CreateBeneficiary(gomock.Any(), gomock.Eq(db.CreateBeneficiaryParams{
+						Owner:     user1.Username,
+						Nickname:  beneficiary.Nickname,
+						AccountID: account2.ID,
+						Currency:  util.USD,
+					})).
+					Times(1).
+					Return(beneficiary, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchBeneficiary(t, recorder.Body, beneficiary)
+			},
+		},
+		{
+			name: "BAD_REQUEST - Own Account",
+			body: gin.H{
+				"nickname":   "me",
+				"account_id": account1.ID,
+				"currency":   util.USD,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().CreateBeneficiary(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "BAD_REQUEST - Missing Nickname",
+			body: gin.H{
+				"username": user2.Username,
+				"currency": util.USD,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateBeneficiary(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/beneficiaries", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, AUTHORIZATION_TYPE_BEARER, user1.Username, DURATION)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestManageBeneficiaryAPI(t *testing.T) {
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+
+	beneficiary := randomBeneficiary(user1.Username, randomAccount(user2.Username))
+
+	testCases := []struct {
+		name          string
+		method        string
+		body          gin.H
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK - Get",
+			method:   http.MethodGet,
+			username: user1.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetBeneficiary(gomock.Any(), gomock.Eq(beneficiary.ID)).Times(1).Return(beneficiary, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchBeneficiary(t, recorder.Body, beneficiary)
+			},
+		},
+		{
+			name:     "OK - Update",
+			method:   http.MethodPatch,
+			body:     gin.H{"nickname": "landlord"},
+			username: user1.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetBeneficiary(gomock.Any(), gomock.Eq(beneficiary.ID)).Times(1).Return(beneficiary, nil)
+				store.EXPECT().
+					UpdateBeneficiary(gomock.Any(), gomock.Eq(db.UpdateBeneficiaryParams{ID: beneficiary.ID, Nickname: "landlord"})).
+					Times(1).
+					Return(beneficiary, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "OK - Delete",
+			method:   http.MethodDelete,
+			username: user1.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetBeneficiary(gomock.Any(), gomock.Eq(beneficiary.ID)).Times(1).Return(beneficiary, nil)
+				store.EXPECT().DeleteBeneficiary(gomock.Any(), gomock.Eq(beneficiary.ID)).Times(1).Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name:     "UNAUTHORIZED - Delete",
+			method:   http.MethodDelete,
+			username: user2.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetBeneficiary(gomock.Any(), gomock.Eq(beneficiary.ID)).Times(1).Return(beneficiary, nil)
+				store.EXPECT().DeleteBeneficiary(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "NOT_FOUND",
+			method:   http.MethodGet,
+			username: user1.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetBeneficiary(gomock.Any(), gomock.Eq(beneficiary.ID)).Times(1).Return(db.Beneficiary{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			var body io.Reader
+			if tc.body != nil {
+				data, err := json.Marshal(tc.body)
+				require.NoError(t, err)
+				body = bytes.NewReader(data)
+			}
+
+			url := fmt.Sprintf("/beneficiaries/%d", beneficiary.ID)
+			request, err := http.NewRequest(tc.method, url, body)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, AUTHORIZATION_TYPE_BEARER, tc.username, DURATION)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestCreateTransferToBeneficiaryAPI(t *testing.T) {
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+
+	account1 := randomAccount(user1.Username)
+	account2 := randomAccount(user2.Username)
+	ownAccount := randomAccount(user1.Username)
+	account1.Currency = util.USD
+	account2.Currency = util.USD
+	ownAccount.Currency = util.USD
+
+	beneficiary := randomBeneficiary(user1.Username, account2)
+	newBeneficiary := beneficiary
+	newBeneficiary.CreatedAt = time.Now()
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"beneficiary_id":  beneficiary.ID,
+				"amount":          500,
+				"currency":        util.USD,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetBeneficiary(gomock.Any(), gomock.Eq(beneficiary.ID)).Times(1).Return(beneficiary, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().
+					GetBeneficiaryByAccount(gomock.Any(), gomock.Eq(db.GetBeneficiaryByAccountParams{Owner: user1.Username, AccountID: account2.ID})).
+					Times(1).
+					Return(beneficiary, nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Eq(db.TransferTxParams{
+						FromAccountID: account1.ID,
+						ToAccountID:   account2.ID,
+						Amount:        500,
+					})).
+					Times(1)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "OK - Small Amount In Cooling-Off",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"beneficiary_id":  beneficiary.ID,
+				"amount":          100,
+				"currency":        util.USD,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetBeneficiary(gomock.Any(), gomock.Eq(beneficiary.ID)).Times(1).Return(newBeneficiary, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().GetBeneficiaryByAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "FORBIDDEN - Large Amount In Cooling-Off",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"beneficiary_id":  beneficiary.ID,
+				"amount":          500,
+				"currency":        util.USD,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetBeneficiary(gomock.Any(), gomock.Eq(beneficiary.ID)).Times(1).Return(newBeneficiary, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().GetBeneficiaryByAccount(gomock.Any(), gomock.Any()).Times(1).Return(newBeneficiary, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "FORBIDDEN - Large Amount In Cooling-Off By Account Number",
+			body: gin.H{
+				"from_account_id":   account1.ID,
+				"to_account_number": account2.Number,
+				"amount":            500,
+				"currency":          util.USD,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account2.Number)).Times(1).Return(account2, nil)
+				store.EXPECT().
+					GetBeneficiaryByAccount(gomock.Any(), gomock.Eq(db.GetBeneficiaryByAccountParams{Owner: user1.Username, AccountID: account2.ID})).
+					Times(1).
+					Return(newBeneficiary, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "FORBIDDEN - Large Amount To Unsaved Account",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          500,
+				"currency":        util.USD,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().GetBeneficiaryByAccount(gomock.Any(), gomock.Any()).Times(1).Return(db.Beneficiary{}, sql.ErrNoRows)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				require.Contains(t, recorder.Body.String(), ErrBeneficiaryRequired.Error())
+			},
+		},
+		{
+			name: "OK - Large Amount To Own Account",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   ownAccount.ID,
+				"amount":          500,
+				"currency":        util.USD,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(ownAccount.ID)).Times(1).Return(ownAccount, nil)
+				store.EXPECT().GetBeneficiaryByAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "BAD_REQUEST - Beneficiary And Account",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"beneficiary_id":  beneficiary.ID,
+				"amount":          500,
+				"currency":        util.USD,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetBeneficiary(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			server.config.BeneficiaryCoolingOffPeriod = 24 * time.Hour
+			server.coolingOffThresholds = map[string]int64{util.USD: 200}
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/transfer", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, AUTHORIZATION_TYPE_BEARER, user1.Username, DURATION)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestCoolingOffAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	otherUser, _ := randomUser(t)
+
+	fromAccount := randomAccount(user.Username)
+	toAccount := randomAccount(otherUser.Username)
+	fromAccount.Currency = util.USD
+	toAccount.Currency = util.USD
+
+	newBeneficiary := randomBeneficiary(user.Username, toAccount)
+	newBeneficiary.CreatedAt = time.Now()
+
+	testCases := []struct {
+		name       string
+		url        string
+		body       interface{}
+		buildStubs func(store *mockdb.MockStore)
+	}{
+		{
+			name: "Scheduled Transfer",
+			url:  "/scheduled_transfers",
+			body: createScheduledTransferRequest{
+				FromAccountID: fromAccount.ID,
+				ToAccountID:   toAccount.ID,
+				Amount:        500,
+				Currency:      util.USD,
+				ExecuteAt:     time.Now().Add(time.Hour),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().CreateScheduledTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name: "Standing Order",
+			url:  "/standing_orders",
+			body: createStandingOrderRequest{
+				FromAccountID: fromAccount.ID,
+				ToAccountID:   toAccount.ID,
+				Amount:        500,
+				Currency:      util.USD,
+				Frequency:     util.DAILY,
+				StartAt:       time.Now().Add(time.Hour),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().CreateStandingOrder(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name: "Hold",
+			url:  "/holds",
+			body: createHoldRequest{
+				FromAccountID: fromAccount.ID,
+				ToAccountID:   toAccount.ID,
+				Amount:        500,
+				Currency:      util.USD,
+				ExpiresAt:     time.Now().Add(time.Hour),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().CreateHoldTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name: "Payment Request Accept",
+			url:  "/payment_requests/1/accept",
+			body: acceptPaymentRequestRequest{
+				FromAccountID: fromAccount.ID,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(int64(1))).Times(1).Return(db.PaymentRequest{
+					ID:          1,
+					Requester:   otherUser.Username,
+					Payer:       user.Username,
+					ToAccountID: toAccount.ID,
+					Amount:      500,
+					Currency:    util.USD,
+					Status:      db.PAYMENT_REQUEST_STATUS_PENDING,
+					ExpiresAt:   time.Now().Add(time.Hour),
+				}, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().AcceptPaymentRequestTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name: "Transfer Batch Destination Total",
+			url:  "/transfer_batches",
+			body: createTransferBatchRequest{
+				FromAccountID: fromAccount.ID,
+				Currency:      util.USD,
+				Mode:          db.TRANSFER_BATCH_MODE_ATOMIC,
+				Items: []transferBatchItemRequest{
+					{ToAccountID: toAccount.ID, Amount: 150},
+					{ToAccountID: toAccount.ID, Amount: 150},
+				},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccountsByIDs(gomock.Any(), gomock.Any()).Times(1).Return([]db.Account{toAccount}, nil)
+				store.EXPECT().TransferBatchTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+			store.EXPECT().
+				GetBeneficiaryByAccount(gomock.Any(), gomock.Eq(db.GetBeneficiaryByAccountParams{Owner: user.Username, AccountID: toAccount.ID})).
+				Times(1).
+				Return(newBeneficiary, nil)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			server.config.BeneficiaryCoolingOffPeriod = 24 * time.Hour
+			server.coolingOffThresholds = map[string]int64{util.USD: 200}
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, tc.url, bytes.NewReader(data))
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", "application/json")
+
+			addAuthorization(t, request, server.tokenMaker, AUTHORIZATION_TYPE_BEARER, user.Username, DURATION)
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusForbidden, recorder.Code)
+			require.Contains(t, recorder.Body.String(), ErrBeneficiaryCoolingOff.Error())
+		})
+	}
+}
+
+func randomBeneficiary(owner string, account db.Account) db.Beneficiary {
+	return db.Beneficiary{
+		ID:        util.RandomInt(1, 1000),
+		Owner:     owner,
+		Nickname:  util.RandomOwner(),
+		AccountID: account.ID,
+		Currency:  account.Currency,
+		CreatedAt: time.Now().Add(-48 * time.Hour).UTC().Truncate(time.Second),
+		UpdatedAt: time.Now().Add(-48 * time.Hour).UTC().Truncate(time.Second),
+	}
+}
+
+func requireBodyMatchBeneficiary(t *testing.T, body *bytes.Buffer, beneficiary db.Beneficiary) {
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+
+	var gotBeneficiary db.Beneficiary
+	err = json.Unmarshal(data, &gotBeneficiary)
+	require.NoError(t, err)
+	require.Equal(t, beneficiary, gotBeneficiary)
+}
@@ -0,0 +1,138 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	db "github.com/VL-037/go-bank/db/sqlc"
+	"github.com/VL-037/go-bank/mail"
+	"github.com/VL-037/go-bank/util"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const EMAIL_VERIFIED_KEY = "email_verified"
+
+var (
+	ErrEmailNotVerified     = errors.New("email address is not verified")
+	ErrEmailAlreadyVerified = errors.New("email address is already verified")
+)
+
+// verifiedEmailMiddleware rejects users who have not verified their email address yet,
+// it must run after authMiddleware
+func verifiedEmailMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !ctx.GetBool(EMAIL_VERIFIED_KEY) {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(ErrEmailNotVerified))
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// sendVerificationEmail mails a link verifying the current email address of the user,
+// only the hash of the token in the link is stored
+func (server *Server) sendVerificationEmail(ctx *gin.Context, user db.User) error {
+	secret, err := util.NewSecureToken()
+	if err != nil {
+		return err
+	}
+
+	emailVerification, err := server.store.CreateEmailVerification(ctx, db.CreateEmailVerificationParams{
+		Username:  user.Username,
+		Email:     user.Email,
+		TokenHash: util.HashToken(secret),
+		ExpiredAt: time.Now().Add(server.config.EmailVerificationDuration),
+	})
+	if err != nil {
+		return err
+	}
+
+	link, err := tokenLink(server.config.EmailVerificationURL, emailVerification.ID, secret)
+	if err != nil {
+		return err
+	}
+
+	return server.mailer.Send(mail.Message{
+		To:      []string{user.Email},
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hello %s,\n\nplease verify your email address by opening the link below:\n\n%s\n\n"+
+			"The link expires at %s.\n",
+			user.FullName, link, emailVerification.ExpiredAt.UTC().Format(time.RFC1123)),
+	})
+}
+
+// tokenLink adds the id and the secret of a one-time token to the link mailed to a user
+func tokenLink(rawURL string, id int64, secret string) (string, error) {
+	link, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid link url: %w", err)
+	}
+
+	query := link.Query()
+	query.Set("id", strconv.FormatInt(id, 10))
+	query.Set("token", secret)
+	link.RawQuery = query.Encode()
+
+	return link.String(), nil
+}
+
+// trySendVerificationEmail only logs failures, the request succeeds regardless of the mail
+// and the user can ask for a new link when it does not arrive
+func (server *Server) trySendVerificationEmail(ctx *gin.Context, user db.User) {
+	if err := server.sendVerificationEmail(ctx, user); err != nil {
+		log.Printf("cannot send verification email to %s: %v", user.Username, err)
+	}
+}
+
+type verifyEmailRequest struct {
+	ID    int64  `form:"id" binding:"required,min=1"`
+	Token string `form:"token" binding:"required"`
+}
+
+func (server *Server) verifyEmail(ctx *gin.Context) {
+	var req verifyEmailRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	response, err := server.store.VerifyEmailTx(ctx, db.VerifyEmailTxParams{
+		ID:        req.ID,
+		TokenHash: util.HashToken(req.Token),
+	})
+	if err != nil {
+		switch err {
+		case db.ErrInvalidEmailVerification, db.ErrEmailChanged:
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newUserResponse(response.User))
+}
+
+func (server *Server) resendVerificationEmail(ctx *gin.Context) {
+	user, valid := server.authenticatedUser(ctx)
+	if !valid {
+		return
+	}
+
+	if user.IsEmailVerified {
+		ctx.JSON(http.StatusBadRequest, errorResponse(ErrEmailAlreadyVerified))
+		return
+	}
+
+	if err := server.sendVerificationEmail(ctx, user); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.Status(http.StatusAccepted)
+}
@@ -0,0 +1,298 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	mockdb "github.com/VL-037/go-bank/db/mock"
+	db "github.com/VL-037/go-bank/db/sqlc"
+	"github.com/VL-037/go-bank/util"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+type eqCreateEmailVerificationParamsMatcher struct {
+	user db.User
+}
+
+func (e eqCreateEmailVerificationParamsMatcher) Matches(x interface{}) bool {
+	arg, ok := x.(db.CreateEmailVerificationParams)
+	if !ok {
+		return false
+	}
+
+	return arg.Username == e.user.Username &&
+		arg.Email == e.user.Email &&
+		len(arg.TokenHash) == 64 &&
+		arg.ExpiredAt.After(time.Now())
+}
+
+func (e eqCreateEmailVerificationParamsMatcher) String() string {
+	return fmt.Sprintf("matches verification of %v for %v", e.user.Email, e.user.Username)
+}
+
+func EqCreateEmailVerificationParams(user db.User) gomock.Matcher {
+	return eqCreateEmailVerificationParamsMatcher{user}
+}
+
+func TestVerifyEmailAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	user.IsEmailVerified = true
+	secret := util.RandomString(32)
+
+	testCases := []struct {
+		name          string
+		query         url.Values
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			query: url.Values{"id": {"1"}, "token": {secret}},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.VerifyEmailTxParams{
+					ID:        1,
+					TokenHash: util.HashToken(secret),
+				}
+				store.EXPECT().
+					VerifyEmailTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.VerifyEmailTxResponse{User: user}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Contains(t, recorder.Body.String(), `"is_email_verified":true`)
+				requireBodyMatchUser(t, recorder.Body, user)
+			},
+		},
+		{
+			name:  "BAD_REQUEST - Invalid Token",
+			query: url.Values{"id": {"1"}, "token": {secret}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					VerifyEmailTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.VerifyEmailTxResponse{}, db.ErrInvalidEmailVerification)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "BAD_REQUEST - Email Changed",
+			query: url.Values{"id": {"1"}, "token": {secret}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					VerifyEmailTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.VerifyEmailTxResponse{}, db.ErrEmailChanged)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "BAD_REQUEST - Missing Token",
+			query: url.Values{"id": {"1"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					VerifyEmailTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "INTERNAL_SERVER_ERROR",
+			query: url.Values{"id": {"1"}, "token": {secret}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					VerifyEmailTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.VerifyEmailTxResponse{}, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/verify_email?"+tc.query.Encode(), nil)
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestResendVerificationEmailAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	verifiedUser := user
+	verifiedUser.IsEmailVerified = true
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder, sender *recordingSender)
+	}{
+		{
+			name: "ACCEPTED",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					CreateEmailVerification(gomock.Any(), EqCreateEmailVerificationParams(user)).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateEmailVerificationParams) (db.EmailVerification, error) {
+						return db.EmailVerification{
+							ID:        7,
+							Username:  arg.Username,
+							Email:     arg.Email,
+							TokenHash: arg.TokenHash,
+							ExpiredAt: arg.ExpiredAt,
+						}, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, sender *recordingSender) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+				require.Len(t, sender.messages, 1)
+				require.Equal(t, []string{user.Email}, sender.messages[0].To)
+				require.Contains(t, sender.messages[0].Body, "http://localhost:8080/verify_email?id=7&token=")
+			},
+		},
+		{
+			name: "BAD_REQUEST - Already Verified",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(verifiedUser, nil)
+				store.EXPECT().
+					CreateEmailVerification(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, sender *recordingSender) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Empty(t, sender.messages)
+			},
+		},
+		{
+			name: "INTERNAL_SERVER_ERROR",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					CreateEmailVerification(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.EmailVerification{}, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, sender *recordingSender) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				require.Empty(t, sender.messages)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			sender := &recordingSender{}
+			server.mailer = sender
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodPost, "/users/me/verify_email", nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, AUTHORIZATION_TYPE_BEARER, user.Username, DURATION)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder, sender)
+		})
+	}
+}
+
+func TestVerifiedEmailMiddleware(t *testing.T) {
+	testCases := []struct {
+		name            string
+		isEmailVerified bool
+		expectedStatus  int
+	}{
+		{
+			name:            "OK",
+			isEmailVerified: true,
+			expectedStatus:  http.StatusOK,
+		},
+		{
+			name:            "FORBIDDEN - Email Not Verified",
+			isEmailVerified: false,
+			expectedStatus:  http.StatusForbidden,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().
+				GetUserAuthState(gomock.Any(), gomock.Eq(USERNAME)).
+				Times(1).
+				Return(db.GetUserAuthStateRow{IsEmailVerified: tc.isEmailVerified}, nil)
+
+			server := newTestServer(t, store)
+
+			path := "/verified"
+			server.router.POST(
+				path,
+				authMiddleware(server.tokenMaker, server.store),
+				verifiedEmailMiddleware(),
+				func(ctx *gin.Context) {
+					ctx.JSON(http.StatusOK, gin.H{})
+				})
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodPost, path, bytes.NewReader(nil))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, AUTHORIZATION_TYPE_BEARER, USERNAME, DURATION)
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, tc.expectedStatus, recorder.Code)
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	db "github.com/VL-037/go-bank/db/sqlc"
+	"github.com/VL-037/go-bank/token"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"time"
+)
+
+type createHoldRequest struct {
+	FromAccountID     int64     `json:"from_account_id" binding:"required_without=FromAccountNumber,excluded_with=FromAccountNumber,omitempty,min=1"`
+	FromAccountNumber string    `json:"from_account_number" binding:"omitempty,account_number"`
+	ToAccountID       int64     `json:"to_account_id" binding:"required_without=ToAccountNumber,excluded_with=ToAccountNumber,omitempty,min=1"`
+	ToAccountNumber   string    `json:"to_account_number" binding:"omitempty,account_number"`
+	Amount            int64     `json:"amount" binding:"required,gt=0"`
+	Currency          string    `json:"currency" binding:"required,currency"`
+	ExpiresAt         time.Time `json:"expires_at" binding:"required"`
+	MFACode           string    `json:"mfa_code" binding:"omitempty,max=32"`
+}
+
+func (server *Server) createHold(ctx *gin.Context) {
+	var req createHoldRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if !req.ExpiresAt.After(time.Now()) {
+		err := errors.New("expires_at must be in the future")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if server.requiresApproval(req.Currency, req.Amount) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(ErrApprovalRequired))
+		return
+	}
+
+	fromAccount, valid := server.validAccountRef(ctx, req.FromAccountID, req.FromAccountNumber, req.Currency)
+	if !valid {
+		return
+	}
+	req.FromAccountID = fromAccount.ID
+
+	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD_KEY).(*token.Payload)
+	if authPayload.Username != fromAccount.Owner {
+		err := errors.New("from account doesn't belong to authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	if !server.validStepUp(ctx, authPayload.Username, req.Currency, req.Amount, req.MFACode) {
+		return
+	}
+
+	toAccount, valid := server.validAccountRef(ctx, req.ToAccountID, req.ToAccountNumber, req.Currency)
+	if !valid {
+		return
+	}
+	req.ToAccountID = toAccount.ID
+
+	if !server.validCoolingOff(ctx, authPayload.Username, toAccount, req.Amount) {
+		return
+	}
+
+	arg := db.CreateHoldTxParams{
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		Currency:      req.Currency,
+		ExpiresAt:     req.ExpiresAt,
+	}
+
+	response, err := server.store.CreateHoldTx(ctx, arg)
+	if err != nil {
+		holdErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+type holdRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) getHold(ctx *gin.Context) {
+	var req holdRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	hold, valid := server.participatingHold(ctx, req.ID)
+	if !valid {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, hold)
+}
+
+type captureHoldRequest struct {
+	Amount  int64  `json:"amount" binding:"omitempty,gt=0"`
+	MFACode string `json:"mfa_code" binding:"omitempty,max=32"`
+}
+
+// captureHold is called by the owner of the to account, usually a merchant
+func (server *Server) captureHold(ctx *gin.Context) {
+	var uri holdRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// the body is optional, an empty one captures the full held amount
+	var req captureHoldRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	hold, err := server.store.GetHold(ctx, uri.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	toAccount, err := server.store.GetAccount(ctx, hold.ToAccountID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD_KEY).(*token.Payload)
+	if authPayload.Username != toAccount.Owner {
+		err := errors.New("to account doesn't belong to authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	amount := req.Amount
+	if amount == 0 {
+		amount = hold.Amount
+	}
+
+	if server.requiresApproval(hold.Currency, amount) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(ErrApprovalRequired))
+		return
+	}
+
+	if !server.validStepUp(ctx, authPayload.Username, hold.Currency, amount, req.MFACode) {
+		return
+	}
+
+	response, err := server.store.CaptureHoldTx(ctx, db.CaptureHoldTxParams{
+		HoldID: hold.ID,
+		Amount: amount,
+	})
+	if err != nil {
+		holdErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// voidHold can be called by the owner of either account of the hold
+func (server *Server) voidHold(ctx *gin.Context) {
+	var req holdRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, valid := server.participatingHold(ctx, req.ID); !valid {
+		return
+	}
+
+	response, err := server.store.VoidHoldTx(ctx, req.ID)
+	if err != nil {
+		holdErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// participatingHold loads a hold and makes sure the authenticated user owns one of its accounts
+func (server *Server) participatingHold(ctx *gin.Context, id int64) (db.Hold, bool) {
+	hold, err := server.store.GetHold(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return hold, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return hold, false
+	}
+
+	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD_KEY).(*token.Payload)
+	for _, accountID := range []int64{hold.FromAccountID, hold.ToAccountID} {
+		account, err := server.store.GetAccount(ctx, accountID)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return hold, false
+		}
+
+		if authPayload.Username == account.Owner {
+			return hold, true
+		}
+	}
+
+	err = errors.New("hold doesn't belong to the authenticated user")
+	ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+	return hold, false
+}
+
+func holdErrorResponse(ctx *gin.Context, err error) {
+	switch err {
+	case sql.ErrNoRows:
+		ctx.JSON(http.StatusNotFound, errorResponse(err))
+	case db.ErrInsufficientFunds, db.ErrHoldNotActive, db.ErrInvalidCapture, db.ErrAccountClosed:
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+	default:
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+	}
+}
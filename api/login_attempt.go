@@ -0,0 +1,88 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"github.com/VL-037/go-bank/util"
+	"github.com/gin-gonic/gin"
+	"math"
+	"net/http"
+	"time"
+)
+
+var (
+	ErrInvalidCredentials   = errors.New("invalid username or password")
+	ErrTooManyLoginAttempts = errors.New("too many failed login attempts, try again later")
+)
+
+// checkDummyPassword spends the time of a password check for unknown usernames,
+// so response times do not reveal which usernames exist
+func (server *Server) checkDummyPassword(password string) {
+	server.dummyPasswordHashOnce.Do(func() {
+		server.dummyPasswordHash, _ = server.passwordHasher.HashPassword(util.RandomString(16))
+	})
+	_ = server.passwordHasher.CheckPassword(server.dummyPasswordHash, password)
+}
+
+// allowLogin rejects the login while the username or the client address is throttled
+func (server *Server) allowLogin(ctx *gin.Context, username string) bool {
+	retryAfter, err := server.loginLimiter.Check(ctx, username, ctx.ClientIP())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+
+	if retryAfter > 0 {
+		ctx.Header("Retry-After", retryAfterSeconds(retryAfter))
+		ctx.JSON(http.StatusTooManyRequests, errorResponse(ErrTooManyLoginAttempts))
+		return false
+	}
+
+	return true
+}
+
+// failLogin counts the failed login and responds with err
+func (server *Server) failLogin(ctx *gin.Context, username string, err error) {
+	if failErr := server.loginLimiter.Fail(ctx, username, ctx.ClientIP()); failErr != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(failErr))
+		return
+	}
+
+	ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+}
+
+type unlockUserRequest struct {
+	Username string `uri:"username" binding:"required,alphanum"`
+}
+
+// unlockUser lets an admin lift the login lockout of a user before it expires
+func (server *Server) unlockUser(ctx *gin.Context) {
+	var req unlockUserRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	admin, valid := server.authenticatedUser(ctx)
+	if !valid {
+		return
+	}
+
+	if admin.Role != util.ADMIN_ROLE {
+		err := errors.New("only admins can unlock users")
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return
+	}
+
+	if err := server.loginLimiter.Unlock(ctx, req.Username); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// retryAfterSeconds formats the Retry-After header, rounding up so clients do not retry too early
+func retryAfterSeconds(retryAfter time.Duration) string {
+	return fmt.Sprint(int64(math.Ceil(retryAfter.Seconds())))
+}
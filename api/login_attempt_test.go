@@ -0,0 +1,240 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	mockdb "github.com/VL-037/go-bank/db/mock"
+	"github.com/VL-037/go-bank/lockout"
+	"github.com/VL-037/go-bank/util"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoginUserLockoutAPI(t *testing.T) {
+	user, password := randomUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	// the third attempt is rejected before the user is looked up
+	store.EXPECT().
+		GetUser(gomock.Any(), gomock.Eq(user.Username)).
+		Times(2).
+		Return(user, nil)
+
+	server := newTestServer(t, store)
+	server.loginLimiter = lockout.NewLimiter(lockout.NewMemoryStore(), lockout.Policy{
+		MaxFailures:     2,
+		LockoutDuration: time.Minute,
+	}, lockout.Policy{})
+
+	login := func(password string) *httptest.ResponseRecorder {
+		data, err := json.Marshal(loginUserRequest{Username: user.Username, Password: password})
+		require.NoError(t, err)
+
+		request, err := http.NewRequest(http.MethodPost, "/users/login", bytes.NewReader(data))
+		require.NoError(t, err)
+
+		recorder := httptest.NewRecorder()
+		server.router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	require.Equal(t, http.StatusUnauthorized, login("WRONG_PASSWORD").Code)
+	require.Equal(t, http.StatusUnauthorized, login("WRONG_PASSWORD").Code)
+
+	recorder := login(password)
+	require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	require.Equal(t, "60", recorder.Header().Get("Retry-After"))
+}
+
+func TestLoginUserForwardedForAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name           string
+		trustedProxies string
+		thirdAttempt   int
+	}{
+		{
+			name:         "Spoofed Header Without Trusted Proxies",
+			thirdAttempt: http.StatusTooManyRequests,
+		},
+		{
+			name:           "Header From Trusted Proxy",
+			trustedProxies: "10.0.0.1",
+			thirdAttempt:   http.StatusUnauthorized,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().
+				GetUser(gomock.Any(), gomock.Eq(user.Username)).
+				AnyTimes().
+				Return(user, nil)
+
+			server := newTestServer(t, store)
+			server.config.TrustedProxies = tc.trustedProxies
+			require.NoError(t, server.setupRouter())
+			server.loginLimiter = lockout.NewLimiter(lockout.NewMemoryStore(), lockout.Policy{}, lockout.Policy{
+				MaxFailures:     2,
+				LockoutDuration: time.Minute,
+			})
+
+			// every attempt claims another client address through the same connection address
+			login := func(forwardedFor string) int {
+				data, err := json.Marshal(loginUserRequest{Username: user.Username, Password: "WRONG_PASSWORD"})
+				require.NoError(t, err)
+
+				request, err := http.NewRequest(http.MethodPost, "/users/login", bytes.NewReader(data))
+				require.NoError(t, err)
+				request.RemoteAddr = "10.0.0.1:4321"
+				request.Header.Set("X-Forwarded-For", forwardedFor)
+
+				recorder := httptest.NewRecorder()
+				server.router.ServeHTTP(recorder, request)
+				return recorder.Code
+			}
+
+			require.Equal(t, http.StatusUnauthorized, login("203.0.113.1"))
+			require.Equal(t, http.StatusUnauthorized, login("203.0.113.2"))
+			require.Equal(t, tc.thirdAttempt, login("203.0.113.3"))
+		})
+	}
+}
+
+func TestLoginUserMFALockoutAPI(t *testing.T) {
+	user, password := randomUser(t)
+	user.TotpSecret = sql.NullString{String: util.RandomString(32), Valid: true}
+	user.IsTotpEnabled = true
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		GetUser(gomock.Any(), gomock.Eq(user.Username)).
+		Times(3).
+		Return(user, nil)
+
+	server := newTestServer(t, store)
+	server.loginLimiter = lockout.NewLimiter(lockout.NewMemoryStore(), lockout.Policy{
+		MaxFailures:     2,
+		LockoutDuration: time.Minute,
+	}, lockout.Policy{})
+
+	login := func(password string) *httptest.ResponseRecorder {
+		data, err := json.Marshal(loginUserRequest{Username: user.Username, Password: password})
+		require.NoError(t, err)
+
+		request, err := http.NewRequest(http.MethodPost, "/users/login", bytes.NewReader(data))
+		require.NoError(t, err)
+
+		recorder := httptest.NewRecorder()
+		server.router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	require.Equal(t, http.StatusUnauthorized, login("WRONG_PASSWORD").Code)
+
+	// the right password alone only gets a challenge, the failures keep counting until the second factor is checked
+	recorder := login(password)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Contains(t, recorder.Body.String(), "challenge_token")
+
+	require.Equal(t, http.StatusUnauthorized, login("WRONG_PASSWORD").Code)
+	require.Equal(t, http.StatusTooManyRequests, login(password).Code)
+}
+
+func TestUnlockUserAPI(t *testing.T) {
+	admin, _ := randomUser(t)
+	admin.Role = util.ADMIN_ROLE
+	depositor, _ := randomUser(t)
+	lockedUsername := util.RandomOwner()
+
+	testCases := []struct {
+		name          string
+		user          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder, limiter *lockout.Limiter)
+	}{
+		{
+			name: "NO_CONTENT",
+			user: admin.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(admin.Username)).
+					Times(1).
+					Return(admin, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, limiter *lockout.Limiter) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+
+				retryAfter, err := limiter.Check(context.Background(), lockedUsername, "10.0.0.1")
+				require.NoError(t, err)
+				require.Zero(t, retryAfter)
+			},
+		},
+		{
+			name: "FORBIDDEN - Not Admin",
+			user: depositor.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(depositor.Username)).
+					Times(1).
+					Return(depositor, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, limiter *lockout.Limiter) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+
+				retryAfter, err := limiter.Check(context.Background(), lockedUsername, "10.0.0.1")
+				require.NoError(t, err)
+				require.Positive(t, retryAfter)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			server.loginLimiter = lockout.NewLimiter(lockout.NewMemoryStore(), lockout.Policy{
+				MaxFailures:     1,
+				LockoutDuration: time.Minute,
+			}, lockout.Policy{})
+			require.NoError(t, server.loginLimiter.Fail(context.Background(), lockedUsername, "10.0.0.1"))
+
+			recorder := httptest.NewRecorder()
+			url := fmt.Sprintf("/admin/users/%s/unlock", lockedUsername)
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, AUTHORIZATION_TYPE_BEARER, tc.user, DURATION)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder, server.loginLimiter)
+		})
+	}
+}
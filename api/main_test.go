@@ -1,10 +1,15 @@
 package api
 
 import (
+	mockdb "github.com/VL-037/go-bank/db/mock"
 	db "github.com/VL-037/go-bank/db/sqlc"
+	"github.com/VL-037/go-bank/lockout"
+	"github.com/VL-037/go-bank/mail"
 	"github.com/VL-037/go-bank/util"
 	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
+	"io"
 	"os"
 	"testing"
 	"time"
@@ -14,13 +19,40 @@ func newTestServer(t *testing.T, store db.Store) *Server {
 	config := util.Config{
 		TokenSymmetricKey:   util.RandomString(32),
 		AccessTokenDuration: time.Minute,
+
+		EmailVerificationURL:      "http://localhost:8080/verify_email",
+		EmailVerificationDuration: time.Hour,
+		PasswordResetURL:          "http://localhost:3000/reset_password",
+		PasswordResetDuration:     time.Hour,
+		LoginAttemptStore:         lockout.STORE_MEMORY,
+
+		OAuthAuthorizationCodeDuration: time.Minute,
+		OAuthRefreshTokenDuration:      time.Hour,
+	}
+
+	// access tokens are valid and emails verified unless the test stubbed otherwise before
+	if mockStore, ok := store.(*mockdb.MockStore); ok {
+		mockStore.EXPECT().
+			GetUserAuthState(gomock.Any(), gomock.Any()).
+			AnyTimes().
+			Return(db.GetUserAuthStateRow{IsEmailVerified: true}, nil)
 	}
 
-	server, err := NewServer(config, store)
+	server, err := NewServer(config, store, mail.NewLogSender(io.Discard, "go-bank <no-reply@go-bank.local>"))
 	require.NoError(t, err)
 	return server
 }
 
+// recordingSender keeps the sent emails for assertions
+type recordingSender struct {
+	messages []mail.Message
+}
+
+func (sender *recordingSender) Send(message mail.Message) error {
+	sender.messages = append(sender.messages, message)
+	return nil
+}
+
 func TestMain(m *testing.M) {
 	gin.SetMode(gin.TestMode)
 	os.Exit(m.Run())
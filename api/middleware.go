@@ -1,8 +1,10 @@
 package api
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
+	db "github.com/VL-037/go-bank/db/sqlc"
 	"github.com/VL-037/go-bank/token"
 	"github.com/gin-gonic/gin"
 	"net/http"
@@ -10,12 +12,37 @@ import (
 )
 
 const (
-	AUTHORIZATION_HEADER_KEY  = "authorization"
-	AUTHORIZATION_TYPE_BEARER = "bearer"
-	AUTHORIZATION_PAYLOAD_KEY = "authorization_payload"
+	AUTHORIZATION_HEADER_KEY   = "authorization"
+	AUTHORIZATION_TYPE_BEARER  = "bearer"
+	AUTHORIZATION_TYPE_API_KEY = "apikey"
+	AUTHORIZATION_PAYLOAD_KEY  = "authorization_payload"
 )
 
-func authMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
+var (
+	ErrInsufficientScope = errors.New("credential does not grant access to this route")
+	ErrClientToken       = errors.New("token does not act on behalf of a user")
+)
+
+// clientRoutes lists the routes open to client credentials tokens, which act for the OAuth client itself
+var clientRoutes = map[string]bool{
+	"GET /oauth/clients/me": true,
+}
+
+// routeScopes lists the routes open to scoped credentials and the scope each one needs,
+// scoped credentials are rejected on every other route
+var routeScopes = map[string]string{
+	"GET /accounts":                token.SCOPE_READ_ACCOUNTS,
+	"GET /accounts/:id":            token.SCOPE_READ_ACCOUNTS,
+	"GET /accounts/:id/statements": token.SCOPE_READ_ACCOUNTS,
+	"GET /accounts/:id/exports":    token.SCOPE_READ_ACCOUNTS,
+	"GET /accounts/:id/balance":    token.SCOPE_READ_ACCOUNTS,
+	"GET /transfers":               token.SCOPE_READ_ACCOUNTS,
+	"GET /transfer_batches/:id":    token.SCOPE_READ_ACCOUNTS,
+	"POST /transfer":               token.SCOPE_WRITE_TRANSFERS,
+	"POST /transfer_batches":       token.SCOPE_WRITE_TRANSFERS,
+}
+
+func authMiddleware(tokenMaker token.Maker, store db.Store) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		authorizationHeader := ctx.GetHeader(AUTHORIZATION_HEADER_KEY)
 		if len(authorizationHeader) == 0 {
@@ -32,20 +59,78 @@ func authMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
 		}
 
 		authorizationType := strings.ToLower(fields[0])
-		if authorizationType != AUTHORIZATION_TYPE_BEARER {
+		var payload *token.Payload
+		switch authorizationType {
+		case AUTHORIZATION_TYPE_BEARER:
+			var err error
+			payload, err = tokenMaker.VerifyToken(fields[1])
+			if err != nil {
+				ctx.AbortWithStatusJSON(http.StatusUnauthorized, err)
+				return
+			}
+
+			if payload.Purpose != token.PURPOSE_ACCESS {
+				ctx.AbortWithStatusJSON(http.StatusUnauthorized, token.ErrInvalidToken)
+				return
+			}
+
+			// client credentials tokens belong to no user, so they are only accepted on the client's own routes
+			if payload.Username == "" {
+				if payload.ClientID == "" || !clientRoutes[ctx.Request.Method+" "+ctx.FullPath()] {
+					ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(ErrClientToken))
+					return
+				}
+
+				ctx.Set(AUTHORIZATION_PAYLOAD_KEY, payload)
+				ctx.Next()
+				return
+			}
+		case AUTHORIZATION_TYPE_API_KEY:
+			var valid bool
+			payload, valid = apiKeyPayload(ctx, store, fields[1])
+			if !valid {
+				return
+			}
+		default:
 			err := fmt.Errorf("unsupported authorization type %s", authorizationType)
 			ctx.AbortWithStatusJSON(http.StatusUnauthorized, err)
 			return
 		}
 
-		accessToken := fields[1]
-		payload, err := tokenMaker.VerifyToken(accessToken)
+		authState, err := store.GetUserAuthState(ctx, payload.Username)
 		if err != nil {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, err)
+			if err == sql.ErrNoRows {
+				ctx.AbortWithStatusJSON(http.StatusUnauthorized, err)
+				return
+			}
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, err)
+			return
+		}
+
+		if authorizationType == AUTHORIZATION_TYPE_BEARER && payload.IssuedAt.Before(authState.TokensValidAfter) {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, token.ErrRevokedToken)
+			return
+		}
+
+		// tokens of OAuth clients stop working as soon as the user revokes the consent
+		if payload.ClientID != "" {
+			if err := checkOAuthConsent(ctx, store, payload); err != nil {
+				if err == token.ErrRevokedToken {
+					ctx.AbortWithStatusJSON(http.StatusUnauthorized, err)
+					return
+				}
+				ctx.AbortWithStatusJSON(http.StatusInternalServerError, err)
+				return
+			}
+		}
+
+		if scope, ok := routeScopes[ctx.Request.Method+" "+ctx.FullPath()]; payload.Scoped() && (!ok || !payload.HasScope(scope)) {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(ErrInsufficientScope))
 			return
 		}
 
 		ctx.Set(AUTHORIZATION_PAYLOAD_KEY, payload)
+		ctx.Set(EMAIL_VERIFIED_KEY, authState.IsEmailVerified)
 		ctx.Next()
 	}
 }
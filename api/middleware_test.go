@@ -1,9 +1,13 @@
 package api
 
 import (
+	"database/sql"
 	"fmt"
+	mockdb "github.com/VL-037/go-bank/db/mock"
+	db "github.com/VL-037/go-bank/db/sqlc"
 	"github.com/VL-037/go-bank/token"
 	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 	"net/http"
 	"net/http/httptest"
@@ -32,6 +36,7 @@ func TestAuthMiddleware(t *testing.T) {
 	testCases := []struct{
 		name string
 		setupAuth func(t *testing.T, req *http.Request, tokenMaker token.Maker)
+		buildStubs func(store *mockdb.MockStore)
 		checkResposne func(t *testing.T, recorder *httptest.ResponseRecorder)
 	}{
 		{
@@ -77,18 +82,67 @@ func TestAuthMiddleware(t *testing.T) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
 		},
+		{
+			name: "UNAUTHORIZED - MFA Challenge Token",
+			setupAuth: func(t *testing.T, req *http.Request, tokenMaker token.Maker) {
+				challengeToken, err := tokenMaker.CreatePurposeToken(USERNAME, token.PURPOSE_MFA_CHALLENGE, DURATION)
+				require.NoError(t, err)
+				req.Header.Set(AUTHORIZATION_HEADER_KEY, fmt.Sprintf("%s %s", AUTHORIZATION_TYPE_BEARER, challengeToken))
+			},
+			checkResposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "UNAUTHORIZED - Revoked Token",
+			setupAuth: func(t *testing.T, req *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, req, tokenMaker, AUTHORIZATION_TYPE_BEARER, USERNAME, DURATION)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserAuthState(gomock.Any(), gomock.Eq(USERNAME)).
+					Times(1).
+					Return(db.GetUserAuthStateRow{TokensValidAfter: time.Now().Add(time.Minute)}, nil)
+			},
+			checkResposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "UNAUTHORIZED - User Not Found",
+			setupAuth: func(t *testing.T, req *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, req, tokenMaker, AUTHORIZATION_TYPE_BEARER, USERNAME, DURATION)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserAuthState(gomock.Any(), gomock.Eq(USERNAME)).
+					Times(1).
+					Return(db.GetUserAuthStateRow{}, sql.ErrNoRows)
+			},
+			checkResposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
 	}
 
 	for i := range testCases {
 		tc := testCases[i]
 
 		t.Run(tc.name, func(t *testing.T) {
-			server := newTestServer(t, nil)
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			if tc.buildStubs != nil {
+				tc.buildStubs(store)
+			}
+
+			server := newTestServer(t, store)
 
 			authPath := "/auth"
 			server.router.GET(
 				authPath,
-				authMiddleware(server.tokenMaker),
+				authMiddleware(server.tokenMaker, server.store),
 				func(ctx *gin.Context) {
 					ctx.JSON(http.StatusOK, gin.H{})
 				})
@@ -99,6 +153,7 @@ func TestAuthMiddleware(t *testing.T) {
 
 			tc.setupAuth(t, request, server.tokenMaker)
 			server.router.ServeHTTP(recorder, request)
+			tc.checkResposne(t, recorder)
 		})
 	}
 }
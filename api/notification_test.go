@@ -0,0 +1,110 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	mockdb "github.com/VL-037/go-bank/db/mock"
+	db "github.com/VL-037/go-bank/db/sqlc"
+	"github.com/VL-037/go-bank/token"
+	"github.com/VL-037/go-bank/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestListNotificationsAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	n := 5
+	notifications := make([]db.Notification, n)
+	for i := 0; i < n; i++ {
+		notifications[i] = db.Notification{
+			ID:       int64(i + 1),
+			Username: user.Username,
+			Message:  util.RandomString(20),
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		setupAuth     func(t *testing.T, req *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			setupAuth: func(t *testing.T, req *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, req, tokenMaker, AUTHORIZATION_TYPE_BEARER, user.Username, DURATION)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListNotificationsParams{
+					Username: user.Username,
+					Limit:    int32(n),
+					Offset:   0,
+				}
+				store.EXPECT().
+					ListNotifications(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(notifications, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got []db.Notification
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, notifications, got)
+			},
+		},
+		{
+			name:      "UNAUTHORIZED",
+			setupAuth: func(t *testing.T, req *http.Request, tokenMaker token.Maker) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListNotifications(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "INTERNAL_SERVER_ERROR",
+			setupAuth: func(t *testing.T, req *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, req, tokenMaker, AUTHORIZATION_TYPE_BEARER, user.Username, DURATION)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListNotifications(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Notification{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/notifications?page_id=1&page_size=5", nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,629 @@
+package api
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	db "github.com/VL-037/go-bank/db/sqlc"
+	"github.com/VL-037/go-bank/token"
+	"github.com/VL-037/go-bank/util"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	GRANT_TYPE_AUTHORIZATION_CODE = "authorization_code"
+	GRANT_TYPE_CLIENT_CREDENTIALS = "client_credentials"
+	GRANT_TYPE_REFRESH_TOKEN      = "refresh_token"
+)
+
+// Types of the tokens handed out and introspected
+const (
+	OAUTH_TOKEN_TYPE_BEARER  = "Bearer"
+	OAUTH_TOKEN_TYPE_REFRESH = "refresh_token"
+)
+
+// Error codes of the token endpoint, see RFC 6749 section 5.2
+const (
+	OAUTH_INVALID_REQUEST        = "invalid_request"
+	OAUTH_INVALID_CLIENT         = "invalid_client"
+	OAUTH_INVALID_GRANT          = "invalid_grant"
+	OAUTH_UNAUTHORIZED_CLIENT    = "unauthorized_client"
+	OAUTH_UNSUPPORTED_GRANT_TYPE = "unsupported_grant_type"
+	OAUTH_INVALID_SCOPE          = "invalid_scope"
+)
+
+// PKCE code verifiers are 43 to 128 characters long, see RFC 7636 section 4.1
+const (
+	PKCE_CODE_VERIFIER_MIN_LENGTH = 43
+	PKCE_CODE_VERIFIER_MAX_LENGTH = 128
+)
+
+var (
+	ErrInvalidRedirectURI   = errors.New("redirect uri is not registered for the client")
+	ErrInvalidOAuthScope    = errors.New("scope is not allowed for the client")
+	ErrInvalidCodeVerifier  = errors.New("code verifier does not match the code challenge")
+	ErrUnsupportedGrantType = errors.New("grant type is not supported")
+	ErrPublicOAuthClient    = errors.New("public clients cannot use the client credentials grant")
+)
+
+func oauthErrorResponse(code string, err error) gin.H {
+	return gin.H{"error": code, "error_description": err.Error()}
+}
+
+type authorizeOAuthClientRequest struct {
+	ResponseType        string `json:"response_type" binding:"required,oneof=code"`
+	ClientID            string `json:"client_id" binding:"required"`
+	RedirectURI         string `json:"redirect_uri" binding:"required,url"`
+	Scope               string `json:"scope"`
+	State               string `json:"state" binding:"max=512"`
+	CodeChallenge       string `json:"code_challenge" binding:"required,len=43"`
+	CodeChallengeMethod string `json:"code_challenge_method" binding:"required,oneof=S256"`
+}
+
+type authorizeOAuthClientResponse struct {
+	RedirectTo string `json:"redirect_to"`
+}
+
+// authorizeOAuthClient records the consent of the authenticated user to a client and issues an authorization code.
+// The consent screen calls it once the user approved, then sends the browser to the returned redirect
+func (server *Server) authorizeOAuthClient(ctx *gin.Context) {
+	var req authorizeOAuthClientRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	client, err := server.store.GetOAuthClient(ctx, req.ClientID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusBadRequest, errorResponse(ErrInvalidOAuthClient))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if client.IsRevoked {
+		ctx.JSON(http.StatusBadRequest, errorResponse(ErrInvalidOAuthClient))
+		return
+	}
+
+	if !containsField(client.RedirectUris, req.RedirectURI) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(ErrInvalidRedirectURI))
+		return
+	}
+
+	scopes, err := grantedScopes(req.Scope, client.Scopes)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	redirectTo, err := url.Parse(req.RedirectURI)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	code, err := util.NewSecureToken()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD_KEY).(*token.Payload)
+	_, err = server.store.UpsertOAuthConsentTx(ctx, db.UpsertOAuthConsentParams{
+		Username: authPayload.Username,
+		ClientID: client.ClientID,
+		Scopes:   scopes,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	_, err = server.store.CreateOAuthAuthorizationCode(ctx, db.CreateOAuthAuthorizationCodeParams{
+		CodeHash:      util.HashToken(code),
+		ClientID:      client.ClientID,
+		Username:      authPayload.Username,
+		RedirectUri:   req.RedirectURI,
+		Scopes:        scopes,
+		CodeChallenge: req.CodeChallenge,
+		ExpiredAt:     time.Now().Add(server.config.OAuthAuthorizationCodeDuration),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	query := redirectTo.Query()
+	query.Set("code", code)
+	if req.State != "" {
+		query.Set("state", req.State)
+	}
+	redirectTo.RawQuery = query.Encode()
+
+	ctx.JSON(http.StatusOK, authorizeOAuthClientResponse{RedirectTo: redirectTo.String()})
+}
+
+type createOAuthTokenRequest struct {
+	GrantType    string `form:"grant_type" binding:"required"`
+	Code         string `form:"code"`
+	RedirectURI  string `form:"redirect_uri"`
+	CodeVerifier string `form:"code_verifier"`
+	RefreshToken string `form:"refresh_token"`
+	Scope        string `form:"scope"`
+	ClientID     string `form:"client_id"`
+	ClientSecret string `form:"client_secret"`
+}
+
+type oauthTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope"`
+}
+
+// createOAuthToken is the token endpoint of RFC 6749, it takes form encoded requests
+// and answers errors in the OAuth format
+func (server *Server) createOAuthToken(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Header("Pragma", "no-cache")
+
+	var req createOAuthTokenRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, oauthErrorResponse(OAUTH_INVALID_REQUEST, err))
+		return
+	}
+
+	client, valid := server.authenticateOAuthClient(ctx, req.ClientID, req.ClientSecret)
+	if !valid {
+		return
+	}
+
+	switch req.GrantType {
+	case GRANT_TYPE_AUTHORIZATION_CODE:
+		server.exchangeOAuthCode(ctx, client, req)
+	case GRANT_TYPE_CLIENT_CREDENTIALS:
+		server.issueClientCredentialsToken(ctx, client, req)
+	case GRANT_TYPE_REFRESH_TOKEN:
+		server.rotateOAuthRefreshToken(ctx, client, req)
+	default:
+		ctx.JSON(http.StatusBadRequest, oauthErrorResponse(OAUTH_UNSUPPORTED_GRANT_TYPE, ErrUnsupportedGrantType))
+	}
+}
+
+func (server *Server) exchangeOAuthCode(ctx *gin.Context, client db.OauthClient, req createOAuthTokenRequest) {
+	if req.Code == "" || req.RedirectURI == "" || req.CodeVerifier == "" {
+		err := errors.New("code, redirect_uri and code_verifier are required")
+		ctx.JSON(http.StatusBadRequest, oauthErrorResponse(OAUTH_INVALID_REQUEST, err))
+		return
+	}
+
+	code, err := server.store.GetOAuthAuthorizationCodeByHash(ctx, util.HashToken(req.Code))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusBadRequest, oauthErrorResponse(OAUTH_INVALID_GRANT, db.ErrOAuthGrantUsed))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if code.ClientID != client.ClientID {
+		ctx.JSON(http.StatusBadRequest, oauthErrorResponse(OAUTH_INVALID_GRANT, db.ErrOAuthGrantUsed))
+		return
+	}
+
+	// a code used twice may have been stolen, so the tokens issued from it are revoked as well
+	if code.UsedAt.Valid {
+		server.revokeOAuthRefreshTokens(ctx, code.Username, code.ClientID)
+		ctx.JSON(http.StatusBadRequest, oauthErrorResponse(OAUTH_INVALID_GRANT, db.ErrOAuthGrantUsed))
+		return
+	}
+
+	if time.Now().After(code.ExpiredAt) {
+		ctx.JSON(http.StatusBadRequest, oauthErrorResponse(OAUTH_INVALID_GRANT, db.ErrOAuthGrantUsed))
+		return
+	}
+
+	if req.RedirectURI != code.RedirectUri {
+		ctx.JSON(http.StatusBadRequest, oauthErrorResponse(OAUTH_INVALID_GRANT, ErrInvalidRedirectURI))
+		return
+	}
+
+	if !validCodeVerifier(req.CodeVerifier, code.CodeChallenge) {
+		ctx.JSON(http.StatusBadRequest, oauthErrorResponse(OAUTH_INVALID_GRANT, ErrInvalidCodeVerifier))
+		return
+	}
+
+	refreshToken, err := util.NewSecureToken()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	result, err := server.store.ExchangeOAuthCodeTx(ctx, db.ExchangeOAuthCodeTxParams{
+		CodeID:                code.ID,
+		RefreshTokenHash:      util.HashToken(refreshToken),
+		RefreshTokenExpiredAt: time.Now().Add(server.config.OAuthRefreshTokenDuration),
+	})
+	if err != nil {
+		switch err {
+		case db.ErrOAuthGrantUsed, db.ErrOAuthConsentRevoked, db.ErrOAuthGrantRevoked:
+			ctx.JSON(http.StatusBadRequest, oauthErrorResponse(OAUTH_INVALID_GRANT, err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	server.issueOAuthToken(ctx, result.RefreshToken.Username, client.ClientID, strings.Fields(result.RefreshToken.Scopes), refreshToken)
+}
+
+// issueClientCredentialsToken lets a confidential client act on its own behalf, the token belongs to no user
+// and no refresh token is issued as the client can always authenticate again
+func (server *Server) issueClientCredentialsToken(ctx *gin.Context, client db.OauthClient, req createOAuthTokenRequest) {
+	if !client.SecretHash.Valid {
+		ctx.JSON(http.StatusBadRequest, oauthErrorResponse(OAUTH_UNAUTHORIZED_CLIENT, ErrPublicOAuthClient))
+		return
+	}
+
+	scopes, err := grantedScopes(req.Scope, client.Scopes)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, oauthErrorResponse(OAUTH_INVALID_SCOPE, err))
+		return
+	}
+
+	server.issueOAuthToken(ctx, "", client.ClientID, strings.Fields(scopes), "")
+}
+
+func (server *Server) rotateOAuthRefreshToken(ctx *gin.Context, client db.OauthClient, req createOAuthTokenRequest) {
+	if req.RefreshToken == "" {
+		err := errors.New("refresh_token is required")
+		ctx.JSON(http.StatusBadRequest, oauthErrorResponse(OAUTH_INVALID_REQUEST, err))
+		return
+	}
+
+	refreshToken, err := server.store.GetOAuthRefreshTokenByHash(ctx, util.HashToken(req.RefreshToken))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusBadRequest, oauthErrorResponse(OAUTH_INVALID_GRANT, db.ErrOAuthGrantUsed))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if refreshToken.ClientID != client.ClientID {
+		ctx.JSON(http.StatusBadRequest, oauthErrorResponse(OAUTH_INVALID_GRANT, db.ErrOAuthGrantUsed))
+		return
+	}
+
+	// a rotated token presented again was copied, either the client or an attacker holds a stale copy
+	// so every refresh token of the grant is revoked
+	if refreshToken.RotatedAt.Valid {
+		server.revokeOAuthRefreshTokens(ctx, refreshToken.Username, refreshToken.ClientID)
+		ctx.JSON(http.StatusBadRequest, oauthErrorResponse(OAUTH_INVALID_GRANT, db.ErrOAuthGrantUsed))
+		return
+	}
+
+	if refreshToken.IsRevoked || time.Now().After(refreshToken.ExpiredAt) {
+		ctx.JSON(http.StatusBadRequest, oauthErrorResponse(OAUTH_INVALID_GRANT, db.ErrOAuthGrantUsed))
+		return
+	}
+
+	// the access token may be narrowed to fewer scopes, the refresh token keeps those of the grant
+	scopes, err := grantedScopes(req.Scope, refreshToken.Scopes)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, oauthErrorResponse(OAUTH_INVALID_SCOPE, err))
+		return
+	}
+
+	newRefreshToken, err := util.NewSecureToken()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	result, err := server.store.RotateOAuthRefreshTokenTx(ctx, db.RotateOAuthRefreshTokenTxParams{
+		ID:        refreshToken.ID,
+		TokenHash: util.HashToken(newRefreshToken),
+		ExpiredAt: time.Now().Add(server.config.OAuthRefreshTokenDuration),
+	})
+	if err != nil {
+		switch err {
+		case db.ErrOAuthGrantUsed:
+			// another request rotated the same token first
+			server.revokeOAuthRefreshTokens(ctx, refreshToken.Username, refreshToken.ClientID)
+			ctx.JSON(http.StatusBadRequest, oauthErrorResponse(OAUTH_INVALID_GRANT, err))
+			return
+		case db.ErrOAuthConsentRevoked, db.ErrOAuthGrantRevoked:
+			ctx.JSON(http.StatusBadRequest, oauthErrorResponse(OAUTH_INVALID_GRANT, err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	server.issueOAuthToken(ctx, result.RefreshToken.Username, client.ClientID, strings.Fields(scopes), newRefreshToken)
+}
+
+func (server *Server) issueOAuthToken(ctx *gin.Context, username string, clientID string, scopes []string, refreshToken string) {
+	accessToken, err := server.tokenMaker.CreateScopedToken(username, clientID, scopes, server.config.AccessTokenDuration)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, oauthTokenResponse{
+		AccessToken:  accessToken,
+		TokenType:    OAUTH_TOKEN_TYPE_BEARER,
+		ExpiresIn:    int64(server.config.AccessTokenDuration.Seconds()),
+		RefreshToken: refreshToken,
+		Scope:        strings.Join(scopes, " "),
+	})
+}
+
+func (server *Server) revokeOAuthRefreshTokens(ctx *gin.Context, username string, clientID string) {
+	err := server.store.RevokeOAuthRefreshTokens(ctx, db.RevokeOAuthRefreshTokensParams{
+		Username: username,
+		ClientID: clientID,
+	})
+	if err != nil {
+		log.Printf("cannot revoke refresh tokens of client %s for %s: %v", clientID, username, err)
+	}
+}
+
+type introspectOAuthTokenRequest struct {
+	Token string `form:"token" binding:"required"`
+	// TokenTypeHint is accepted as RFC 7662 allows, both token types are always looked up
+	TokenTypeHint string `form:"token_type_hint"`
+	ClientID      string `form:"client_id"`
+	ClientSecret  string `form:"client_secret"`
+}
+
+type introspectOAuthTokenResponse struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientID  string `json:"client_id,omitempty"`
+	Username  string `json:"username,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+	IssuedAt  int64  `json:"iat,omitempty"`
+}
+
+// introspectOAuthToken describes an access or refresh token as in RFC 7662. Clients may only introspect
+// their own tokens, any other token is reported as inactive
+func (server *Server) introspectOAuthToken(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "no-store")
+
+	var req introspectOAuthTokenRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, oauthErrorResponse(OAUTH_INVALID_REQUEST, err))
+		return
+	}
+
+	client, valid := server.authenticateOAuthClient(ctx, req.ClientID, req.ClientSecret)
+	if !valid {
+		return
+	}
+
+	payload, err := server.tokenMaker.VerifyToken(req.Token)
+	if err == nil {
+		if payload.ClientID != client.ClientID {
+			ctx.JSON(http.StatusOK, introspectOAuthTokenResponse{Active: false})
+			return
+		}
+
+		active, err := server.activeOAuthAccessToken(ctx, payload)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		if !active {
+			ctx.JSON(http.StatusOK, introspectOAuthTokenResponse{Active: false})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, introspectOAuthTokenResponse{
+			Active:    true,
+			Scope:     strings.Join(payload.Scopes, " "),
+			ClientID:  payload.ClientID,
+			Username:  payload.Username,
+			TokenType: OAUTH_TOKEN_TYPE_BEARER,
+			ExpiresAt: payload.ExpiredAt.Unix(),
+			IssuedAt:  payload.IssuedAt.Unix(),
+		})
+		return
+	}
+
+	refreshToken, err := server.store.GetOAuthRefreshTokenByHash(ctx, util.HashToken(req.Token))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusOK, introspectOAuthTokenResponse{Active: false})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if refreshToken.ClientID != client.ClientID || refreshToken.IsRevoked || refreshToken.RotatedAt.Valid ||
+		time.Now().After(refreshToken.ExpiredAt) {
+		ctx.JSON(http.StatusOK, introspectOAuthTokenResponse{Active: false})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, introspectOAuthTokenResponse{
+		Active:    true,
+		Scope:     refreshToken.Scopes,
+		ClientID:  refreshToken.ClientID,
+		Username:  refreshToken.Username,
+		TokenType: OAUTH_TOKEN_TYPE_REFRESH,
+		ExpiresAt: refreshToken.ExpiredAt.Unix(),
+		IssuedAt:  refreshToken.CreatedAt.Unix(),
+	})
+}
+
+// activeOAuthAccessToken applies the checks of authMiddleware to a verified token of an OAuth client
+func (server *Server) activeOAuthAccessToken(ctx *gin.Context, payload *token.Payload) (bool, error) {
+	if payload.Purpose != token.PURPOSE_ACCESS || payload.ClientID == "" {
+		return false, nil
+	}
+
+	// client credentials tokens belong to no user
+	if payload.Username == "" {
+		return true, nil
+	}
+
+	authState, err := server.store.GetUserAuthState(ctx, payload.Username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if payload.IssuedAt.Before(authState.TokensValidAfter) {
+		return false, nil
+	}
+
+	err = checkOAuthConsent(ctx, server.store, payload)
+	if err == token.ErrRevokedToken {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// checkOAuthConsent rejects access tokens of a client whose consent was revoked or given with other scopes
+// since they were issued, and of a client which was revoked altogether
+func checkOAuthConsent(ctx *gin.Context, store db.Store, payload *token.Payload) error {
+	consent, err := store.GetOAuthConsent(ctx, db.GetOAuthConsentParams{
+		Username: payload.Username,
+		ClientID: payload.ClientID,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return token.ErrRevokedToken
+		}
+		return err
+	}
+
+	if consent.IsRevoked || payload.IssuedAt.Before(consent.GrantedAt) {
+		return token.ErrRevokedToken
+	}
+
+	client, err := store.GetOAuthClient(ctx, payload.ClientID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return token.ErrRevokedToken
+		}
+		return err
+	}
+
+	if client.IsRevoked {
+		return token.ErrRevokedToken
+	}
+	return nil
+}
+
+type oauthConsentResponse struct {
+	ClientID  string    `json:"client_id"`
+	Scopes    []string  `json:"scopes"`
+	GrantedAt time.Time `json:"granted_at"`
+}
+
+func (server *Server) listOAuthConsents(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD_KEY).(*token.Payload)
+
+	consents, err := server.store.ListOAuthConsents(ctx, authPayload.Username)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	response := make([]oauthConsentResponse, len(consents))
+	for i, consent := range consents {
+		response[i] = oauthConsentResponse{
+			ClientID:  consent.ClientID,
+			Scopes:    strings.Fields(consent.Scopes),
+			GrantedAt: consent.GrantedAt,
+		}
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+type revokeOAuthConsentRequest struct {
+	ClientID string `uri:"client_id" binding:"required"`
+}
+
+// revokeOAuthConsent withdraws access of a client, its refresh and access tokens stop working at once
+func (server *Server) revokeOAuthConsent(ctx *gin.Context) {
+	var req revokeOAuthConsentRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD_KEY).(*token.Payload)
+	_, err := server.store.RevokeOAuthConsentTx(ctx, db.RevokeOAuthConsentParams{
+		Username: authPayload.Username,
+		ClientID: req.ClientID,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// grantedScopes checks the space separated requested scopes against the allowed ones and returns them
+// as stored, no requested scope grants every allowed scope
+func grantedScopes(requested string, allowed string) (string, error) {
+	scopes := strings.Fields(requested)
+	if len(scopes) == 0 {
+		return allowed, nil
+	}
+
+	for _, scope := range scopes {
+		if !containsField(allowed, scope) {
+			return "", ErrInvalidOAuthScope
+		}
+	}
+	return joinScopes(scopes), nil
+}
+
+// containsField reports whether the space separated list holds the value
+func containsField(list string, value string) bool {
+	for _, field := range strings.Fields(list) {
+		if field == value {
+			return true
+		}
+	}
+	return false
+}
+
+// validCodeVerifier checks a PKCE code verifier against its S256 code challenge
+func validCodeVerifier(codeVerifier string, codeChallenge string) bool {
+	if len(codeVerifier) < PKCE_CODE_VERIFIER_MIN_LENGTH || len(codeVerifier) > PKCE_CODE_VERIFIER_MAX_LENGTH {
+		return false
+	}
+
+	sum := sha256.Sum256([]byte(codeVerifier))
+	challenge := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(challenge), []byte(codeChallenge)) == 1
+}
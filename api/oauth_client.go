@@ -0,0 +1,174 @@
+package api
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	db "github.com/VL-037/go-bank/db/sqlc"
+	"github.com/VL-037/go-bank/token"
+	"github.com/VL-037/go-bank/util"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidOAuthClient = errors.New("client is unknown, revoked or failed to authenticate")
+	ErrNotClientToken     = errors.New("route is only open to client credentials tokens")
+)
+
+type oauthClientResponse struct {
+	ClientID     string    `json:"client_id"`
+	Name         string    `json:"name"`
+	RedirectURIs []string  `json:"redirect_uris"`
+	Scopes       []string  `json:"scopes"`
+	Confidential bool      `json:"confidential"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func newOAuthClientResponse(client db.OauthClient) oauthClientResponse {
+	return oauthClientResponse{
+		ClientID:     client.ClientID,
+		Name:         client.Name,
+		RedirectURIs: strings.Fields(client.RedirectUris),
+		Scopes:       strings.Fields(client.Scopes),
+		Confidential: client.SecretHash.Valid,
+		CreatedAt:    client.CreatedAt,
+	}
+}
+
+type registerOAuthClientRequest struct {
+	Name         string   `json:"name" binding:"required,max=64"`
+	RedirectURIs []string `json:"redirect_uris" binding:"required,min=1,dive,url"`
+	Scopes       []string `json:"scopes" binding:"required,min=1,dive,oneof=read:accounts write:transfers"`
+	// Confidential clients get a secret, public clients such as mobile apps authenticate with PKCE alone
+	Confidential bool `json:"confidential"`
+}
+
+type registerOAuthClientResponse struct {
+	ClientSecret string              `json:"client_secret,omitempty"`
+	Client       oauthClientResponse `json:"client"`
+}
+
+// registerOAuthClient lets an admin onboard a partner app, the client secret is only shown in this response
+func (server *Server) registerOAuthClient(ctx *gin.Context) {
+	var req registerOAuthClientRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	for _, redirectURI := range req.RedirectURIs {
+		// redirect URIs are stored space separated
+		if strings.ContainsAny(redirectURI, " \t\n") {
+			err := errors.New("redirect uris cannot contain whitespace")
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+	}
+
+	admin, valid := server.authenticatedUser(ctx)
+	if !valid {
+		return
+	}
+
+	if admin.Role != util.ADMIN_ROLE {
+		err := errors.New("only admins can register oauth clients")
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return
+	}
+
+	var clientSecret string
+	var secretHash sql.NullString
+	if req.Confidential {
+		var err error
+		clientSecret, err = util.NewSecureToken()
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		secretHash = sql.NullString{String: util.HashToken(clientSecret), Valid: true}
+	}
+
+	client, err := server.store.CreateOAuthClient(ctx, db.CreateOAuthClientParams{
+		ClientID:     uuid.NewString(),
+		SecretHash:   secretHash,
+		Name:         req.Name,
+		RedirectUris: strings.Join(req.RedirectURIs, " "),
+		Scopes:       joinScopes(req.Scopes),
+		CreatedBy:    sql.NullString{String: admin.Username, Valid: true},
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, registerOAuthClientResponse{
+		ClientSecret: clientSecret,
+		Client:       newOAuthClientResponse(client),
+	})
+}
+
+// getOAuthClient lets a client holding a client credentials token read its own registration
+func (server *Server) getOAuthClient(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD_KEY).(*token.Payload)
+	if authPayload.Username != "" || authPayload.ClientID == "" {
+		ctx.JSON(http.StatusForbidden, errorResponse(ErrNotClientToken))
+		return
+	}
+
+	client, err := server.store.GetOAuthClient(ctx, authPayload.ClientID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusUnauthorized, errorResponse(ErrInvalidOAuthClient))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if client.IsRevoked {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(ErrInvalidOAuthClient))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newOAuthClientResponse(client))
+}
+
+// authenticateOAuthClient identifies the client by HTTP basic authentication or by the client_id and
+// client_secret form fields, confidential clients must present their secret
+func (server *Server) authenticateOAuthClient(ctx *gin.Context, clientID string, clientSecret string) (db.OauthClient, bool) {
+	if id, secret, ok := ctx.Request.BasicAuth(); ok {
+		clientID, clientSecret = id, secret
+	}
+
+	if clientID == "" {
+		ctx.JSON(http.StatusUnauthorized, oauthErrorResponse(OAUTH_INVALID_CLIENT, ErrInvalidOAuthClient))
+		return db.OauthClient{}, false
+	}
+
+	client, err := server.store.GetOAuthClient(ctx, clientID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusUnauthorized, oauthErrorResponse(OAUTH_INVALID_CLIENT, ErrInvalidOAuthClient))
+			return client, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return client, false
+	}
+
+	if client.IsRevoked {
+		ctx.JSON(http.StatusUnauthorized, oauthErrorResponse(OAUTH_INVALID_CLIENT, ErrInvalidOAuthClient))
+		return client, false
+	}
+
+	if client.SecretHash.Valid &&
+		subtle.ConstantTimeCompare([]byte(client.SecretHash.String), []byte(util.HashToken(clientSecret))) != 1 {
+		ctx.JSON(http.StatusUnauthorized, oauthErrorResponse(OAUTH_INVALID_CLIENT, ErrInvalidOAuthClient))
+		return client, false
+	}
+
+	return client, true
+}
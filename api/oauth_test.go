@@ -0,0 +1,1485 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	mockdb "github.com/VL-037/go-bank/db/mock"
+	db "github.com/VL-037/go-bank/db/sqlc"
+	"github.com/VL-037/go-bank/token"
+	"github.com/VL-037/go-bank/util"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+const OAUTH_REDIRECT_URI = "https://partner.example.com/callback"
+
+func randomOAuthClient(t *testing.T, confidential bool) (client db.OauthClient, secret string) {
+	client = db.OauthClient{
+		ID:           util.RandomInt(1, 1000),
+		ClientID:     util.RandomString(16),
+		Name:         util.RandomOwner(),
+		RedirectUris: OAUTH_REDIRECT_URI,
+		Scopes:       joinScopes([]string{token.SCOPE_READ_ACCOUNTS, token.SCOPE_WRITE_TRANSFERS}),
+		CreatedAt:    time.Now(),
+	}
+
+	if confidential {
+		var err error
+		secret, err = util.NewSecureToken()
+		require.NoError(t, err)
+		client.SecretHash = sql.NullString{String: util.HashToken(secret), Valid: true}
+	}
+	return
+}
+
+func randomCodeVerifier(t *testing.T) (codeVerifier string, codeChallenge string) {
+	codeVerifier, err := util.NewSecureToken()
+	require.NoError(t, err)
+
+	sum := sha256.Sum256([]byte(codeVerifier))
+	return codeVerifier, base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func randomOAuthConsent(username string, client db.OauthClient) db.OauthConsent {
+	return db.OauthConsent{
+		ID:        util.RandomInt(1, 1000),
+		Username:  username,
+		ClientID:  client.ClientID,
+		Scopes:    client.Scopes,
+		GrantedAt: time.Now().Add(-time.Hour),
+	}
+}
+
+func newOAuthFormRequest(t *testing.T, path string, form url.Values, client db.OauthClient, secret string) *http.Request {
+	request, err := http.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+	require.NoError(t, err)
+
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.SetBasicAuth(client.ClientID, secret)
+	return request
+}
+
+func requireOAuthError(t *testing.T, recorder *httptest.ResponseRecorder, status int, code string) {
+	require.Equal(t, status, recorder.Code)
+
+	var response map[string]string
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	require.Equal(t, code, response["error"])
+	require.NotEmpty(t, response["error_description"])
+}
+
+func TestRegisterOAuthClientAPI(t *testing.T) {
+	admin, _ := randomUser(t)
+	admin.Role = util.ADMIN_ROLE
+	user, _ := randomUser(t)
+
+	body := gin.H{
+		"name":          "Partner App",
+		"redirect_uris": []string{OAUTH_REDIRECT_URI},
+		"scopes":        []string{token.SCOPE_READ_ACCOUNTS},
+		"confidential":  true,
+	}
+
+	testCases := []struct {
+		name          string
+		username      string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK - Confidential",
+			username: admin.Username,
+			body:     body,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(admin.Username)).
+					Times(1).
+					Return(admin, nil)
+				store.EXPECT().
+					CreateOAuthClient(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateOAuthClientParams) (db.OauthClient, error) {
+						require.NotEmpty(t, arg.ClientID)
+						require.True(t, arg.SecretHash.Valid)
+						require.Equal(t, OAUTH_REDIRECT_URI, arg.RedirectUris)
+						require.Equal(t, token.SCOPE_READ_ACCOUNTS, arg.Scopes)
+						require.Equal(t, admin.Username, arg.CreatedBy.String)
+						return db.OauthClient{
+							ClientID:     arg.ClientID,
+							SecretHash:   arg.SecretHash,
+							Name:         arg.Name,
+							RedirectUris: arg.RedirectUris,
+							Scopes:       arg.Scopes,
+						}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response registerOAuthClientResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.NotEmpty(t, response.ClientSecret)
+				require.NotEmpty(t, response.Client.ClientID)
+				require.True(t, response.Client.Confidential)
+				require.Equal(t, []string{OAUTH_REDIRECT_URI}, response.Client.RedirectURIs)
+				require.NotContains(t, recorder.Body.String(), util.HashToken(response.ClientSecret))
+			},
+		},
+		{
+			name:     "OK - Public",
+			username: admin.Username,
+			body: gin.H{
+				"name":          "Partner Mobile App",
+				"redirect_uris": []string{OAUTH_REDIRECT_URI},
+				"scopes":        []string{token.SCOPE_READ_ACCOUNTS},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(admin.Username)).
+					Times(1).
+					Return(admin, nil)
+				store.EXPECT().
+					CreateOAuthClient(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateOAuthClientParams) (db.OauthClient, error) {
+						require.False(t, arg.SecretHash.Valid)
+						return db.OauthClient{ClientID: arg.ClientID, Name: arg.Name}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response registerOAuthClientResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Empty(t, response.ClientSecret)
+				require.False(t, response.Client.Confidential)
+			},
+		},
+		{
+			name:     "FORBIDDEN - Not Admin",
+			username: user.Username,
+			body:     body,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					CreateOAuthClient(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "BAD_REQUEST - Invalid Redirect URI",
+			username: admin.Username,
+			body: gin.H{
+				"name":          "Partner App",
+				"redirect_uris": []string{"not a url"},
+				"scopes":        []string{token.SCOPE_READ_ACCOUNTS},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateOAuthClient(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "BAD_REQUEST - Unknown Scope",
+			username: admin.Username,
+			body: gin.H{
+				"name":          "Partner App",
+				"redirect_uris": []string{OAUTH_REDIRECT_URI},
+				"scopes":        []string{"admin"},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateOAuthClient(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/admin/oauth_clients", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, AUTHORIZATION_TYPE_BEARER, tc.username, DURATION)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestAuthorizeOAuthClientAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	client, _ := randomOAuthClient(t, false)
+	revokedClient, _ := randomOAuthClient(t, false)
+	revokedClient.IsRevoked = true
+	_, codeChallenge := randomCodeVerifier(t)
+
+	body := func(clientID string, redirectURI string, scope string) gin.H {
+		return gin.H{
+			"response_type":         "code",
+			"client_id":             clientID,
+			"redirect_uri":          redirectURI,
+			"scope":                 scope,
+			"state":                 "xyz",
+			"code_challenge":        codeChallenge,
+			"code_challenge_method": "S256",
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: body(client.ClientID, OAUTH_REDIRECT_URI, token.SCOPE_READ_ACCOUNTS),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetOAuthClient(gomock.Any(), gomock.Eq(client.ClientID)).
+					Times(1).
+					Return(client, nil)
+				store.EXPECT().
+					UpsertOAuthConsentTx(gomock.Any(), gomock.Eq(db.UpsertOAuthConsentParams{
+						Username: user.Username,
+						ClientID: client.ClientID,
+						Scopes:   token.SCOPE_READ_ACCOUNTS,
+					})).
+					Times(1).
+					Return(db.UpsertOAuthConsentTxResponse{Consent: randomOAuthConsent(user.Username, client)}, nil)
+				store.EXPECT().
+					CreateOAuthAuthorizationCode(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateOAuthAuthorizationCodeParams) (db.OauthAuthorizationCode, error) {
+						require.Equal(t, client.ClientID, arg.ClientID)
+						require.Equal(t, user.Username, arg.Username)
+						require.Equal(t, OAUTH_REDIRECT_URI, arg.RedirectUri)
+						require.Equal(t, codeChallenge, arg.CodeChallenge)
+						require.True(t, arg.ExpiredAt.After(time.Now()))
+						return db.OauthAuthorizationCode{CodeHash: arg.CodeHash}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response authorizeOAuthClientResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.True(t, strings.HasPrefix(response.RedirectTo, OAUTH_REDIRECT_URI+"?"))
+
+				redirectTo, err := url.Parse(response.RedirectTo)
+				require.NoError(t, err)
+				require.NotEmpty(t, redirectTo.Query().Get("code"))
+				require.Equal(t, "xyz", redirectTo.Query().Get("state"))
+			},
+		},
+		{
+			name: "BAD_REQUEST - Unknown Client",
+			body: body(client.ClientID, OAUTH_REDIRECT_URI, ""),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetOAuthClient(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.OauthClient{}, sql.ErrNoRows)
+				store.EXPECT().
+					UpsertOAuthConsentTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "BAD_REQUEST - Revoked Client",
+			body: body(revokedClient.ClientID, OAUTH_REDIRECT_URI, ""),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetOAuthClient(gomock.Any(), gomock.Eq(revokedClient.ClientID)).
+					Times(1).
+					Return(revokedClient, nil)
+				store.EXPECT().
+					UpsertOAuthConsentTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "BAD_REQUEST - Unregistered Redirect URI",
+			body: body(client.ClientID, "https://attacker.example.com/callback", ""),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetOAuthClient(gomock.Any(), gomock.Eq(client.ClientID)).
+					Times(1).
+					Return(client, nil)
+				store.EXPECT().
+					UpsertOAuthConsentTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Contains(t, recorder.Body.String(), ErrInvalidRedirectURI.Error())
+			},
+		},
+		{
+			name: "BAD_REQUEST - Scope Not Allowed",
+			body: body(client.ClientID, OAUTH_REDIRECT_URI, "read:accounts admin"),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetOAuthClient(gomock.Any(), gomock.Eq(client.ClientID)).
+					Times(1).
+					Return(client, nil)
+				store.EXPECT().
+					UpsertOAuthConsentTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Contains(t, recorder.Body.String(), ErrInvalidOAuthScope.Error())
+			},
+		},
+		{
+			name: "BAD_REQUEST - Plain Code Challenge",
+			body: gin.H{
+				"response_type":         "code",
+				"client_id":             client.ClientID,
+				"redirect_uri":          OAUTH_REDIRECT_URI,
+				"code_challenge":        codeChallenge,
+				"code_challenge_method": "plain",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetOAuthClient(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/oauth/authorize", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, AUTHORIZATION_TYPE_BEARER, user.Username, DURATION)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestOAuthAuthorizationCodeGrantAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	client, secret := randomOAuthClient(t, true)
+	codeVerifier, codeChallenge := randomCodeVerifier(t)
+
+	code, err := util.NewSecureToken()
+	require.NoError(t, err)
+
+	authorizationCode := db.OauthAuthorizationCode{
+		ID:            util.RandomInt(1, 1000),
+		CodeHash:      util.HashToken(code),
+		ClientID:      client.ClientID,
+		Username:      user.Username,
+		RedirectUri:   OAUTH_REDIRECT_URI,
+		Scopes:        token.SCOPE_READ_ACCOUNTS,
+		CodeChallenge: codeChallenge,
+		ExpiredAt:     time.Now().Add(time.Minute),
+	}
+	usedCode := authorizationCode
+	usedCode.UsedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	expiredCode := authorizationCode
+	expiredCode.ExpiredAt = time.Now().Add(-time.Second)
+
+	form := url.Values{
+		"grant_type":    {GRANT_TYPE_AUTHORIZATION_CODE},
+		"code":          {code},
+		"redirect_uri":  {OAUTH_REDIRECT_URI},
+		"code_verifier": {codeVerifier},
+	}
+	withForm := func(key string, value string) url.Values {
+		changed := url.Values{}
+		for k, v := range form {
+			changed[k] = v
+		}
+		changed.Set(key, value)
+		return changed
+	}
+
+	expectClient := func(store *mockdb.MockStore) {
+		store.EXPECT().
+			GetOAuthClient(gomock.Any(), gomock.Eq(client.ClientID)).
+			Times(1).
+			Return(client, nil)
+	}
+	expectCode := func(store *mockdb.MockStore, code db.OauthAuthorizationCode) {
+		store.EXPECT().
+			GetOAuthAuthorizationCodeByHash(gomock.Any(), gomock.Eq(authorizationCode.CodeHash)).
+			Times(1).
+			Return(code, nil)
+	}
+
+	testCases := []struct {
+		name          string
+		form          url.Values
+		secret        string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker)
+	}{
+		{
+			name:   "OK",
+			form:   form,
+			secret: secret,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectClient(store)
+				expectCode(store, authorizationCode)
+				store.EXPECT().
+					ExchangeOAuthCodeTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.ExchangeOAuthCodeTxParams) (db.ExchangeOAuthCodeTxResponse, error) {
+						require.Equal(t, authorizationCode.ID, arg.CodeID)
+						require.NotEmpty(t, arg.RefreshTokenHash)
+						return db.ExchangeOAuthCodeTxResponse{
+							AuthorizationCode: usedCode,
+							RefreshToken: db.OauthRefreshToken{
+								TokenHash: arg.RefreshTokenHash,
+								ClientID:  client.ClientID,
+								Username:  user.Username,
+								Scopes:    authorizationCode.Scopes,
+								ExpiredAt: arg.RefreshTokenExpiredAt,
+							},
+						}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "no-store", recorder.Header().Get("Cache-Control"))
+
+				var response oauthTokenResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, OAUTH_TOKEN_TYPE_BEARER, response.TokenType)
+				require.Equal(t, int64(time.Minute.Seconds()), response.ExpiresIn)
+				require.NotEmpty(t, response.RefreshToken)
+				require.Equal(t, token.SCOPE_READ_ACCOUNTS, response.Scope)
+
+				payload, err := tokenMaker.VerifyToken(response.AccessToken)
+				require.NoError(t, err)
+				require.Equal(t, user.Username, payload.Username)
+				require.Equal(t, client.ClientID, payload.ClientID)
+				require.Equal(t, []string{token.SCOPE_READ_ACCOUNTS}, payload.Scopes)
+			},
+		},
+		{
+			name:   "BAD_REQUEST - Wrong Code Verifier",
+			form:   withForm("code_verifier", strings.Repeat("a", PKCE_CODE_VERIFIER_MIN_LENGTH)),
+			secret: secret,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectClient(store)
+				expectCode(store, authorizationCode)
+				store.EXPECT().
+					ExchangeOAuthCodeTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				requireOAuthError(t, recorder, http.StatusBadRequest, OAUTH_INVALID_GRANT)
+			},
+		},
+		{
+			name:   "BAD_REQUEST - Redirect URI Mismatch",
+			form:   withForm("redirect_uri", "https://partner.example.com/other"),
+			secret: secret,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectClient(store)
+				expectCode(store, authorizationCode)
+				store.EXPECT().
+					ExchangeOAuthCodeTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				requireOAuthError(t, recorder, http.StatusBadRequest, OAUTH_INVALID_GRANT)
+			},
+		},
+		{
+			name:   "BAD_REQUEST - Code Used Twice",
+			form:   form,
+			secret: secret,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectClient(store)
+				expectCode(store, usedCode)
+				store.EXPECT().
+					RevokeOAuthRefreshTokens(gomock.Any(), gomock.Eq(db.RevokeOAuthRefreshTokensParams{
+						Username: user.Username,
+						ClientID: client.ClientID,
+					})).
+					Times(1).
+					Return(nil)
+				store.EXPECT().
+					ExchangeOAuthCodeTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				requireOAuthError(t, recorder, http.StatusBadRequest, OAUTH_INVALID_GRANT)
+			},
+		},
+		{
+			name:   "BAD_REQUEST - Expired Code",
+			form:   form,
+			secret: secret,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectClient(store)
+				expectCode(store, expiredCode)
+				store.EXPECT().
+					ExchangeOAuthCodeTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				requireOAuthError(t, recorder, http.StatusBadRequest, OAUTH_INVALID_GRANT)
+			},
+		},
+		{
+			name:   "BAD_REQUEST - Consent Revoked",
+			form:   form,
+			secret: secret,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectClient(store)
+				expectCode(store, authorizationCode)
+				store.EXPECT().
+					ExchangeOAuthCodeTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ExchangeOAuthCodeTxResponse{}, db.ErrOAuthConsentRevoked)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				requireOAuthError(t, recorder, http.StatusBadRequest, OAUTH_INVALID_GRANT)
+			},
+		},
+		{
+			name:   "BAD_REQUEST - Missing Code Verifier",
+			form:   withForm("code_verifier", ""),
+			secret: secret,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectClient(store)
+				store.EXPECT().
+					GetOAuthAuthorizationCodeByHash(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				requireOAuthError(t, recorder, http.StatusBadRequest, OAUTH_INVALID_REQUEST)
+			},
+		},
+		{
+			name:   "BAD_REQUEST - Unsupported Grant Type",
+			form:   withForm("grant_type", "password"),
+			secret: secret,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectClient(store)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				requireOAuthError(t, recorder, http.StatusBadRequest, OAUTH_UNSUPPORTED_GRANT_TYPE)
+			},
+		},
+		{
+			name:   "UNAUTHORIZED - Wrong Client Secret",
+			form:   form,
+			secret: "WRONG_SECRET",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectClient(store)
+				store.EXPECT().
+					GetOAuthAuthorizationCodeByHash(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				requireOAuthError(t, recorder, http.StatusUnauthorized, OAUTH_INVALID_CLIENT)
+			},
+		},
+		{
+			name:   "INTERNAL_SERVER_ERROR",
+			form:   form,
+			secret: secret,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectClient(store)
+				store.EXPECT().
+					GetOAuthAuthorizationCodeByHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.OauthAuthorizationCode{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request := newOAuthFormRequest(t, "/oauth/token", tc.form, client, tc.secret)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder, server.tokenMaker)
+		})
+	}
+}
+
+func TestOAuthClientCredentialsGrantAPI(t *testing.T) {
+	client, secret := randomOAuthClient(t, true)
+	publicClient, _ := randomOAuthClient(t, false)
+
+	testCases := []struct {
+		name          string
+		client        db.OauthClient
+		secret        string
+		scope         string
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker)
+	}{
+		{
+			name:   "OK",
+			client: client,
+			secret: secret,
+			scope:  token.SCOPE_READ_ACCOUNTS,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response oauthTokenResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Empty(t, response.RefreshToken)
+				require.Equal(t, token.SCOPE_READ_ACCOUNTS, response.Scope)
+
+				payload, err := tokenMaker.VerifyToken(response.AccessToken)
+				require.NoError(t, err)
+				require.Empty(t, payload.Username)
+				require.Equal(t, client.ClientID, payload.ClientID)
+			},
+		},
+		{
+			name:   "OK - Every Client Scope",
+			client: client,
+			secret: secret,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response oauthTokenResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, client.Scopes, response.Scope)
+			},
+		},
+		{
+			name:   "BAD_REQUEST - Public Client",
+			client: publicClient,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				requireOAuthError(t, recorder, http.StatusBadRequest, OAUTH_UNAUTHORIZED_CLIENT)
+			},
+		},
+		{
+			name:   "BAD_REQUEST - Scope Not Allowed",
+			client: client,
+			secret: secret,
+			scope:  "admin",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				requireOAuthError(t, recorder, http.StatusBadRequest, OAUTH_INVALID_SCOPE)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().
+				GetOAuthClient(gomock.Any(), gomock.Eq(tc.client.ClientID)).
+				Times(1).
+				Return(tc.client, nil)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			form := url.Values{"grant_type": {GRANT_TYPE_CLIENT_CREDENTIALS}, "scope": {tc.scope}}
+			request := newOAuthFormRequest(t, "/oauth/token", form, tc.client, tc.secret)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder, server.tokenMaker)
+		})
+	}
+}
+
+func TestOAuthRefreshTokenGrantAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	client, _ := randomOAuthClient(t, false)
+	otherClient, _ := randomOAuthClient(t, false)
+
+	secret, err := util.NewSecureToken()
+	require.NoError(t, err)
+
+	refreshToken := db.OauthRefreshToken{
+		ID:        util.RandomInt(1, 1000),
+		TokenHash: util.HashToken(secret),
+		ClientID:  client.ClientID,
+		Username:  user.Username,
+		Scopes:    client.Scopes,
+		ExpiredAt: time.Now().Add(time.Hour),
+		CreatedAt: time.Now(),
+	}
+	rotatedToken := refreshToken
+	rotatedToken.RotatedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	expiredToken := refreshToken
+	expiredToken.ExpiredAt = time.Now().Add(-time.Second)
+
+	expectRefreshToken := func(store *mockdb.MockStore, refreshToken db.OauthRefreshToken) {
+		store.EXPECT().
+			GetOAuthRefreshTokenByHash(gomock.Any(), gomock.Eq(util.HashToken(secret))).
+			Times(1).
+			Return(refreshToken, nil)
+	}
+	expectRevoke := func(store *mockdb.MockStore) {
+		store.EXPECT().
+			RevokeOAuthRefreshTokens(gomock.Any(), gomock.Eq(db.RevokeOAuthRefreshTokensParams{
+				Username: user.Username,
+				ClientID: client.ClientID,
+			})).
+			Times(1).
+			Return(nil)
+	}
+
+	testCases := []struct {
+		name          string
+		client        db.OauthClient
+		scope         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker)
+	}{
+		{
+			name:   "OK",
+			client: client,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectRefreshToken(store, refreshToken)
+				store.EXPECT().
+					RotateOAuthRefreshTokenTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.RotateOAuthRefreshTokenTxParams) (db.RotateOAuthRefreshTokenTxResponse, error) {
+						require.Equal(t, refreshToken.ID, arg.ID)
+						require.NotEqual(t, refreshToken.TokenHash, arg.TokenHash)
+						newToken := refreshToken
+						newToken.TokenHash = arg.TokenHash
+						return db.RotateOAuthRefreshTokenTxResponse{
+							RotatedRefreshToken: rotatedToken,
+							RefreshToken:        newToken,
+						}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response oauthTokenResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.NotEmpty(t, response.RefreshToken)
+				require.NotEqual(t, secret, response.RefreshToken)
+				require.Equal(t, client.Scopes, response.Scope)
+
+				payload, err := tokenMaker.VerifyToken(response.AccessToken)
+				require.NoError(t, err)
+				require.Equal(t, user.Username, payload.Username)
+				require.Equal(t, client.ClientID, payload.ClientID)
+			},
+		},
+		{
+			name:   "OK - Narrowed Scope",
+			client: client,
+			scope:  token.SCOPE_READ_ACCOUNTS,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectRefreshToken(store, refreshToken)
+				store.EXPECT().
+					RotateOAuthRefreshTokenTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.RotateOAuthRefreshTokenTxResponse{RefreshToken: refreshToken}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response oauthTokenResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, token.SCOPE_READ_ACCOUNTS, response.Scope)
+			},
+		},
+		{
+			name:   "BAD_REQUEST - Rotated Token Reused",
+			client: client,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectRefreshToken(store, rotatedToken)
+				expectRevoke(store)
+				store.EXPECT().
+					RotateOAuthRefreshTokenTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				requireOAuthError(t, recorder, http.StatusBadRequest, OAUTH_INVALID_GRANT)
+			},
+		},
+		{
+			name:   "BAD_REQUEST - Concurrent Rotation",
+			client: client,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectRefreshToken(store, refreshToken)
+				store.EXPECT().
+					RotateOAuthRefreshTokenTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.RotateOAuthRefreshTokenTxResponse{}, db.ErrOAuthGrantUsed)
+				expectRevoke(store)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				requireOAuthError(t, recorder, http.StatusBadRequest, OAUTH_INVALID_GRANT)
+			},
+		},
+		{
+			name:   "BAD_REQUEST - Revoked By User",
+			client: client,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectRefreshToken(store, refreshToken)
+				store.EXPECT().
+					RotateOAuthRefreshTokenTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.RotateOAuthRefreshTokenTxResponse{}, db.ErrOAuthGrantRevoked)
+				store.EXPECT().
+					RevokeOAuthRefreshTokens(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				requireOAuthError(t, recorder, http.StatusBadRequest, OAUTH_INVALID_GRANT)
+			},
+		},
+		{
+			name:   "BAD_REQUEST - Expired Token",
+			client: client,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectRefreshToken(store, expiredToken)
+				store.EXPECT().
+					RotateOAuthRefreshTokenTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				requireOAuthError(t, recorder, http.StatusBadRequest, OAUTH_INVALID_GRANT)
+			},
+		},
+		{
+			name:   "BAD_REQUEST - Token Of Other Client",
+			client: otherClient,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectRefreshToken(store, refreshToken)
+				store.EXPECT().
+					RotateOAuthRefreshTokenTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				requireOAuthError(t, recorder, http.StatusBadRequest, OAUTH_INVALID_GRANT)
+			},
+		},
+		{
+			name:   "BAD_REQUEST - Scope Widened",
+			client: client,
+			scope:  "admin",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectRefreshToken(store, refreshToken)
+				store.EXPECT().
+					RotateOAuthRefreshTokenTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				requireOAuthError(t, recorder, http.StatusBadRequest, OAUTH_INVALID_SCOPE)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().
+				GetOAuthClient(gomock.Any(), gomock.Eq(tc.client.ClientID)).
+				Times(1).
+				Return(tc.client, nil)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			// public clients identify themselves with the client_id field alone
+			form := url.Values{
+				"grant_type":    {GRANT_TYPE_REFRESH_TOKEN},
+				"refresh_token": {secret},
+				"scope":         {tc.scope},
+				"client_id":     {tc.client.ClientID},
+			}
+			request, err := http.NewRequest(http.MethodPost, "/oauth/token", strings.NewReader(form.Encode()))
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder, server.tokenMaker)
+		})
+	}
+}
+
+func TestIntrospectOAuthTokenAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	client, secret := randomOAuthClient(t, true)
+	otherClient, _ := randomOAuthClient(t, true)
+	consent := randomOAuthConsent(user.Username, client)
+	revokedConsent := consent
+	revokedConsent.IsRevoked = true
+
+	refreshSecret, err := util.NewSecureToken()
+	require.NoError(t, err)
+	refreshToken := db.OauthRefreshToken{
+		TokenHash: util.HashToken(refreshSecret),
+		ClientID:  client.ClientID,
+		Username:  user.Username,
+		Scopes:    client.Scopes,
+		ExpiredAt: time.Now().Add(time.Hour),
+		CreatedAt: time.Now(),
+	}
+
+	accessToken := func(tokenMaker token.Maker, clientID string) string {
+		accessToken, err := tokenMaker.CreateScopedToken(user.Username, clientID, []string{token.SCOPE_READ_ACCOUNTS}, DURATION)
+		require.NoError(t, err)
+		return accessToken
+	}
+
+	requireInactive := func(t *testing.T, recorder *httptest.ResponseRecorder) {
+		require.Equal(t, http.StatusOK, recorder.Code)
+		require.JSONEq(t, `{"active": false}`, recorder.Body.String())
+	}
+
+	testCases := []struct {
+		name          string
+		token         func(tokenMaker token.Maker) string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK - Access Token",
+			token: func(tokenMaker token.Maker) string {
+				return accessToken(tokenMaker, client.ClientID)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetOAuthConsent(gomock.Any(), gomock.Eq(db.GetOAuthConsentParams{
+						Username: user.Username,
+						ClientID: client.ClientID,
+					})).
+					Times(1).
+					Return(consent, nil)
+				// the client of the token is looked up again, after the client introspecting it
+				store.EXPECT().
+					GetOAuthClient(gomock.Any(), gomock.Eq(client.ClientID)).
+					Times(1).
+					Return(client, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response introspectOAuthTokenResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.True(t, response.Active)
+				require.Equal(t, token.SCOPE_READ_ACCOUNTS, response.Scope)
+				require.Equal(t, client.ClientID, response.ClientID)
+				require.Equal(t, user.Username, response.Username)
+				require.Equal(t, OAUTH_TOKEN_TYPE_BEARER, response.TokenType)
+				require.NotZero(t, response.ExpiresAt)
+			},
+		},
+		{
+			name: "OK - Refresh Token",
+			token: func(tokenMaker token.Maker) string {
+				return refreshSecret
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetOAuthRefreshTokenByHash(gomock.Any(), gomock.Eq(refreshToken.TokenHash)).
+					Times(1).
+					Return(refreshToken, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response introspectOAuthTokenResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.True(t, response.Active)
+				require.Equal(t, OAUTH_TOKEN_TYPE_REFRESH, response.TokenType)
+				require.Equal(t, user.Username, response.Username)
+			},
+		},
+		{
+			name: "INACTIVE - Consent Revoked",
+			token: func(tokenMaker token.Maker) string {
+				return accessToken(tokenMaker, client.ClientID)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetOAuthConsent(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(revokedConsent, nil)
+			},
+			checkResponse: requireInactive,
+		},
+		{
+			name: "INACTIVE - Token Of Other Client",
+			token: func(tokenMaker token.Maker) string {
+				return accessToken(tokenMaker, otherClient.ClientID)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetOAuthConsent(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requireInactive,
+		},
+		{
+			name: "INACTIVE - First Party Token",
+			token: func(tokenMaker token.Maker) string {
+				accessToken, err := tokenMaker.CreateToken(user.Username, DURATION)
+				require.NoError(t, err)
+				return accessToken
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetOAuthConsent(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requireInactive,
+		},
+		{
+			name: "INACTIVE - Unknown Token",
+			token: func(tokenMaker token.Maker) string {
+				return "UNKNOWN_TOKEN"
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetOAuthRefreshTokenByHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.OauthRefreshToken{}, sql.ErrNoRows)
+			},
+			checkResponse: requireInactive,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().
+				GetOAuthClient(gomock.Any(), gomock.Eq(client.ClientID)).
+				Times(1).
+				Return(client, nil)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			form := url.Values{"token": {tc.token(server.tokenMaker)}}
+			request := newOAuthFormRequest(t, "/oauth/introspect", form, client, secret)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestOAuthAccessTokenAuthMiddleware(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	client, _ := randomOAuthClient(t, false)
+	consent := randomOAuthConsent(user.Username, client)
+	revokedConsent := consent
+	revokedConsent.IsRevoked = true
+	regrantedConsent := consent
+	regrantedConsent.GrantedAt = time.Now().Add(time.Minute)
+
+	addOAuthAuthorization := func(t *testing.T, req *http.Request, tokenMaker token.Maker, username string) {
+		accessToken, err := tokenMaker.CreateScopedToken(username, client.ClientID, []string{token.SCOPE_READ_ACCOUNTS}, DURATION)
+		require.NoError(t, err)
+		req.Header.Set(AUTHORIZATION_HEADER_KEY, fmt.Sprintf("Bearer %s", accessToken))
+	}
+	expectConsent := func(store *mockdb.MockStore, consent db.OauthConsent) {
+		store.EXPECT().
+			GetOAuthConsent(gomock.Any(), gomock.Eq(db.GetOAuthConsentParams{
+				Username: user.Username,
+				ClientID: client.ClientID,
+			})).
+			Times(1).
+			Return(consent, nil)
+	}
+	expectClient := func(store *mockdb.MockStore, client db.OauthClient) {
+		store.EXPECT().
+			GetOAuthClient(gomock.Any(), gomock.Eq(client.ClientID)).
+			Times(1).
+			Return(client, nil)
+	}
+
+	testCases := []struct {
+		name          string
+		url           string
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			url:      fmt.Sprintf("/accounts/%d", account.ID),
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectConsent(store, consent)
+				expectClient(store, client)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchAccount(t, recorder.Body, account)
+			},
+		},
+		{
+			name:     "FORBIDDEN - Route Without Scope",
+			url:      "/users/me/api_keys",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectConsent(store, consent)
+				expectClient(store, client)
+				store.EXPECT().
+					ListAPIKeys(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "UNAUTHORIZED - Consent Revoked",
+			url:      fmt.Sprintf("/accounts/%d", account.ID),
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectConsent(store, revokedConsent)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "UNAUTHORIZED - Client Revoked After Consent",
+			url:      fmt.Sprintf("/accounts/%d", account.ID),
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				revokedClient := client
+				revokedClient.IsRevoked = true
+
+				expectConsent(store, consent)
+				expectClient(store, revokedClient)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "UNAUTHORIZED - Issued Before Consent Granted Again",
+			url:      fmt.Sprintf("/accounts/%d", account.ID),
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectConsent(store, regrantedConsent)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "UNAUTHORIZED - Client Credentials Token",
+			url:      fmt.Sprintf("/accounts/%d", account.ID),
+			username: "",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetOAuthConsent(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "OK - Client Credentials Token On Client Route",
+			url:      "/oauth/clients/me",
+			username: "",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetOAuthConsent(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					GetOAuthClient(gomock.Any(), gomock.Eq(client.ClientID)).
+					Times(1).
+					Return(client, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response oauthClientResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, client.ClientID, response.ClientID)
+			},
+		},
+		{
+			name:     "UNAUTHORIZED - Client Revoked",
+			url:      "/oauth/clients/me",
+			username: "",
+			buildStubs: func(store *mockdb.MockStore) {
+				revokedClient := client
+				revokedClient.IsRevoked = true
+				store.EXPECT().
+					GetOAuthClient(gomock.Any(), gomock.Eq(client.ClientID)).
+					Times(1).
+					Return(revokedClient, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "FORBIDDEN - User Token On Client Route",
+			url:      "/oauth/clients/me",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectConsent(store, consent)
+				expectClient(store, client)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "INTERNAL_SERVER_ERROR",
+			url:      fmt.Sprintf("/accounts/%d", account.ID),
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetOAuthConsent(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.OauthConsent{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, tc.url, nil)
+			require.NoError(t, err)
+
+			addOAuthAuthorization(t, request, server.tokenMaker, tc.username)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListOAuthConsentsAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	client, _ := randomOAuthClient(t, false)
+	consent := randomOAuthConsent(user.Username, client)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		ListOAuthConsents(gomock.Any(), gomock.Eq(user.Username)).
+		Times(1).
+		Return([]db.OauthConsent{consent}, nil)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/users/me/oauth_consents", nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, AUTHORIZATION_TYPE_BEARER, user.Username, DURATION)
+	server.router.ServeHTTP(recorder, request)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var response []oauthConsentResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	require.Len(t, response, 1)
+	require.Equal(t, client.ClientID, response[0].ClientID)
+	require.Equal(t, strings.Fields(client.Scopes), response[0].Scopes)
+}
+
+func TestRevokeOAuthConsentAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	client, _ := randomOAuthClient(t, false)
+	consent := randomOAuthConsent(user.Username, client)
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "NO_CONTENT",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					RevokeOAuthConsentTx(gomock.Any(), gomock.Eq(db.RevokeOAuthConsentParams{
+						Username: user.Username,
+						ClientID: client.ClientID,
+					})).
+					Times(1).
+					Return(db.RevokeOAuthConsentTxResponse{Consent: consent}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name: "NOT_FOUND",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					RevokeOAuthConsentTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.RevokeOAuthConsentTxResponse{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "INTERNAL_SERVER_ERROR",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					RevokeOAuthConsentTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.RevokeOAuthConsentTxResponse{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/users/me/oauth_consents/%s", client.ClientID)
+			request, err := http.NewRequest(http.MethodDelete, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, AUTHORIZATION_TYPE_BEARER, user.Username, DURATION)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
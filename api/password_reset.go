@@ -0,0 +1,154 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	db "github.com/VL-037/go-bank/db/sqlc"
+	"github.com/VL-037/go-bank/mail"
+	"github.com/VL-037/go-bank/util"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"time"
+)
+
+type forgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// PASSWORD_RESET_TIMEOUT bounds the lookup and the mail of a password reset request once it has been answered
+const PASSWORD_RESET_TIMEOUT = time.Minute
+
+// forgotPassword mails a password reset link when the email belongs to a user. It answers every
+// valid request before looking the email up, so neither the response nor its timing tells whether it is registered
+func (server *Server) forgotPassword(ctx *gin.Context) {
+	var req forgotPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	ctx.Status(http.StatusAccepted)
+
+	server.background.Add(1)
+	go func() {
+		defer server.background.Done()
+
+		resetCtx, cancel := context.WithTimeout(context.Background(), PASSWORD_RESET_TIMEOUT)
+		defer cancel()
+		server.requestPasswordReset(resetCtx, req.Email)
+	}()
+}
+
+// requestPasswordReset mails the reset link to the user of the email, if any.
+// The email is left out of the logs, only known users are logged by username
+func (server *Server) requestPasswordReset(ctx context.Context, email string) {
+	user, err := server.store.GetUserByEmail(ctx, email)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("cannot look up the user of a password reset request: %v", err)
+		}
+		return
+	}
+
+	err = server.sendPasswordResetEmail(ctx, user)
+	if err != nil {
+		log.Printf("cannot send password reset email to %s: %v", user.Username, err)
+	}
+}
+
+// sendPasswordResetEmail mails a link to reset the password of the user,
+// only the hash of the token in the link is stored
+func (server *Server) sendPasswordResetEmail(ctx context.Context, user db.User) error {
+	secret, err := util.NewSecureToken()
+	if err != nil {
+		return err
+	}
+
+	passwordReset, err := server.store.CreatePasswordReset(ctx, db.CreatePasswordResetParams{
+		Username:  user.Username,
+		TokenHash: util.HashToken(secret),
+		ExpiredAt: time.Now().Add(server.config.PasswordResetDuration),
+	})
+	if err != nil {
+		return err
+	}
+
+	link, err := tokenLink(server.config.PasswordResetURL, passwordReset.ID, secret)
+	if err != nil {
+		return err
+	}
+
+	return server.mailer.Send(mail.Message{
+		To:      []string{user.Email},
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hello %s,\n\nsomeone asked to reset the password of your account. "+
+			"Open the link below to choose a new password:\n\n%s\n\n"+
+			"The link can be used once and expires at %s. If you did not ask for it, you can ignore this email.\n",
+			user.FullName, link, passwordReset.ExpiredAt.UTC().Format(time.RFC1123)),
+	})
+}
+
+type resetPasswordRequest struct {
+	ID          int64  `json:"id" binding:"required,min=1"`
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=6"`
+}
+
+// resetPassword sets a new password with a token from the password reset email,
+// every access token, API key and OAuth refresh token issued before is revoked
+func (server *Server) resetPassword(ctx *gin.Context) {
+	var req resetPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// the reset is only looked up for the policy check, ResetPasswordTx uses it atomically
+	passwordReset, err := server.store.GetPasswordReset(ctx, req.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusBadRequest, errorResponse(db.ErrInvalidPasswordReset))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if passwordReset.TokenHash != util.HashToken(req.Token) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(db.ErrInvalidPasswordReset))
+		return
+	}
+
+	user, err := server.store.GetUser(ctx, passwordReset.Username)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if !server.validPassword(ctx, req.NewPassword, user.Username, user.Email) {
+		return
+	}
+
+	hashedPassword, err := server.passwordHasher.HashPassword(req.NewPassword)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	response, err := server.store.ResetPasswordTx(ctx, db.ResetPasswordTxParams{
+		ID:             req.ID,
+		TokenHash:      util.HashToken(req.Token),
+		HashedPassword: hashedPassword,
+	})
+	if err != nil {
+		if err == db.ErrInvalidPasswordReset {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newUserResponse(response.User))
+}
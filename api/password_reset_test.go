@@ -0,0 +1,386 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	mockdb "github.com/VL-037/go-bank/db/mock"
+	db "github.com/VL-037/go-bank/db/sqlc"
+	"github.com/VL-037/go-bank/util"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+type eqResetPasswordTxParamsMatcher struct {
+	arg      db.ResetPasswordTxParams
+	password string
+}
+
+func (e eqResetPasswordTxParamsMatcher) Matches(x interface{}) bool {
+	arg, ok := x.(db.ResetPasswordTxParams)
+	if !ok {
+		return false
+	}
+
+	if err := util.CheckPassword(arg.HashedPassword, e.password); err != nil {
+		return false
+	}
+
+	return arg.ID == e.arg.ID && arg.TokenHash == e.arg.TokenHash
+}
+
+func (e eqResetPasswordTxParamsMatcher) String() string {
+	return fmt.Sprintf("matches arg %v and password %v", e.arg, e.password)
+}
+
+func EqResetPasswordTxParams(arg db.ResetPasswordTxParams, password string) gomock.Matcher {
+	return eqResetPasswordTxParamsMatcher{arg, password}
+}
+
+func TestForgotPasswordAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder, sender *recordingSender)
+	}{
+		{
+			name: "ACCEPTED",
+			body: gin.H{"email": user.Email},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					CreatePasswordReset(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreatePasswordResetParams) (db.PasswordReset, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.Len(t, arg.TokenHash, 64)
+						require.WithinDuration(t, time.Now().Add(time.Hour), arg.ExpiredAt, time.Second)
+
+						return db.PasswordReset{
+							ID:        3,
+							Username:  arg.Username,
+							TokenHash: arg.TokenHash,
+							ExpiredAt: arg.ExpiredAt,
+						}, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, sender *recordingSender) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+				require.Len(t, sender.messages, 1)
+				require.Equal(t, []string{user.Email}, sender.messages[0].To)
+				require.Contains(t, sender.messages[0].Body, "http://localhost:3000/reset_password?id=3&token=")
+			},
+		},
+		{
+			name: "ACCEPTED - Unknown Email",
+			body: gin.H{"email": user.Email},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().
+					CreatePasswordReset(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, sender *recordingSender) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+				require.Empty(t, sender.messages)
+			},
+		},
+		{
+			name: "ACCEPTED - Reset Not Stored",
+			body: gin.H{"email": user.Email},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					CreatePasswordReset(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.PasswordReset{}, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, sender *recordingSender) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+				require.Empty(t, sender.messages)
+			},
+		},
+		{
+			name: "BAD_REQUEST - Invalid Email",
+			body: gin.H{"email": "invalid-email"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, sender *recordingSender) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			sender := &recordingSender{}
+			server.mailer = sender
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/users/password/forgot", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			server.background.Wait()
+			tc.checkResponse(recorder, sender)
+		})
+	}
+}
+
+func TestForgotPasswordAnswersFirstAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// the lookup only returns once the response is written, so a handler waiting on it would hang
+	answered := make(chan struct{})
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		GetUserByEmail(gomock.Any(), gomock.Eq(user.Email)).
+		Times(1).
+		DoAndReturn(func(_ interface{}, _ string) (db.User, error) {
+			<-answered
+			return db.User{}, sql.ErrNoRows
+		})
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	data, err := json.Marshal(gin.H{"email": user.Email})
+	require.NoError(t, err)
+
+	request, err := http.NewRequest(http.MethodPost, "/users/password/forgot", bytes.NewReader(data))
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusAccepted, recorder.Code)
+
+	close(answered)
+	server.background.Wait()
+}
+
+func expectPasswordResetLookup(store *mockdb.MockStore, passwordReset db.PasswordReset, user db.User) {
+	store.EXPECT().
+		GetPasswordReset(gomock.Any(), gomock.Eq(passwordReset.ID)).
+		Times(1).
+		Return(passwordReset, nil)
+	store.EXPECT().
+		GetUser(gomock.Any(), gomock.Eq(user.Username)).
+		Times(1).
+		Return(user, nil)
+}
+
+func TestResetPasswordAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	secret := util.RandomString(32)
+	newPassword := util.RandomString(8)
+	passwordReset := db.PasswordReset{
+		ID:        3,
+		Username:  user.Username,
+		TokenHash: util.HashToken(secret),
+		ExpiredAt: time.Now().Add(time.Hour),
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"id":           3,
+				"token":        secret,
+				"new_password": newPassword,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectPasswordResetLookup(store, passwordReset, user)
+				arg := db.ResetPasswordTxParams{
+					ID:        3,
+					TokenHash: util.HashToken(secret),
+				}
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), EqResetPasswordTxParams(arg, newPassword)).
+					Times(1).
+					Return(db.ResetPasswordTxResponse{User: user}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchUser(t, recorder.Body, user)
+			},
+		},
+		{
+			name: "BAD_REQUEST - Invalid Token",
+			body: gin.H{
+				"id":           3,
+				"token":        secret,
+				"new_password": newPassword,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectPasswordResetLookup(store, passwordReset, user)
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ResetPasswordTxResponse{}, db.ErrInvalidPasswordReset)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "BAD_REQUEST - Unknown Reset",
+			body: gin.H{
+				"id":           3,
+				"token":        secret,
+				"new_password": newPassword,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetPasswordReset(gomock.Any(), gomock.Eq(int64(3))).
+					Times(1).
+					Return(db.PasswordReset{}, sql.ErrNoRows)
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "BAD_REQUEST - Token Mismatch",
+			body: gin.H{
+				"id":           3,
+				"token":        util.RandomString(32),
+				"new_password": newPassword,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetPasswordReset(gomock.Any(), gomock.Eq(int64(3))).
+					Times(1).
+					Return(passwordReset, nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "BAD_REQUEST - Password Policy",
+			body: gin.H{
+				"id":           3,
+				"token":        secret,
+				"new_password": user.Username + "-password",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectPasswordResetLookup(store, passwordReset, user)
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Contains(t, recorder.Body.String(), "password must not contain the username")
+			},
+		},
+		{
+			name: "BAD_REQUEST - Short Password",
+			body: gin.H{
+				"id":           3,
+				"token":        secret,
+				"new_password": "12345",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "INTERNAL_SERVER_ERROR",
+			body: gin.H{
+				"id":           3,
+				"token":        secret,
+				"new_password": newPassword,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectPasswordResetLookup(store, passwordReset, user)
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ResetPasswordTxResponse{}, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/users/password/reset", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	db "github.com/VL-037/go-bank/db/sqlc"
+	"github.com/VL-037/go-bank/util"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// payee is the destination of a transfer, addressed by exactly one of its fields
+type payee struct {
+	AccountID     int64
+	AccountNumber string
+	Username      string
+	Email         string
+}
+
+// payeeAccount resolves a payee to its account in the given currency
+func (server *Server) payeeAccount(ctx *gin.Context, to payee, currency string) (db.Account, bool) {
+	addressed := 0
+	for _, set := range []bool{to.AccountID != 0, to.AccountNumber != "", to.Username != "", to.Email != ""} {
+		if set {
+			addressed++
+		}
+	}
+	if addressed != 1 {
+		err := errors.New("payee must be addressed by exactly one of account id, account number, username or email")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return db.Account{}, false
+	}
+
+	if to.AccountID != 0 || to.AccountNumber != "" {
+		return server.validAccountRef(ctx, to.AccountID, to.AccountNumber, currency)
+	}
+
+	owner := to.Username
+	if to.Email != "" {
+		user, err := server.store.GetUserByEmail(ctx, to.Email)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				ctx.JSON(http.StatusNotFound, errorResponse(err))
+				return db.Account{}, false
+			}
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return db.Account{}, false
+		}
+		owner = user.Username
+	}
+
+	// owners have at most one checking account per currency, which receives the transfers addressed to them
+	account, err := server.store.GetAccountByOwner(ctx, db.GetAccountByOwnerParams{
+		Owner:    owner,
+		Currency: currency,
+		Type:     util.CHECKING_ACCOUNT_TYPE,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return account, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return account, false
+	}
+
+	if account.MarkForDelete {
+		ctx.JSON(http.StatusBadRequest, errorResponse(ErrAccountClosed))
+		return account, false
+	}
+
+	return account, true
+}
+
+type lookupPayeeRequest struct {
+	AccountNumber string `form:"account_number" binding:"omitempty,account_number"`
+	Username      string `form:"username" binding:"omitempty,alphanum"`
+	Email         string `form:"email" binding:"omitempty,email"`
+	Currency      string `form:"currency" binding:"required,currency"`
+}
+
+type lookupPayeeResponse struct {
+	MaskedName string `json:"masked_name"`
+	Currency   string `json:"currency"`
+}
+
+// lookupPayee lets the sender confirm who they are about to pay without revealing the account
+func (server *Server) lookupPayee(ctx *gin.Context) {
+	var req lookupPayeeRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, valid := server.payeeAccount(ctx, payee{
+		AccountNumber: req.AccountNumber,
+		Username:      req.Username,
+		Email:         req.Email,
+	}, req.Currency)
+	if !valid {
+		return
+	}
+
+	user, err := server.store.GetUser(ctx, account.Owner)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, lookupPayeeResponse{
+		MaskedName: util.MaskName(user.FullName),
+		Currency:   account.Currency,
+	})
+}
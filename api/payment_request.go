@@ -0,0 +1,259 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	db "github.com/VL-037/go-bank/db/sqlc"
+	"github.com/VL-037/go-bank/token"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+// createPaymentRequestRequest addresses the payer either by username or by one of their accounts
+type createPaymentRequestRequest struct {
+	ToAccountID        int64      `json:"to_account_id" binding:"required_without=ToAccountNumber,excluded_with=ToAccountNumber,omitempty,min=1"`
+	ToAccountNumber    string     `json:"to_account_number" binding:"omitempty,account_number"`
+	PayerUsername      string     `json:"payer_username" binding:"required_without_all=PayerAccountID PayerAccountNumber,excluded_with=PayerAccountID PayerAccountNumber,omitempty,alphanum"`
+	PayerAccountID     int64      `json:"payer_account_id" binding:"excluded_with=PayerAccountNumber,omitempty,min=1"`
+	PayerAccountNumber string     `json:"payer_account_number" binding:"omitempty,account_number"`
+	Amount             int64      `json:"amount" binding:"required,gt=0"`
+	Currency           string     `json:"currency" binding:"required,currency"`
+	Note               string     `json:"note" binding:"omitempty,max=255"`
+	ExpiresAt          *time.Time `json:"expires_at"`
+}
+
+func (server *Server) createPaymentRequest(ctx *gin.Context) {
+	var req createPaymentRequestRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	expiresAt := time.Now().Add(server.config.PaymentRequestDuration)
+	if req.ExpiresAt != nil {
+		expiresAt = *req.ExpiresAt
+	}
+
+	if !expiresAt.After(time.Now()) {
+		err := errors.New("expires_at must be in the future")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if server.requiresApproval(req.Currency, req.Amount) {
+		err := errors.New("amount requires approval, it cannot be requested")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	toAccount, valid := server.validAccountRef(ctx, req.ToAccountID, req.ToAccountNumber, req.Currency)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD_KEY).(*token.Payload)
+	if authPayload.Username != toAccount.Owner {
+		err := errors.New("to account doesn't belong to authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	var payer string
+	if req.PayerAccountID != 0 || req.PayerAccountNumber != "" {
+		payerAccount, valid := server.validAccountRef(ctx, req.PayerAccountID, req.PayerAccountNumber, req.Currency)
+		if !valid {
+			return
+		}
+		payer = payerAccount.Owner
+	} else {
+		user, err := server.store.GetUser(ctx, req.PayerUsername)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				ctx.JSON(http.StatusNotFound, errorResponse(err))
+				return
+			}
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		payer = user.Username
+	}
+
+	if payer == authPayload.Username {
+		err := errors.New("cannot request money from yourself")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg := db.CreatePaymentRequestParams{
+		Requester:   authPayload.Username,
+		Payer:       payer,
+		ToAccountID: toAccount.ID,
+		Amount:      req.Amount,
+		Currency:    req.Currency,
+		Note:        sql.NullString{String: req.Note, Valid: req.Note != ""},
+		ExpiresAt:   expiresAt,
+	}
+
+	paymentRequest, err := server.store.CreatePaymentRequest(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, paymentRequest)
+}
+
+type listPaymentRequestRequest struct {
+	Direction string `form:"direction" binding:"omitempty,oneof=incoming outgoing"`
+	PageID    int32  `form:"page_id" binding:"required,min=1"`
+	PageSize  int32  `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+// listPaymentRequests lists the requests the caller has to pay, or the ones they sent with direction=outgoing
+func (server *Server) listPaymentRequests(ctx *gin.Context) {
+	var req listPaymentRequestRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD_KEY).(*token.Payload)
+
+	var paymentRequests []db.PaymentRequest
+	var err error
+	if req.Direction == "outgoing" {
+		paymentRequests, err = server.store.ListOutgoingPaymentRequests(ctx, db.ListOutgoingPaymentRequestsParams{
+			Requester: authPayload.Username,
+			Limit:     req.PageSize,
+			Offset:    (req.PageID - 1) * req.PageSize,
+		})
+	} else {
+		paymentRequests, err = server.store.ListIncomingPaymentRequests(ctx, db.ListIncomingPaymentRequestsParams{
+			Payer:  authPayload.Username,
+			Limit:  req.PageSize,
+			Offset: (req.PageID - 1) * req.PageSize,
+		})
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, paymentRequests)
+}
+
+type paymentRequestRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type acceptPaymentRequestRequest struct {
+	FromAccountID     int64  `json:"from_account_id" binding:"required_without=FromAccountNumber,excluded_with=FromAccountNumber,omitempty,min=1"`
+	FromAccountNumber string `json:"from_account_number" binding:"omitempty,account_number"`
+	MFACode           string `json:"mfa_code" binding:"omitempty,max=32"`
+}
+
+func (server *Server) acceptPaymentRequest(ctx *gin.Context) {
+	var uri paymentRequestRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req acceptPaymentRequestRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	paymentRequest, valid := server.payableRequest(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	fromAccount, valid := server.validAccountRef(ctx, req.FromAccountID, req.FromAccountNumber, paymentRequest.Currency)
+	if !valid {
+		return
+	}
+
+	if fromAccount.Owner != paymentRequest.Payer {
+		err := errors.New("from account doesn't belong to authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	if !server.validStepUp(ctx, paymentRequest.Payer, paymentRequest.Currency, paymentRequest.Amount, req.MFACode) {
+		return
+	}
+
+	toAccount, valid := server.validAccount(ctx, paymentRequest.ToAccountID, paymentRequest.Currency)
+	if !valid {
+		return
+	}
+
+	if !server.validCoolingOff(ctx, paymentRequest.Payer, toAccount, paymentRequest.Amount) {
+		return
+	}
+
+	response, err := server.store.AcceptPaymentRequestTx(ctx, db.AcceptPaymentRequestTxParams{
+		PaymentRequestID: paymentRequest.ID,
+		FromAccountID:    fromAccount.ID,
+	})
+	if err != nil {
+		switch err {
+		case db.ErrPaymentRequestNotPending, db.ErrInsufficientFunds, db.ErrAccountClosed:
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		default:
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (server *Server) declinePaymentRequest(ctx *gin.Context) {
+	var req paymentRequestRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, valid := server.payableRequest(ctx, req.ID); !valid {
+		return
+	}
+
+	paymentRequest, err := server.store.DeclinePaymentRequest(ctx, req.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusBadRequest, errorResponse(db.ErrPaymentRequestNotPending))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, paymentRequest)
+}
+
+// payableRequest loads a payment request and makes sure the authenticated user is its payer
+func (server *Server) payableRequest(ctx *gin.Context, id int64) (db.PaymentRequest, bool) {
+	paymentRequest, err := server.store.GetPaymentRequest(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return paymentRequest, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return paymentRequest, false
+	}
+
+	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD_KEY).(*token.Payload)
+	if authPayload.Username != paymentRequest.Payer {
+		err := errors.New("payment request isn't addressed to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return paymentRequest, false
+	}
+
+	return paymentRequest, true
+}
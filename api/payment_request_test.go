@@ -0,0 +1,350 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	mockdb "github.com/VL-037/go-bank/db/mock"
+	db "github.com/VL-037/go-bank/db/sqlc"
+	"github.com/VL-037/go-bank/util"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCreatePaymentRequestAPI(t *testing.T) {
+	requester, _ := randomUser(t)
+	payer, _ := randomUser(t)
+
+	toAccount := randomAccount(requester.Username)
+	payerAccount := randomAccount(payer.Username)
+	toAccount.Currency = util.EUR
+	payerAccount.Currency = util.EUR
+
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK - By Username",
+			body: gin.H{
+				"to_account_id":  toAccount.ID,
+				"payer_username": payer.Username,
+				"amount":         25,
+				"currency":       util.EUR,
+				"note":           "dinner",
+				"expires_at":     expiresAt,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(payer.Username)).Times(1).Return(payer, nil)
+				store.EXPECT().
+					CreatePaymentRequest(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreatePaymentRequestParams) (db.PaymentRequest, error) {
+						require.Equal(t, requester.Username, arg.Requester)
+						require.Equal(t, payer.Username, arg.Payer)
+						require.Equal(t, int64(25), arg.Amount)
+						require.Equal(t, "dinner", arg.Note.String)
+						require.True(t, expiresAt.Equal(arg.ExpiresAt))
+						return db.PaymentRequest{}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "OK - By Account",
+			body: gin.H{
+				"to_account_id":    toAccount.ID,
+				"payer_account_id": payerAccount.ID,
+				"amount":           25,
+				"currency":         util.EUR,
+				"expires_at":       expiresAt,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(payerAccount.ID)).Times(1).Return(payerAccount, nil)
+				store.EXPECT().
+					CreatePaymentRequest(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreatePaymentRequestParams) (db.PaymentRequest, error) {
+						require.Equal(t, payer.Username, arg.Payer)
+						require.False(t, arg.Note.Valid)
+						return db.PaymentRequest{}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "BAD_REQUEST - Both Payer Fields",
+			body: gin.H{
+				"to_account_id":    toAccount.ID,
+				"payer_username":   payer.Username,
+				"payer_account_id": payerAccount.ID,
+				"amount":           25,
+				"currency":         util.EUR,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreatePaymentRequest(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "BAD_REQUEST - Requesting From Yourself",
+			body: gin.H{
+				"to_account_id":  toAccount.ID,
+				"payer_username": requester.Username,
+				"amount":         25,
+				"currency":       util.EUR,
+				"expires_at":     expiresAt,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(requester.Username)).Times(1).Return(requester, nil)
+				store.EXPECT().CreatePaymentRequest(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NOT_FOUND - Payer",
+			body: gin.H{
+				"to_account_id":  toAccount.ID,
+				"payer_username": payer.Username,
+				"amount":         25,
+				"currency":       util.EUR,
+				"expires_at":     expiresAt,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(payer.Username)).Times(1).Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().CreatePaymentRequest(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "UNAUTHORIZED - To Account Of Someone Else",
+			body: gin.H{
+				"to_account_id":  payerAccount.ID,
+				"payer_username": payer.Username,
+				"amount":         25,
+				"currency":       util.EUR,
+				"expires_at":     expiresAt,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(payerAccount.ID)).Times(1).Return(payerAccount, nil)
+				store.EXPECT().CreatePaymentRequest(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/payment_requests", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, AUTHORIZATION_TYPE_BEARER, requester.Username, DURATION)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestAcceptAndDeclinePaymentRequestAPI(t *testing.T) {
+	requester, _ := randomUser(t)
+	payer, _ := randomUser(t)
+
+	payerAccount := randomAccount(payer.Username)
+	requesterAccount := randomAccount(requester.Username)
+	payerAccount.Currency = util.USD
+	requesterAccount.Currency = util.USD
+
+	paymentRequest := db.PaymentRequest{
+		ID:          util.RandomInt(1, 1000),
+		Requester:   requester.Username,
+		Payer:       payer.Username,
+		ToAccountID: requesterAccount.ID,
+		Amount:      25,
+		Currency:    util.USD,
+		Status:      db.PAYMENT_REQUEST_STATUS_PENDING,
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+
+	testCases := []struct {
+		name          string
+		action        string
+		body          gin.H
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK - Accept",
+			action:   "accept",
+			body:     gin.H{"from_account_id": payerAccount.ID},
+			username: payer.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(paymentRequest.ID)).Times(1).Return(paymentRequest, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(payerAccount.ID)).Times(1).Return(payerAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(requesterAccount.ID)).Times(1).Return(requesterAccount, nil)
+				store.EXPECT().
+					AcceptPaymentRequestTx(gomock.Any(), gomock.Eq(db.AcceptPaymentRequestTxParams{
+						PaymentRequestID: paymentRequest.ID,
+						FromAccountID:    payerAccount.ID,
+					})).
+					Times(1).
+					Return(db.AcceptPaymentRequestTxResponse{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "BAD_REQUEST - Accept Expired",
+			action:   "accept",
+			body:     gin.H{"from_account_id": payerAccount.ID},
+			username: payer.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(paymentRequest.ID)).Times(1).Return(paymentRequest, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(payerAccount.ID)).Times(1).Return(payerAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(requesterAccount.ID)).Times(1).Return(requesterAccount, nil)
+				store.EXPECT().
+					AcceptPaymentRequestTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AcceptPaymentRequestTxResponse{}, db.ErrPaymentRequestNotPending)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "UNAUTHORIZED - Requester Accepts",
+			action:   "accept",
+			body:     gin.H{"from_account_id": payerAccount.ID},
+			username: requester.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(paymentRequest.ID)).Times(1).Return(paymentRequest, nil)
+				store.EXPECT().AcceptPaymentRequestTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "OK - Decline",
+			action:   "decline",
+			username: payer.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(paymentRequest.ID)).Times(1).Return(paymentRequest, nil)
+				store.EXPECT().DeclinePaymentRequest(gomock.Any(), gomock.Eq(paymentRequest.ID)).Times(1).Return(paymentRequest, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "BAD_REQUEST - Decline Not Pending",
+			action:   "decline",
+			username: payer.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(paymentRequest.ID)).Times(1).Return(paymentRequest, nil)
+				store.EXPECT().DeclinePaymentRequest(gomock.Any(), gomock.Eq(paymentRequest.ID)).Times(1).Return(db.PaymentRequest{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			var body []byte
+			if tc.body != nil {
+				var err error
+				body, err = json.Marshal(tc.body)
+				require.NoError(t, err)
+			}
+
+			url := fmt.Sprintf("/payment_requests/%d/%s", paymentRequest.ID, tc.action)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, AUTHORIZATION_TYPE_BEARER, tc.username, DURATION)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListPaymentRequestsAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		ListIncomingPaymentRequests(gomock.Any(), gomock.Eq(db.ListIncomingPaymentRequestsParams{Payer: user.Username, Limit: 5, Offset: 0})).
+		Times(1).
+		Return([]db.PaymentRequest{}, nil)
+	store.EXPECT().
+		ListOutgoingPaymentRequests(gomock.Any(), gomock.Eq(db.ListOutgoingPaymentRequestsParams{Requester: user.Username, Limit: 5, Offset: 5})).
+		Times(1).
+		Return([]db.PaymentRequest{}, nil)
+
+	server := newTestServer(t, store)
+
+	for _, query := range []string{"page_id=1&page_size=5", "direction=outgoing&page_id=2&page_size=5"} {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodGet, "/payment_requests?"+query, nil)
+		require.NoError(t, err)
+
+		addAuthorization(t, request, server.tokenMaker, AUTHORIZATION_TYPE_BEARER, user.Username, DURATION)
+		server.router.ServeHTTP(recorder, request)
+		require.Equal(t, http.StatusOK, recorder.Code)
+	}
+}
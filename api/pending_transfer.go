@@ -0,0 +1,223 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	db "github.com/VL-037/go-bank/db/sqlc"
+	"github.com/VL-037/go-bank/token"
+	"github.com/VL-037/go-bank/util"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+)
+
+// ErrApprovalRequired rejects money movements above the approval threshold which cannot wait for an approver
+var ErrApprovalRequired = errors.New("amount requires approval, send it as a single transfer")
+
+// requiresApproval reports whether a transfer is above the approval threshold of its currency
+func (server *Server) requiresApproval(currency string, amount int64) bool {
+	threshold, ok := server.approvalThresholds[currency]
+	return ok && amount > threshold
+}
+
+// createPendingTransfer stores a transfer for a second approver instead of moving the funds
+func (server *Server) createPendingTransfer(ctx *gin.Context, req transferRequest, metadata json.RawMessage, username string) {
+	if metadata == nil {
+		metadata = json.RawMessage("{}")
+	}
+
+	arg := db.CreatePendingTransferParams{
+		FromAccountID:   req.FromAccountID,
+		ToAccountID:     req.ToAccountID,
+		Amount:          req.Amount,
+		Currency:        req.Currency,
+		RequestedBy:     username,
+		Description:     sql.NullString{String: req.Description, Valid: req.Description != ""},
+		ClientReference: sql.NullString{String: req.ClientReference, Valid: req.ClientReference != ""},
+		Metadata:        metadata,
+	}
+
+	pendingTransfer, err := server.store.CreatePendingTransfer(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, pendingTransfer)
+}
+
+type listPendingTransferRequest struct {
+	Status   string `form:"status" binding:"omitempty,oneof=pending_approval approved rejected"`
+	PageID   int32  `form:"page_id" binding:"required,min=1"`
+	PageSize int32  `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+// listPendingTransfers lists transfers by status for approvers, and the transfers requested by the caller otherwise
+func (server *Server) listPendingTransfers(ctx *gin.Context) {
+	var req listPendingTransferRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	user, valid := server.authenticatedUser(ctx)
+	if !valid {
+		return
+	}
+
+	var pendingTransfers []db.PendingTransfer
+	var err error
+	if user.Role == util.APPROVER_ROLE {
+		status := req.Status
+		if status == "" {
+			status = db.PENDING_TRANSFER_STATUS_PENDING_APPROVAL
+		}
+
+		pendingTransfers, err = server.store.ListPendingTransfers(ctx, db.ListPendingTransfersParams{
+			Status: status,
+			Limit:  req.PageSize,
+			Offset: (req.PageID - 1) * req.PageSize,
+		})
+	} else {
+		pendingTransfers, err = server.store.ListPendingTransfersByRequester(ctx, db.ListPendingTransfersByRequesterParams{
+			RequestedBy: user.Username,
+			Limit:       req.PageSize,
+			Offset:      (req.PageID - 1) * req.PageSize,
+		})
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, pendingTransfers)
+}
+
+type pendingTransferRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type pendingTransferResponse struct {
+	PendingTransfer db.PendingTransfer    `json:"pending_transfer"`
+	Approvals       []db.TransferApproval `json:"approvals"`
+}
+
+// getPendingTransfer returns a pending transfer with the history of its decisions
+func (server *Server) getPendingTransfer(ctx *gin.Context) {
+	var req pendingTransferRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	pendingTransfer, err := server.store.GetPendingTransfer(ctx, req.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	user, valid := server.authenticatedUser(ctx)
+	if !valid {
+		return
+	}
+
+	if user.Role != util.APPROVER_ROLE && user.Username != pendingTransfer.RequestedBy {
+		err := errors.New("pending transfer doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	approvals, err := server.store.ListTransferApprovals(ctx, pendingTransfer.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, pendingTransferResponse{
+		PendingTransfer: pendingTransfer,
+		Approvals:       approvals,
+	})
+}
+
+type decidePendingTransferRequest struct {
+	Comment string `json:"comment" binding:"omitempty,max=255"`
+}
+
+func (server *Server) approvePendingTransfer(ctx *gin.Context) {
+	server.decidePendingTransfer(ctx, server.store.ApproveTransferTx)
+}
+
+func (server *Server) rejectPendingTransfer(ctx *gin.Context) {
+	server.decidePendingTransfer(ctx, server.store.RejectTransferTx)
+}
+
+// decidePendingTransfer lets an approver run the given decision on a pending transfer
+func (server *Server) decidePendingTransfer(ctx *gin.Context, decide func(ctx context.Context, arg db.DecideTransferTxParams) (db.DecideTransferTxResponse, error)) {
+	var uri pendingTransferRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// the body is optional, it only carries the comment of the approver
+	var req decidePendingTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	user, valid := server.authenticatedUser(ctx)
+	if !valid {
+		return
+	}
+
+	if user.Role != util.APPROVER_ROLE {
+		err := errors.New("only approvers can decide on pending transfers")
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return
+	}
+
+	response, err := decide(ctx, db.DecideTransferTxParams{
+		PendingTransferID: uri.ID,
+		Approver:          user.Username,
+		Comment:           req.Comment,
+	})
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+		case db.ErrSelfApproval:
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+		case db.ErrTransferNotPending, db.ErrInsufficientFunds, db.ErrAccountClosed:
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		default:
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// authenticatedUser loads the user of the access token
+func (server *Server) authenticatedUser(ctx *gin.Context) (db.User, bool) {
+	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD_KEY).(*token.Payload)
+
+	user, err := server.store.GetUser(ctx, authPayload.Username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+			return user, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return user, false
+	}
+
+	return user, true
+}
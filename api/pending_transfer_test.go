@@ -0,0 +1,386 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	mockdb "github.com/VL-037/go-bank/db/mock"
+	db "github.com/VL-037/go-bank/db/sqlc"
+	"github.com/VL-037/go-bank/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCreateTransferAboveApprovalThresholdAPI(t *testing.T) {
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+
+	account1 := randomAccount(user1.Username)
+	account2 := randomAccount(user2.Username)
+	account1.Currency = util.USD
+	account2.Currency = util.USD
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+	store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+	store.EXPECT().
+		CreatePendingTransfer(gomock.Any(), gomock.Eq(db.CreatePendingTransferParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        1001,
+			Currency:      util.USD,
+			RequestedBy:   user1.Username,
+			Metadata:      json.RawMessage("{}"),
+		})).
+		Times(1).
+		Return(db.PendingTransfer{ID: 1, Status: db.PENDING_TRANSFER_STATUS_PENDING_APPROVAL}, nil)
+
+	server := newTestServer(t, store)
+	server.approvalThresholds = map[string]int64{util.USD: 1000}
+	recorder := httptest.NewRecorder()
+
+	data, err := json.Marshal(transferRequest{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1001,
+		Currency:      util.USD,
+	})
+	require.NoError(t, err)
+
+	request, err := http.NewRequest(http.MethodPost, "/transfer", bytes.NewReader(data))
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, AUTHORIZATION_TYPE_BEARER, user1.Username, DURATION)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusAccepted, recorder.Code)
+}
+
+func TestGetPendingTransferAPI(t *testing.T) {
+	requester, _ := randomUser(t)
+	approver, _ := randomUser(t)
+	approver.Role = util.APPROVER_ROLE
+	stranger, _ := randomUser(t)
+
+	pendingTransfer := db.PendingTransfer{
+		ID:          util.RandomInt(1, 1000),
+		Amount:      5000,
+		Status:      db.PENDING_TRANSFER_STATUS_PENDING_APPROVAL,
+		RequestedBy: requester.Username,
+	}
+
+	testCases := []struct {
+		name          string
+		user          db.User
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK - Requester",
+			user: requester,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPendingTransfer(gomock.Any(), gomock.Eq(pendingTransfer.ID)).Times(1).Return(pendingTransfer, nil)
+				store.EXPECT().ListTransferApprovals(gomock.Any(), gomock.Eq(pendingTransfer.ID)).Times(1).Return([]db.TransferApproval{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "OK - Approver",
+			user: approver,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPendingTransfer(gomock.Any(), gomock.Eq(pendingTransfer.ID)).Times(1).Return(pendingTransfer, nil)
+				store.EXPECT().ListTransferApprovals(gomock.Any(), gomock.Eq(pendingTransfer.ID)).Times(1).Return([]db.TransferApproval{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "UNAUTHORIZED",
+			user: stranger,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPendingTransfer(gomock.Any(), gomock.Eq(pendingTransfer.ID)).Times(1).Return(pendingTransfer, nil)
+				store.EXPECT().ListTransferApprovals(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "NOT_FOUND",
+			user: requester,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPendingTransfer(gomock.Any(), gomock.Eq(pendingTransfer.ID)).Times(1).Return(db.PendingTransfer{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetUser(gomock.Any(), gomock.Eq(tc.user.Username)).AnyTimes().Return(tc.user, nil)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/pending_transfers/%d", pendingTransfer.ID)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, AUTHORIZATION_TYPE_BEARER, tc.user.Username, DURATION)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestDecidePendingTransferAPI(t *testing.T) {
+	depositor, _ := randomUser(t)
+	approver, _ := randomUser(t)
+	approver.Role = util.APPROVER_ROLE
+
+	id := util.RandomInt(1, 1000)
+
+	testCases := []struct {
+		name          string
+		action        string
+		body          []byte
+		user          db.User
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "OK - Approve",
+			action: "approve",
+			body:   []byte(`{"comment": "checked with the customer"}`),
+			user:   approver,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ApproveTransferTx(gomock.Any(), gomock.Eq(db.DecideTransferTxParams{
+						PendingTransferID: id,
+						Approver:          approver.Username,
+						Comment:           "checked with the customer",
+					})).
+					Times(1).
+					Return(db.DecideTransferTxResponse{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "OK - Reject",
+			action: "reject",
+			user:   approver,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					RejectTransferTx(gomock.Any(), gomock.Eq(db.DecideTransferTxParams{
+						PendingTransferID: id,
+						Approver:          approver.Username,
+					})).
+					Times(1).
+					Return(db.DecideTransferTxResponse{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "FORBIDDEN - Not Approver",
+			action: "approve",
+			user:   depositor,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ApproveTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:   "FORBIDDEN - Self Approval",
+			action: "approve",
+			user:   approver,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ApproveTransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.DecideTransferTxResponse{}, db.ErrSelfApproval)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:   "BAD_REQUEST - Not Pending",
+			action: "reject",
+			user:   approver,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().RejectTransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.DecideTransferTxResponse{}, db.ErrTransferNotPending)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "NOT_FOUND",
+			action: "approve",
+			user:   approver,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ApproveTransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.DecideTransferTxResponse{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetUser(gomock.Any(), gomock.Eq(tc.user.Username)).Times(1).Return(tc.user, nil)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/pending_transfers/%d/%s", id, tc.action)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(tc.body))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, AUTHORIZATION_TYPE_BEARER, tc.user.Username, DURATION)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestRequiresApprovalRejectedAPI(t *testing.T) {
+	payer, _ := randomUser(t)
+	merchant, _ := randomUser(t)
+
+	account1 := randomAccount(payer.Username)
+	account2 := randomAccount(merchant.Username)
+	account1.Currency = util.USD
+	account2.Currency = util.USD
+
+	hold := db.Hold{
+		ID:            util.RandomInt(1, 1000),
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1001,
+		Currency:      util.USD,
+		Status:        db.HOLD_STATUS_ACTIVE,
+		ExpiresAt:     time.Now().Add(time.Hour),
+	}
+
+	testCases := []struct {
+		name       string
+		url        string
+		body       interface{}
+		username   string
+		buildStubs func(store *mockdb.MockStore)
+	}{
+		{
+			name: "Scheduled Transfer",
+			url:  "/scheduled_transfers",
+			body: createScheduledTransferRequest{
+				FromAccountID: account1.ID,
+				ToAccountID:   account2.ID,
+				Amount:        1001,
+				Currency:      util.USD,
+				ExecuteAt:     time.Now().Add(time.Hour),
+			},
+			username: payer.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateScheduledTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name: "Standing Order",
+			url:  "/standing_orders",
+			body: createStandingOrderRequest{
+				FromAccountID: account1.ID,
+				ToAccountID:   account2.ID,
+				Amount:        1001,
+				Currency:      util.USD,
+				Frequency:     util.DAILY,
+				StartAt:       time.Now().Add(time.Hour),
+			},
+			username: payer.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateStandingOrder(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name: "Hold",
+			url:  "/holds",
+			body: createHoldRequest{
+				FromAccountID: account1.ID,
+				ToAccountID:   account2.ID,
+				Amount:        1001,
+				Currency:      util.USD,
+				ExpiresAt:     time.Now().Add(time.Hour),
+			},
+			username: payer.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateHoldTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name:     "Hold Capture",
+			url:      fmt.Sprintf("/holds/%d/capture", hold.ID),
+			body:     captureHoldRequest{},
+			username: merchant.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().CaptureHoldTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			server.approvalThresholds = map[string]int64{util.USD: 1000}
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, tc.url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, AUTHORIZATION_TYPE_BEARER, tc.username, DURATION)
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusBadRequest, recorder.Code)
+			require.Contains(t, recorder.Body.String(), ErrApprovalRequired.Error())
+		})
+	}
+}
@@ -3,11 +3,15 @@ package api
 import (
 	"fmt"
 	db "github.com/VL-037/go-bank/db/sqlc"
+	"github.com/VL-037/go-bank/lockout"
+	"github.com/VL-037/go-bank/mail"
 	"github.com/VL-037/go-bank/token"
 	"github.com/VL-037/go-bank/util"
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
+	"strings"
+	"sync"
 )
 
 // Server serves HTTP requests for the banking service
@@ -15,11 +19,25 @@ type Server struct {
 	config util.Config
 	store  db.Store
 	tokenMaker token.Maker
+	mailer mail.Sender
+	loginLimiter *lockout.Limiter
+	passwordPolicy util.PasswordPolicy
+	passwordHasher util.PasswordHasher
 	router *gin.Engine
+
+	dummyPasswordHashOnce sync.Once
+	dummyPasswordHash     string
+
+	// background tracks the work handlers leave running after they respond
+	background sync.WaitGroup
+
+	approvalThresholds   map[string]int64
+	coolingOffThresholds map[string]int64
+	stepUpThresholds     map[string]int64
 }
 
 // NewServer for routing
-func NewServer(config util.Config, store db.Store) (*Server, error) {
+func NewServer(config util.Config, store db.Store, mailer mail.Sender) (*Server, error) {
 	tokenMaker, err := token.NewPasetoMaker(config.TokenSymmetricKey) // to use Paseto
 	//tokenMaker, err := token.NewJWTMaker(config.TokenSymmetricKey) // want to use JWT
 
@@ -27,35 +45,171 @@ func NewServer(config util.Config, store db.Store) (*Server, error) {
 		return nil, fmt.Errorf("cannot create token maker %w", err)
 	}
 
+	approvalThresholds, err := util.ParseCurrencyAmounts(config.TransferApprovalThresholds)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse transfer approval thresholds %w", err)
+	}
+
+	coolingOffThresholds, err := util.ParseCurrencyAmounts(config.BeneficiaryCoolingOffThresholds)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse beneficiary cooling-off thresholds %w", err)
+	}
+
+	stepUpThresholds, err := util.ParseCurrencyAmounts(config.MFAStepUpThresholds)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse mfa step-up thresholds %w", err)
+	}
+
+	passwordHasher, err := util.NewPasswordHasher(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create password hasher %w", err)
+	}
+
+	loginAttempts, err := lockout.NewStore(config.LoginAttemptStore, store)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create login attempt store %w", err)
+	}
+
+	loginLimiter := lockout.NewLimiter(loginAttempts, lockout.Policy{
+		DelayAfter:      config.LoginDelayAfter,
+		BaseDelay:       config.LoginBaseDelay,
+		MaxFailures:     config.LoginMaxFailures,
+		LockoutDuration: config.LoginLockoutDuration,
+	}, lockout.Policy{
+		MaxFailures:     config.LoginIPMaxFailures,
+		LockoutDuration: config.LoginLockoutDuration,
+	})
+
 	server := &Server{
 		config: config,
 		store: store,
 		tokenMaker: tokenMaker,
+		mailer: mailer,
+		loginLimiter: loginLimiter,
+		passwordPolicy: util.PasswordPolicy{
+			MinLength:            config.PasswordMinLength,
+			RequireUpper:         config.PasswordRequireUpper,
+			RequireLower:         config.PasswordRequireLower,
+			RequireDigit:         config.PasswordRequireDigit,
+			RequireSymbol:        config.PasswordRequireSymbol,
+			BreachedPasswordsDir: config.BreachedPasswordsDir,
+		},
+		passwordHasher: passwordHasher,
+		approvalThresholds: approvalThresholds,
+		coolingOffThresholds: coolingOffThresholds,
+		stepUpThresholds: stepUpThresholds,
 	}
 
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
 		v.RegisterValidation("currency", validCurrency)
+		v.RegisterValidation("frequency", validFrequency)
+		v.RegisterValidation("account_number", validAccountNumber)
 	}
 
-	server.setupRouter()
+	if err := server.setupRouter(); err != nil {
+		return nil, fmt.Errorf("cannot set up router %w", err)
+	}
 	return server, nil
 }
 
-func (server *Server) setupRouter() {
+func (server *Server) setupRouter() error {
 	router := gin.Default()
 
+	// the login throttling keys on the client address, so only configured proxies may forward it
+	var trustedProxies []string
+	for _, proxy := range strings.Split(server.config.TrustedProxies, ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			trustedProxies = append(trustedProxies, proxy)
+		}
+	}
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		return err
+	}
+
 	router.POST("/users", server.createUser)
 	router.POST("/users/login", server.loginUser)
+	router.POST("/users/login/mfa", server.loginMFA)
+	router.POST("/users/password/forgot", server.forgotPassword)
+	router.POST("/users/password/reset", server.resetPassword)
+	router.GET("/verify_email", server.verifyEmail)
+	router.POST("/oauth/token", server.createOAuthToken)
+	router.POST("/oauth/introspect", server.introspectOAuthToken)
+
+	authRoutes := router.Group("/").Use(authMiddleware(server.tokenMaker, server.store))
+
+	// routes moving money additionally require a verified email address
+	verified := verifiedEmailMiddleware()
+
+	authRoutes.PATCH("/users/me", server.updateUser)
+	authRoutes.DELETE("/users/me", server.deleteUser)
+	authRoutes.GET("/users/me/export", server.exportUser)
+	authRoutes.POST("/users/me/password", server.changePassword)
+	authRoutes.POST("/users/me/verify_email", server.resendVerificationEmail)
+	authRoutes.POST("/users/me/totp", server.enrollTOTP)
+	authRoutes.POST("/users/me/totp/confirm", server.confirmTOTP)
+	authRoutes.DELETE("/users/me/totp", server.disableTOTP)
+	authRoutes.POST("/users/me/api_keys", server.createAPIKey)
+	authRoutes.GET("/users/me/api_keys", server.listAPIKeys)
+	authRoutes.DELETE("/users/me/api_keys/:id", server.revokeAPIKey)
+	authRoutes.GET("/users/me/oauth_consents", server.listOAuthConsents)
+	authRoutes.DELETE("/users/me/oauth_consents/:client_id", server.revokeOAuthConsent)
+
+	authRoutes.POST("/oauth/authorize", server.authorizeOAuthClient)
+	authRoutes.GET("/oauth/clients/me", server.getOAuthClient)
 
-	authRoutes := router.Group("/").Use(authMiddleware(server.tokenMaker))
+	authRoutes.POST("/admin/users/:username/unlock", server.unlockUser)
+	authRoutes.POST("/admin/oauth_clients", server.registerOAuthClient)
 
 	authRoutes.POST("/accounts", server.createAccount)
 	authRoutes.GET("/accounts/:id", server.getAccount)
+	authRoutes.GET("/accounts/:id/statements", server.getAccountStatement)
+	authRoutes.GET("/accounts/:id/exports", server.exportAccountStatement)
+	authRoutes.GET("/accounts/:id/balance", server.getAccountBalance)
 	authRoutes.GET("/accounts", server.listAccounts)
+	authRoutes.DELETE("/accounts/:id", server.closeAccount)
+
+	authRoutes.POST("/transfer", verified, server.createTransfer)
+	authRoutes.GET("/transfers", server.listTransfers)
+	authRoutes.GET("/payees/lookup", server.lookupPayee)
+
+	authRoutes.POST("/beneficiaries", server.createBeneficiary)
+	authRoutes.GET("/beneficiaries", server.listBeneficiaries)
+	authRoutes.GET("/beneficiaries/:id", server.getBeneficiary)
+	authRoutes.PATCH("/beneficiaries/:id", server.updateBeneficiary)
+	authRoutes.DELETE("/beneficiaries/:id", server.deleteBeneficiary)
+
+	authRoutes.POST("/transfer_batches", verified, server.createTransferBatch)
+	authRoutes.GET("/transfer_batches/:id", server.getTransferBatch)
+
+	authRoutes.GET("/pending_transfers", server.listPendingTransfers)
+	authRoutes.GET("/pending_transfers/:id", server.getPendingTransfer)
+	authRoutes.POST("/pending_transfers/:id/approve", verified, server.approvePendingTransfer)
+	authRoutes.POST("/pending_transfers/:id/reject", server.rejectPendingTransfer)
+
+	authRoutes.POST("/payment_requests", server.createPaymentRequest)
+	authRoutes.GET("/payment_requests", server.listPaymentRequests)
+	authRoutes.POST("/payment_requests/:id/accept", verified, server.acceptPaymentRequest)
+	authRoutes.POST("/payment_requests/:id/decline", server.declinePaymentRequest)
+
+	authRoutes.POST("/scheduled_transfers", verified, server.createScheduledTransfer)
+	authRoutes.GET("/scheduled_transfers", server.listScheduledTransfers)
+	authRoutes.POST("/scheduled_transfers/:id/cancel", server.cancelScheduledTransfer)
+
+	authRoutes.POST("/standing_orders", verified, server.createStandingOrder)
+	authRoutes.GET("/standing_orders", server.listStandingOrders)
+	authRoutes.POST("/standing_orders/:id/pause", server.pauseStandingOrder)
+	authRoutes.POST("/standing_orders/:id/resume", server.resumeStandingOrder)
+	authRoutes.DELETE("/standing_orders/:id", server.deleteStandingOrder)
+
+	authRoutes.POST("/holds", verified, server.createHold)
+	authRoutes.GET("/holds/:id", server.getHold)
+	authRoutes.POST("/holds/:id/capture", verified, server.captureHold)
+	authRoutes.POST("/holds/:id/void", server.voidHold)
 
-	authRoutes.POST("/transfer", server.createTransfer)
+	authRoutes.GET("/notifications", server.listNotifications)
 
 	server.router = router
+	return nil
 }
 
 // Start runs the HTTP server on a specific address
@@ -0,0 +1,243 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	db "github.com/VL-037/go-bank/db/sqlc"
+	"github.com/VL-037/go-bank/token"
+	"github.com/VL-037/go-bank/util"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+type createStandingOrderRequest struct {
+	FromAccountID     int64      `json:"from_account_id" binding:"required_without=FromAccountNumber,excluded_with=FromAccountNumber,omitempty,min=1"`
+	FromAccountNumber string     `json:"from_account_number" binding:"omitempty,account_number"`
+	ToAccountID       int64      `json:"to_account_id" binding:"required_without=ToAccountNumber,excluded_with=ToAccountNumber,omitempty,min=1"`
+	ToAccountNumber   string     `json:"to_account_number" binding:"omitempty,account_number"`
+	Amount            int64      `json:"amount" binding:"required,gt=0"`
+	Currency          string     `json:"currency" binding:"required,currency"`
+	Frequency         string     `json:"frequency" binding:"required,frequency"`
+	DayOfMonth        int32      `json:"day_of_month" binding:"required_if=Frequency monthly,omitempty,min=1,max=31"`
+	StartAt           time.Time  `json:"start_at" binding:"required"`
+	EndAt             *time.Time `json:"end_at"`
+	MaxExecutions     int32      `json:"max_executions" binding:"omitempty,min=1"`
+	MFACode           string     `json:"mfa_code" binding:"omitempty,max=32"`
+}
+
+func (server *Server) createStandingOrder(ctx *gin.Context) {
+	var req createStandingOrderRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if !req.StartAt.After(time.Now()) {
+		err := errors.New("start_at must be in the future")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.EndAt != nil && !req.EndAt.After(req.StartAt) {
+		err := errors.New("end_at must be after start_at")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if server.requiresApproval(req.Currency, req.Amount) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(ErrApprovalRequired))
+		return
+	}
+
+	fromAccount, valid := server.validAccountRef(ctx, req.FromAccountID, req.FromAccountNumber, req.Currency)
+	if !valid {
+		return
+	}
+	req.FromAccountID = fromAccount.ID
+
+	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD_KEY).(*token.Payload)
+	if authPayload.Username != fromAccount.Owner {
+		err := errors.New("from account doesn't belong to authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	// every occurrence moves the amount, so it is stepped up once when the order is set up
+	if !server.validStepUp(ctx, authPayload.Username, req.Currency, req.Amount, req.MFACode) {
+		return
+	}
+
+	toAccount, valid := server.validAccountRef(ctx, req.ToAccountID, req.ToAccountNumber, req.Currency)
+	if !valid {
+		return
+	}
+	req.ToAccountID = toAccount.ID
+
+	if !server.validCoolingOff(ctx, authPayload.Username, toAccount, req.Amount) {
+		return
+	}
+
+	arg := db.CreateStandingOrderParams{
+		FromAccountID:   req.FromAccountID,
+		ToAccountID:     req.ToAccountID,
+		Amount:          req.Amount,
+		Currency:        req.Currency,
+		Frequency:       req.Frequency,
+		DayOfMonth:      sql.NullInt32{Int32: req.DayOfMonth, Valid: req.Frequency == util.MONTHLY},
+		StartAt:         req.StartAt,
+		MaxExecutions:   sql.NullInt32{Int32: req.MaxExecutions, Valid: req.MaxExecutions > 0},
+		NextExecutionAt: util.NextOccurrence(req.Frequency, int(req.DayOfMonth), req.StartAt, req.StartAt.Add(-time.Nanosecond)),
+	}
+	if req.EndAt != nil {
+		arg.EndAt = sql.NullTime{Time: *req.EndAt, Valid: true}
+	}
+
+	standingOrder, err := server.store.CreateStandingOrder(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, standingOrder)
+}
+
+type listStandingOrderRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+func (server *Server) listStandingOrders(ctx *gin.Context) {
+	var req listStandingOrderRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD_KEY).(*token.Payload)
+	arg := db.ListStandingOrdersParams{
+		Owner:  authPayload.Username,
+		Limit:  req.PageSize,
+		Offset: (req.PageID - 1) * req.PageSize,
+	}
+
+	standingOrders, err := server.store.ListStandingOrders(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, standingOrders)
+}
+
+type standingOrderRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) pauseStandingOrder(ctx *gin.Context) {
+	var req standingOrderRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, valid := server.ownedStandingOrder(ctx, req.ID); !valid {
+		return
+	}
+
+	standingOrder, err := server.store.PauseStandingOrder(ctx, req.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err := errors.New("only active standing orders can be paused")
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, standingOrder)
+}
+
+func (server *Server) resumeStandingOrder(ctx *gin.Context) {
+	var req standingOrderRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	standingOrder, valid := server.ownedStandingOrder(ctx, req.ID)
+	if !valid {
+		return
+	}
+
+	// occurrences missed while paused are skipped instead of executed all at once
+	nextExecutionAt := standingOrder.NextExecutionAt
+	if now := time.Now(); nextExecutionAt.Before(now) {
+		nextExecutionAt = util.NextOccurrence(standingOrder.Frequency, int(standingOrder.DayOfMonth.Int32), standingOrder.StartAt, now)
+	}
+
+	standingOrder, err := server.store.ResumeStandingOrder(ctx, db.ResumeStandingOrderParams{
+		ID:              req.ID,
+		NextExecutionAt: nextExecutionAt,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err := errors.New("only paused standing orders can be resumed")
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, standingOrder)
+}
+
+func (server *Server) deleteStandingOrder(ctx *gin.Context) {
+	var req standingOrderRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, valid := server.ownedStandingOrder(ctx, req.ID); !valid {
+		return
+	}
+
+	err := server.store.DeleteStandingOrder(ctx, req.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// ownedStandingOrder loads a standing order and makes sure its from account belongs to the authenticated user
+func (server *Server) ownedStandingOrder(ctx *gin.Context, id int64) (db.StandingOrder, bool) {
+	standingOrder, err := server.store.GetStandingOrder(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return standingOrder, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return standingOrder, false
+	}
+
+	fromAccount, err := server.store.GetAccount(ctx, standingOrder.FromAccountID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return standingOrder, false
+	}
+
+	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD_KEY).(*token.Payload)
+	if authPayload.Username != fromAccount.Owner {
+		err := errors.New("standing order doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return standingOrder, false
+	}
+
+	return standingOrder, true
+}
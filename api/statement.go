@@ -0,0 +1,191 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	db "github.com/VL-037/go-bank/db/sqlc"
+	"github.com/VL-037/go-bank/statement"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+// Formats of a statement
+const (
+	STATEMENT_FORMAT_CSV     = "csv"
+	STATEMENT_FORMAT_PDF     = "pdf"
+	STATEMENT_FORMAT_CAMT053 = "camt053"
+	STATEMENT_FORMAT_MT940   = "mt940"
+	STATEMENT_FORMAT_OFX     = "ofx"
+	STATEMENT_FORMAT_QIF     = "qif"
+)
+
+// MAX_EXPORT_DAYS bounds the date range of an export
+const MAX_EXPORT_DAYS = 366
+
+var statementContentTypes = map[string]string{
+	STATEMENT_FORMAT_CSV:     "text/csv",
+	STATEMENT_FORMAT_PDF:     "application/pdf",
+	STATEMENT_FORMAT_CAMT053: "application/xml",
+	STATEMENT_FORMAT_MT940:   "text/plain",
+	STATEMENT_FORMAT_OFX:     "application/x-ofx",
+	STATEMENT_FORMAT_QIF:     "application/qif",
+}
+
+var statementFileExtensions = map[string]string{
+	STATEMENT_FORMAT_CSV:     "csv",
+	STATEMENT_FORMAT_PDF:     "pdf",
+	STATEMENT_FORMAT_CAMT053: "xml",
+	STATEMENT_FORMAT_MT940:   "sta",
+	STATEMENT_FORMAT_OFX:     "ofx",
+	STATEMENT_FORMAT_QIF:     "qif",
+}
+
+type getAccountStatementRequest struct {
+	Month  string `form:"month" binding:"required,datetime=2006-01"`
+	Format string `form:"format" binding:"omitempty,oneof=csv pdf"`
+}
+
+// getAccountStatement renders the monthly statement of an account as a CSV or PDF download
+func (server *Server) getAccountStatement(ctx *gin.Context) {
+	account, valid := server.uriAccount(ctx)
+	if !valid {
+		return
+	}
+
+	var req getAccountStatementRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	format := req.Format
+	if format == "" {
+		format = STATEMENT_FORMAT_PDF
+	}
+
+	periodStart, err := time.Parse("2006-01", req.Month)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	periodEnd := periodStart.AddDate(0, 1, 0)
+
+	if periodStart.After(time.Now()) {
+		err := errors.New("month must not be in the future")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	accountStatement, err := server.accountStatement(ctx, account, periodStart, periodEnd)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	server.writeStatement(ctx, accountStatement, format, req.Month)
+}
+
+type exportAccountStatementRequest struct {
+	Format string `form:"format" binding:"required,oneof=camt053 mt940 ofx qif"`
+	From   string `form:"from" binding:"required,datetime=2006-01-02"`
+	To     string `form:"to" binding:"required,datetime=2006-01-02"`
+}
+
+// exportAccountStatement exports the entries of an account between two dates, both included,
+// in the statement formats imported by accounting software and personal finance apps
+func (server *Server) exportAccountStatement(ctx *gin.Context) {
+	account, valid := server.uriAccount(ctx)
+	if !valid {
+		return
+	}
+
+	var req exportAccountStatementRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	from, err := time.Parse("2006-01-02", req.From)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	to, err := time.Parse("2006-01-02", req.To)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	periodEnd := to.AddDate(0, 0, 1)
+
+	if to.Before(from) {
+		err := errors.New("to must not be before from")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if periodEnd.Sub(from) > MAX_EXPORT_DAYS*24*time.Hour {
+		err := fmt.Errorf("date range must not exceed %d days", MAX_EXPORT_DAYS)
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	accountStatement, err := server.accountStatement(ctx, account, from, periodEnd)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	server.writeStatement(ctx, accountStatement, req.Format, fmt.Sprintf("%s-%s", req.From, req.To))
+}
+
+// writeStatement renders a statement in the given format as a file download
+func (server *Server) writeStatement(ctx *gin.Context, accountStatement statement.Statement, format string, period string) {
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case STATEMENT_FORMAT_CSV:
+		err = accountStatement.WriteCSV(&buf)
+	case STATEMENT_FORMAT_PDF:
+		err = accountStatement.WritePDF(&buf)
+	case STATEMENT_FORMAT_CAMT053:
+		err = accountStatement.WriteCamt053(&buf)
+	case STATEMENT_FORMAT_MT940:
+		err = accountStatement.WriteMT940(&buf)
+	case STATEMENT_FORMAT_OFX:
+		err = accountStatement.WriteOFX(&buf)
+	case STATEMENT_FORMAT_QIF:
+		err = accountStatement.WriteQIF(&buf)
+	default:
+		err = fmt.Errorf("unsupported statement format %q", format)
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	filename := fmt.Sprintf("statement-%s-%s.%s", accountStatement.Account.Number, period, statementFileExtensions[format])
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	ctx.Data(http.StatusOK, statementContentTypes[format], buf.Bytes())
+}
+
+// accountStatement computes the opening balance of the period and lists the entries of the period
+func (server *Server) accountStatement(ctx *gin.Context, account db.Account, periodStart, periodEnd time.Time) (statement.Statement, error) {
+	openingBalance, err := server.balanceAt(ctx, account, periodStart)
+	if err != nil {
+		return statement.Statement{}, err
+	}
+
+	entries, err := server.store.ListAccountStatementEntries(ctx, db.ListAccountStatementEntriesParams{
+		AccountID: account.ID,
+		FromTime:  periodStart,
+		ToTime:    periodEnd,
+	})
+	if err != nil {
+		return statement.Statement{}, err
+	}
+
+	return statement.New(account, periodStart, periodEnd, openingBalance, entries), nil
+}
@@ -0,0 +1,328 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	mockdb "github.com/VL-037/go-bank/db/mock"
+	db "github.com/VL-037/go-bank/db/sqlc"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetAccountStatementAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	account.Currency = "USD"
+	account.Balance = 15000
+
+	otherUser, _ := randomUser(t)
+	otherAccount := randomAccount(otherUser.Username)
+
+	periodStart := time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)
+	entries := []db.ListAccountStatementEntriesRow{
+		{ID: 1, Amount: 2500, CreatedAt: periodStart.Add(time.Hour)},
+		{ID: 2, Amount: -1000, CreatedAt: periodStart.Add(2 * time.Hour)},
+	}
+
+	buildStatementStubs := func(store *mockdb.MockStore) {
+		store.EXPECT().
+			GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+			Times(1).
+			Return(account, nil)
+		store.EXPECT().
+			GetLatestBalanceSnapshot(gomock.Any(), gomock.Any()).
+			Times(1).
+			Return(db.AccountBalanceSnapshot{}, sql.ErrNoRows)
+		store.EXPECT().
+			SumAccountEntriesSince(gomock.Any(), gomock.Eq(db.SumAccountEntriesSinceParams{
+				AccountID: account.ID,
+				Since:     periodStart,
+			})).
+			Times(1).
+			Return(int64(5000), nil)
+		store.EXPECT().
+			ListAccountStatementEntries(gomock.Any(), gomock.Eq(db.ListAccountStatementEntriesParams{
+				AccountID: account.ID,
+				FromTime:  periodStart,
+				ToTime:    periodStart.AddDate(0, 1, 0),
+			})).
+			Times(1).
+			Return(entries, nil)
+	}
+
+	testCases := []struct {
+		name          string
+		accountID     int64
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:       "OK - CSV",
+			accountID:  account.ID,
+			query:      "month=2023-01&format=csv",
+			buildStubs: buildStatementStubs,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "text/csv", recorder.Header().Get("Content-Type"))
+				require.Equal(t, fmt.Sprintf(`attachment; filename="statement-%s-2023-01.csv"`, account.Number), recorder.Header().Get("Content-Disposition"))
+
+				expected := "date,entry_id,description,amount,balance\n" +
+					"2023-01-01,,Opening balance,,100.00\n" +
+					"2023-01-01,1,Credit,25.00,125.00\n" +
+					"2023-01-01,2,Debit,-10.00,115.00\n" +
+					"2023-01-31,,Closing balance,,115.00\n"
+				require.Equal(t, expected, recorder.Body.String())
+			},
+		},
+		{
+			name:       "OK - PDF",
+			accountID:  account.ID,
+			query:      "month=2023-01",
+			buildStubs: buildStatementStubs,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "application/pdf", recorder.Header().Get("Content-Type"))
+				require.True(t, strings.HasPrefix(recorder.Body.String(), "%PDF-1.4"))
+			},
+		},
+		{
+			name:      "UNAUTHORIZED - Other User's Account",
+			accountID: otherAccount.ID,
+			query:     "month=2023-01",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(otherAccount.ID)).
+					Times(1).
+					Return(otherAccount, nil)
+				store.EXPECT().SumAccountEntriesSince(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:      "BAD_REQUEST - Invalid Month",
+			accountID: account.ID,
+			query:     "month=2023-13",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(1).Return(account, nil)
+				store.EXPECT().SumAccountEntriesSince(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "BAD_REQUEST - Future Month",
+			accountID: account.ID,
+			query:     "month=" + time.Now().AddDate(0, 2, 0).Format("2006-01"),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(1).Return(account, nil)
+				store.EXPECT().SumAccountEntriesSince(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "BAD_REQUEST - Invalid Format",
+			accountID: account.ID,
+			query:     "month=2023-01&format=xls",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(1).Return(account, nil)
+				store.EXPECT().SumAccountEntriesSince(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "INTERNAL_SERVER_ERROR",
+			accountID: account.ID,
+			query:     "month=2023-01",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(1).Return(account, nil)
+				store.EXPECT().
+					GetLatestBalanceSnapshot(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AccountBalanceSnapshot{}, sql.ErrNoRows)
+				store.EXPECT().
+					SumAccountEntriesSince(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/%d/statements?%s", tc.accountID, tc.query)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, AUTHORIZATION_TYPE_BEARER, user.Username, DURATION)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestExportAccountStatementAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	account.Currency = "EUR"
+	account.Balance = 15000
+
+	periodStart := time.Date(2023, time.March, 1, 0, 0, 0, 0, time.UTC)
+	periodEnd := time.Date(2023, time.March, 16, 0, 0, 0, 0, time.UTC)
+	entries := []db.ListAccountStatementEntriesRow{
+		{ID: 1, Amount: 2500, CreatedAt: periodStart.Add(time.Hour)},
+	}
+
+	buildExportStubs := func(store *mockdb.MockStore) {
+		store.EXPECT().
+			GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+			Times(1).
+			Return(account, nil)
+		store.EXPECT().
+			GetLatestBalanceSnapshot(gomock.Any(), gomock.Any()).
+			Times(1).
+			Return(db.AccountBalanceSnapshot{}, sql.ErrNoRows)
+		store.EXPECT().
+			SumAccountEntriesSince(gomock.Any(), gomock.Eq(db.SumAccountEntriesSinceParams{
+				AccountID: account.ID,
+				Since:     periodStart,
+			})).
+			Times(1).
+			Return(int64(2500), nil)
+		store.EXPECT().
+			ListAccountStatementEntries(gomock.Any(), gomock.Eq(db.ListAccountStatementEntriesParams{
+				AccountID: account.ID,
+				FromTime:  periodStart,
+				ToTime:    periodEnd,
+			})).
+			Times(1).
+			Return(entries, nil)
+	}
+
+	buildBadRequestStubs := func(store *mockdb.MockStore) {
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(1).Return(account, nil)
+		store.EXPECT().SumAccountEntriesSince(gomock.Any(), gomock.Any()).Times(0)
+	}
+
+	testCases := []struct {
+		name          string
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:       "OK - camt.053",
+			query:      "format=camt053&from=2023-03-01&to=2023-03-15",
+			buildStubs: buildExportStubs,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "application/xml", recorder.Header().Get("Content-Type"))
+				require.Equal(t, fmt.Sprintf(`attachment; filename="statement-%s-2023-03-01-2023-03-15.xml"`, account.Number), recorder.Header().Get("Content-Disposition"))
+				require.Contains(t, recorder.Body.String(), `<Amt Ccy="EUR">150.00</Amt>`)
+			},
+		},
+		{
+			name:       "OK - MT940",
+			query:      "format=mt940&from=2023-03-01&to=2023-03-15",
+			buildStubs: buildExportStubs,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "text/plain", recorder.Header().Get("Content-Type"))
+				require.Contains(t, recorder.Body.String(), ":60F:C230301EUR125,00\r\n")
+				require.Contains(t, recorder.Body.String(), ":62F:C230315EUR150,00\r\n")
+			},
+		},
+		{
+			name:       "OK - OFX",
+			query:      "format=ofx&from=2023-03-01&to=2023-03-15",
+			buildStubs: buildExportStubs,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "application/x-ofx", recorder.Header().Get("Content-Type"))
+				require.Contains(t, recorder.Body.String(), "<FITID>1</FITID>")
+				require.Contains(t, recorder.Body.String(), "<BALAMT>150.00</BALAMT>")
+			},
+		},
+		{
+			name:       "OK - QIF",
+			query:      "format=qif&from=2023-03-01&to=2023-03-15",
+			buildStubs: buildExportStubs,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, fmt.Sprintf(`attachment; filename="statement-%s-2023-03-01-2023-03-15.qif"`, account.Number), recorder.Header().Get("Content-Disposition"))
+				require.Equal(t, "!Type:Bank\nD03/01/2023\nT25.00\nN1\n^\n", recorder.Body.String())
+			},
+		},
+		{
+			name:       "BAD_REQUEST - Invalid Format",
+			query:      "format=pdf&from=2023-03-01&to=2023-03-15",
+			buildStubs: buildBadRequestStubs,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:       "BAD_REQUEST - To Before From",
+			query:      "format=mt940&from=2023-03-15&to=2023-03-01",
+			buildStubs: buildBadRequestStubs,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:       "BAD_REQUEST - Range Too Long",
+			query:      "format=mt940&from=2022-01-01&to=2023-03-15",
+			buildStubs: buildBadRequestStubs,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/%d/exports?%s", account.ID, tc.query)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, AUTHORIZATION_TYPE_BEARER, user.Username, DURATION)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,335 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	db "github.com/VL-037/go-bank/db/sqlc"
+	"github.com/VL-037/go-bank/token"
+	"github.com/VL-037/go-bank/totp"
+	"github.com/VL-037/go-bank/util"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	RECOVERY_CODE_COUNT = 10
+	RECOVERY_CODE_BYTES = 5
+)
+
+var (
+	ErrTOTPAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrTOTPNotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrTOTPNotEnrolled    = errors.New("two-factor authentication enrollment has not been started")
+	ErrInvalidMFACode     = errors.New("mfa code is invalid")
+	ErrMFARequired        = errors.New("mfa code is required")
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+type enrollTOTPResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// enrollTOTP generates a new TOTP secret, two-factor authentication is enabled once a code is confirmed
+func (server *Server) enrollTOTP(ctx *gin.Context) {
+	user, valid := server.authenticatedUser(ctx)
+	if !valid {
+		return
+	}
+
+	if user.IsTotpEnabled {
+		ctx.JSON(http.StatusBadRequest, errorResponse(ErrTOTPAlreadyEnabled))
+		return
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	_, err = server.store.SetUserTOTPSecret(ctx, db.SetUserTOTPSecretParams{
+		Username:   user.Username,
+		TotpSecret: sql.NullString{String: secret, Valid: true},
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusBadRequest, errorResponse(ErrTOTPAlreadyEnabled))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, enrollTOTPResponse{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(server.config.TOTPIssuer, user.Username, secret),
+	})
+}
+
+type confirmTOTPRequest struct {
+	Code string `json:"code" binding:"required,len=6,numeric"`
+}
+
+type confirmTOTPResponse struct {
+	RecoveryCodes []string     `json:"recovery_codes"`
+	User          userResponse `json:"user"`
+}
+
+// confirmTOTP enables two-factor authentication with a code of the enrolled secret,
+// the recovery codes are only shown in this response
+func (server *Server) confirmTOTP(ctx *gin.Context) {
+	var req confirmTOTPRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	user, valid := server.authenticatedUser(ctx)
+	if !valid {
+		return
+	}
+
+	if user.IsTotpEnabled {
+		ctx.JSON(http.StatusBadRequest, errorResponse(ErrTOTPAlreadyEnabled))
+		return
+	}
+	if !user.TotpSecret.Valid {
+		ctx.JSON(http.StatusBadRequest, errorResponse(ErrTOTPNotEnrolled))
+		return
+	}
+
+	step, ok := totp.Validate(user.TotpSecret.String, req.Code, time.Now())
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, errorResponse(ErrInvalidMFACode))
+		return
+	}
+
+	recoveryCodes := make([]string, RECOVERY_CODE_COUNT)
+	recoveryCodeHashes := make([]string, RECOVERY_CODE_COUNT)
+	for i := range recoveryCodes {
+		recoveryCode, err := newRecoveryCode()
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		recoveryCodes[i] = recoveryCode
+		recoveryCodeHashes[i] = util.HashToken(normalizeRecoveryCode(recoveryCode))
+	}
+
+	response, err := server.store.EnableTOTPTx(ctx, db.EnableTOTPTxParams{
+		Username:           user.Username,
+		Step:               step,
+		RecoveryCodeHashes: recoveryCodeHashes,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, confirmTOTPResponse{
+		RecoveryCodes: recoveryCodes,
+		User:          newUserResponse(response.User),
+	})
+}
+
+type disableTOTPRequest struct {
+	Code string `json:"code" binding:"required,max=32"`
+}
+
+// disableTOTP turns off two-factor authentication, it takes a TOTP or a recovery code
+func (server *Server) disableTOTP(ctx *gin.Context) {
+	var req disableTOTPRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	user, valid := server.authenticatedUser(ctx)
+	if !valid {
+		return
+	}
+
+	if !user.IsTotpEnabled {
+		ctx.JSON(http.StatusBadRequest, errorResponse(ErrTOTPNotEnabled))
+		return
+	}
+
+	if !server.validMFACode(ctx, user, req.Code) {
+		return
+	}
+
+	user, err := server.store.DisableUserTOTP(ctx, user.Username)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newUserResponse(user))
+}
+
+type mfaChallengeResponse struct {
+	MFARequired    bool   `json:"mfa_required"`
+	ChallengeToken string `json:"challenge_token"`
+}
+
+type loginMFARequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required,max=32"`
+}
+
+// loginMFA exchanges the challenge token of loginUser and a TOTP or recovery code for an access token
+func (server *Server) loginMFA(ctx *gin.Context) {
+	var req loginMFARequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	payload, err := server.tokenMaker.VerifyToken(req.ChallengeToken)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+	if payload.Purpose != token.PURPOSE_MFA_CHALLENGE {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(token.ErrInvalidToken))
+		return
+	}
+
+	user, err := server.store.GetUser(ctx, payload.Username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if payload.IssuedAt.Before(user.TokensValidAfter) {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(token.ErrRevokedToken))
+		return
+	}
+	if !user.IsTotpEnabled {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(ErrTOTPNotEnabled))
+		return
+	}
+
+	if !server.validMFACode(ctx, user, req.Code) {
+		return
+	}
+
+	accessToken, err := server.tokenMaker.CreateToken(user.Username, server.config.AccessTokenDuration)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, loginUserResponse{
+		AccessToken: accessToken,
+		User:        newUserResponse(user),
+	})
+}
+
+// validStepUp asks users with two-factor authentication for a code on amounts above the step-up threshold
+func (server *Server) validStepUp(ctx *gin.Context, username string, currency string, amount int64, code string) bool {
+	threshold, ok := server.stepUpThresholds[currency]
+	if !ok || amount <= threshold {
+		return true
+	}
+
+	user, err := server.store.GetUser(ctx, username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+			return false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+
+	if !user.IsTotpEnabled {
+		return true
+	}
+	if code == "" {
+		ctx.JSON(http.StatusForbidden, errorResponse(ErrMFARequired))
+		return false
+	}
+
+	return server.validMFACode(ctx, user, code)
+}
+
+// validMFACode accepts a TOTP code once or an unused recovery code of the user,
+// the code is guessed under the same limits as the password wherever it is asked for
+func (server *Server) validMFACode(ctx *gin.Context, user db.User, code string) bool {
+	if !server.allowLogin(ctx, user.Username) {
+		return false
+	}
+
+	err := server.useMFACode(ctx, user, code)
+	if err != nil {
+		if err == ErrInvalidMFACode {
+			server.failLogin(ctx, user.Username, err)
+			return false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+
+	err = server.loginLimiter.Succeed(ctx, user.Username)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+
+	return true
+}
+
+func (server *Server) useMFACode(ctx context.Context, user db.User, code string) error {
+	code = strings.TrimSpace(code)
+
+	if step, ok := totp.Validate(user.TotpSecret.String, code, time.Now()); ok {
+		rows, err := server.store.UseUserTOTPStep(ctx, db.UseUserTOTPStepParams{
+			Username:         user.Username,
+			TotpLastUsedStep: step,
+		})
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return ErrInvalidMFACode
+		}
+		return nil
+	}
+
+	_, err := server.store.UseRecoveryCode(ctx, db.UseRecoveryCodeParams{
+		Username: user.Username,
+		CodeHash: util.HashToken(normalizeRecoveryCode(code)),
+	})
+	if err == sql.ErrNoRows {
+		return ErrInvalidMFACode
+	}
+	return err
+}
+
+// newRecoveryCode returns a random code formatted as xxxx-xxxx
+func newRecoveryCode() (string, error) {
+	b := make([]byte, RECOVERY_CODE_BYTES)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate recovery code: %w", err)
+	}
+
+	code := strings.ToLower(recoveryCodeEncoding.EncodeToString(b))
+	return code[:4] + "-" + code[4:], nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
@@ -0,0 +1,792 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	mockdb "github.com/VL-037/go-bank/db/mock"
+	db "github.com/VL-037/go-bank/db/sqlc"
+	"github.com/VL-037/go-bank/lockout"
+	"github.com/VL-037/go-bank/token"
+	"github.com/VL-037/go-bank/totp"
+	"github.com/VL-037/go-bank/util"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func randomTOTPUser(t *testing.T) (user db.User, secret string) {
+	user, _ = randomUser(t)
+
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+
+	user.TotpSecret = sql.NullString{String: secret, Valid: true}
+	user.IsTotpEnabled = true
+	return
+}
+
+func currentTOTPCode(t *testing.T, secret string) string {
+	code, err := totp.GenerateCode(secret, time.Now())
+	require.NoError(t, err)
+	return code
+}
+
+func TestEnrollTOTPAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	enabledUser, _ := randomTOTPUser(t)
+
+	testCases := []struct {
+		name          string
+		user          db.User
+		buildStubs    func(store *mockdb.MockStore, user db.User)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			user: user,
+			buildStubs: func(store *mockdb.MockStore, user db.User) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					SetUserTOTPSecret(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.SetUserTOTPSecretParams) (db.User, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.True(t, arg.TotpSecret.Valid)
+						return user, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response enrollTOTPResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.NotEmpty(t, response.Secret)
+				require.Contains(t, response.ProvisioningURI, "otpauth://totp/go-bank:"+user.Username)
+				require.Contains(t, response.ProvisioningURI, "secret="+response.Secret)
+			},
+		},
+		{
+			name: "BAD_REQUEST - Already Enabled",
+			user: enabledUser,
+			buildStubs: func(store *mockdb.MockStore, user db.User) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					SetUserTOTPSecret(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "INTERNAL_SERVER_ERROR",
+			user: user,
+			buildStubs: func(store *mockdb.MockStore, user db.User) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					SetUserTOTPSecret(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store, tc.user)
+
+			server := newTestServer(t, store)
+			server.config.TOTPIssuer = "go-bank"
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodPost, "/users/me/totp", nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, AUTHORIZATION_TYPE_BEARER, tc.user.Username, DURATION)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestConfirmTOTPAPI(t *testing.T) {
+	enrolledUser, secret := randomTOTPUser(t)
+	enrolledUser.IsTotpEnabled = false
+	notEnrolledUser, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		user          db.User
+		body          func() gin.H
+		buildStubs    func(store *mockdb.MockStore, user db.User)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			user: enrolledUser,
+			body: func() gin.H {
+				return gin.H{"code": currentTOTPCode(t, secret)}
+			},
+			buildStubs: func(store *mockdb.MockStore, user db.User) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					EnableTOTPTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.EnableTOTPTxParams) (db.EnableTOTPTxResponse, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.Equal(t, totp.Step(time.Now()), arg.Step)
+						require.Len(t, arg.RecoveryCodeHashes, RECOVERY_CODE_COUNT)
+
+						enabledUser := user
+						enabledUser.IsTotpEnabled = true
+						return db.EnableTOTPTxResponse{User: enabledUser}, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response confirmTOTPResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Len(t, response.RecoveryCodes, RECOVERY_CODE_COUNT)
+				require.Regexp(t, `^[a-z2-7]{4}-[a-z2-7]{4}$`, response.RecoveryCodes[0])
+				require.True(t, response.User.IsTOTPEnabled)
+			},
+		},
+		{
+			name: "BAD_REQUEST - Invalid Code",
+			user: enrolledUser,
+			body: func() gin.H {
+				code := currentTOTPCode(t, secret)
+				return gin.H{"code": code[1:] + code[:1]}
+			},
+			buildStubs: func(store *mockdb.MockStore, user db.User) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					EnableTOTPTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "BAD_REQUEST - Not Enrolled",
+			user: notEnrolledUser,
+			body: func() gin.H {
+				return gin.H{"code": "123456"}
+			},
+			buildStubs: func(store *mockdb.MockStore, user db.User) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					EnableTOTPTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "BAD_REQUEST - Malformed Code",
+			user: enrolledUser,
+			body: func() gin.H {
+				return gin.H{"code": "abc"}
+			},
+			buildStubs: func(store *mockdb.MockStore, user db.User) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store, tc.user)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body())
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/users/me/totp/confirm", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, AUTHORIZATION_TYPE_BEARER, tc.user.Username, DURATION)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestLoginMFAAPI(t *testing.T) {
+	user, secret := randomTOTPUser(t)
+	recoveryCode := "abcd-efgh"
+
+	testCases := []struct {
+		name          string
+		challenge     func(t *testing.T, tokenMaker token.Maker) string
+		code          func() string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK - TOTP",
+			code: func() string { return currentTOTPCode(t, secret) },
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					UseUserTOTPStep(gomock.Any(), gomock.Eq(db.UseUserTOTPStepParams{
+						Username:         user.Username,
+						TotpLastUsedStep: totp.Step(time.Now()),
+					})).
+					Times(1).
+					Return(int64(1), nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response loginUserResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.NotEmpty(t, response.AccessToken)
+				require.Equal(t, user.Username, response.User.Username)
+			},
+		},
+		{
+			name: "OK - Recovery Code",
+			code: func() string { return "ABCD-EFGH" },
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					UseRecoveryCode(gomock.Any(), gomock.Eq(db.UseRecoveryCodeParams{
+						Username: user.Username,
+						CodeHash: util.HashToken(normalizeRecoveryCode(recoveryCode)),
+					})).
+					Times(1).
+					Return(db.RecoveryCode{Username: user.Username, IsUsed: true}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "UNAUTHORIZED - Replayed Code",
+			code: func() string { return currentTOTPCode(t, secret) },
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					UseUserTOTPStep(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "UNAUTHORIZED - Invalid Code",
+			code: func() string { return "wrong-code" },
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					UseRecoveryCode(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.RecoveryCode{}, sql.ErrNoRows)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "UNAUTHORIZED - Access Token As Challenge",
+			challenge: func(t *testing.T, tokenMaker token.Maker) string {
+				accessToken, err := tokenMaker.CreateToken(user.Username, DURATION)
+				require.NoError(t, err)
+				return accessToken
+			},
+			code: func() string { return currentTOTPCode(t, secret) },
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "UNAUTHORIZED - Expired Challenge",
+			challenge: func(t *testing.T, tokenMaker token.Maker) string {
+				challengeToken, err := tokenMaker.CreatePurposeToken(user.Username, token.PURPOSE_MFA_CHALLENGE, -DURATION)
+				require.NoError(t, err)
+				return challengeToken
+			},
+			code: func() string { return currentTOTPCode(t, secret) },
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			var challengeToken string
+			if tc.challenge != nil {
+				challengeToken = tc.challenge(t, server.tokenMaker)
+			} else {
+				var err error
+				challengeToken, err = server.tokenMaker.CreatePurposeToken(user.Username, token.PURPOSE_MFA_CHALLENGE, DURATION)
+				require.NoError(t, err)
+			}
+
+			data, err := json.Marshal(gin.H{
+				"challenge_token": challengeToken,
+				"code":            tc.code(),
+			})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/users/login/mfa", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestDisableTOTPAPI(t *testing.T) {
+	user, secret := randomTOTPUser(t)
+	disabledUser, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		user          db.User
+		buildStubs    func(store *mockdb.MockStore, user db.User)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			user: user,
+			buildStubs: func(store *mockdb.MockStore, user db.User) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					UseUserTOTPStep(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(1), nil)
+				store.EXPECT().
+					DisableUserTOTP(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(disabledUser, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "BAD_REQUEST - Not Enabled",
+			user: disabledUser,
+			buildStubs: func(store *mockdb.MockStore, user db.User) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					DisableUserTOTP(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store, tc.user)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{"code": currentTOTPCode(t, secret)})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodDelete, "/users/me/totp", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, AUTHORIZATION_TYPE_BEARER, tc.user.Username, DURATION)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestTransferStepUpAPI(t *testing.T) {
+	user, secret := randomTOTPUser(t)
+	otherUser, _ := randomUser(t)
+
+	fromAccount := randomAccount(user.Username)
+	toAccount := randomAccount(otherUser.Username)
+	fromAccount.Currency = util.USD
+	toAccount.Currency = util.USD
+
+	testCases := []struct {
+		name          string
+		amount        int64
+		code          func() string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "OK - Below Threshold",
+			amount: 100,
+			code:   func() string { return "" },
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "OK - With Code",
+			amount: 5000,
+			code:   func() string { return currentTOTPCode(t, secret) },
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().UseUserTOTPStep(gomock.Any(), gomock.Any()).Times(1).Return(int64(1), nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "FORBIDDEN - MFA Required",
+			amount: 5000,
+			code:   func() string { return "" },
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				require.Contains(t, recorder.Body.String(), ErrMFARequired.Error())
+			},
+		},
+		{
+			name:   "UNAUTHORIZED - Invalid Code",
+			amount: 5000,
+			code:   func() string { return "000000" },
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().UseUserTOTPStep(gomock.Any(), gomock.Any()).AnyTimes().Return(int64(0), nil)
+				store.EXPECT().UseRecoveryCode(gomock.Any(), gomock.Any()).AnyTimes().Return(db.RecoveryCode{}, sql.ErrNoRows)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			server.stepUpThresholds = map[string]int64{util.USD: 1000}
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(transferRequest{
+				FromAccountID: fromAccount.ID,
+				ToAccountID:   toAccount.ID,
+				Amount:        tc.amount,
+				Currency:      util.USD,
+				MFACode:       tc.code(),
+			})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/transfer", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, AUTHORIZATION_TYPE_BEARER, user.Username, DURATION)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestMFACodeThrottledAPI(t *testing.T) {
+	user, secret := randomTOTPUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(2).Return(user, nil)
+	store.EXPECT().UseUserTOTPStep(gomock.Any(), gomock.Any()).AnyTimes().Return(int64(0), nil)
+	store.EXPECT().UseRecoveryCode(gomock.Any(), gomock.Any()).Times(1).Return(db.RecoveryCode{}, sql.ErrNoRows)
+	store.EXPECT().DisableUserTOTP(gomock.Any(), gomock.Any()).Times(0)
+
+	server := newTestServer(t, store)
+	server.loginLimiter = lockout.NewLimiter(lockout.NewMemoryStore(), lockout.Policy{
+		MaxFailures:     1,
+		LockoutDuration: time.Minute,
+	}, lockout.Policy{})
+
+	// a wrong code counts as a failed login, the next code is not even checked
+	for _, tc := range []struct {
+		code string
+		want int
+	}{
+		{code: "wrong-code", want: http.StatusUnauthorized},
+		{code: currentTOTPCode(t, secret), want: http.StatusTooManyRequests},
+	} {
+		data, err := json.Marshal(gin.H{"code": tc.code})
+		require.NoError(t, err)
+
+		request, err := http.NewRequest(http.MethodDelete, "/users/me/totp", bytes.NewReader(data))
+		require.NoError(t, err)
+
+		recorder := httptest.NewRecorder()
+		addAuthorization(t, request, server.tokenMaker, AUTHORIZATION_TYPE_BEARER, user.Username, DURATION)
+		server.router.ServeHTTP(recorder, request)
+		require.Equal(t, tc.want, recorder.Code)
+	}
+}
+
+func TestStepUpRequiredAPI(t *testing.T) {
+	user, _ := randomTOTPUser(t)
+	otherUser, _ := randomUser(t)
+
+	fromAccount := randomAccount(user.Username)
+	toAccount := randomAccount(otherUser.Username)
+	fromAccount.Currency = util.USD
+	toAccount.Currency = util.USD
+
+	testCases := []struct {
+		name       string
+		url        string
+		body       interface{}
+		buildStubs func(store *mockdb.MockStore)
+	}{
+		{
+			name: "Scheduled Transfer",
+			url:  "/scheduled_transfers",
+			body: createScheduledTransferRequest{
+				FromAccountID: fromAccount.ID,
+				ToAccountID:   toAccount.ID,
+				Amount:        5000,
+				Currency:      util.USD,
+				ExecuteAt:     time.Now().Add(time.Hour),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateScheduledTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name: "Standing Order",
+			url:  "/standing_orders",
+			body: createStandingOrderRequest{
+				FromAccountID: fromAccount.ID,
+				ToAccountID:   toAccount.ID,
+				Amount:        5000,
+				Currency:      util.USD,
+				Frequency:     util.DAILY,
+				StartAt:       time.Now().Add(time.Hour),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateStandingOrder(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name: "Hold",
+			url:  "/holds",
+			body: createHoldRequest{
+				FromAccountID: fromAccount.ID,
+				ToAccountID:   toAccount.ID,
+				Amount:        5000,
+				Currency:      util.USD,
+				ExpiresAt:     time.Now().Add(time.Hour),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateHoldTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name: "Hold Capture",
+			url:  "/holds/1/capture",
+			body: captureHoldRequest{},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(int64(1))).Times(1).Return(db.Hold{
+					ID:            1,
+					FromAccountID: toAccount.ID,
+					ToAccountID:   fromAccount.ID,
+					Amount:        5000,
+					Currency:      util.USD,
+					Status:        db.HOLD_STATUS_ACTIVE,
+				}, nil)
+				store.EXPECT().CaptureHoldTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name: "Payment Request Accept",
+			url:  "/payment_requests/1/accept",
+			body: acceptPaymentRequestRequest{
+				FromAccountID: fromAccount.ID,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(int64(1))).Times(1).Return(db.PaymentRequest{
+					ID:          1,
+					Requester:   otherUser.Username,
+					Payer:       user.Username,
+					ToAccountID: toAccount.ID,
+					Amount:      5000,
+					Currency:    util.USD,
+					Status:      db.PAYMENT_REQUEST_STATUS_PENDING,
+					ExpiresAt:   time.Now().Add(time.Hour),
+				}, nil)
+				store.EXPECT().AcceptPaymentRequestTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name: "Transfer Batch Total",
+			url:  "/transfer_batches",
+			body: createTransferBatchRequest{
+				FromAccountID: fromAccount.ID,
+				Currency:      util.USD,
+				Mode:          db.TRANSFER_BATCH_MODE_ATOMIC,
+				Items: []transferBatchItemRequest{
+					{ToAccountID: toAccount.ID, Amount: 600},
+					{ToAccountID: toAccount.ID, Amount: 600},
+				},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccountsByIDs(gomock.Any(), gomock.Any()).Times(1).Return([]db.Account{toAccount}, nil)
+				store.EXPECT().TransferBatchTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+			store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			server.stepUpThresholds = map[string]int64{util.USD: 1000}
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, tc.url, bytes.NewReader(data))
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", "application/json")
+
+			addAuthorization(t, request, server.tokenMaker, AUTHORIZATION_TYPE_BEARER, user.Username, DURATION)
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusForbidden, recorder.Code)
+			require.Contains(t, recorder.Body.String(), ErrMFARequired.Error())
+		})
+	}
+}
@@ -1,20 +1,32 @@
 package api
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	db "github.com/VL-037/go-bank/db/sqlc"
 	"github.com/VL-037/go-bank/token"
 	"github.com/gin-gonic/gin"
 	"net/http"
+	"strconv"
 )
 
 type transferRequest struct {
-	FromAccountID int64  `json:"from_account_id" binding:"required,min=1"`
-	ToAccountID   int64  `json:"to_account_id" binding:"required,min=1"`
-	Amount        int64  `json:"amount" binding:"required,gt=0"`
-	Currency      string `json:"currency" binding:"required,currency"`
+	FromAccountID     int64             `json:"from_account_id" binding:"required_without=FromAccountNumber,excluded_with=FromAccountNumber,omitempty,min=1"`
+	FromAccountNumber string            `json:"from_account_number" binding:"omitempty,account_number"`
+	ToAccountID       int64             `json:"to_account_id" binding:"required_without_all=ToAccountNumber ToUsername ToEmail BeneficiaryID,omitempty,min=1"`
+	ToAccountNumber   string            `json:"to_account_number" binding:"omitempty,account_number"`
+	ToUsername        string            `json:"to_username" binding:"omitempty,alphanum"`
+	ToEmail           string            `json:"to_email" binding:"omitempty,email"`
+	BeneficiaryID     int64             `json:"beneficiary_id" binding:"excluded_with=ToAccountID ToAccountNumber ToUsername ToEmail,omitempty,min=1"`
+	Amount            int64             `json:"amount" binding:"required,gt=0"`
+	Currency          string            `json:"currency" binding:"required,currency"`
+	Description       string            `json:"description" binding:"omitempty,max=255"`
+	ClientReference   string            `json:"client_reference" binding:"omitempty,max=64"`
+	Metadata          map[string]string `json:"metadata" binding:"omitempty,max=20,dive,keys,min=1,max=40,endkeys,max=255"`
+	MFACode           string            `json:"mfa_code" binding:"omitempty,max=32"`
 }
 
 func (server *Server) createTransfer(ctx *gin.Context) {
@@ -24,10 +36,11 @@ func (server *Server) createTransfer(ctx *gin.Context) {
 		return
 	}
 
-	fromAccount, valid := server.validAccount(ctx, req.FromAccountID, req.Currency)
+	fromAccount, valid := server.validAccountRef(ctx, req.FromAccountID, req.FromAccountNumber, req.Currency)
 	if !valid {
 		return
 	}
+	req.FromAccountID = fromAccount.ID
 
 	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD_KEY).(*token.Payload)
 	if authPayload.Username != fromAccount.Owner {
@@ -36,27 +49,134 @@ func (server *Server) createTransfer(ctx *gin.Context) {
 		return
 	}
 
-	_, valid = server.validAccount(ctx, req.ToAccountID, req.Currency)
+	if !server.validStepUp(ctx, authPayload.Username, req.Currency, req.Amount, req.MFACode) {
+		return
+	}
+
+	if req.BeneficiaryID != 0 {
+		beneficiary, valid := server.ownedBeneficiary(ctx, req.BeneficiaryID)
+		if !valid {
+			return
+		}
+		req.ToAccountID = beneficiary.AccountID
+	}
+
+	toAccount, valid := server.payeeAccount(ctx, payee{
+		AccountID:     req.ToAccountID,
+		AccountNumber: req.ToAccountNumber,
+		Username:      req.ToUsername,
+		Email:         req.ToEmail,
+	}, req.Currency)
 	if !valid {
 		return
 	}
+	req.ToAccountID = toAccount.ID
+
+	if !server.validCoolingOff(ctx, authPayload.Username, toAccount, req.Amount) {
+		return
+	}
+
+	metadata, err := transferMetadata(req.Metadata)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if server.requiresApproval(req.Currency, req.Amount) {
+		server.createPendingTransfer(ctx, req, metadata, authPayload.Username)
+		return
+	}
 
 	arg := db.TransferTxParams{
-		FromAccountID: req.FromAccountID,
-		ToAccountID:   req.ToAccountID,
-		Amount:        req.Amount,
+		FromAccountID:   req.FromAccountID,
+		ToAccountID:     req.ToAccountID,
+		Amount:          req.Amount,
+		Description:     req.Description,
+		ClientReference: req.ClientReference,
+		Metadata:        metadata,
 	}
 
 	response, err := server.store.TransferTx(ctx, arg)
 	if err != nil {
+		if err == db.ErrInsufficientFunds || err == db.ErrAccountClosed {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
 	}
 
 	ctx.JSON(http.StatusOK, response)
 }
 
+type listTransferRequest struct {
+	AccountID       int64  `form:"account_id" binding:"required_without=AccountNumber,excluded_with=AccountNumber,omitempty,min=1"`
+	AccountNumber   string `form:"account_number" binding:"omitempty,account_number"`
+	ClientReference string `form:"client_reference" binding:"omitempty,max=64"`
+	MetadataKey     string `form:"metadata_key" binding:"required_with=MetadataValue,omitempty,max=40"`
+	MetadataValue   string `form:"metadata_value" binding:"omitempty,max=255"`
+	PageID          int32  `form:"page_id" binding:"required,min=1"`
+	PageSize        int32  `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+// listTransfers returns the transfer history of an account, newest first
+func (server *Server) listTransfers(ctx *gin.Context) {
+	var req listTransferRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, err := server.accountByRef(ctx, req.AccountID, req.AccountNumber)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD_KEY).(*token.Payload)
+	if authPayload.Username != account.Owner {
+		err := errors.New("account doesn't belong to authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	arg := db.ListAccountTransfersParams{
+		AccountID:       account.ID,
+		ClientReference: sql.NullString{String: req.ClientReference, Valid: req.ClientReference != ""},
+		MetadataKey:     sql.NullString{String: req.MetadataKey, Valid: req.MetadataKey != ""},
+		MetadataValue:   sql.NullString{String: req.MetadataValue, Valid: req.MetadataKey != ""},
+		PageLimit:       req.PageSize,
+		PageOffset:      (req.PageID - 1) * req.PageSize,
+	}
+
+	transfers, err := server.store.ListAccountTransfers(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, transfers)
+}
+
+// transferMetadata encodes the metadata of a transfer request, leaving it empty when there is none
+func transferMetadata(metadata map[string]string) (json.RawMessage, error) {
+	if len(metadata) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(metadata)
+}
+
 func (server *Server) validAccount(ctx *gin.Context, accountID int64, currency string) (db.Account, bool) {
-	account, err := server.store.GetAccount(ctx, accountID)
+	return server.validAccountRef(ctx, accountID, "", currency)
+}
+
+// validAccountRef is validAccount for an account given either by its id or by its public account number
+func (server *Server) validAccountRef(ctx *gin.Context, accountID int64, accountNumber string, currency string) (db.Account, bool) {
+	account, err := server.accountByRef(ctx, accountID, accountNumber)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			ctx.JSON(http.StatusNotFound, errorResponse(err))
@@ -66,11 +186,36 @@ func (server *Server) validAccount(ctx *gin.Context, accountID int64, currency s
 		return account, false
 	}
 
-	if account.Currency != currency {
-		err := fmt.Errorf("account [%d] currency mismatch: %s vs %s", accountID, account.Currency, currency)
+	ref := accountNumber
+	if ref == "" {
+		ref = strconv.FormatInt(accountID, 10)
+	}
+
+	if err := checkAccount(account, ref, currency); err != nil {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return account, false
 	}
 
 	return account, true
 }
+
+// checkAccount reports why an account, referred to as ref, cannot take part in a transfer in the given currency
+func checkAccount(account db.Account, ref string, currency string) error {
+	if account.MarkForDelete {
+		return fmt.Errorf("account [%s]: %w", ref, ErrAccountClosed)
+	}
+
+	if account.Currency != currency {
+		return fmt.Errorf("account [%s] currency mismatch: %s vs %s", ref, account.Currency, currency)
+	}
+
+	return nil
+}
+
+// accountByRef loads an account by its public account number when one is given, and by its id otherwise
+func (server *Server) accountByRef(ctx context.Context, accountID int64, accountNumber string) (db.Account, error) {
+	if accountNumber != "" {
+		return server.store.GetAccountByNumber(ctx, accountNumber)
+	}
+	return server.store.GetAccount(ctx, accountID)
+}
@@ -0,0 +1,382 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	db "github.com/VL-037/go-bank/db/sqlc"
+	"github.com/VL-037/go-bank/token"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// MAX_TRANSFER_BATCH_ITEMS caps the size of a batch so it fits in a single request and DB transaction
+const MAX_TRANSFER_BATCH_ITEMS = 1000
+
+type transferBatchItemRequest struct {
+	ToAccountID     int64  `json:"to_account_id" binding:"required_without=ToAccountNumber,excluded_with=ToAccountNumber,omitempty,min=1"`
+	ToAccountNumber string `json:"to_account_number" binding:"omitempty,account_number"`
+	Amount          int64  `json:"amount" binding:"required,gt=0"`
+	Description     string `json:"description" binding:"omitempty,max=255"`
+	ClientReference string `json:"client_reference" binding:"omitempty,max=64"`
+}
+
+// createTransferBatchRequest is sent either as JSON, or as a multipart form with the items in a CSV "file"
+type createTransferBatchRequest struct {
+	FromAccountID     int64                      `json:"from_account_id" form:"from_account_id" binding:"required_without=FromAccountNumber,excluded_with=FromAccountNumber,omitempty,min=1"`
+	FromAccountNumber string                     `json:"from_account_number" form:"from_account_number" binding:"omitempty,account_number"`
+	Currency          string                     `json:"currency" form:"currency" binding:"required,currency"`
+	Mode              string                     `json:"mode" form:"mode" binding:"required,oneof=atomic best_effort"`
+	Items             []transferBatchItemRequest `json:"items" form:"-" binding:"max=1000,dive"`
+	MFACode           string                     `json:"mfa_code" form:"mfa_code" binding:"omitempty,max=32"`
+}
+
+func (server *Server) createTransferBatch(ctx *gin.Context) {
+	var req createTransferBatchRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if ctx.ContentType() == binding.MIMEMultipartPOSTForm {
+		items, err := transferBatchItemsFromCSV(ctx)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+
+		req.Items = items
+		if err := binding.Validator.ValidateStruct(req); err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+	}
+
+	if len(req.Items) == 0 {
+		err := errors.New("transfer batch has no items")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	fromAccount, valid := server.validAccountRef(ctx, req.FromAccountID, req.FromAccountNumber, req.Currency)
+	if !valid {
+		return
+	}
+	req.FromAccountID = fromAccount.ID
+
+	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD_KEY).(*token.Payload)
+	if authPayload.Username != fromAccount.Owner {
+		err := errors.New("from account doesn't belong to authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	if valid = server.validTransferBatchItems(ctx, req); !valid {
+		return
+	}
+
+	// the step-up applies to the batch total, like the approval threshold
+	totalAmount, _ := transferBatchTotal(req.Items)
+	if !server.validStepUp(ctx, authPayload.Username, req.Currency, totalAmount, req.MFACode) {
+		return
+	}
+
+	arg := db.TransferBatchTxParams{
+		FromAccountID: req.FromAccountID,
+		Currency:      req.Currency,
+		Mode:          req.Mode,
+	}
+	for _, item := range req.Items {
+		arg.Items = append(arg.Items, db.TransferBatchItemParams{
+			ToAccountID:     item.ToAccountID,
+			Amount:          item.Amount,
+			Description:     item.Description,
+			ClientReference: item.ClientReference,
+		})
+	}
+
+	// a best effort batch commits item by item, it runs to its end even when the client goes away
+	// so that it is never left processing
+	batchCtx := context.Context(ctx)
+	if req.Mode == db.TRANSFER_BATCH_MODE_BEST_EFFORT {
+		batchCtx = context.Background()
+	}
+
+	response, err := server.store.TransferBatchTx(batchCtx, arg)
+	if err != nil {
+		if err == db.ErrInsufficientFunds || err == db.ErrAccountClosed || err == db.ErrTransferBatchTotalOverflow {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// validTransferBatchItems checks every destination of a batch up front and reports all invalid items at once.
+// The total of the batch is held to the approval threshold as well, so it cannot be split into smaller items.
+// Destinations given by account number are resolved to their account id in place
+func (server *Server) validTransferBatchItems(ctx *gin.Context, req createTransferBatchRequest) bool {
+	totalAmount, valid := transferBatchTotal(req.Items)
+	if !valid {
+		ctx.JSON(http.StatusBadRequest, errorResponse(db.ErrTransferBatchTotalOverflow))
+		return false
+	}
+
+	if server.requiresApproval(req.Currency, totalAmount) {
+		err := fmt.Errorf("batch total: %w", ErrApprovalRequired)
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return false
+	}
+
+	var accountIDs []int64
+	var accountNumbers []string
+	for _, item := range req.Items {
+		if item.ToAccountNumber != "" {
+			accountNumbers = append(accountNumbers, item.ToAccountNumber)
+		} else {
+			accountIDs = append(accountIDs, item.ToAccountID)
+		}
+	}
+
+	var accounts []db.Account
+	if len(accountIDs) > 0 {
+		idAccounts, err := server.store.ListAccountsByIDs(ctx, accountIDs)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return false
+		}
+		accounts = append(accounts, idAccounts...)
+	}
+
+	if len(accountNumbers) > 0 {
+		numberedAccounts, err := server.store.ListAccountsByNumbers(ctx, accountNumbers)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return false
+		}
+		accounts = append(accounts, numberedAccounts...)
+	}
+
+	accountsByID := make(map[int64]db.Account)
+	accountsByNumber := make(map[string]db.Account)
+	for _, account := range accounts {
+		accountsByID[account.ID] = account
+		accountsByNumber[account.Number] = account
+	}
+
+	var errs []error
+	for i := range req.Items {
+		item := &req.Items[i]
+
+		ref := strconv.FormatInt(item.ToAccountID, 10)
+		account, found := accountsByID[item.ToAccountID]
+		if item.ToAccountNumber != "" {
+			ref = item.ToAccountNumber
+			account, found = accountsByNumber[item.ToAccountNumber]
+			item.ToAccountID = account.ID
+		}
+
+		if !found {
+			errs = append(errs, fmt.Errorf("item %d: account [%s] not found", i+1, ref))
+			continue
+		}
+
+		if err := checkAccount(account, ref, req.Currency); err != nil {
+			errs = append(errs, fmt.Errorf("item %d: %w", i+1, err))
+			continue
+		}
+
+		switch {
+		case item.ToAccountID == req.FromAccountID:
+			errs = append(errs, fmt.Errorf("item %d: cannot transfer to the from account", i+1))
+		case server.requiresApproval(req.Currency, item.Amount):
+			errs = append(errs, fmt.Errorf("item %d: %w", i+1, ErrApprovalRequired))
+		}
+	}
+
+	if len(errs) > 0 {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errors.Join(errs...)))
+		return false
+	}
+
+	// the cooling-off applies to what the batch pays each destination in total, reported on its first item
+	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD_KEY).(*token.Payload)
+	amounts := make(map[int64]int64)
+	for _, item := range req.Items {
+		amounts[item.ToAccountID] += item.Amount
+	}
+	for i, item := range req.Items {
+		amount, pending := amounts[item.ToAccountID]
+		if !pending {
+			continue
+		}
+		delete(amounts, item.ToAccountID)
+
+		err := server.checkCoolingOff(ctx, authPayload.Username, accountsByID[item.ToAccountID], amount)
+		if err != nil {
+			if err != ErrBeneficiaryRequired && err != ErrBeneficiaryCoolingOff {
+				ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+				return false
+			}
+			errs = append(errs, fmt.Errorf("item %d: %w", i+1, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		ctx.JSON(http.StatusForbidden, errorResponse(errors.Join(errs...)))
+		return false
+	}
+
+	return true
+}
+
+// transferBatchTotal sums the amounts of the items, it is not valid when the sum overflows an int64
+func transferBatchTotal(items []transferBatchItemRequest) (int64, bool) {
+	var totalAmount int64
+	for _, item := range items {
+		if item.Amount > math.MaxInt64-totalAmount {
+			return 0, false
+		}
+		totalAmount += item.Amount
+	}
+	return totalAmount, true
+}
+
+// transferBatchItemsFromCSV reads the uploaded CSV file, whose header names the columns:
+// amount and either to_account_id or to_account_number are required, description and client_reference are optional
+func transferBatchItemsFromCSV(ctx *gin.Context) ([]transferBatchItemRequest, error) {
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read csv header: %w", err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["amount"]; !ok {
+		return nil, errors.New("csv is missing the amount column")
+	}
+	_, hasAccountID := columns["to_account_id"]
+	_, hasAccountNumber := columns["to_account_number"]
+	if !hasAccountID && !hasAccountNumber {
+		return nil, errors.New("csv is missing the to_account_id or to_account_number column")
+	}
+
+	value := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var items []transferBatchItemRequest
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if len(items) == MAX_TRANSFER_BATCH_ITEMS {
+			return nil, fmt.Errorf("transfer batch has more than %d items", MAX_TRANSFER_BATCH_ITEMS)
+		}
+
+		var toAccountID int64
+		toAccountNumber := value(record, "to_account_number")
+		if toAccountNumber == "" {
+			toAccountID, err = strconv.ParseInt(value(record, "to_account_id"), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid to_account_id", line)
+			}
+		}
+
+		amount, err := strconv.ParseInt(value(record, "amount"), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid amount", line)
+		}
+
+		items = append(items, transferBatchItemRequest{
+			ToAccountID:     toAccountID,
+			ToAccountNumber: toAccountNumber,
+			Amount:          amount,
+			Description:     value(record, "description"),
+			ClientReference: value(record, "client_reference"),
+		})
+	}
+
+	return items, nil
+}
+
+type transferBatchRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// getTransferBatch reports the status of a batch and of each of its items
+func (server *Server) getTransferBatch(ctx *gin.Context) {
+	var req transferBatchRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	batch, err := server.store.GetTransferBatch(ctx, req.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	fromAccount, err := server.store.GetAccount(ctx, batch.FromAccountID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD_KEY).(*token.Payload)
+	if authPayload.Username != fromAccount.Owner {
+		err := errors.New("transfer batch doesn't belong to authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	items, err := server.store.ListTransferBatchItems(ctx, batch.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, db.TransferBatchTxResponse{
+		Batch: batch,
+		Items: items,
+	})
+}
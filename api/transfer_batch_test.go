@@ -0,0 +1,505 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	mockdb "github.com/VL-037/go-bank/db/mock"
+	db "github.com/VL-037/go-bank/db/sqlc"
+	"github.com/VL-037/go-bank/util"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"math"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestCreateTransferBatchAPI(t *testing.T) {
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+	user3, _ := randomUser(t)
+
+	fromAccount := randomAccount(user1.Username)
+	account2 := randomAccount(user2.Username)
+	account3 := randomAccount(user3.Username)
+	fromAccount.Currency = util.USD
+	account2.Currency = util.USD
+	account3.Currency = util.USD
+
+	items := []gin.H{
+		{"to_account_id": account2.ID, "amount": 10, "description": "salary"},
+		{"to_account_id": account3.ID, "amount": 20},
+	}
+	arg := db.TransferBatchTxParams{
+		FromAccountID: fromAccount.ID,
+		Currency:      util.USD,
+		Mode:          db.TRANSFER_BATCH_MODE_ATOMIC,
+		Items: []db.TransferBatchItemParams{
+			{ToAccountID: account2.ID, Amount: 10, Description: "salary"},
+			{ToAccountID: account3.ID, Amount: 20},
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"from_account_id": fromAccount.ID,
+				"currency":        util.USD,
+				"mode":            db.TRANSFER_BATCH_MODE_ATOMIC,
+				"items":           items,
+			},
+			username: user1.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().
+					ListAccountsByIDs(gomock.Any(), gomock.Eq([]int64{account2.ID, account3.ID})).
+					Times(1).
+					Return([]db.Account{account2, account3}, nil)
+				store.EXPECT().TransferBatchTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.TransferBatchTxResponse{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "OK - By Account Numbers",
+			body: gin.H{
+				"from_account_number": fromAccount.Number,
+				"currency":            util.USD,
+				"mode":                db.TRANSFER_BATCH_MODE_ATOMIC,
+				"items": []gin.H{
+					{"to_account_number": account2.Number, "amount": 10, "description": "salary"},
+					{"to_account_id": account3.ID, "amount": 20},
+				},
+			},
+			username: user1.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(fromAccount.Number)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().
+					ListAccountsByIDs(gomock.Any(), gomock.Eq([]int64{account3.ID})).
+					Times(1).
+					Return([]db.Account{account3}, nil)
+				store.EXPECT().
+					ListAccountsByNumbers(gomock.Any(), gomock.Eq([]string{account2.Number})).
+					Times(1).
+					Return([]db.Account{account2}, nil)
+				store.EXPECT().TransferBatchTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.TransferBatchTxResponse{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "BAD_REQUEST - No Items",
+			body: gin.H{
+				"from_account_id": fromAccount.ID,
+				"currency":        util.USD,
+				"mode":            db.TRANSFER_BATCH_MODE_ATOMIC,
+			},
+			username: user1.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().TransferBatchTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "BAD_REQUEST - Invalid Mode",
+			body: gin.H{
+				"from_account_id": fromAccount.ID,
+				"currency":        util.USD,
+				"mode":            "eventually",
+				"items":           items,
+			},
+			username: user1.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().TransferBatchTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "BAD_REQUEST - Invalid Item",
+			body: gin.H{
+				"from_account_id": fromAccount.ID,
+				"currency":        util.USD,
+				"mode":            db.TRANSFER_BATCH_MODE_ATOMIC,
+				"items":           []gin.H{{"to_account_id": account2.ID, "amount": 0}},
+			},
+			username: user1.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().TransferBatchTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "BAD_REQUEST - Unknown And Mismatched Accounts",
+			body: gin.H{
+				"from_account_id": fromAccount.ID,
+				"currency":        util.USD,
+				"mode":            db.TRANSFER_BATCH_MODE_BEST_EFFORT,
+				"items":           items,
+			},
+			username: user1.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				mismatched := account3
+				mismatched.Currency = util.EUR
+
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().ListAccountsByIDs(gomock.Any(), gomock.Any()).Times(1).Return([]db.Account{mismatched}, nil)
+				store.EXPECT().TransferBatchTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Contains(t, recorder.Body.String(), "item 1")
+				require.Contains(t, recorder.Body.String(), "item 2")
+			},
+		},
+		{
+			name: "BAD_REQUEST - Closed Account",
+			body: gin.H{
+				"from_account_id": fromAccount.ID,
+				"currency":        util.USD,
+				"mode":            db.TRANSFER_BATCH_MODE_BEST_EFFORT,
+				"items":           items,
+			},
+			username: user1.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				closed := account3
+				closed.MarkForDelete = true
+
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().ListAccountsByIDs(gomock.Any(), gomock.Any()).Times(1).Return([]db.Account{account2, closed}, nil)
+				store.EXPECT().TransferBatchTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Contains(t, recorder.Body.String(), "item 2")
+				require.Contains(t, recorder.Body.String(), ErrAccountClosed.Error())
+			},
+		},
+		{
+			name: "BAD_REQUEST - Total Overflows",
+			body: gin.H{
+				"from_account_id": fromAccount.ID,
+				"currency":        util.USD,
+				"mode":            db.TRANSFER_BATCH_MODE_ATOMIC,
+				"items": []gin.H{
+					{"to_account_id": account2.ID, "amount": int64(math.MaxInt64)},
+					{"to_account_id": account3.ID, "amount": 1},
+				},
+			},
+			username: user1.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().ListAccountsByIDs(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferBatchTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Contains(t, recorder.Body.String(), db.ErrTransferBatchTotalOverflow.Error())
+			},
+		},
+		{
+			name: "BAD_REQUEST - Insufficient Funds",
+			body: gin.H{
+				"from_account_id": fromAccount.ID,
+				"currency":        util.USD,
+				"mode":            db.TRANSFER_BATCH_MODE_ATOMIC,
+				"items":           items,
+			},
+			username: user1.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().ListAccountsByIDs(gomock.Any(), gomock.Any()).Times(1).Return([]db.Account{account2, account3}, nil)
+				store.EXPECT().TransferBatchTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferBatchTxResponse{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "UNAUTHORIZED",
+			body: gin.H{
+				"from_account_id": fromAccount.ID,
+				"currency":        util.USD,
+				"mode":            db.TRANSFER_BATCH_MODE_ATOMIC,
+				"items":           items,
+			},
+			username: user2.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().ListAccountsByIDs(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferBatchTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/transfer_batches", bytes.NewReader(data))
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", "application/json")
+
+			addAuthorization(t, request, server.tokenMaker, AUTHORIZATION_TYPE_BEARER, tc.username, DURATION)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestCreateTransferBatchAboveApprovalThresholdAPI(t *testing.T) {
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+
+	fromAccount := randomAccount(user1.Username)
+	account2 := randomAccount(user2.Username)
+	fromAccount.Currency = util.USD
+	account2.Currency = util.USD
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+	store.EXPECT().ListAccountsByIDs(gomock.Any(), gomock.Any()).Times(0)
+	store.EXPECT().TransferBatchTx(gomock.Any(), gomock.Any()).Times(0)
+
+	server := newTestServer(t, store)
+	server.approvalThresholds = map[string]int64{util.USD: 1000}
+	recorder := httptest.NewRecorder()
+
+	// every item is below the threshold but their total is not
+	data, err := json.Marshal(gin.H{
+		"from_account_id": fromAccount.ID,
+		"currency":        util.USD,
+		"mode":            db.TRANSFER_BATCH_MODE_ATOMIC,
+		"items": []gin.H{
+			{"to_account_id": account2.ID, "amount": 600},
+			{"to_account_id": account2.ID, "amount": 600},
+		},
+	})
+	require.NoError(t, err)
+
+	request, err := http.NewRequest(http.MethodPost, "/transfer_batches", bytes.NewReader(data))
+	require.NoError(t, err)
+	request.Header.Set("Content-Type", "application/json")
+
+	addAuthorization(t, request, server.tokenMaker, AUTHORIZATION_TYPE_BEARER, user1.Username, DURATION)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+	require.Contains(t, recorder.Body.String(), ErrApprovalRequired.Error())
+}
+
+func TestCreateTransferBatchFromCSVAPI(t *testing.T) {
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+
+	fromAccount := randomAccount(user1.Username)
+	toAccount := randomAccount(user2.Username)
+	fromAccount.Currency = util.IDR
+	toAccount.Currency = util.IDR
+
+	testCases := []struct {
+		name          string
+		csv           string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			csv:  "to_account_id,amount,description\n" + fmt.Sprintf("%d,100,March salary\n%d,50,Bonus\n", toAccount.ID, toAccount.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().ListAccountsByIDs(gomock.Any(), gomock.Any()).Times(1).Return([]db.Account{toAccount}, nil)
+				// best effort batches do not run on the request context
+				store.EXPECT().
+					TransferBatchTx(gomock.Not(gomock.AssignableToTypeOf(&gin.Context{})), gomock.Eq(db.TransferBatchTxParams{
+						FromAccountID: fromAccount.ID,
+						Currency:      util.IDR,
+						Mode:          db.TRANSFER_BATCH_MODE_BEST_EFFORT,
+						Items: []db.TransferBatchItemParams{
+							{ToAccountID: toAccount.ID, Amount: 100, Description: "March salary"},
+							{ToAccountID: toAccount.ID, Amount: 50, Description: "Bonus"},
+						},
+					})).
+					Times(1).
+					Return(db.TransferBatchTxResponse{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "BAD_REQUEST - Missing Column",
+			csv:  "to_account_id,description\n1,March salary\n",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().TransferBatchTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "BAD_REQUEST - Invalid Amount",
+			csv:  "to_account_id,amount\n" + fmt.Sprintf("%d,-5\n", toAccount.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().TransferBatchTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			body := new(bytes.Buffer)
+			writer := multipart.NewWriter(body)
+			require.NoError(t, writer.WriteField("from_account_id", strconv.FormatInt(fromAccount.ID, 10)))
+			require.NoError(t, writer.WriteField("currency", util.IDR))
+			require.NoError(t, writer.WriteField("mode", db.TRANSFER_BATCH_MODE_BEST_EFFORT))
+			part, err := writer.CreateFormFile("file", "payroll.csv")
+			require.NoError(t, err)
+			_, err = part.Write([]byte(tc.csv))
+			require.NoError(t, err)
+			require.NoError(t, writer.Close())
+
+			request, err := http.NewRequest(http.MethodPost, "/transfer_batches", body)
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", writer.FormDataContentType())
+
+			addAuthorization(t, request, server.tokenMaker, AUTHORIZATION_TYPE_BEARER, user1.Username, DURATION)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestGetTransferBatchAPI(t *testing.T) {
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+	fromAccount := randomAccount(user1.Username)
+
+	batch := db.TransferBatch{
+		ID:            util.RandomInt(1, 1000),
+		FromAccountID: fromAccount.ID,
+		Status:        db.TRANSFER_BATCH_STATUS_PARTIALLY_COMPLETED,
+	}
+	items := []db.TransferBatchItem{
+		{ID: 1, BatchID: batch.ID, LineNumber: 1, Status: db.TRANSFER_BATCH_ITEM_STATUS_EXECUTED},
+		{ID: 2, BatchID: batch.ID, LineNumber: 2, Status: db.TRANSFER_BATCH_ITEM_STATUS_FAILED},
+	}
+
+	testCases := []struct {
+		name          string
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: user1.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferBatch(gomock.Any(), gomock.Eq(batch.ID)).Times(1).Return(batch, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().ListTransferBatchItems(gomock.Any(), gomock.Eq(batch.ID)).Times(1).Return(items, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got db.TransferBatchTxResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, batch.ID, got.Batch.ID)
+				require.Len(t, got.Items, len(items))
+			},
+		},
+		{
+			name:     "UNAUTHORIZED",
+			username: user2.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferBatch(gomock.Any(), gomock.Eq(batch.ID)).Times(1).Return(batch, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().ListTransferBatchItems(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "NOT_FOUND",
+			username: user1.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferBatch(gomock.Any(), gomock.Eq(batch.ID)).Times(1).Return(db.TransferBatch{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/transfer_batches/%d", batch.ID)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, AUTHORIZATION_TYPE_BEARER, tc.username, DURATION)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	mockdb "github.com/VL-037/go-bank/db/mock"
 	db "github.com/VL-037/go-bank/db/sqlc"
 	"github.com/VL-037/go-bank/token"
@@ -71,6 +72,46 @@ func TestCreateTransferAPI(t *testing.T) {
 				require.Equal(t, http.StatusOK, recorder.Code)
 			},
 		},
+		{
+			name: "OK - With Details",
+			body: transferRequest{
+				FromAccountID:   account1.ID,
+				ToAccountID:     account2.ID,
+				Amount:          amount,
+				Currency:        util.IDR,
+				Description:     "rent",
+				ClientReference: "INV-1",
+				Metadata:        map[string]string{"invoice": "INV-1"},
+			},
+			setupAuth: func(t *testing.T, req *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, req, tokenMaker, AUTHORIZATION_TYPE_BEARER, user1.Username, DURATION)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.TransferTxParams{
+					FromAccountID:   account1.ID,
+					ToAccountID:     account2.ID,
+					Amount:          amount,
+					Description:     "rent",
+					ClientReference: "INV-1",
+					Metadata:        json.RawMessage(`{"invoice":"INV-1"}`),
+				}
+
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(arg.FromAccountID)).
+					Times(1).
+					Return(account1, nil)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(arg.ToAccountID)).
+					Times(1).
+					Return(account2, nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
 		{
 			name: "UNAUTHORIZED",
 			body: transferRequest{
@@ -410,6 +451,67 @@ func TestCreateTransferAPI(t *testing.T) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
 		},
+		{
+			name: "BAD_REQUEST - ToAccount Closed",
+			body: transferRequest{
+				FromAccountID: account1.ID,
+				ToAccountID:   account2.ID,
+				Amount:        amount,
+				Currency:      util.IDR,
+			},
+			setupAuth: func(t *testing.T, req *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, req, tokenMaker, AUTHORIZATION_TYPE_BEARER, user1.Username, DURATION)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				closedAccount := account2
+				closedAccount.MarkForDelete = true
+
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account1.ID)).
+					Times(1).
+					Return(account1, nil)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account2.ID)).
+					Times(1).
+					Return(closedAccount, nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Contains(t, recorder.Body.String(), ErrAccountClosed.Error())
+			},
+		},
+		{
+			name: "BAD_REQUEST - Insufficient Funds",
+			body: transferRequest{
+				FromAccountID: account1.ID,
+				ToAccountID:   account2.ID,
+				Amount:        amount,
+				Currency:      util.IDR,
+			},
+			setupAuth: func(t *testing.T, req *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, req, tokenMaker, AUTHORIZATION_TYPE_BEARER, user1.Username, DURATION)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account1.ID)).
+					Times(1).
+					Return(account1, nil)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account2.ID)).
+					Times(1).
+					Return(account2, nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(1).Return(db.TransferTxResponse{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Contains(t, recorder.Body.String(), db.ErrInsufficientFunds.Error())
+			},
+		},
 		{
 			name: "INTERNAL_SERVER_ERROR - TransferTx Error",
 			body: transferRequest{
@@ -471,3 +573,113 @@ func TestCreateTransferAPI(t *testing.T) {
 		})
 	}
 }
+
+func TestListTransfersAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	otherUser, _ := randomUser(t)
+	account := randomAccount(user.Username)
+
+	transfers := []db.Transfer{
+		{ID: 2, FromAccountID: account.ID, ToAccountID: account.ID + 1, Amount: 10},
+		{ID: 1, FromAccountID: account.ID + 1, ToAccountID: account.ID, Amount: 20},
+	}
+
+	testCases := []struct {
+		name          string
+		query         string
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			query:    fmt.Sprintf("account_id=%d&page_id=1&page_size=5", account.ID),
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					ListAccountTransfers(gomock.Any(), gomock.Eq(db.ListAccountTransfersParams{
+						AccountID:  account.ID,
+						PageLimit:  5,
+						PageOffset: 0,
+					})).
+					Times(1).
+					Return(transfers, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var gotTransfers []db.Transfer
+				err := json.Unmarshal(recorder.Body.Bytes(), &gotTransfers)
+				require.NoError(t, err)
+				require.Len(t, gotTransfers, len(transfers))
+			},
+		},
+		{
+			name:     "OK - Filtered",
+			query:    fmt.Sprintf("account_id=%d&client_reference=INV-1&metadata_key=invoice&metadata_value=INV-1&page_id=2&page_size=5", account.ID),
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					ListAccountTransfers(gomock.Any(), gomock.Eq(db.ListAccountTransfersParams{
+						AccountID:       account.ID,
+						ClientReference: sql.NullString{String: "INV-1", Valid: true},
+						MetadataKey:     sql.NullString{String: "invoice", Valid: true},
+						MetadataValue:   sql.NullString{String: "INV-1", Valid: true},
+						PageLimit:       5,
+						PageOffset:      5,
+					})).
+					Times(1).
+					Return([]db.Transfer{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "BAD_REQUEST - Metadata Value Without Key",
+			query:    fmt.Sprintf("account_id=%d&metadata_value=INV-1&page_id=1&page_size=5", account.ID),
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccountTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "UNAUTHORIZED",
+			query:    fmt.Sprintf("account_id=%d&page_id=1&page_size=5", account.ID),
+			username: otherUser.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ListAccountTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/transfers?"+tc.query, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, AUTHORIZATION_TYPE_BEARER, tc.username, DURATION)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
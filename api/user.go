@@ -3,9 +3,11 @@ package api
 import (
 	"database/sql"
 	db "github.com/VL-037/go-bank/db/sqlc"
+	"github.com/VL-037/go-bank/token"
 	"github.com/VL-037/go-bank/util"
 	"github.com/gin-gonic/gin"
 	"github.com/lib/pq"
+	"log"
 	"net/http"
 	"time"
 )
@@ -22,6 +24,9 @@ func newUserResponse(user db.User) userResponse {
 		Username:          user.Username,
 		FullName:          user.FullName,
 		Email:             user.Email,
+		Role:              user.Role,
+		IsEmailVerified:   user.IsEmailVerified,
+		IsTOTPEnabled:     user.IsTotpEnabled,
 		PasswordUpdatedAt: user.PasswordUpdatedAt,
 		CreatedBy:         user.CreatedBy,
 		CreatedAt:         user.CreatedAt,
@@ -35,6 +40,9 @@ type userResponse struct {
 	Username          string         `json:"username"`
 	FullName          string         `json:"full_name"`
 	Email             string         `json:"email"`
+	Role              string         `json:"role"`
+	IsEmailVerified   bool           `json:"is_email_verified"`
+	IsTOTPEnabled     bool           `json:"is_totp_enabled"`
 	PasswordUpdatedAt time.Time      `json:"password_updated_at"`
 	CreatedBy         sql.NullString `json:"created_by"`
 	CreatedAt         time.Time      `json:"created_at"`
@@ -50,7 +58,11 @@ func (server *Server) createUser(ctx *gin.Context) {
 		return
 	}
 
-	hashedPassword, err := util.HashPassword(req.Password)
+	if !server.validPassword(ctx, req.Password, req.Username, req.Email) {
+		return
+	}
+
+	hashedPassword, err := server.passwordHasher.HashPassword(req.Password)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
@@ -76,6 +88,8 @@ func (server *Server) createUser(ctx *gin.Context) {
 		return
 	}
 
+	server.trySendVerificationEmail(ctx, user)
+
 	ctx.JSON(http.StatusOK, newUserResponse(user))
 }
 
@@ -96,19 +110,56 @@ func (server *Server) loginUser(ctx *gin.Context) {
 		return
 	}
 
+	if !server.allowLogin(ctx, req.Username) {
+		return
+	}
+
+	// unknown usernames and wrong passwords get the same response
 	user, err := server.store.GetUser(ctx, req.Username)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			server.checkDummyPassword(req.Password)
+			server.failLogin(ctx, req.Username, ErrInvalidCredentials)
 			return
 		}
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	err = util.CheckPassword(user.HashedPassword, req.Password)
+	// deleted users cannot log in anymore, they look like unknown usernames
+	if user.MarkForDelete {
+		server.checkDummyPassword(req.Password)
+		server.failLogin(ctx, req.Username, ErrInvalidCredentials)
+		return
+	}
+
+	err = server.passwordHasher.CheckPassword(user.HashedPassword, req.Password)
 	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		server.failLogin(ctx, req.Username, ErrInvalidCredentials)
+		return
+	}
+
+	server.tryRehashPassword(ctx, user, req.Password)
+
+	// the access token and the reset of the failed logins wait for loginMFA to check the second factor
+	if user.IsTotpEnabled {
+		challengeToken, err := server.tokenMaker.CreatePurposeToken(
+			user.Username, token.PURPOSE_MFA_CHALLENGE, server.config.MFAChallengeDuration)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, mfaChallengeResponse{
+			MFARequired:    true,
+			ChallengeToken: challengeToken,
+		})
+		return
+	}
+
+	err = server.loginLimiter.Succeed(ctx, user.Username)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
@@ -124,6 +175,9 @@ func (server *Server) loginUser(ctx *gin.Context) {
 			Username:          user.Username,
 			FullName:          user.FullName,
 			Email:             user.Email,
+			Role:              user.Role,
+			IsEmailVerified:   user.IsEmailVerified,
+			IsTOTPEnabled:     user.IsTotpEnabled,
 			PasswordUpdatedAt: user.PasswordUpdatedAt,
 			CreatedBy:         user.CreatedBy,
 			CreatedAt:         user.CreatedAt,
@@ -134,3 +188,144 @@ func (server *Server) loginUser(ctx *gin.Context) {
 	}
 	ctx.JSON(http.StatusOK, response)
 }
+
+type updateUserRequest struct {
+	FullName string `json:"full_name" binding:"required_without=Email"`
+	Email    string `json:"email" binding:"omitempty,email"`
+}
+
+// updateUser changes the profile of the authenticated user, only the given fields are updated
+func (server *Server) updateUser(ctx *gin.Context) {
+	var req updateUserRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD_KEY).(*token.Payload)
+
+	arg := db.UpdateUserParams{
+		Username: authPayload.Username,
+		FullName: sql.NullString{String: req.FullName, Valid: req.FullName != ""},
+		Email:    sql.NullString{String: req.Email, Valid: req.Email != ""},
+	}
+
+	user, err := server.store.UpdateUser(ctx, arg)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code.Name() {
+			case "unique_violation":
+				ctx.JSON(http.StatusForbidden, errorResponse(err))
+				return
+			}
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// a changed email address has to be verified again
+	if req.Email != "" && !user.IsEmailVerified {
+		server.trySendVerificationEmail(ctx, user)
+	}
+
+	ctx.JSON(http.StatusOK, newUserResponse(user))
+}
+
+type changePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required,min=6"`
+	NewPassword     string `json:"new_password" binding:"required,min=6,nefield=CurrentPassword"`
+}
+
+// changePassword sets a new password and revokes every access token, API key and OAuth refresh token
+// issued before, the caller gets a new access token to stay logged in
+func (server *Server) changePassword(ctx *gin.Context) {
+	var req changePasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	user, valid := server.authenticatedUser(ctx)
+	if !valid {
+		return
+	}
+
+	err := server.passwordHasher.CheckPassword(user.HashedPassword, req.CurrentPassword)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	if !server.validPassword(ctx, req.NewPassword, user.Username, user.Email) {
+		return
+	}
+
+	hashedPassword, err := server.passwordHasher.HashPassword(req.NewPassword)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	response, err := server.store.ChangePasswordTx(ctx, db.ChangePasswordTxParams{
+		Username:       user.Username,
+		HashedPassword: hashedPassword,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	user = response.User
+
+	accessToken, err := server.tokenMaker.CreateToken(user.Username, server.config.AccessTokenDuration)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, loginUserResponse{
+		AccessToken: accessToken,
+		User:        newUserResponse(user),
+	})
+}
+
+// validPassword checks a new password against the password policy and lists every broken rule
+func (server *Server) validPassword(ctx *gin.Context, password string, username string, email string) bool {
+	err := server.passwordPolicy.Validate(password, username, email)
+	if err != nil {
+		if policyErr, ok := err.(*util.PasswordPolicyError); ok {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error":      policyErr.Error(),
+				"violations": policyErr.Violations,
+			})
+			return false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+
+	return true
+}
+
+// tryRehashPassword upgrades a hash made with an outdated algorithm or cost while the plain password is at hand,
+// failures are only logged because the login itself succeeded
+func (server *Server) tryRehashPassword(ctx *gin.Context, user db.User, password string) {
+	if !server.passwordHasher.NeedsRehash(user.HashedPassword) {
+		return
+	}
+
+	hashedPassword, err := server.passwordHasher.HashPassword(password)
+	if err == nil {
+		err = server.store.RehashUserPassword(ctx, db.RehashUserPasswordParams{
+			NewHashedPassword: hashedPassword,
+			Username:          user.Username,
+			HashedPassword:    user.HashedPassword,
+		})
+	}
+	if err != nil {
+		log.Printf("cannot rehash password of %s: %v", user.Username, err)
+	}
+}
@@ -0,0 +1,181 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	db "github.com/VL-037/go-bank/db/sqlc"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Formats of a user data export
+const (
+	EXPORT_FORMAT_JSON = "json"
+	EXPORT_FORMAT_ZIP  = "zip"
+)
+
+type deleteUserRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"max=32"`
+}
+
+// deleteUser erases the profile of the authenticated user once all of their accounts are closed.
+// Personal fields are pseudonymized and every access token is revoked, the ledger is kept as it is
+func (server *Server) deleteUser(ctx *gin.Context) {
+	var req deleteUserRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	user, valid := server.authenticatedUser(ctx)
+	if !valid {
+		return
+	}
+
+	err := server.passwordHasher.CheckPassword(user.HashedPassword, req.Password)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	if user.IsTotpEnabled {
+		if req.Code == "" {
+			ctx.JSON(http.StatusForbidden, errorResponse(ErrMFARequired))
+			return
+		}
+		if !server.validMFACode(ctx, user, req.Code) {
+			return
+		}
+	}
+
+	_, err = server.store.DeleteUserTx(ctx, user.Username)
+	if err != nil {
+		switch err {
+		case db.ErrOpenAccounts, db.ErrUserDeleted:
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+type exportUserRequest struct {
+	Format string `form:"format" binding:"omitempty,oneof=json zip"`
+}
+
+type userExport struct {
+	ExportedAt time.Time     `json:"exported_at"`
+	Profile    userResponse  `json:"profile"`
+	Accounts   []db.Account  `json:"accounts"`
+	Transfers  []db.Transfer `json:"transfers"`
+	Entries    []db.Entry    `json:"entries"`
+}
+
+// exportUser downloads everything stored about the authenticated user: the profile, the accounts,
+// and the transfers and entries of those accounts, as one JSON document or as a ZIP of JSON files
+func (server *Server) exportUser(ctx *gin.Context) {
+	var req exportUserRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	format := req.Format
+	if format == "" {
+		format = EXPORT_FORMAT_JSON
+	}
+
+	user, valid := server.authenticatedUser(ctx)
+	if !valid {
+		return
+	}
+
+	export, err := server.userExport(ctx, user)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	var buf bytes.Buffer
+	contentType := "application/json"
+	if format == EXPORT_FORMAT_ZIP {
+		err = export.writeZIP(&buf)
+		contentType = "application/zip"
+	} else {
+		err = writeJSON(&buf, export)
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	filename := fmt.Sprintf("go-bank-export-%s-%s.%s", user.Username, export.ExportedAt.Format("2006-01-02"), format)
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	ctx.Data(http.StatusOK, contentType, buf.Bytes())
+}
+
+func (server *Server) userExport(ctx *gin.Context, user db.User) (userExport, error) {
+	export := userExport{
+		ExportedAt: time.Now().UTC(),
+		Profile:    newUserResponse(user),
+	}
+
+	var err error
+	export.Accounts, err = server.store.ListOwnerAccounts(ctx, user.Username)
+	if err != nil {
+		return export, err
+	}
+
+	export.Transfers, err = server.store.ListOwnerTransfers(ctx, user.Username)
+	if err != nil {
+		return export, err
+	}
+
+	export.Entries, err = server.store.ListOwnerEntries(ctx, user.Username)
+	return export, err
+}
+
+// writeZIP puts each part of the export in its own JSON file
+func (export userExport) writeZIP(w io.Writer) error {
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"profile.json", export.Profile},
+		{"accounts.json", export.Accounts},
+		{"transfers.json", export.Transfers},
+		{"entries.json", export.Entries},
+	}
+
+	archive := zip.NewWriter(w)
+	for _, file := range files {
+		fileWriter, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     file.name,
+			Method:   zip.Deflate,
+			Modified: export.ExportedAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := writeJSON(fileWriter, file.data); err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
+
+func writeJSON(w io.Writer, data interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(data)
+}
@@ -0,0 +1,337 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	mockdb "github.com/VL-037/go-bank/db/mock"
+	db "github.com/VL-037/go-bank/db/sqlc"
+	"github.com/VL-037/go-bank/totp"
+	"github.com/VL-037/go-bank/util"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteUserAPI(t *testing.T) {
+	user, password := randomUser(t)
+
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+	mfaUser := user
+	mfaUser.TotpSecret = sql.NullString{String: secret, Valid: true}
+	mfaUser.IsTotpEnabled = true
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "NO_CONTENT",
+			body: gin.H{"password": password},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					DeleteUserTx(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.DeleteUserTxResponse{User: user}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name: "NO_CONTENT - MFA Code",
+			body: gin.H{"password": password, "code": currentTOTPCode(t, secret)},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(mfaUser, nil)
+				store.EXPECT().
+					UseUserTOTPStep(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(1), nil)
+				store.EXPECT().
+					DeleteUserTx(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.DeleteUserTxResponse{User: mfaUser}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name: "FORBIDDEN - MFA Code Missing",
+			body: gin.H{"password": password},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(mfaUser, nil)
+				store.EXPECT().
+					DeleteUserTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "UNAUTHORIZED - Wrong Password",
+			body: gin.H{"password": "WRONG_PASSWORD"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					DeleteUserTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "BAD_REQUEST - Open Accounts",
+			body: gin.H{"password": password},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					DeleteUserTx(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.DeleteUserTxResponse{}, db.ErrOpenAccounts)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Contains(t, recorder.Body.String(), db.ErrOpenAccounts.Error())
+			},
+		},
+		{
+			name: "BAD_REQUEST - Missing Password",
+			body: gin.H{},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					DeleteUserTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "INTERNAL_SERVER_ERROR",
+			body: gin.H{"password": password},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					DeleteUserTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.DeleteUserTxResponse{}, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := "/users/me"
+			request, err := http.NewRequest(http.MethodDelete, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, AUTHORIZATION_TYPE_BEARER, user.Username, DURATION)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestExportUserAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	otherAccount := randomAccount(util.RandomOwner())
+	transfers := []db.Transfer{
+		{ID: 1, FromAccountID: account.ID, ToAccountID: otherAccount.ID, Amount: 10, Metadata: json.RawMessage(`{}`)},
+	}
+	entries := []db.Entry{
+		{ID: 1, AccountID: account.ID, Amount: -10},
+	}
+
+	buildExportStubs := func(store *mockdb.MockStore) {
+		store.EXPECT().
+			GetUser(gomock.Any(), gomock.Eq(user.Username)).
+			Times(1).
+			Return(user, nil)
+		store.EXPECT().
+			ListOwnerAccounts(gomock.Any(), gomock.Eq(user.Username)).
+			Times(1).
+			Return([]db.Account{account}, nil)
+		store.EXPECT().
+			ListOwnerTransfers(gomock.Any(), gomock.Eq(user.Username)).
+			Times(1).
+			Return(transfers, nil)
+		store.EXPECT().
+			ListOwnerEntries(gomock.Any(), gomock.Eq(user.Username)).
+			Times(1).
+			Return(entries, nil)
+	}
+
+	requireExport := func(t *testing.T, data []byte) {
+		var export userExport
+		require.NoError(t, json.Unmarshal(data, &export))
+		require.Equal(t, user.Username, export.Profile.Username)
+		require.Equal(t, user.Email, export.Profile.Email)
+		require.Len(t, export.Accounts, 1)
+		require.Equal(t, account.Number, export.Accounts[0].Number)
+		require.Len(t, export.Transfers, 1)
+		require.Equal(t, transfers[0].ID, export.Transfers[0].ID)
+		require.Len(t, export.Entries, 1)
+		require.Equal(t, entries[0].Amount, export.Entries[0].Amount)
+		require.NotContains(t, string(data), "hashed_password")
+	}
+
+	testCases := []struct {
+		name          string
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:       "OK - JSON",
+			query:      "",
+			buildStubs: buildExportStubs,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+				require.Contains(t, recorder.Header().Get("Content-Disposition"), ".json")
+				requireExport(t, recorder.Body.Bytes())
+			},
+		},
+		{
+			name:       "OK - ZIP",
+			query:      "?format=zip",
+			buildStubs: buildExportStubs,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "application/zip", recorder.Header().Get("Content-Type"))
+				require.Contains(t, recorder.Header().Get("Content-Disposition"), ".zip")
+
+				archive, err := zip.NewReader(bytes.NewReader(recorder.Body.Bytes()), int64(recorder.Body.Len()))
+				require.NoError(t, err)
+
+				// the files together hold the same data as the JSON document
+				parts := map[string]json.RawMessage{}
+				for _, file := range archive.File {
+					f, err := file.Open()
+					require.NoError(t, err)
+					data, err := io.ReadAll(f)
+					require.NoError(t, err)
+					require.NoError(t, f.Close())
+					parts[file.Name] = data
+				}
+				require.Len(t, parts, 4)
+
+				data, err := json.Marshal(gin.H{
+					"profile":   parts["profile.json"],
+					"accounts":  parts["accounts.json"],
+					"transfers": parts["transfers.json"],
+					"entries":   parts["entries.json"],
+				})
+				require.NoError(t, err)
+				requireExport(t, data)
+			},
+		},
+		{
+			name:  "BAD_REQUEST - Invalid Format",
+			query: "?format=xml",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "INTERNAL_SERVER_ERROR",
+			query: "",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					ListOwnerAccounts(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
+				store.EXPECT().
+					ListOwnerTransfers(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := "/users/me/export" + tc.query
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, AUTHORIZATION_TYPE_BEARER, user.Username, DURATION)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
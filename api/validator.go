@@ -12,3 +12,16 @@ var validCurrency validator.Func = func(fieldLevel validator.FieldLevel) bool {
 	return false
 }
 
+var validFrequency validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	if frequency, ok := fieldLevel.Field().Interface().(string); ok {
+		return util.IsSupportedFrequency(frequency)
+	}
+	return false
+}
+
+var validAccountNumber validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	if number, ok := fieldLevel.Field().Interface().(string); ok {
+		return util.IsValidAccountNumber(number)
+	}
+	return false
+}
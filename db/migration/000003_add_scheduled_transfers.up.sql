@@ -0,0 +1,37 @@
+CREATE TABLE "scheduled_transfers"
+(
+    "id"              bigserial PRIMARY KEY,
+    "from_account_id" bigint      NOT NULL,
+    "to_account_id"   bigint      NOT NULL,
+    "amount"          bigint      NOT NULL,
+    "currency"        varchar     NOT NULL,
+    "execute_at"      timestamptz NOT NULL,
+    "status"          varchar     NOT NULL DEFAULT 'pending',
+    "transfer_id"     bigint,
+    "failure_reason"  varchar,
+    "executed_at"     timestamptz,
+    "created_by"      varchar,
+    "created_at"      timestamptz NOT NULL DEFAULT (now()),
+    "updated_by"      varchar,
+    "updated_at"      timestamptz NOT NULL DEFAULT (now()),
+    "mark_for_delete" boolean     NOT NULL DEFAULT false
+);
+
+CREATE INDEX ON "scheduled_transfers" ("from_account_id");
+
+CREATE INDEX ON "scheduled_transfers" ("status", "execute_at");
+
+COMMENT
+ON COLUMN "scheduled_transfers"."amount" IS 'must be positive';
+
+COMMENT
+ON COLUMN "scheduled_transfers"."status" IS 'pending, executed, failed or cancelled';
+
+ALTER TABLE "scheduled_transfers"
+    ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "scheduled_transfers"
+    ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "scheduled_transfers"
+    ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
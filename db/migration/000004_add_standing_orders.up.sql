@@ -0,0 +1,61 @@
+CREATE TABLE "standing_orders"
+(
+    "id"                  bigserial PRIMARY KEY,
+    "from_account_id"     bigint      NOT NULL,
+    "to_account_id"       bigint      NOT NULL,
+    "amount"              bigint      NOT NULL,
+    "currency"            varchar     NOT NULL,
+    "frequency"           varchar     NOT NULL,
+    "day_of_month"        integer,
+    "start_at"            timestamptz NOT NULL,
+    "end_at"              timestamptz,
+    "max_executions"      integer,
+    "executed_count"      integer     NOT NULL DEFAULT 0,
+    "next_execution_at"   timestamptz NOT NULL,
+    "status"              varchar     NOT NULL DEFAULT 'active',
+    "retry_count"         integer     NOT NULL DEFAULT 0,
+    "last_failure_reason" varchar,
+    "last_executed_at"    timestamptz,
+    "created_by"          varchar,
+    "created_at"          timestamptz NOT NULL DEFAULT (now()),
+    "updated_by"          varchar,
+    "updated_at"          timestamptz NOT NULL DEFAULT (now()),
+    "mark_for_delete"     boolean     NOT NULL DEFAULT false
+);
+
+CREATE TABLE "notifications"
+(
+    "id"              bigserial PRIMARY KEY,
+    "username"        varchar     NOT NULL,
+    "message"         varchar     NOT NULL,
+    "is_read"         boolean     NOT NULL DEFAULT false,
+    "created_by"      varchar,
+    "created_at"      timestamptz NOT NULL DEFAULT (now()),
+    "updated_by"      varchar,
+    "updated_at"      timestamptz NOT NULL DEFAULT (now()),
+    "mark_for_delete" boolean     NOT NULL DEFAULT false
+);
+
+CREATE INDEX ON "standing_orders" ("from_account_id");
+
+CREATE INDEX ON "standing_orders" ("status", "next_execution_at");
+
+CREATE INDEX ON "notifications" ("username");
+
+COMMENT
+ON COLUMN "standing_orders"."frequency" IS 'daily, weekly or monthly';
+
+COMMENT
+ON COLUMN "standing_orders"."day_of_month" IS 'only used by monthly standing orders';
+
+COMMENT
+ON COLUMN "standing_orders"."status" IS 'active, paused or completed';
+
+ALTER TABLE "standing_orders"
+    ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "standing_orders"
+    ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "notifications"
+    ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS holds;
+ALTER TABLE IF EXISTS "accounts"
+    DROP COLUMN IF EXISTS "available_balance";
\ No newline at end of file
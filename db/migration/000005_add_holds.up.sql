@@ -0,0 +1,47 @@
+ALTER TABLE "accounts"
+    ADD COLUMN "available_balance" bigint NOT NULL DEFAULT 0;
+
+UPDATE "accounts"
+SET "available_balance" = "balance";
+
+COMMENT
+ON COLUMN "accounts"."available_balance" IS 'balance minus active holds';
+
+CREATE TABLE "holds"
+(
+    "id"              bigserial PRIMARY KEY,
+    "from_account_id" bigint      NOT NULL,
+    "to_account_id"   bigint      NOT NULL,
+    "amount"          bigint      NOT NULL,
+    "currency"        varchar     NOT NULL,
+    "status"          varchar     NOT NULL DEFAULT 'active',
+    "expires_at"      timestamptz NOT NULL,
+    "captured_amount" bigint,
+    "transfer_id"     bigint,
+    "created_by"      varchar,
+    "created_at"      timestamptz NOT NULL DEFAULT (now()),
+    "updated_by"      varchar,
+    "updated_at"      timestamptz NOT NULL DEFAULT (now()),
+    "mark_for_delete" boolean     NOT NULL DEFAULT false
+);
+
+CREATE INDEX ON "holds" ("from_account_id");
+
+CREATE INDEX ON "holds" ("to_account_id");
+
+CREATE INDEX ON "holds" ("status", "expires_at");
+
+COMMENT
+ON COLUMN "holds"."amount" IS 'must be positive';
+
+COMMENT
+ON COLUMN "holds"."status" IS 'active, captured, voided or expired';
+
+ALTER TABLE "holds"
+    ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "holds"
+    ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "holds"
+    ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
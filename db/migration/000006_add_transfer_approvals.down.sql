@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS transfer_approvals;
+DROP TABLE IF EXISTS pending_transfers;
+ALTER TABLE IF EXISTS "users"
+    DROP COLUMN IF EXISTS "role";
\ No newline at end of file
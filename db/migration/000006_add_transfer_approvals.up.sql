@@ -0,0 +1,71 @@
+ALTER TABLE "users"
+    ADD COLUMN "role" varchar NOT NULL DEFAULT 'depositor';
+
+COMMENT
+ON COLUMN "users"."role" IS 'depositor or approver';
+
+CREATE TABLE "pending_transfers"
+(
+    "id"              bigserial PRIMARY KEY,
+    "from_account_id" bigint      NOT NULL,
+    "to_account_id"   bigint      NOT NULL,
+    "amount"          bigint      NOT NULL,
+    "currency"        varchar     NOT NULL,
+    "status"          varchar     NOT NULL DEFAULT 'pending_approval',
+    "requested_by"    varchar     NOT NULL,
+    "transfer_id"     bigint,
+    "created_by"      varchar,
+    "created_at"      timestamptz NOT NULL DEFAULT (now()),
+    "updated_by"      varchar,
+    "updated_at"      timestamptz NOT NULL DEFAULT (now()),
+    "mark_for_delete" boolean     NOT NULL DEFAULT false
+);
+
+CREATE TABLE "transfer_approvals"
+(
+    "id"                  bigserial PRIMARY KEY,
+    "pending_transfer_id" bigint      NOT NULL,
+    "approver"            varchar     NOT NULL,
+    "decision"            varchar     NOT NULL,
+    "comment"             varchar,
+    "created_by"          varchar,
+    "created_at"          timestamptz NOT NULL DEFAULT (now()),
+    "updated_by"          varchar,
+    "updated_at"          timestamptz NOT NULL DEFAULT (now()),
+    "mark_for_delete"     boolean     NOT NULL DEFAULT false
+);
+
+CREATE INDEX ON "pending_transfers" ("status");
+
+CREATE INDEX ON "pending_transfers" ("requested_by");
+
+CREATE INDEX ON "transfer_approvals" ("pending_transfer_id");
+
+CREATE INDEX ON "transfer_approvals" ("approver");
+
+COMMENT
+ON COLUMN "pending_transfers"."amount" IS 'must be positive';
+
+COMMENT
+ON COLUMN "pending_transfers"."status" IS 'pending_approval, approved or rejected';
+
+COMMENT
+ON COLUMN "transfer_approvals"."decision" IS 'approved or rejected';
+
+ALTER TABLE "pending_transfers"
+    ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "pending_transfers"
+    ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "pending_transfers"
+    ADD FOREIGN KEY ("requested_by") REFERENCES "users" ("username");
+
+ALTER TABLE "pending_transfers"
+    ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "transfer_approvals"
+    ADD FOREIGN KEY ("pending_transfer_id") REFERENCES "pending_transfers" ("id");
+
+ALTER TABLE "transfer_approvals"
+    ADD FOREIGN KEY ("approver") REFERENCES "users" ("username");
@@ -0,0 +1,12 @@
+ALTER TABLE IF EXISTS "pending_transfers"
+    DROP COLUMN IF EXISTS "metadata";
+ALTER TABLE IF EXISTS "pending_transfers"
+    DROP COLUMN IF EXISTS "client_reference";
+ALTER TABLE IF EXISTS "pending_transfers"
+    DROP COLUMN IF EXISTS "description";
+ALTER TABLE IF EXISTS "transfers"
+    DROP COLUMN IF EXISTS "metadata";
+ALTER TABLE IF EXISTS "transfers"
+    DROP COLUMN IF EXISTS "client_reference";
+ALTER TABLE IF EXISTS "transfers"
+    DROP COLUMN IF EXISTS "description";
\ No newline at end of file
@@ -0,0 +1,25 @@
+ALTER TABLE "transfers"
+    ADD COLUMN "description" varchar;
+
+ALTER TABLE "transfers"
+    ADD COLUMN "client_reference" varchar;
+
+ALTER TABLE "transfers"
+    ADD COLUMN "metadata" jsonb NOT NULL DEFAULT '{}';
+
+ALTER TABLE "pending_transfers"
+    ADD COLUMN "description" varchar;
+
+ALTER TABLE "pending_transfers"
+    ADD COLUMN "client_reference" varchar;
+
+ALTER TABLE "pending_transfers"
+    ADD COLUMN "metadata" jsonb NOT NULL DEFAULT '{}';
+
+CREATE INDEX ON "transfers" ("client_reference");
+
+COMMENT
+ON COLUMN "transfers"."client_reference" IS 'reference chosen by the client, not unique';
+
+COMMENT
+ON COLUMN "transfers"."metadata" IS 'flat map of string keys and values';
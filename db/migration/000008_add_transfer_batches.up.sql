@@ -0,0 +1,61 @@
+CREATE TABLE "transfer_batches"
+(
+    "id"              bigserial PRIMARY KEY,
+    "from_account_id" bigint      NOT NULL,
+    "currency"        varchar     NOT NULL,
+    "mode"            varchar     NOT NULL,
+    "status"          varchar     NOT NULL DEFAULT 'processing',
+    "item_count"      integer     NOT NULL,
+    "total_amount"    bigint      NOT NULL,
+    "executed_count"  integer     NOT NULL DEFAULT 0,
+    "failed_count"    integer     NOT NULL DEFAULT 0,
+    "created_by"      varchar,
+    "created_at"      timestamptz NOT NULL DEFAULT (now()),
+    "updated_by"      varchar,
+    "updated_at"      timestamptz NOT NULL DEFAULT (now()),
+    "mark_for_delete" boolean     NOT NULL DEFAULT false
+);
+
+CREATE TABLE "transfer_batch_items"
+(
+    "id"               bigserial PRIMARY KEY,
+    "batch_id"         bigint      NOT NULL,
+    "line_number"      integer     NOT NULL,
+    "to_account_id"    bigint      NOT NULL,
+    "amount"           bigint      NOT NULL,
+    "description"      varchar,
+    "client_reference" varchar,
+    "status"           varchar     NOT NULL,
+    "failure_reason"   varchar,
+    "transfer_id"      bigint,
+    "created_by"       varchar,
+    "created_at"       timestamptz NOT NULL DEFAULT (now()),
+    "updated_by"       varchar,
+    "updated_at"       timestamptz NOT NULL DEFAULT (now()),
+    "mark_for_delete"  boolean     NOT NULL DEFAULT false
+);
+
+CREATE INDEX ON "transfer_batches" ("from_account_id");
+
+CREATE UNIQUE INDEX ON "transfer_batch_items" ("batch_id", "line_number");
+
+COMMENT
+ON COLUMN "transfer_batches"."mode" IS 'atomic or best_effort';
+
+COMMENT
+ON COLUMN "transfer_batches"."status" IS 'processing, completed, partially_completed or failed';
+
+COMMENT
+ON COLUMN "transfer_batch_items"."status" IS 'executed or failed';
+
+ALTER TABLE "transfer_batches"
+    ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfer_batch_items"
+    ADD FOREIGN KEY ("batch_id") REFERENCES "transfer_batches" ("id");
+
+ALTER TABLE "transfer_batch_items"
+    ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfer_batch_items"
+    ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
@@ -0,0 +1,49 @@
+CREATE TABLE "payment_requests"
+(
+    "id"              bigserial PRIMARY KEY,
+    "requester"       varchar     NOT NULL,
+    "payer"           varchar     NOT NULL,
+    "to_account_id"   bigint      NOT NULL,
+    "amount"          bigint      NOT NULL,
+    "currency"        varchar     NOT NULL,
+    "note"            varchar,
+    "status"          varchar     NOT NULL DEFAULT 'pending',
+    "expires_at"      timestamptz NOT NULL,
+    "from_account_id" bigint,
+    "transfer_id"     bigint,
+    "created_by"      varchar,
+    "created_at"      timestamptz NOT NULL DEFAULT (now()),
+    "updated_by"      varchar,
+    "updated_at"      timestamptz NOT NULL DEFAULT (now()),
+    "mark_for_delete" boolean     NOT NULL DEFAULT false
+);
+
+CREATE INDEX ON "payment_requests" ("requester");
+
+CREATE INDEX ON "payment_requests" ("payer");
+
+CREATE INDEX ON "payment_requests" ("status", "expires_at");
+
+COMMENT
+ON COLUMN "payment_requests"."amount" IS 'must be positive';
+
+COMMENT
+ON COLUMN "payment_requests"."status" IS 'pending, accepted, declined or expired';
+
+COMMENT
+ON COLUMN "payment_requests"."from_account_id" IS 'account chosen by the payer on accept';
+
+ALTER TABLE "payment_requests"
+    ADD FOREIGN KEY ("requester") REFERENCES "users" ("username");
+
+ALTER TABLE "payment_requests"
+    ADD FOREIGN KEY ("payer") REFERENCES "users" ("username");
+
+ALTER TABLE "payment_requests"
+    ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "payment_requests"
+    ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "payment_requests"
+    ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
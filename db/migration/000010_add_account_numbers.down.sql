@@ -0,0 +1,2 @@
+ALTER TABLE IF EXISTS "accounts"
+    DROP COLUMN IF EXISTS "number";
\ No newline at end of file
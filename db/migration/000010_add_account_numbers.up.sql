@@ -0,0 +1,16 @@
+ALTER TABLE "accounts"
+    ADD COLUMN "number" varchar;
+
+-- existing accounts get their id as basic account number, check digits computed like in util.NewAccountNumber
+UPDATE "accounts"
+SET "number" = 'VL' ||
+               lpad((98 - ((lpad("id"::text, 10, '0') || '312100')::numeric % 97))::text, 2, '0') ||
+               lpad("id"::text, 10, '0');
+
+ALTER TABLE "accounts"
+    ALTER COLUMN "number" SET NOT NULL;
+
+CREATE UNIQUE INDEX ON "accounts" ("number");
+
+COMMENT
+ON COLUMN "accounts"."number" IS 'public account number with mod-97 check digits';
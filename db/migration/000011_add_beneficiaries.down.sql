@@ -0,0 +1 @@
+DROP TABLE IF EXISTS beneficiaries;
\ No newline at end of file
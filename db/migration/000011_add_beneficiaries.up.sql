@@ -0,0 +1,26 @@
+CREATE TABLE "beneficiaries"
+(
+    "id"              bigserial PRIMARY KEY,
+    "owner"           varchar     NOT NULL,
+    "nickname"        varchar     NOT NULL,
+    "account_id"      bigint      NOT NULL,
+    "currency"        varchar     NOT NULL,
+    "created_by"      varchar,
+    "created_at"      timestamptz NOT NULL DEFAULT (now()),
+    "updated_by"      varchar,
+    "updated_at"      timestamptz NOT NULL DEFAULT (now()),
+    "mark_for_delete" boolean     NOT NULL DEFAULT false
+);
+
+CREATE INDEX ON "beneficiaries" ("owner");
+
+CREATE UNIQUE INDEX ON "beneficiaries" ("owner", "account_id");
+
+COMMENT
+ON COLUMN "beneficiaries"."created_at" IS 'start of the cooling-off period before large transfers';
+
+ALTER TABLE "beneficiaries"
+    ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+ALTER TABLE "beneficiaries"
+    ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
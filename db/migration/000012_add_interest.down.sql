@@ -0,0 +1,23 @@
+DROP TABLE IF EXISTS interest_accruals;
+DELETE
+FROM entries
+WHERE account_id IN (SELECT id FROM accounts WHERE owner = 'interest_expense');
+DELETE
+FROM transfers
+WHERE from_account_id IN (SELECT id FROM accounts WHERE owner = 'interest_expense');
+DELETE
+FROM accounts
+WHERE owner = 'interest_expense';
+DELETE
+FROM users
+WHERE username = 'interest_expense';
+ALTER TABLE IF EXISTS "accounts"
+    DROP CONSTRAINT IF EXISTS "owner_currency_type_key";
+ALTER TABLE IF EXISTS "accounts"
+    ADD CONSTRAINT "owner_currency_key" UNIQUE ("owner", "currency");
+ALTER TABLE IF EXISTS "accounts"
+    DROP COLUMN IF EXISTS "interest_accrued_until";
+ALTER TABLE IF EXISTS "accounts"
+    DROP COLUMN IF EXISTS "accrued_interest";
+ALTER TABLE IF EXISTS "accounts"
+    DROP COLUMN IF EXISTS "type";
\ No newline at end of file
@@ -0,0 +1,71 @@
+ALTER TABLE "accounts"
+    ADD COLUMN "type" varchar NOT NULL DEFAULT 'checking';
+
+ALTER TABLE "accounts"
+    ADD COLUMN "accrued_interest" bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE "accounts"
+    ADD COLUMN "interest_accrued_until" date NOT NULL DEFAULT (CURRENT_DATE);
+
+COMMENT
+ON COLUMN "accounts"."type" IS 'checking, savings or internal';
+
+COMMENT
+ON COLUMN "accounts"."accrued_interest" IS 'interest accrued but not paid yet, in millionths of the minor unit';
+
+COMMENT
+ON COLUMN "accounts"."interest_accrued_until" IS 'interest is accrued for the days before this date';
+
+-- a user can hold one account of each type per currency
+ALTER TABLE "accounts"
+    DROP CONSTRAINT "owner_currency_key";
+
+ALTER TABLE "accounts"
+    ADD CONSTRAINT "owner_currency_type_key" UNIQUE ("owner", "currency", "type");
+
+CREATE TABLE "interest_accruals"
+(
+    "id"              bigserial PRIMARY KEY,
+    "account_id"      bigint      NOT NULL,
+    "start_date"      date        NOT NULL,
+    "end_date"        date        NOT NULL,
+    "balance"         bigint      NOT NULL,
+    "annual_rate"     bigint      NOT NULL,
+    "day_count"       varchar     NOT NULL,
+    "amount"          bigint      NOT NULL,
+    "transfer_id"     bigint,
+    "created_by"      varchar,
+    "created_at"      timestamptz NOT NULL DEFAULT (now()),
+    "updated_by"      varchar,
+    "updated_at"      timestamptz NOT NULL DEFAULT (now()),
+    "mark_for_delete" boolean     NOT NULL DEFAULT false
+);
+
+CREATE INDEX ON "interest_accruals" ("account_id", "end_date");
+
+COMMENT
+ON COLUMN "interest_accruals"."end_date" IS 'exclusive';
+
+COMMENT
+ON COLUMN "interest_accruals"."annual_rate" IS 'in basis points';
+
+COMMENT
+ON COLUMN "interest_accruals"."amount" IS 'in millionths of the minor unit';
+
+COMMENT
+ON COLUMN "interest_accruals"."transfer_id" IS 'capitalization posted at the end of the month';
+
+ALTER TABLE "interest_accruals"
+    ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "interest_accruals"
+    ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+-- interest is paid from one internal account per currency, it cannot be used to log in
+INSERT INTO "users" ("username", "hashed_password", "full_name", "email")
+VALUES ('interest_expense', '', 'Interest Expense', 'interest_expense@go-bank.internal');
+
+INSERT INTO "accounts" ("owner", "balance", "available_balance", "currency", "number", "type")
+VALUES ('interest_expense', 0, 0, 'IDR', 'VL709990000001', 'internal'),
+       ('interest_expense', 0, 0, 'USD', 'VL439990000002', 'internal'),
+       ('interest_expense', 0, 0, 'EUR', 'VL169990000003', 'internal');
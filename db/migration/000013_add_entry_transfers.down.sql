@@ -0,0 +1,2 @@
+ALTER TABLE IF EXISTS "entries"
+    DROP COLUMN IF EXISTS "transfer_id";
\ No newline at end of file
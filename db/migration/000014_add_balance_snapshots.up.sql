@@ -0,0 +1,20 @@
+CREATE TABLE "account_balance_snapshots"
+(
+    "id"              bigserial PRIMARY KEY,
+    "account_id"      bigint      NOT NULL,
+    "snapshot_date"   date        NOT NULL,
+    "balance"         bigint      NOT NULL,
+    "created_by"      varchar,
+    "created_at"      timestamptz NOT NULL DEFAULT (now()),
+    "updated_by"      varchar,
+    "updated_at"      timestamptz NOT NULL DEFAULT (now()),
+    "mark_for_delete" boolean     NOT NULL DEFAULT false
+);
+
+CREATE UNIQUE INDEX ON "account_balance_snapshots" ("account_id", "snapshot_date");
+
+COMMENT
+ON COLUMN "account_balance_snapshots"."balance" IS 'balance at the end of the snapshot date, midnight UTC';
+
+ALTER TABLE "account_balance_snapshots"
+    ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
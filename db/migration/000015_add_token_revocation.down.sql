@@ -0,0 +1,2 @@
+ALTER TABLE IF EXISTS "users"
+    DROP COLUMN IF EXISTS "tokens_valid_after";
\ No newline at end of file
@@ -0,0 +1,5 @@
+ALTER TABLE "users"
+    ADD COLUMN "tokens_valid_after" timestamptz NOT NULL DEFAULT '0001-01-01 00:00:00Z';
+
+COMMENT
+ON COLUMN "users"."tokens_valid_after" IS 'access tokens issued before are rejected';
@@ -0,0 +1,32 @@
+ALTER TABLE "users"
+    ADD COLUMN "is_email_verified" boolean NOT NULL DEFAULT false;
+
+-- users who signed up before email verification existed keep access to transfers
+UPDATE "users"
+SET "is_email_verified" = true;
+
+CREATE TABLE "email_verifications"
+(
+    "id"              bigserial PRIMARY KEY,
+    "username"        varchar     NOT NULL,
+    "email"           varchar     NOT NULL,
+    "token_hash"      varchar     NOT NULL,
+    "is_used"         boolean     NOT NULL DEFAULT false,
+    "expired_at"      timestamptz NOT NULL,
+    "created_by"      varchar,
+    "created_at"      timestamptz NOT NULL DEFAULT (now()),
+    "updated_by"      varchar,
+    "updated_at"      timestamptz NOT NULL DEFAULT (now()),
+    "mark_for_delete" boolean     NOT NULL DEFAULT false
+);
+
+CREATE INDEX ON "email_verifications" ("username");
+
+COMMENT
+ON COLUMN "email_verifications"."email" IS 'address the token was sent to';
+
+COMMENT
+ON COLUMN "email_verifications"."token_hash" IS 'SHA-256 of the token, the token itself is only sent by email';
+
+ALTER TABLE "email_verifications"
+    ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
@@ -0,0 +1,21 @@
+CREATE TABLE "password_resets"
+(
+    "id"              bigserial PRIMARY KEY,
+    "username"        varchar     NOT NULL,
+    "token_hash"      varchar     NOT NULL,
+    "is_used"         boolean     NOT NULL DEFAULT false,
+    "expired_at"      timestamptz NOT NULL,
+    "created_by"      varchar,
+    "created_at"      timestamptz NOT NULL DEFAULT (now()),
+    "updated_by"      varchar,
+    "updated_at"      timestamptz NOT NULL DEFAULT (now()),
+    "mark_for_delete" boolean     NOT NULL DEFAULT false
+);
+
+CREATE INDEX ON "password_resets" ("username");
+
+COMMENT
+ON COLUMN "password_resets"."token_hash" IS 'SHA-256 of the token, the token itself is only sent by email';
+
+ALTER TABLE "password_resets"
+    ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS recovery_codes;
+
+ALTER TABLE IF EXISTS "users"
+    DROP COLUMN IF EXISTS "totp_secret",
+    DROP COLUMN IF EXISTS "is_totp_enabled",
+    DROP COLUMN IF EXISTS "totp_last_used_step";
\ No newline at end of file
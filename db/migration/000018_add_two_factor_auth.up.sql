@@ -0,0 +1,31 @@
+ALTER TABLE "users"
+    ADD COLUMN "totp_secret"         varchar,
+    ADD COLUMN "is_totp_enabled"     boolean NOT NULL DEFAULT false,
+    ADD COLUMN "totp_last_used_step" bigint  NOT NULL DEFAULT 0;
+
+COMMENT
+ON COLUMN "users"."totp_secret" IS 'base32 TOTP secret, set on enrollment and enabled once a code is confirmed';
+
+COMMENT
+ON COLUMN "users"."totp_last_used_step" IS 'time step of the last accepted code, codes of earlier steps cannot be replayed';
+
+CREATE TABLE "recovery_codes"
+(
+    "id"              bigserial PRIMARY KEY,
+    "username"        varchar     NOT NULL,
+    "code_hash"       varchar     NOT NULL,
+    "is_used"         boolean     NOT NULL DEFAULT false,
+    "created_by"      varchar,
+    "created_at"      timestamptz NOT NULL DEFAULT (now()),
+    "updated_by"      varchar,
+    "updated_at"      timestamptz NOT NULL DEFAULT (now()),
+    "mark_for_delete" boolean     NOT NULL DEFAULT false
+);
+
+CREATE UNIQUE INDEX ON "recovery_codes" ("username", "code_hash");
+
+COMMENT
+ON COLUMN "recovery_codes"."code_hash" IS 'SHA-256 of the recovery code, the codes are only shown once';
+
+ALTER TABLE "recovery_codes"
+    ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
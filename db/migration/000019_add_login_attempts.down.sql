@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS login_attempts;
+
+COMMENT
+ON COLUMN "users"."role" IS 'depositor or approver';
\ No newline at end of file
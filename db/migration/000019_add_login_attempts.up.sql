@@ -0,0 +1,20 @@
+CREATE TABLE "login_attempts"
+(
+    "attempt_key"     varchar PRIMARY KEY,
+    "failures"        integer     NOT NULL DEFAULT 0,
+    "last_failed_at"  timestamptz NOT NULL,
+    "created_by"      varchar,
+    "created_at"      timestamptz NOT NULL DEFAULT (now()),
+    "updated_by"      varchar,
+    "updated_at"      timestamptz NOT NULL DEFAULT (now()),
+    "mark_for_delete" boolean     NOT NULL DEFAULT false
+);
+
+COMMENT
+ON COLUMN "login_attempts"."attempt_key" IS 'user:<username> or ip:<address>';
+
+COMMENT
+ON COLUMN "login_attempts"."failures" IS 'failed logins since the counter was last reset';
+
+COMMENT
+ON COLUMN "users"."role" IS 'depositor, approver or admin';
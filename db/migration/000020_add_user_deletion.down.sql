@@ -0,0 +1,5 @@
+COMMENT
+ON COLUMN "users"."mark_for_delete" IS NULL;
+
+COMMENT
+ON COLUMN "accounts"."mark_for_delete" IS NULL;
\ No newline at end of file
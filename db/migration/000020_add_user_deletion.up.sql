@@ -0,0 +1,5 @@
+COMMENT
+ON COLUMN "users"."mark_for_delete" IS 'the user deleted the profile, personal fields are pseudonymized';
+
+COMMENT
+ON COLUMN "accounts"."mark_for_delete" IS 'the account is closed, it keeps its entries but cannot move money';
@@ -0,0 +1,33 @@
+CREATE TABLE "api_keys"
+(
+    "id"              bigserial PRIMARY KEY,
+    "username"        varchar     NOT NULL,
+    "name"            varchar     NOT NULL,
+    "key_prefix"      varchar     NOT NULL,
+    "key_hash"        varchar     NOT NULL,
+    "scopes"          varchar     NOT NULL,
+    "expired_at"      timestamptz NOT NULL,
+    "last_used_at"    timestamptz,
+    "is_revoked"      boolean     NOT NULL DEFAULT false,
+    "created_by"      varchar,
+    "created_at"      timestamptz NOT NULL DEFAULT (now()),
+    "updated_by"      varchar,
+    "updated_at"      timestamptz NOT NULL DEFAULT (now()),
+    "mark_for_delete" boolean     NOT NULL DEFAULT false
+);
+
+CREATE INDEX ON "api_keys" ("username");
+
+CREATE UNIQUE INDEX ON "api_keys" ("key_hash");
+
+COMMENT
+ON COLUMN "api_keys"."key_prefix" IS 'first characters of the key, shown to tell keys apart';
+
+COMMENT
+ON COLUMN "api_keys"."key_hash" IS 'SHA-256 of the key, the key itself is only shown once';
+
+COMMENT
+ON COLUMN "api_keys"."scopes" IS 'space separated scopes, e.g. read:accounts write:transfers';
+
+ALTER TABLE "api_keys"
+    ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
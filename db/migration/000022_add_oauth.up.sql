@@ -0,0 +1,111 @@
+CREATE TABLE "oauth_clients"
+(
+    "id"              bigserial PRIMARY KEY,
+    "client_id"       varchar     NOT NULL,
+    "secret_hash"     varchar,
+    "name"            varchar     NOT NULL,
+    "redirect_uris"   varchar     NOT NULL,
+    "scopes"          varchar     NOT NULL,
+    "is_revoked"      boolean     NOT NULL DEFAULT false,
+    "created_by"      varchar,
+    "created_at"      timestamptz NOT NULL DEFAULT (now()),
+    "updated_by"      varchar,
+    "updated_at"      timestamptz NOT NULL DEFAULT (now()),
+    "mark_for_delete" boolean     NOT NULL DEFAULT false
+);
+
+CREATE TABLE "oauth_consents"
+(
+    "id"              bigserial PRIMARY KEY,
+    "username"        varchar     NOT NULL,
+    "client_id"       varchar     NOT NULL,
+    "scopes"          varchar     NOT NULL,
+    "granted_at"      timestamptz NOT NULL DEFAULT (now()),
+    "is_revoked"      boolean     NOT NULL DEFAULT false,
+    "created_by"      varchar,
+    "created_at"      timestamptz NOT NULL DEFAULT (now()),
+    "updated_by"      varchar,
+    "updated_at"      timestamptz NOT NULL DEFAULT (now()),
+    "mark_for_delete" boolean     NOT NULL DEFAULT false
+);
+
+CREATE TABLE "oauth_authorization_codes"
+(
+    "id"              bigserial PRIMARY KEY,
+    "code_hash"       varchar     NOT NULL,
+    "client_id"       varchar     NOT NULL,
+    "username"        varchar     NOT NULL,
+    "redirect_uri"    varchar     NOT NULL,
+    "scopes"          varchar     NOT NULL,
+    "code_challenge"  varchar     NOT NULL,
+    "expired_at"      timestamptz NOT NULL,
+    "used_at"         timestamptz,
+    "created_by"      varchar,
+    "created_at"      timestamptz NOT NULL DEFAULT (now()),
+    "updated_by"      varchar,
+    "updated_at"      timestamptz NOT NULL DEFAULT (now()),
+    "mark_for_delete" boolean     NOT NULL DEFAULT false
+);
+
+CREATE TABLE "oauth_refresh_tokens"
+(
+    "id"              bigserial PRIMARY KEY,
+    "token_hash"      varchar     NOT NULL,
+    "client_id"       varchar     NOT NULL,
+    "username"        varchar     NOT NULL,
+    "scopes"          varchar     NOT NULL,
+    "expired_at"      timestamptz NOT NULL,
+    "rotated_at"      timestamptz,
+    "is_revoked"      boolean     NOT NULL DEFAULT false,
+    "created_by"      varchar,
+    "created_at"      timestamptz NOT NULL DEFAULT (now()),
+    "updated_by"      varchar,
+    "updated_at"      timestamptz NOT NULL DEFAULT (now()),
+    "mark_for_delete" boolean     NOT NULL DEFAULT false
+);
+
+CREATE UNIQUE INDEX ON "oauth_clients" ("client_id");
+
+CREATE UNIQUE INDEX ON "oauth_consents" ("username", "client_id");
+
+CREATE UNIQUE INDEX ON "oauth_authorization_codes" ("code_hash");
+
+CREATE UNIQUE INDEX ON "oauth_refresh_tokens" ("token_hash");
+
+CREATE INDEX ON "oauth_refresh_tokens" ("username", "client_id");
+
+COMMENT
+ON COLUMN "oauth_clients"."secret_hash" IS 'SHA-256 of the client secret, null for public clients which rely on PKCE alone';
+
+COMMENT
+ON COLUMN "oauth_clients"."redirect_uris" IS 'space separated redirect URIs, matched exactly';
+
+COMMENT
+ON COLUMN "oauth_clients"."scopes" IS 'space separated scopes the client may request';
+
+COMMENT
+ON COLUMN "oauth_consents"."granted_at" IS 'access tokens issued before are rejected, reset when a revoked consent is granted again or its scopes change';
+
+COMMENT
+ON COLUMN "oauth_authorization_codes"."code_challenge" IS 'PKCE S256 challenge, base64url SHA-256 of the code verifier';
+
+COMMENT
+ON COLUMN "oauth_refresh_tokens"."rotated_at" IS 'set when the token was exchanged for a new one, a rotated token is never accepted again';
+
+ALTER TABLE "oauth_consents"
+    ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "oauth_consents"
+    ADD FOREIGN KEY ("client_id") REFERENCES "oauth_clients" ("client_id");
+
+ALTER TABLE "oauth_authorization_codes"
+    ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "oauth_authorization_codes"
+    ADD FOREIGN KEY ("client_id") REFERENCES "oauth_clients" ("client_id");
+
+ALTER TABLE "oauth_refresh_tokens"
+    ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "oauth_refresh_tokens"
+    ADD FOREIGN KEY ("client_id") REFERENCES "oauth_clients" ("client_id");
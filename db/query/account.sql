@@ -1,14 +1,29 @@
 -- name: CreateAccount :one
 INSERT INTO accounts (owner,
                       balance,
-                      currency)
-VALUES ($1, $2, $3) RETURNING *;
+                      available_balance,
+                      currency,
+                      number,
+                      type)
+VALUES ($1, $2, $2, $3, $4, $5) RETURNING *;
 
 -- name: GetAccount :one
 SELECT *
 FROM accounts
 WHERE id = $1 LIMIT 1;
 
+-- name: GetAccountByNumber :one
+SELECT *
+FROM accounts
+WHERE number = $1 LIMIT 1;
+
+-- name: GetAccountByOwner :one
+SELECT *
+FROM accounts
+WHERE owner = $1
+  AND currency = $2
+  AND type = $3 LIMIT 1;
+
 -- name: GetAccountForUpdate :one
 SELECT *
 FROM accounts
@@ -22,17 +37,75 @@ WHERE owner = $1
 ORDER BY id LIMIT $2
 OFFSET $3;
 
+-- name: ListOwnerAccounts :many
+SELECT *
+FROM accounts
+WHERE owner = $1
+ORDER BY id;
+
+-- name: CountOpenAccounts :one
+SELECT COUNT(*)
+FROM accounts
+WHERE owner = $1
+  AND mark_for_delete = false;
+
 -- name: UpdateAccount :one
 UPDATE accounts
-SET balance = $2
+SET balance           = $2,
+    available_balance = available_balance + ($2 - balance)
 WHERE id = $1 RETURNING *;
 
 -- name: AddAccountBalance :one
 UPDATE accounts
-SET balance = balance + sqlc.arg(amount)
+SET balance           = balance + sqlc.arg(amount),
+    available_balance = available_balance + sqlc.arg(amount)
+WHERE id = sqlc.arg(id) RETURNING *;
+
+-- name: AddAccountAvailableBalance :one
+UPDATE accounts
+SET available_balance = available_balance + sqlc.arg(amount)
 WHERE id = sqlc.arg(id) RETURNING *;
 
 -- name: DeleteAccount :exec
 DELETE
 FROM accounts
-WHERE id = $1;
\ No newline at end of file
+WHERE id = $1;
+
+-- name: ListAccountsByIDs :many
+SELECT *
+FROM accounts
+WHERE id = ANY (sqlc.arg(ids)::bigint[])
+ORDER BY id;
+
+-- name: ListAccountsByNumbers :many
+SELECT *
+FROM accounts
+WHERE number = ANY (sqlc.arg(numbers)::varchar[])
+ORDER BY id;
+
+-- name: GetNextInterestAccountForUpdate :one
+SELECT *
+FROM accounts
+WHERE type = 'savings'
+  AND mark_for_delete = false
+  AND interest_accrued_until < sqlc.arg(today)
+ORDER BY id LIMIT 1 FOR NO KEY
+UPDATE SKIP LOCKED;
+
+-- name: UpdateAccountInterest :one
+UPDATE accounts
+SET accrued_interest       = $2,
+    interest_accrued_until = $3,
+    updated_at             = now()
+WHERE id = $1 RETURNING *;
+
+-- name: CloseAccount :one
+UPDATE accounts
+SET mark_for_delete  = true,
+    accrued_interest = 0,
+    updated_at       = now()
+WHERE id = sqlc.arg(id)
+  AND balance = 0
+  AND available_balance = 0
+  AND accrued_interest < sqlc.arg(interest_scale)
+  AND mark_for_delete = false RETURNING *;
\ No newline at end of file
@@ -0,0 +1,38 @@
+-- name: CreateAPIKey :one
+INSERT INTO api_keys (username,
+                      name,
+                      key_prefix,
+                      key_hash,
+                      scopes,
+                      expired_at)
+VALUES ($1, $2, $3, $4, $5, $6) RETURNING *;
+
+-- name: GetAPIKeyByHash :one
+SELECT *
+FROM api_keys
+WHERE key_hash = $1 LIMIT 1;
+
+-- name: ListAPIKeys :many
+SELECT *
+FROM api_keys
+WHERE username = $1
+ORDER BY id;
+
+-- name: UpdateAPIKeyLastUsed :exec
+UPDATE api_keys
+SET last_used_at = now()
+WHERE id = $1;
+
+-- name: RevokeAPIKey :one
+UPDATE api_keys
+SET is_revoked = true,
+    updated_at = now()
+WHERE id = $1
+  AND username = $2 RETURNING *;
+
+-- name: RevokeUserAPIKeys :exec
+UPDATE api_keys
+SET is_revoked = true,
+    updated_at = now()
+WHERE username = $1
+  AND is_revoked = false;
\ No newline at end of file
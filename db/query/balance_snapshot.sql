@@ -0,0 +1,20 @@
+-- name: CreateBalanceSnapshots :execrows
+INSERT INTO account_balance_snapshots (account_id,
+                                       snapshot_date,
+                                       balance)
+SELECT a.id,
+       sqlc.arg(snapshot_date)::date,
+       a.balance - COALESCE((SELECT SUM(e.amount)
+                             FROM entries e
+                             WHERE e.account_id = a.id
+                               AND e.created_at >= sqlc.arg(end_time)), 0)
+FROM accounts a
+WHERE a.created_at < sqlc.arg(end_time)
+ON CONFLICT (account_id, snapshot_date) DO NOTHING;
+
+-- name: GetLatestBalanceSnapshot :one
+SELECT *
+FROM account_balance_snapshots
+WHERE account_id = sqlc.arg(account_id)
+  AND snapshot_date < sqlc.arg(before_date)
+ORDER BY snapshot_date DESC LIMIT 1;
\ No newline at end of file
@@ -0,0 +1,40 @@
+-- name: CreateBeneficiary :one
+INSERT INTO beneficiaries (owner,
+                           nickname,
+                           account_id,
+                           currency)
+VALUES ($1, $2, $3, $4) RETURNING *;
+
+-- name: GetBeneficiary :one
+SELECT *
+FROM beneficiaries
+WHERE id = $1 LIMIT 1;
+
+-- name: GetBeneficiaryByAccount :one
+SELECT *
+FROM beneficiaries
+WHERE owner = $1
+  AND account_id = $2 LIMIT 1;
+
+-- name: ListBeneficiaries :many
+SELECT *
+FROM beneficiaries
+WHERE owner = $1
+ORDER BY nickname, id LIMIT $2
+OFFSET $3;
+
+-- name: UpdateBeneficiary :one
+UPDATE beneficiaries
+SET nickname   = $2,
+    updated_at = now()
+WHERE id = $1 RETURNING *;
+
+-- name: DeleteBeneficiary :exec
+DELETE
+FROM beneficiaries
+WHERE id = $1;
+
+-- name: DeleteOwnerBeneficiaries :exec
+DELETE
+FROM beneficiaries
+WHERE owner = $1;
\ No newline at end of file
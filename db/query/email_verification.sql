@@ -0,0 +1,20 @@
+-- name: CreateEmailVerification :one
+INSERT INTO email_verifications (username,
+                                 email,
+                                 token_hash,
+                                 expired_at)
+VALUES ($1, $2, $3, $4) RETURNING *;
+
+-- name: UseEmailVerification :one
+UPDATE email_verifications
+SET is_used    = true,
+    updated_at = now()
+WHERE id = $1
+  AND token_hash = $2
+  AND is_used = false
+  AND expired_at > now() RETURNING *;
+
+-- name: DeleteEmailVerifications :exec
+DELETE
+FROM email_verifications
+WHERE username = $1;
\ No newline at end of file
@@ -1,7 +1,8 @@
 -- name: CreateEntry :one
 INSERT INTO entries (account_id,
-                     amount)
-VALUES ($1, $2) RETURNING *;
+                     amount,
+                     transfer_id)
+VALUES ($1, $2, $3) RETURNING *;
 
 -- name: GetEntry :one
 SELECT *
@@ -13,4 +14,42 @@ SELECT *
 FROM entries
 WHERE account_id = $1
 ORDER BY id LIMIT $2
-OFFSET $3;
\ No newline at end of file
+OFFSET $3;
+
+-- name: ListOwnerEntries :many
+SELECT *
+FROM entries
+WHERE account_id IN (SELECT id FROM accounts WHERE owner = $1)
+ORDER BY id;
+
+-- name: SumAccountEntriesSince :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS total
+FROM entries
+WHERE account_id = sqlc.arg(account_id)
+  AND created_at >= sqlc.arg(since);
+
+-- name: SumAccountEntriesBetween :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS total
+FROM entries
+WHERE account_id = sqlc.arg(account_id)
+  AND created_at >= sqlc.arg(from_time)
+  AND created_at < sqlc.arg(to_time);
+
+-- name: ListAccountStatementEntries :many
+SELECT e.id,
+       e.amount,
+       e.created_at,
+       e.transfer_id,
+       t.description,
+       t.client_reference,
+       c.number AS counterparty_number,
+       c.owner  AS counterparty_owner
+FROM entries e
+         LEFT JOIN transfers t ON t.id = e.transfer_id
+         LEFT JOIN accounts c ON c.id = CASE
+                                            WHEN t.from_account_id = e.account_id THEN t.to_account_id
+                                            ELSE t.from_account_id END
+WHERE e.account_id = sqlc.arg(account_id)
+  AND e.created_at >= sqlc.arg(from_time)
+  AND e.created_at < sqlc.arg(to_time)
+ORDER BY e.created_at, e.id;
\ No newline at end of file
@@ -0,0 +1,51 @@
+-- name: CreateHold :one
+INSERT INTO holds (from_account_id,
+                   to_account_id,
+                   amount,
+                   currency,
+                   expires_at)
+VALUES ($1, $2, $3, $4, $5) RETURNING *;
+
+-- name: GetHold :one
+SELECT *
+FROM holds
+WHERE id = $1 LIMIT 1;
+
+-- name: GetHoldForUpdate :one
+SELECT *
+FROM holds
+WHERE id = $1 LIMIT 1 FOR NO KEY
+UPDATE;
+
+-- name: GetNextExpiredHoldForUpdate :one
+SELECT *
+FROM holds
+WHERE status = 'active'
+  AND expires_at <= now()
+ORDER BY expires_at, id LIMIT 1 FOR NO KEY
+UPDATE SKIP LOCKED;
+
+-- name: ListHolds :many
+SELECT *
+FROM holds
+WHERE from_account_id IN (SELECT id FROM accounts WHERE owner = $1)
+   OR to_account_id IN (SELECT id FROM accounts WHERE owner = $1)
+ORDER BY id LIMIT $2
+OFFSET $3;
+
+-- name: UpdateHoldStatus :one
+UPDATE holds
+SET status          = $2,
+    captured_amount = $3,
+    transfer_id     = $4,
+    updated_at      = now()
+WHERE id = $1 RETURNING *;
+
+
+-- name: ListAccountActiveHoldsForUpdate :many
+SELECT *
+FROM holds
+WHERE status = 'active'
+  AND (from_account_id = sqlc.arg(account_id) OR to_account_id = sqlc.arg(account_id))
+ORDER BY id FOR NO KEY
+UPDATE;
\ No newline at end of file
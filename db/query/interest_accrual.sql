@@ -0,0 +1,17 @@
+-- name: CreateInterestAccrual :one
+INSERT INTO interest_accruals (account_id,
+                               start_date,
+                               end_date,
+                               balance,
+                               annual_rate,
+                               day_count,
+                               amount,
+                               transfer_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING *;
+
+-- name: ListInterestAccruals :many
+SELECT *
+FROM interest_accruals
+WHERE account_id = $1
+ORDER BY id DESC LIMIT $2
+OFFSET $3;
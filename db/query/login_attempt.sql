@@ -0,0 +1,22 @@
+-- name: RecordLoginFailure :one
+INSERT INTO login_attempts (attempt_key,
+                            failures,
+                            last_failed_at)
+VALUES (sqlc.arg(attempt_key), 1, now())
+ON CONFLICT (attempt_key) DO UPDATE
+    SET failures       = CASE
+                             WHEN login_attempts.last_failed_at < sqlc.arg(window_start) THEN 1
+                             ELSE login_attempts.failures + 1
+        END,
+        last_failed_at = now(),
+        updated_at     = now() RETURNING *;
+
+-- name: GetLoginAttempt :one
+SELECT *
+FROM login_attempts
+WHERE attempt_key = $1 LIMIT 1;
+
+-- name: DeleteLoginAttempt :exec
+DELETE
+FROM login_attempts
+WHERE attempt_key = $1;
\ No newline at end of file
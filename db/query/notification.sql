@@ -0,0 +1,12 @@
+-- name: CreateNotification :one
+INSERT INTO notifications (username,
+                           message)
+VALUES ($1, $2) RETURNING *;
+
+-- name: ListNotifications :many
+SELECT *
+FROM notifications
+WHERE username = $1
+  AND mark_for_delete = false
+ORDER BY id DESC LIMIT $2
+OFFSET $3;
@@ -0,0 +1,22 @@
+-- name: CreateOAuthAuthorizationCode :one
+INSERT INTO oauth_authorization_codes (code_hash,
+                                       client_id,
+                                       username,
+                                       redirect_uri,
+                                       scopes,
+                                       code_challenge,
+                                       expired_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING *;
+
+-- name: GetOAuthAuthorizationCodeByHash :one
+SELECT *
+FROM oauth_authorization_codes
+WHERE code_hash = $1 LIMIT 1;
+
+-- name: UseOAuthAuthorizationCode :one
+UPDATE oauth_authorization_codes
+SET used_at    = now(),
+    updated_at = now()
+WHERE id = $1
+  AND used_at IS NULL
+  AND expired_at > now() RETURNING *;
\ No newline at end of file
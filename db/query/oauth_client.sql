@@ -0,0 +1,13 @@
+-- name: CreateOAuthClient :one
+INSERT INTO oauth_clients (client_id,
+                           secret_hash,
+                           name,
+                           redirect_uris,
+                           scopes,
+                           created_by)
+VALUES ($1, $2, $3, $4, $5, $6) RETURNING *;
+
+-- name: GetOAuthClient :one
+SELECT *
+FROM oauth_clients
+WHERE client_id = $1 LIMIT 1;
\ No newline at end of file
@@ -0,0 +1,40 @@
+-- name: UpsertOAuthConsent :one
+INSERT INTO oauth_consents (username,
+                            client_id,
+                            scopes)
+VALUES ($1, $2, $3) ON CONFLICT (username, client_id) DO
+UPDATE
+SET scopes     = EXCLUDED.scopes,
+    granted_at = CASE
+                     WHEN oauth_consents.is_revoked OR oauth_consents.scopes <> EXCLUDED.scopes THEN now()
+                     ELSE oauth_consents.granted_at END,
+    is_revoked = false,
+    updated_at = now() RETURNING *;
+
+-- name: GetOAuthConsent :one
+SELECT *
+FROM oauth_consents
+WHERE username = $1
+  AND client_id = $2 LIMIT 1;
+
+-- name: ListOAuthConsents :many
+SELECT *
+FROM oauth_consents
+WHERE username = $1
+  AND is_revoked = false
+ORDER BY id;
+
+-- name: RevokeOAuthConsent :one
+UPDATE oauth_consents
+SET is_revoked = true,
+    updated_at = now()
+WHERE username = $1
+  AND client_id = $2
+  AND is_revoked = false RETURNING *;
+
+-- name: RevokeUserOAuthConsents :exec
+UPDATE oauth_consents
+SET is_revoked = true,
+    updated_at = now()
+WHERE username = $1
+  AND is_revoked = false;
\ No newline at end of file
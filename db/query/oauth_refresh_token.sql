@@ -0,0 +1,45 @@
+-- name: CreateOAuthRefreshToken :one
+INSERT INTO oauth_refresh_tokens (token_hash,
+                                  client_id,
+                                  username,
+                                  scopes,
+                                  expired_at)
+VALUES ($1, $2, $3, $4, $5) RETURNING *;
+
+-- name: GetOAuthRefreshTokenByHash :one
+SELECT *
+FROM oauth_refresh_tokens
+WHERE token_hash = $1 LIMIT 1;
+
+-- name: RotateOAuthRefreshToken :one
+UPDATE oauth_refresh_tokens
+SET rotated_at = now(),
+    updated_at = now()
+WHERE id = $1
+  AND rotated_at IS NULL
+  AND is_revoked = false
+  AND expired_at > now() RETURNING *;
+
+-- name: RevokeOAuthRefreshTokens :exec
+UPDATE oauth_refresh_tokens
+SET is_revoked = true,
+    updated_at = now()
+WHERE username = $1
+  AND client_id = $2
+  AND is_revoked = false;
+
+-- name: RevokeUserOAuthRefreshTokens :exec
+UPDATE oauth_refresh_tokens
+SET is_revoked = true,
+    updated_at = now()
+WHERE username = $1
+  AND is_revoked = false;
+
+-- name: RevokeOAuthRefreshTokensIssuedBefore :exec
+UPDATE oauth_refresh_tokens
+SET is_revoked = true,
+    updated_at = now()
+WHERE username = sqlc.arg(username)
+  AND client_id = sqlc.arg(client_id)
+  AND created_at < sqlc.arg(issued_before)
+  AND is_revoked = false;
\ No newline at end of file
@@ -0,0 +1,26 @@
+-- name: CreatePasswordReset :one
+INSERT INTO password_resets (username,
+                             token_hash,
+                             expired_at)
+VALUES ($1, $2, $3) RETURNING *;
+
+-- name: GetPasswordReset :one
+SELECT *
+FROM password_resets
+WHERE id = $1 LIMIT 1;
+
+-- name: UsePasswordReset :one
+UPDATE password_resets
+SET is_used    = true,
+    updated_at = now()
+WHERE id = $1
+  AND token_hash = $2
+  AND is_used = false
+  AND expired_at > now() RETURNING *;
+
+-- name: InvalidatePasswordResets :exec
+UPDATE password_resets
+SET is_used    = true,
+    updated_at = now()
+WHERE username = $1
+  AND is_used = false;
\ No newline at end of file
@@ -0,0 +1,42 @@
+-- name: CreatePendingTransfer :one
+INSERT INTO pending_transfers (from_account_id,
+                               to_account_id,
+                               amount,
+                               currency,
+                               requested_by,
+                               description,
+                               client_reference,
+                               metadata)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING *;
+
+-- name: GetPendingTransfer :one
+SELECT *
+FROM pending_transfers
+WHERE id = $1 LIMIT 1;
+
+-- name: GetPendingTransferForUpdate :one
+SELECT *
+FROM pending_transfers
+WHERE id = $1 LIMIT 1 FOR NO KEY
+UPDATE;
+
+-- name: ListPendingTransfers :many
+SELECT *
+FROM pending_transfers
+WHERE status = $1
+ORDER BY id LIMIT $2
+OFFSET $3;
+
+-- name: ListPendingTransfersByRequester :many
+SELECT *
+FROM pending_transfers
+WHERE requested_by = $1
+ORDER BY id LIMIT $2
+OFFSET $3;
+
+-- name: UpdatePendingTransferStatus :one
+UPDATE pending_transfers
+SET status      = $2,
+    transfer_id = $3,
+    updated_at  = now()
+WHERE id = $1 RETURNING *;
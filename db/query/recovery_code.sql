@@ -0,0 +1,17 @@
+-- name: CreateRecoveryCode :one
+INSERT INTO recovery_codes (username,
+                            code_hash)
+VALUES ($1, $2) RETURNING *;
+
+-- name: UseRecoveryCode :one
+UPDATE recovery_codes
+SET is_used    = true,
+    updated_at = now()
+WHERE username = $1
+  AND code_hash = $2
+  AND is_used = false RETURNING *;
+
+-- name: DeleteRecoveryCodes :exec
+DELETE
+FROM recovery_codes
+WHERE username = $1;
\ No newline at end of file
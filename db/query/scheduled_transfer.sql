@@ -0,0 +1,58 @@
+-- name: CreateScheduledTransfer :one
+INSERT INTO scheduled_transfers (from_account_id,
+                                 to_account_id,
+                                 amount,
+                                 currency,
+                                 execute_at)
+VALUES ($1, $2, $3, $4, $5) RETURNING *;
+
+-- name: GetScheduledTransfer :one
+SELECT *
+FROM scheduled_transfers
+WHERE id = $1 LIMIT 1;
+
+-- name: ListScheduledTransfers :many
+SELECT *
+FROM scheduled_transfers
+WHERE from_account_id IN (SELECT id FROM accounts WHERE owner = $1)
+ORDER BY execute_at, id LIMIT $2
+OFFSET $3;
+
+-- name: GetNextDueScheduledTransferForUpdate :one
+SELECT *
+FROM scheduled_transfers
+WHERE status = 'pending'
+  AND execute_at <= now()
+ORDER BY execute_at, id LIMIT 1 FOR UPDATE SKIP LOCKED;
+
+-- name: MarkScheduledTransferExecuted :one
+UPDATE scheduled_transfers
+SET status      = 'executed',
+    transfer_id = $2,
+    executed_at = now(),
+    updated_at  = now()
+WHERE id = $1 RETURNING *;
+
+-- name: MarkScheduledTransferFailed :one
+UPDATE scheduled_transfers
+SET status         = 'failed',
+    failure_reason = $2,
+    executed_at    = now(),
+    updated_at     = now()
+WHERE id = $1
+  AND status = 'pending' RETURNING *;
+
+-- name: CancelScheduledTransfer :one
+UPDATE scheduled_transfers
+SET status     = 'cancelled',
+    updated_at = now()
+WHERE id = $1
+  AND status = 'pending' RETURNING *;
+
+
+-- name: CancelAccountScheduledTransfers :exec
+UPDATE scheduled_transfers
+SET status     = 'cancelled',
+    updated_at = now()
+WHERE (from_account_id = sqlc.arg(account_id) OR to_account_id = sqlc.arg(account_id))
+  AND status = 'pending';
\ No newline at end of file
@@ -0,0 +1,84 @@
+-- name: CreateStandingOrder :one
+INSERT INTO standing_orders (from_account_id,
+                             to_account_id,
+                             amount,
+                             currency,
+                             frequency,
+                             day_of_month,
+                             start_at,
+                             end_at,
+                             max_executions,
+                             next_execution_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING *;
+
+-- name: GetStandingOrder :one
+SELECT *
+FROM standing_orders
+WHERE id = $1
+  AND mark_for_delete = false LIMIT 1;
+
+-- name: ListStandingOrders :many
+SELECT *
+FROM standing_orders
+WHERE from_account_id IN (SELECT id FROM accounts WHERE owner = $1)
+  AND mark_for_delete = false
+ORDER BY id LIMIT $2
+OFFSET $3;
+
+-- name: GetNextDueStandingOrderForUpdate :one
+SELECT *
+FROM standing_orders
+WHERE status = 'active'
+  AND mark_for_delete = false
+  AND next_execution_at <= now()
+ORDER BY next_execution_at, id LIMIT 1 FOR UPDATE SKIP LOCKED;
+
+-- name: RetryStandingOrder :one
+UPDATE standing_orders
+SET retry_count         = retry_count + 1,
+    next_execution_at   = $2,
+    last_failure_reason = $3,
+    updated_at          = now()
+WHERE id = $1 RETURNING *;
+
+-- name: AdvanceStandingOrder :one
+UPDATE standing_orders
+SET next_execution_at   = $2,
+    status              = $3,
+    executed_count      = $4,
+    last_executed_at    = $5,
+    last_failure_reason = $6,
+    retry_count         = 0,
+    updated_at          = now()
+WHERE id = $1 RETURNING *;
+
+-- name: PauseStandingOrder :one
+UPDATE standing_orders
+SET status     = 'paused',
+    updated_at = now()
+WHERE id = $1
+  AND status = 'active'
+  AND mark_for_delete = false RETURNING *;
+
+-- name: ResumeStandingOrder :one
+UPDATE standing_orders
+SET status            = 'active',
+    next_execution_at = $2,
+    updated_at        = now()
+WHERE id = $1
+  AND status = 'paused'
+  AND mark_for_delete = false RETURNING *;
+
+-- name: DeleteStandingOrder :exec
+UPDATE standing_orders
+SET mark_for_delete = true,
+    updated_at      = now()
+WHERE id = $1;
+
+
+-- name: DeleteAccountStandingOrders :exec
+UPDATE standing_orders
+SET mark_for_delete = true,
+    updated_at      = now()
+WHERE (from_account_id = sqlc.arg(account_id) OR to_account_id = sqlc.arg(account_id))
+  AND mark_for_delete = false;
\ No newline at end of file
@@ -1,8 +1,11 @@
 -- name: CreateTransfer :one
 INSERT INTO transfers (from_account_id,
                        to_account_id,
-                       amount)
-VALUES ($1, $2, $3) RETURNING *;
+                       amount,
+                       description,
+                       client_reference,
+                       metadata)
+VALUES ($1, $2, $3, $4, $5, $6) RETURNING *;
 
 -- name: GetTransfer :one
 SELECT *
@@ -15,4 +18,20 @@ FROM transfers
 WHERE from_account_id = $1
    OR to_account_id = $2
 ORDER BY id LIMIT $3
-OFFSET $4;
\ No newline at end of file
+OFFSET $4;
+
+-- name: ListOwnerTransfers :many
+SELECT *
+FROM transfers
+WHERE from_account_id IN (SELECT id FROM accounts WHERE owner = $1)
+   OR to_account_id IN (SELECT id FROM accounts WHERE owner = $1)
+ORDER BY id;
+
+-- name: ListAccountTransfers :many
+SELECT *
+FROM transfers
+WHERE (from_account_id = sqlc.arg(account_id) OR to_account_id = sqlc.arg(account_id))
+  AND (sqlc.narg(client_reference)::varchar IS NULL OR client_reference = sqlc.narg(client_reference))
+  AND (sqlc.narg(metadata_key)::varchar IS NULL OR metadata ->> sqlc.narg(metadata_key) = sqlc.narg(metadata_value))
+ORDER BY id DESC LIMIT sqlc.arg(page_limit)
+OFFSET sqlc.arg(page_offset);
@@ -0,0 +1,12 @@
+-- name: CreateTransferApproval :one
+INSERT INTO transfer_approvals (pending_transfer_id,
+                                approver,
+                                decision,
+                                comment)
+VALUES ($1, $2, $3, $4) RETURNING *;
+
+-- name: ListTransferApprovals :many
+SELECT *
+FROM transfer_approvals
+WHERE pending_transfer_id = $1
+ORDER BY id;
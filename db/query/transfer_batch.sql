@@ -0,0 +1,38 @@
+-- name: CreateTransferBatch :one
+INSERT INTO transfer_batches (from_account_id,
+                              currency,
+                              mode,
+                              item_count,
+                              total_amount)
+VALUES ($1, $2, $3, $4, $5) RETURNING *;
+
+-- name: GetTransferBatch :one
+SELECT *
+FROM transfer_batches
+WHERE id = $1 LIMIT 1;
+
+-- name: CompleteTransferBatch :one
+UPDATE transfer_batches
+SET status         = $2,
+    executed_count = $3,
+    failed_count   = $4,
+    updated_at     = now()
+WHERE id = $1 RETURNING *;
+
+-- name: CreateTransferBatchItem :one
+INSERT INTO transfer_batch_items (batch_id,
+                                  line_number,
+                                  to_account_id,
+                                  amount,
+                                  description,
+                                  client_reference,
+                                  status,
+                                  failure_reason,
+                                  transfer_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING *;
+
+-- name: ListTransferBatchItems :many
+SELECT *
+FROM transfer_batch_items
+WHERE batch_id = $1
+ORDER BY line_number;
@@ -8,4 +8,87 @@ VALUES ($1, $2, $3, $4) RETURNING *;
 -- name: GetUser :one
 SELECT *
 FROM users
-WHERE username = $1 LIMIT 1;
\ No newline at end of file
+WHERE username = $1 LIMIT 1;
+
+-- name: GetUserByEmail :one
+SELECT *
+FROM users
+WHERE email = $1 LIMIT 1;
+
+-- name: GetUserAuthState :one
+SELECT tokens_valid_after, is_email_verified
+FROM users
+WHERE username = $1 LIMIT 1;
+
+-- name: UpdateUser :one
+UPDATE users
+SET full_name         = COALESCE(sqlc.narg(full_name), full_name),
+    email             = COALESCE(sqlc.narg(email), email),
+    -- a new email address has to be verified again
+    is_email_verified = is_email_verified AND (sqlc.narg(email)::varchar IS NULL OR sqlc.narg(email) = email),
+    updated_at        = now()
+WHERE username = sqlc.arg(username) RETURNING *;
+
+-- name: DeleteUser :one
+UPDATE users
+SET full_name          = '',
+    email              = 'deleted-' || gen_random_uuid() || '@deleted.invalid',
+    hashed_password    = '',
+    is_email_verified  = false,
+    totp_secret        = NULL,
+    is_totp_enabled    = false,
+    tokens_valid_after = now(),
+    mark_for_delete    = true,
+    updated_at         = now()
+WHERE username = $1
+  AND mark_for_delete = false RETURNING *;
+
+-- name: UpdateUserPassword :one
+UPDATE users
+SET hashed_password     = sqlc.arg(hashed_password),
+    password_updated_at = now(),
+    tokens_valid_after  = now(),
+    updated_at          = now()
+WHERE username = sqlc.arg(username) RETURNING *;
+
+-- name: RehashUserPassword :exec
+UPDATE users
+SET hashed_password = sqlc.arg(new_hashed_password)
+WHERE username = sqlc.arg(username)
+  AND hashed_password = sqlc.arg(hashed_password);
+
+-- name: VerifyUserEmail :one
+UPDATE users
+SET is_email_verified = true,
+    updated_at        = now()
+WHERE username = $1
+  AND email = $2 RETURNING *;
+
+-- name: SetUserTOTPSecret :one
+UPDATE users
+SET totp_secret = $2,
+    updated_at  = now()
+WHERE username = $1
+  AND is_totp_enabled = false RETURNING *;
+
+-- name: EnableUserTOTP :one
+UPDATE users
+SET is_totp_enabled     = true,
+    totp_last_used_step = $2,
+    updated_at          = now()
+WHERE username = $1
+  AND totp_secret IS NOT NULL RETURNING *;
+
+-- name: DisableUserTOTP :one
+UPDATE users
+SET totp_secret         = NULL,
+    is_totp_enabled     = false,
+    totp_last_used_step = 0,
+    updated_at          = now()
+WHERE username = $1 RETURNING *;
+
+-- name: UseUserTOTPStep :execrows
+UPDATE users
+SET totp_last_used_step = $2
+WHERE username = $1
+  AND totp_last_used_step < $2;
\ No newline at end of file
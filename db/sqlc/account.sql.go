@@ -7,12 +7,49 @@ package db
 
 import (
 	"context"
+	"time"
+
+	"github.com/lib/pq"
 )
 
+const addAccountAvailableBalance = `-- name: AddAccountAvailableBalance :one
+UPDATE accounts
+SET available_balance = available_balance + $1
+WHERE id = $2 RETURNING id, owner, balance, currency, created_by, created_at, updated_by, updated_at, mark_for_delete, available_balance, number, type, accrued_interest, interest_accrued_until
+`
+
+type AddAccountAvailableBalanceParams struct {
+	Amount int64 `json:"amount"`
+	ID     int64 `json:"id"`
+}
+
+func (q *Queries) AddAccountAvailableBalance(ctx context.Context, arg AddAccountAvailableBalanceParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, addAccountAvailableBalance, arg.Amount, arg.ID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedBy,
+		&i.UpdatedAt,
+		&i.MarkForDelete,
+		&i.AvailableBalance,
+		&i.Number,
+		&i.Type,
+		&i.AccruedInterest,
+		&i.InterestAccruedUntil,
+	)
+	return i, err
+}
+
 const addAccountBalance = `-- name: AddAccountBalance :one
 UPDATE accounts
-SET balance = balance + $1
-WHERE id = $2 RETURNING id, owner, balance, currency, created_by, created_at, updated_by, updated_at, mark_for_delete
+SET balance           = balance + $1,
+    available_balance = available_balance + $1
+WHERE id = $2 RETURNING id, owner, balance, currency, created_by, created_at, updated_by, updated_at, mark_for_delete, available_balance, number, type, accrued_interest, interest_accrued_until
 `
 
 type AddAccountBalanceParams struct {
@@ -33,25 +70,94 @@ func (q *Queries) AddAccountBalance(ctx context.Context, arg AddAccountBalancePa
 		&i.UpdatedBy,
 		&i.UpdatedAt,
 		&i.MarkForDelete,
+		&i.AvailableBalance,
+		&i.Number,
+		&i.Type,
+		&i.AccruedInterest,
+		&i.InterestAccruedUntil,
 	)
 	return i, err
 }
 
+const closeAccount = `-- name: CloseAccount :one
+UPDATE accounts
+SET mark_for_delete  = true,
+    accrued_interest = 0,
+    updated_at       = now()
+WHERE id = $1
+  AND balance = 0
+  AND available_balance = 0
+  AND accrued_interest < $2
+  AND mark_for_delete = false RETURNING id, owner, balance, currency, created_by, created_at, updated_by, updated_at, mark_for_delete, available_balance, number, type, accrued_interest, interest_accrued_until
+`
+
+type CloseAccountParams struct {
+	ID            int64 `json:"id"`
+	InterestScale int64 `json:"interest_scale"`
+}
+
+func (q *Queries) CloseAccount(ctx context.Context, arg CloseAccountParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, closeAccount, arg.ID, arg.InterestScale)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedBy,
+		&i.UpdatedAt,
+		&i.MarkForDelete,
+		&i.AvailableBalance,
+		&i.Number,
+		&i.Type,
+		&i.AccruedInterest,
+		&i.InterestAccruedUntil,
+	)
+	return i, err
+}
+
+const countOpenAccounts = `-- name: CountOpenAccounts :one
+SELECT COUNT(*)
+FROM accounts
+WHERE owner = $1
+  AND mark_for_delete = false
+`
+
+func (q *Queries) CountOpenAccounts(ctx context.Context, owner string) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countOpenAccounts, owner)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createAccount = `-- name: CreateAccount :one
 INSERT INTO accounts (owner,
                       balance,
-                      currency)
-VALUES ($1, $2, $3) RETURNING id, owner, balance, currency, created_by, created_at, updated_by, updated_at, mark_for_delete
+                      available_balance,
+                      currency,
+                      number,
+                      type)
+VALUES ($1, $2, $2, $3, $4, $5) RETURNING id, owner, balance, currency, created_by, created_at, updated_by, updated_at, mark_for_delete, available_balance, number, type, accrued_interest, interest_accrued_until
 `
 
 type CreateAccountParams struct {
 	Owner    string `json:"owner"`
 	Balance  int64  `json:"balance"`
 	Currency string `json:"currency"`
+	Number   string `json:"number"`
+	Type     string `json:"type"`
 }
 
 func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error) {
-	row := q.db.QueryRowContext(ctx, createAccount, arg.Owner, arg.Balance, arg.Currency)
+	row := q.db.QueryRowContext(ctx, createAccount,
+		arg.Owner,
+		arg.Balance,
+		arg.Currency,
+		arg.Number,
+		arg.Type,
+	)
 	var i Account
 	err := row.Scan(
 		&i.ID,
@@ -63,6 +169,11 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (A
 		&i.UpdatedBy,
 		&i.UpdatedAt,
 		&i.MarkForDelete,
+		&i.AvailableBalance,
+		&i.Number,
+		&i.Type,
+		&i.AccruedInterest,
+		&i.InterestAccruedUntil,
 	)
 	return i, err
 }
@@ -79,7 +190,7 @@ func (q *Queries) DeleteAccount(ctx context.Context, id int64) error {
 }
 
 const getAccount = `-- name: GetAccount :one
-SELECT id, owner, balance, currency, created_by, created_at, updated_by, updated_at, mark_for_delete
+SELECT id, owner, balance, currency, created_by, created_at, updated_by, updated_at, mark_for_delete, available_balance, number, type, accrued_interest, interest_accrued_until
 FROM accounts
 WHERE id = $1 LIMIT 1
 `
@@ -97,12 +208,81 @@ func (q *Queries) GetAccount(ctx context.Context, id int64) (Account, error) {
 		&i.UpdatedBy,
 		&i.UpdatedAt,
 		&i.MarkForDelete,
+		&i.AvailableBalance,
+		&i.Number,
+		&i.Type,
+		&i.AccruedInterest,
+		&i.InterestAccruedUntil,
+	)
+	return i, err
+}
+
+const getAccountByNumber = `-- name: GetAccountByNumber :one
+SELECT id, owner, balance, currency, created_by, created_at, updated_by, updated_at, mark_for_delete, available_balance, number, type, accrued_interest, interest_accrued_until
+FROM accounts
+WHERE number = $1 LIMIT 1
+`
+
+func (q *Queries) GetAccountByNumber(ctx context.Context, number string) (Account, error) {
+	row := q.db.QueryRowContext(ctx, getAccountByNumber, number)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedBy,
+		&i.UpdatedAt,
+		&i.MarkForDelete,
+		&i.AvailableBalance,
+		&i.Number,
+		&i.Type,
+		&i.AccruedInterest,
+		&i.InterestAccruedUntil,
+	)
+	return i, err
+}
+
+const getAccountByOwner = `-- name: GetAccountByOwner :one
+SELECT id, owner, balance, currency, created_by, created_at, updated_by, updated_at, mark_for_delete, available_balance, number, type, accrued_interest, interest_accrued_until
+FROM accounts
+WHERE owner = $1
+  AND currency = $2
+  AND type = $3 LIMIT 1
+`
+
+type GetAccountByOwnerParams struct {
+	Owner    string `json:"owner"`
+	Currency string `json:"currency"`
+	Type     string `json:"type"`
+}
+
+func (q *Queries) GetAccountByOwner(ctx context.Context, arg GetAccountByOwnerParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, getAccountByOwner, arg.Owner, arg.Currency, arg.Type)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedBy,
+		&i.UpdatedAt,
+		&i.MarkForDelete,
+		&i.AvailableBalance,
+		&i.Number,
+		&i.Type,
+		&i.AccruedInterest,
+		&i.InterestAccruedUntil,
 	)
 	return i, err
 }
 
 const getAccountForUpdate = `-- name: GetAccountForUpdate :one
-SELECT id, owner, balance, currency, created_by, created_at, updated_by, updated_at, mark_for_delete
+SELECT id, owner, balance, currency, created_by, created_at, updated_by, updated_at, mark_for_delete, available_balance, number, type, accrued_interest, interest_accrued_until
 FROM accounts
 WHERE id = $1 LIMIT 1 FOR NO KEY
 UPDATE
@@ -121,12 +301,49 @@ func (q *Queries) GetAccountForUpdate(ctx context.Context, id int64) (Account, e
 		&i.UpdatedBy,
 		&i.UpdatedAt,
 		&i.MarkForDelete,
+		&i.AvailableBalance,
+		&i.Number,
+		&i.Type,
+		&i.AccruedInterest,
+		&i.InterestAccruedUntil,
+	)
+	return i, err
+}
+
+const getNextInterestAccountForUpdate = `-- name: GetNextInterestAccountForUpdate :one
+SELECT id, owner, balance, currency, created_by, created_at, updated_by, updated_at, mark_for_delete, available_balance, number, type, accrued_interest, interest_accrued_until
+FROM accounts
+WHERE type = 'savings'
+  AND mark_for_delete = false
+  AND interest_accrued_until < $1
+ORDER BY id LIMIT 1 FOR NO KEY
+UPDATE SKIP LOCKED
+`
+
+func (q *Queries) GetNextInterestAccountForUpdate(ctx context.Context, today time.Time) (Account, error) {
+	row := q.db.QueryRowContext(ctx, getNextInterestAccountForUpdate, today)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedBy,
+		&i.UpdatedAt,
+		&i.MarkForDelete,
+		&i.AvailableBalance,
+		&i.Number,
+		&i.Type,
+		&i.AccruedInterest,
+		&i.InterestAccruedUntil,
 	)
 	return i, err
 }
 
 const listAccounts = `-- name: ListAccounts :many
-SELECT id, owner, balance, currency, created_by, created_at, updated_by, updated_at, mark_for_delete
+SELECT id, owner, balance, currency, created_by, created_at, updated_by, updated_at, mark_for_delete, available_balance, number, type, accrued_interest, interest_accrued_until
 FROM accounts
 WHERE owner = $1
 ORDER BY id LIMIT $2
@@ -158,6 +375,146 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 			&i.UpdatedBy,
 			&i.UpdatedAt,
 			&i.MarkForDelete,
+			&i.AvailableBalance,
+			&i.Number,
+			&i.Type,
+			&i.AccruedInterest,
+			&i.InterestAccruedUntil,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccountsByIDs = `-- name: ListAccountsByIDs :many
+SELECT id, owner, balance, currency, created_by, created_at, updated_by, updated_at, mark_for_delete, available_balance, number, type, accrued_interest, interest_accrued_until
+FROM accounts
+WHERE id = ANY ($1::bigint[])
+ORDER BY id
+`
+
+func (q *Queries) ListAccountsByIDs(ctx context.Context, ids []int64) ([]Account, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountsByIDs, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Account{}
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Balance,
+			&i.Currency,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedBy,
+			&i.UpdatedAt,
+			&i.MarkForDelete,
+			&i.AvailableBalance,
+			&i.Number,
+			&i.Type,
+			&i.AccruedInterest,
+			&i.InterestAccruedUntil,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccountsByNumbers = `-- name: ListAccountsByNumbers :many
+SELECT id, owner, balance, currency, created_by, created_at, updated_by, updated_at, mark_for_delete, available_balance, number, type, accrued_interest, interest_accrued_until
+FROM accounts
+WHERE number = ANY ($1::varchar[])
+ORDER BY id
+`
+
+func (q *Queries) ListAccountsByNumbers(ctx context.Context, numbers []string) ([]Account, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountsByNumbers, pq.Array(numbers))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Account{}
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Balance,
+			&i.Currency,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedBy,
+			&i.UpdatedAt,
+			&i.MarkForDelete,
+			&i.AvailableBalance,
+			&i.Number,
+			&i.Type,
+			&i.AccruedInterest,
+			&i.InterestAccruedUntil,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOwnerAccounts = `-- name: ListOwnerAccounts :many
+SELECT id, owner, balance, currency, created_by, created_at, updated_by, updated_at, mark_for_delete, available_balance, number, type, accrued_interest, interest_accrued_until
+FROM accounts
+WHERE owner = $1
+ORDER BY id
+`
+
+func (q *Queries) ListOwnerAccounts(ctx context.Context, owner string) ([]Account, error) {
+	rows, err := q.db.QueryContext(ctx, listOwnerAccounts, owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Account{}
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Balance,
+			&i.Currency,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedBy,
+			&i.UpdatedAt,
+			&i.MarkForDelete,
+			&i.AvailableBalance,
+			&i.Number,
+			&i.Type,
+			&i.AccruedInterest,
+			&i.InterestAccruedUntil,
 		); err != nil {
 			return nil, err
 		}
@@ -174,8 +531,9 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 
 const updateAccount = `-- name: UpdateAccount :one
 UPDATE accounts
-SET balance = $2
-WHERE id = $1 RETURNING id, owner, balance, currency, created_by, created_at, updated_by, updated_at, mark_for_delete
+SET balance           = $2,
+    available_balance = available_balance + ($2 - balance)
+WHERE id = $1 RETURNING id, owner, balance, currency, created_by, created_at, updated_by, updated_at, mark_for_delete, available_balance, number, type, accrued_interest, interest_accrued_until
 `
 
 type UpdateAccountParams struct {
@@ -196,6 +554,47 @@ func (q *Queries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (A
 		&i.UpdatedBy,
 		&i.UpdatedAt,
 		&i.MarkForDelete,
+		&i.AvailableBalance,
+		&i.Number,
+		&i.Type,
+		&i.AccruedInterest,
+		&i.InterestAccruedUntil,
+	)
+	return i, err
+}
+
+const updateAccountInterest = `-- name: UpdateAccountInterest :one
+UPDATE accounts
+SET accrued_interest       = $2,
+    interest_accrued_until = $3,
+    updated_at             = now()
+WHERE id = $1 RETURNING id, owner, balance, currency, created_by, created_at, updated_by, updated_at, mark_for_delete, available_balance, number, type, accrued_interest, interest_accrued_until
+`
+
+type UpdateAccountInterestParams struct {
+	ID                   int64     `json:"id"`
+	AccruedInterest      int64     `json:"accrued_interest"`
+	InterestAccruedUntil time.Time `json:"interest_accrued_until"`
+}
+
+func (q *Queries) UpdateAccountInterest(ctx context.Context, arg UpdateAccountInterestParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, updateAccountInterest, arg.ID, arg.AccruedInterest, arg.InterestAccruedUntil)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedBy,
+		&i.UpdatedAt,
+		&i.MarkForDelete,
+		&i.AvailableBalance,
+		&i.Number,
+		&i.Type,
+		&i.AccruedInterest,
+		&i.InterestAccruedUntil,
 	)
 	return i, err
 }
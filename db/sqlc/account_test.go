@@ -11,12 +11,18 @@ import (
 )
 
 func createRandomAccount(t *testing.T) Account {
+	return createRandomAccountWithCurrency(t, util.RandomCurrency())
+}
+
+func createRandomAccountWithCurrency(t *testing.T, currency string) Account {
 	user := createRandomUser(t)
 
 	arg := CreateAccountParams{
 		Owner:    user.Username,
 		Balance:  util.RandomMoney(),
-		Currency: util.RandomCurrency(),
+		Currency: currency,
+		Number:   util.NewAccountNumber(),
+		Type:     util.CHECKING_ACCOUNT_TYPE,
 	}
 
 	account, err := testQueries.CreateAccount(context.Background(), arg)
@@ -26,6 +32,8 @@ func createRandomAccount(t *testing.T) Account {
 	require.Equal(t, arg.Owner, account.Owner)
 	require.Equal(t, arg.Balance, account.Balance)
 	require.Equal(t, arg.Currency, account.Currency)
+	require.Equal(t, arg.Number, account.Number)
+	require.Equal(t, arg.Type, account.Type)
 
 	require.NotZero(t, account.ID)
 	require.NotZero(t, account.CreatedAt)
@@ -55,6 +63,45 @@ func TestGetAccount(t *testing.T) {
 	require.Equal(t, savedAccount.MarkForDelete, account.MarkForDelete)
 }
 
+func TestGetAccountByOwner(t *testing.T) {
+	savedAccount := createRandomAccount(t)
+	account, err := testQueries.GetAccountByOwner(context.Background(), GetAccountByOwnerParams{
+		Owner:    savedAccount.Owner,
+		Currency: savedAccount.Currency,
+		Type:     savedAccount.Type,
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, savedAccount.ID, account.ID)
+
+	_, err = testQueries.GetAccountByOwner(context.Background(), GetAccountByOwnerParams{
+		Owner:    util.RandomOwner(),
+		Currency: savedAccount.Currency,
+		Type:     savedAccount.Type,
+	})
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+}
+
+func TestGetAccountByNumber(t *testing.T) {
+	savedAccount := createRandomAccount(t)
+	account, err := testQueries.GetAccountByNumber(context.Background(), savedAccount.Number)
+
+	require.NoError(t, err)
+	require.Equal(t, savedAccount.ID, account.ID)
+	require.True(t, util.IsValidAccountNumber(account.Number))
+}
+
+func TestListAccountsByNumbers(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	accounts, err := testQueries.ListAccountsByNumbers(context.Background(), []string{account1.Number, account2.Number, util.NewAccountNumber()})
+	require.NoError(t, err)
+	require.Len(t, accounts, 2)
+	require.Equal(t, account1.ID, accounts[0].ID)
+	require.Equal(t, account2.ID, accounts[1].ID)
+}
+
 func TestUpdateAccount(t *testing.T) {
 	savedAccount := createRandomAccount(t)
 
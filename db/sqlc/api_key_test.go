@@ -0,0 +1,88 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/VL-037/go-bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomAPIKey(t *testing.T, username string) ApiKey {
+	key := "gbk_" + util.RandomString(32)
+	arg := CreateAPIKeyParams{
+		Username:  username,
+		Name:      util.RandomString(8),
+		KeyPrefix: key[:12],
+		KeyHash:   util.HashToken(key),
+		Scopes:    "read:accounts",
+		ExpiredAt: time.Now().Add(time.Hour),
+	}
+
+	apiKey, err := testQueries.CreateAPIKey(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, apiKey.ID)
+	require.Equal(t, arg.Username, apiKey.Username)
+	require.Equal(t, arg.Name, apiKey.Name)
+	require.Equal(t, arg.KeyPrefix, apiKey.KeyPrefix)
+	require.Equal(t, arg.KeyHash, apiKey.KeyHash)
+	require.Equal(t, arg.Scopes, apiKey.Scopes)
+	require.WithinDuration(t, arg.ExpiredAt, apiKey.ExpiredAt, time.Second)
+	require.False(t, apiKey.LastUsedAt.Valid)
+	require.False(t, apiKey.IsRevoked)
+
+	return apiKey
+}
+
+func TestCreateAPIKey(t *testing.T) {
+	createRandomAPIKey(t, createRandomUser(t).Username)
+}
+
+func TestGetAPIKeyByHash(t *testing.T) {
+	apiKey1 := createRandomAPIKey(t, createRandomUser(t).Username)
+
+	require.NoError(t, testQueries.UpdateAPIKeyLastUsed(context.Background(), apiKey1.ID))
+
+	apiKey2, err := testQueries.GetAPIKeyByHash(context.Background(), apiKey1.KeyHash)
+	require.NoError(t, err)
+	require.Equal(t, apiKey1.ID, apiKey2.ID)
+	require.True(t, apiKey2.LastUsedAt.Valid)
+}
+
+func TestRevokeAPIKey(t *testing.T) {
+	user := createRandomUser(t)
+	apiKey := createRandomAPIKey(t, user.Username)
+
+	// only the owner can revoke a key
+	_, err := testQueries.RevokeAPIKey(context.Background(), RevokeAPIKeyParams{
+		ID:       apiKey.ID,
+		Username: createRandomUser(t).Username,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	revoked, err := testQueries.RevokeAPIKey(context.Background(), RevokeAPIKeyParams{
+		ID:       apiKey.ID,
+		Username: user.Username,
+	})
+	require.NoError(t, err)
+	require.True(t, revoked.IsRevoked)
+}
+
+func TestRevokeUserAPIKeys(t *testing.T) {
+	user := createRandomUser(t)
+	for i := 0; i < 3; i++ {
+		createRandomAPIKey(t, user.Username)
+	}
+
+	require.NoError(t, testQueries.RevokeUserAPIKeys(context.Background(), user.Username))
+
+	apiKeys, err := testQueries.ListAPIKeys(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Len(t, apiKeys, 3)
+	for _, apiKey := range apiKeys {
+		require.Equal(t, user.Username, apiKey.Username)
+		require.True(t, apiKey.IsRevoked)
+	}
+}
@@ -0,0 +1,66 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateBalanceSnapshots(t *testing.T) {
+	account := createRandomAccount(t)
+	createRandomEntry(t, account)
+
+	// the snapshot date is far in the future so that only this test takes snapshots for it
+	snapshotDate := time.Date(2999, time.January, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, int(account.ID%1000))
+	arg := CreateBalanceSnapshotsParams{
+		SnapshotDate: snapshotDate,
+		EndTime:      snapshotDate.AddDate(0, 0, 1),
+	}
+
+	count, err := testQueries.CreateBalanceSnapshots(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, count)
+
+	snapshot, err := testQueries.GetLatestBalanceSnapshot(context.Background(), GetLatestBalanceSnapshotParams{
+		AccountID:  account.ID,
+		BeforeDate: arg.EndTime,
+	})
+	require.NoError(t, err)
+	require.Equal(t, account.ID, snapshot.AccountID)
+	require.Equal(t, account.Balance, snapshot.Balance)
+	require.True(t, snapshotDate.Equal(snapshot.SnapshotDate))
+
+	// snapshots already taken are kept
+	count, err = testQueries.CreateBalanceSnapshots(context.Background(), arg)
+	require.NoError(t, err)
+	require.Zero(t, count)
+}
+
+func TestCreateBalanceSnapshotsTakesLaterEntriesOff(t *testing.T) {
+	account := createRandomAccount(t)
+	entry := createRandomEntry(t, account)
+
+	_, err := testQueries.AddAccountBalance(context.Background(), AddAccountBalanceParams{
+		ID:     account.ID,
+		Amount: entry.Amount,
+	})
+	require.NoError(t, err)
+
+	// the entry was created after the end of the snapshot date
+	snapshotDate := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -1)
+	endTime := entry.CreatedAt.Add(-time.Microsecond)
+	_, err = testQueries.CreateBalanceSnapshots(context.Background(), CreateBalanceSnapshotsParams{
+		SnapshotDate: snapshotDate,
+		EndTime:      endTime,
+	})
+	require.NoError(t, err)
+
+	snapshot, err := testQueries.GetLatestBalanceSnapshot(context.Background(), GetLatestBalanceSnapshotParams{
+		AccountID:  account.ID,
+		BeforeDate: snapshotDate.AddDate(0, 0, 1),
+	})
+	require.NoError(t, err)
+	require.Equal(t, account.Balance, snapshot.Balance)
+}
@@ -0,0 +1,112 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/VL-037/go-bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomBeneficiary(t *testing.T, owner string) Beneficiary {
+	account := createRandomAccount(t)
+
+	arg := CreateBeneficiaryParams{
+		Owner:     owner,
+		Nickname:  util.RandomOwner(),
+		AccountID: account.ID,
+		Currency:  account.Currency,
+	}
+
+	beneficiary, err := testQueries.CreateBeneficiary(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, beneficiary)
+
+	require.Equal(t, arg.Owner, beneficiary.Owner)
+	require.Equal(t, arg.Nickname, beneficiary.Nickname)
+	require.Equal(t, arg.AccountID, beneficiary.AccountID)
+	require.Equal(t, arg.Currency, beneficiary.Currency)
+	require.NotZero(t, beneficiary.CreatedAt)
+
+	return beneficiary
+}
+
+func TestCreateBeneficiary(t *testing.T) {
+	user := createRandomUser(t)
+	beneficiary := createRandomBeneficiary(t, user.Username)
+
+	// the same account cannot be saved twice by the same user
+	_, err := testQueries.CreateBeneficiary(context.Background(), CreateBeneficiaryParams{
+		Owner:     user.Username,
+		Nickname:  util.RandomOwner(),
+		AccountID: beneficiary.AccountID,
+		Currency:  beneficiary.Currency,
+	})
+	require.Error(t, err)
+}
+
+func TestGetBeneficiaryByAccount(t *testing.T) {
+	user := createRandomUser(t)
+	beneficiary := createRandomBeneficiary(t, user.Username)
+
+	found, err := testQueries.GetBeneficiaryByAccount(context.Background(), GetBeneficiaryByAccountParams{
+		Owner:     user.Username,
+		AccountID: beneficiary.AccountID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, beneficiary.ID, found.ID)
+
+	// beneficiaries are private to their owner
+	other := createRandomUser(t)
+	_, err = testQueries.GetBeneficiaryByAccount(context.Background(), GetBeneficiaryByAccountParams{
+		Owner:     other.Username,
+		AccountID: beneficiary.AccountID,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestUpdateBeneficiary(t *testing.T) {
+	user := createRandomUser(t)
+	beneficiary := createRandomBeneficiary(t, user.Username)
+
+	nickname := util.RandomOwner()
+	updated, err := testQueries.UpdateBeneficiary(context.Background(), UpdateBeneficiaryParams{
+		ID:       beneficiary.ID,
+		Nickname: nickname,
+	})
+	require.NoError(t, err)
+	require.Equal(t, nickname, updated.Nickname)
+	require.Equal(t, beneficiary.AccountID, updated.AccountID)
+	require.Equal(t, beneficiary.CreatedAt, updated.CreatedAt)
+}
+
+func TestDeleteBeneficiary(t *testing.T) {
+	user := createRandomUser(t)
+	beneficiary := createRandomBeneficiary(t, user.Username)
+
+	err := testQueries.DeleteBeneficiary(context.Background(), beneficiary.ID)
+	require.NoError(t, err)
+
+	_, err = testQueries.GetBeneficiary(context.Background(), beneficiary.ID)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+}
+
+func TestListBeneficiaries(t *testing.T) {
+	user := createRandomUser(t)
+	for i := 0; i < 3; i++ {
+		createRandomBeneficiary(t, user.Username)
+	}
+
+	beneficiaries, err := testQueries.ListBeneficiaries(context.Background(), ListBeneficiariesParams{
+		Owner:  user.Username,
+		Limit:  5,
+		Offset: 0,
+	})
+	require.NoError(t, err)
+	require.Len(t, beneficiaries, 3)
+
+	for _, beneficiary := range beneficiaries {
+		require.Equal(t, user.Username, beneficiary.Owner)
+	}
+}
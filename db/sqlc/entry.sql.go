@@ -7,21 +7,25 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
 const createEntry = `-- name: CreateEntry :one
 INSERT INTO entries (account_id,
-                     amount)
-VALUES ($1, $2) RETURNING id, account_id, amount, created_by, created_at, updated_by, updated_at, mark_for_delete
+                     amount,
+                     transfer_id)
+VALUES ($1, $2, $3) RETURNING id, account_id, amount, created_by, created_at, updated_by, updated_at, mark_for_delete, transfer_id
 `
 
 type CreateEntryParams struct {
-	AccountID int64 `json:"account_id"`
-	Amount    int64 `json:"amount"`
+	AccountID  int64         `json:"account_id"`
+	Amount     int64         `json:"amount"`
+	TransferID sql.NullInt64 `json:"transfer_id"`
 }
 
 func (q *Queries) CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error) {
-	row := q.db.QueryRowContext(ctx, createEntry, arg.AccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createEntry, arg.AccountID, arg.Amount, arg.TransferID)
 	var i Entry
 	err := row.Scan(
 		&i.ID,
@@ -32,12 +36,13 @@ func (q *Queries) CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry
 		&i.UpdatedBy,
 		&i.UpdatedAt,
 		&i.MarkForDelete,
+		&i.TransferID,
 	)
 	return i, err
 }
 
 const getEntry = `-- name: GetEntry :one
-SELECT id, account_id, amount, created_by, created_at, updated_by, updated_at, mark_for_delete
+SELECT id, account_id, amount, created_by, created_at, updated_by, updated_at, mark_for_delete, transfer_id
 FROM entries
 WHERE id = $1 LIMIT 1
 `
@@ -54,12 +59,82 @@ func (q *Queries) GetEntry(ctx context.Context, id int64) (Entry, error) {
 		&i.UpdatedBy,
 		&i.UpdatedAt,
 		&i.MarkForDelete,
+		&i.TransferID,
 	)
 	return i, err
 }
 
+const listAccountStatementEntries = `-- name: ListAccountStatementEntries :many
+SELECT e.id,
+       e.amount,
+       e.created_at,
+       e.transfer_id,
+       t.description,
+       t.client_reference,
+       c.number AS counterparty_number,
+       c.owner  AS counterparty_owner
+FROM entries e
+         LEFT JOIN transfers t ON t.id = e.transfer_id
+         LEFT JOIN accounts c ON c.id = CASE
+                                            WHEN t.from_account_id = e.account_id THEN t.to_account_id
+                                            ELSE t.from_account_id END
+WHERE e.account_id = $1
+  AND e.created_at >= $2
+  AND e.created_at < $3
+ORDER BY e.created_at, e.id
+`
+
+type ListAccountStatementEntriesParams struct {
+	AccountID int64     `json:"account_id"`
+	FromTime  time.Time `json:"from_time"`
+	ToTime    time.Time `json:"to_time"`
+}
+
+type ListAccountStatementEntriesRow struct {
+	ID                 int64          `json:"id"`
+	Amount             int64          `json:"amount"`
+	CreatedAt          time.Time      `json:"created_at"`
+	TransferID         sql.NullInt64  `json:"transfer_id"`
+	Description        sql.NullString `json:"description"`
+	ClientReference    sql.NullString `json:"client_reference"`
+	CounterpartyNumber sql.NullString `json:"counterparty_number"`
+	CounterpartyOwner  sql.NullString `json:"counterparty_owner"`
+}
+
+func (q *Queries) ListAccountStatementEntries(ctx context.Context, arg ListAccountStatementEntriesParams) ([]ListAccountStatementEntriesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountStatementEntries, arg.AccountID, arg.FromTime, arg.ToTime)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListAccountStatementEntriesRow{}
+	for rows.Next() {
+		var i ListAccountStatementEntriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+			&i.Description,
+			&i.ClientReference,
+			&i.CounterpartyNumber,
+			&i.CounterpartyOwner,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listEntries = `-- name: ListEntries :many
-SELECT id, account_id, amount, created_by, created_at, updated_by, updated_at, mark_for_delete
+SELECT id, account_id, amount, created_by, created_at, updated_by, updated_at, mark_for_delete, transfer_id
 FROM entries
 WHERE account_id = $1
 ORDER BY id LIMIT $2
@@ -90,6 +165,7 @@ func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Ent
 			&i.UpdatedBy,
 			&i.UpdatedAt,
 			&i.MarkForDelete,
+			&i.TransferID,
 		); err != nil {
 			return nil, err
 		}
@@ -103,3 +179,83 @@ func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Ent
 	}
 	return items, nil
 }
+
+const listOwnerEntries = `-- name: ListOwnerEntries :many
+SELECT id, account_id, amount, created_by, created_at, updated_by, updated_at, mark_for_delete, transfer_id
+FROM entries
+WHERE account_id IN (SELECT id FROM accounts WHERE owner = $1)
+ORDER BY id
+`
+
+func (q *Queries) ListOwnerEntries(ctx context.Context, owner string) ([]Entry, error) {
+	rows, err := q.db.QueryContext(ctx, listOwnerEntries, owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entry{}
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedBy,
+			&i.UpdatedAt,
+			&i.MarkForDelete,
+			&i.TransferID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const sumAccountEntriesBetween = `-- name: SumAccountEntriesBetween :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS total
+FROM entries
+WHERE account_id = $1
+  AND created_at >= $2
+  AND created_at < $3
+`
+
+type SumAccountEntriesBetweenParams struct {
+	AccountID int64     `json:"account_id"`
+	FromTime  time.Time `json:"from_time"`
+	ToTime    time.Time `json:"to_time"`
+}
+
+func (q *Queries) SumAccountEntriesBetween(ctx context.Context, arg SumAccountEntriesBetweenParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, sumAccountEntriesBetween, arg.AccountID, arg.FromTime, arg.ToTime)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
+
+const sumAccountEntriesSince = `-- name: SumAccountEntriesSince :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS total
+FROM entries
+WHERE account_id = $1
+  AND created_at >= $2
+`
+
+type SumAccountEntriesSinceParams struct {
+	AccountID int64     `json:"account_id"`
+	Since     time.Time `json:"since"`
+}
+
+func (q *Queries) SumAccountEntriesSince(ctx context.Context, arg SumAccountEntriesSinceParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, sumAccountEntriesSince, arg.AccountID, arg.Since)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
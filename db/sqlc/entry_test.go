@@ -70,3 +70,92 @@ func TestListEntries(t *testing.T) {
 		require.Equal(t, arg.AccountID, entry.AccountID)
 	}
 }
+
+func TestSumAccountEntriesSince(t *testing.T) {
+	account := createRandomAccount(t)
+	since := time.Now()
+
+	var total int64
+	for i := 0; i < 3; i++ {
+		entry := createRandomEntry(t, account)
+		total += entry.Amount
+	}
+
+	sum, err := testQueries.SumAccountEntriesSince(context.Background(), SumAccountEntriesSinceParams{
+		AccountID: account.ID,
+		Since:     since.Add(-time.Second),
+	})
+	require.NoError(t, err)
+	require.Equal(t, total, sum)
+
+	sum, err = testQueries.SumAccountEntriesSince(context.Background(), SumAccountEntriesSinceParams{
+		AccountID: account.ID,
+		Since:     time.Now().Add(time.Minute),
+	})
+	require.NoError(t, err)
+	require.Zero(t, sum)
+}
+
+func TestListAccountStatementEntries(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	from := time.Now().Add(-time.Second)
+
+	entry := createRandomEntry(t, account1)
+
+	result, err := NewStore(testDB).TransferTx(context.Background(), TransferTxParams{
+		FromAccountID:   account1.ID,
+		ToAccountID:     account2.ID,
+		Amount:          10,
+		Description:     "rent",
+		ClientReference: util.RandomString(10),
+	})
+	require.NoError(t, err)
+
+	entries, err := testQueries.ListAccountStatementEntries(context.Background(), ListAccountStatementEntriesParams{
+		AccountID: account1.ID,
+		FromTime:  from,
+		ToTime:    time.Now().Add(time.Second),
+	})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	require.Equal(t, entry.ID, entries[0].ID)
+	require.False(t, entries[0].TransferID.Valid)
+	require.False(t, entries[0].CounterpartyNumber.Valid)
+
+	require.Equal(t, result.FromEntry.ID, entries[1].ID)
+	require.Equal(t, -result.Transfer.Amount, entries[1].Amount)
+	require.Equal(t, result.Transfer.ID, entries[1].TransferID.Int64)
+	require.Equal(t, "rent", entries[1].Description.String)
+	require.Equal(t, result.Transfer.ClientReference, entries[1].ClientReference)
+	require.Equal(t, account2.Number, entries[1].CounterpartyNumber.String)
+	require.Equal(t, account2.Owner, entries[1].CounterpartyOwner.String)
+}
+
+func TestSumAccountEntriesBetween(t *testing.T) {
+	account := createRandomAccount(t)
+	from := time.Now().Add(-time.Second)
+
+	var total int64
+	for i := 0; i < 3; i++ {
+		entry := createRandomEntry(t, account)
+		total += entry.Amount
+	}
+
+	sum, err := testQueries.SumAccountEntriesBetween(context.Background(), SumAccountEntriesBetweenParams{
+		AccountID: account.ID,
+		FromTime:  from,
+		ToTime:    time.Now().Add(time.Second),
+	})
+	require.NoError(t, err)
+	require.Equal(t, total, sum)
+
+	sum, err = testQueries.SumAccountEntriesBetween(context.Background(), SumAccountEntriesBetweenParams{
+		AccountID: account.ID,
+		FromTime:  from.Add(-time.Hour),
+		ToTime:    from,
+	})
+	require.NoError(t, err)
+	require.Zero(t, sum)
+}
@@ -0,0 +1,50 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/VL-037/go-bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordLoginFailure(t *testing.T) {
+	key := "user:" + util.RandomOwner() + util.RandomString(6)
+	windowStart := time.Now().Add(-time.Hour)
+
+	attempt, err := testQueries.RecordLoginFailure(context.Background(), RecordLoginFailureParams{
+		AttemptKey:  key,
+		WindowStart: windowStart,
+	})
+	require.NoError(t, err)
+	require.Equal(t, key, attempt.AttemptKey)
+	require.Equal(t, int32(1), attempt.Failures)
+	require.WithinDuration(t, time.Now(), attempt.LastFailedAt, time.Second)
+
+	attempt, err = testQueries.RecordLoginFailure(context.Background(), RecordLoginFailureParams{
+		AttemptKey:  key,
+		WindowStart: windowStart,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(2), attempt.Failures)
+
+	// failures before the window are forgotten
+	attempt, err = testQueries.RecordLoginFailure(context.Background(), RecordLoginFailureParams{
+		AttemptKey:  key,
+		WindowStart: time.Now().Add(time.Minute),
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(1), attempt.Failures)
+
+	savedAttempt, err := testQueries.GetLoginAttempt(context.Background(), key)
+	require.NoError(t, err)
+	require.Equal(t, attempt.Failures, savedAttempt.Failures)
+
+	err = testQueries.DeleteLoginAttempt(context.Background(), key)
+	require.NoError(t, err)
+
+	_, err = testQueries.GetLoginAttempt(context.Background(), key)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
@@ -6,14 +6,89 @@ package db
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 )
 
 type Account struct {
-	ID            int64          `json:"id"`
-	Owner         string         `json:"owner"`
+	ID        int64          `json:"id"`
+	Owner     string         `json:"owner"`
+	Balance   int64          `json:"balance"`
+	Currency  string         `json:"currency"`
+	CreatedBy sql.NullString `json:"created_by"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedBy sql.NullString `json:"updated_by"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	// the account is closed, it keeps its entries but cannot move money
+	MarkForDelete bool `json:"mark_for_delete"`
+	// balance minus active holds
+	AvailableBalance int64 `json:"available_balance"`
+	// public account number with mod-97 check digits
+	Number string `json:"number"`
+	// checking, savings or internal
+	Type string `json:"type"`
+	// interest accrued but not paid yet, in millionths of the minor unit
+	AccruedInterest int64 `json:"accrued_interest"`
+	// interest is accrued for the days before this date
+	InterestAccruedUntil time.Time `json:"interest_accrued_until"`
+}
+
+type AccountBalanceSnapshot struct {
+	ID           int64     `json:"id"`
+	AccountID    int64     `json:"account_id"`
+	SnapshotDate time.Time `json:"snapshot_date"`
+	// balance at the end of the snapshot date, midnight UTC
 	Balance       int64          `json:"balance"`
-	Currency      string         `json:"currency"`
+	CreatedBy     sql.NullString `json:"created_by"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedBy     sql.NullString `json:"updated_by"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	MarkForDelete bool           `json:"mark_for_delete"`
+}
+
+type ApiKey struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	Name     string `json:"name"`
+	// first characters of the key, shown to tell keys apart
+	KeyPrefix string `json:"key_prefix"`
+	// SHA-256 of the key, the key itself is only shown once
+	KeyHash string `json:"key_hash"`
+	// space separated scopes, e.g. read:accounts write:transfers
+	Scopes        string         `json:"scopes"`
+	ExpiredAt     time.Time      `json:"expired_at"`
+	LastUsedAt    sql.NullTime   `json:"last_used_at"`
+	IsRevoked     bool           `json:"is_revoked"`
+	CreatedBy     sql.NullString `json:"created_by"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedBy     sql.NullString `json:"updated_by"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	MarkForDelete bool           `json:"mark_for_delete"`
+}
+
+type Beneficiary struct {
+	ID        int64          `json:"id"`
+	Owner     string         `json:"owner"`
+	Nickname  string         `json:"nickname"`
+	AccountID int64          `json:"account_id"`
+	Currency  string         `json:"currency"`
+	CreatedBy sql.NullString `json:"created_by"`
+	// start of the cooling-off period before large transfers
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedBy     sql.NullString `json:"updated_by"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	MarkForDelete bool           `json:"mark_for_delete"`
+}
+
+type EmailVerification struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	// address the token was sent to
+	Email string `json:"email"`
+	// SHA-256 of the token, the token itself is only sent by email
+	TokenHash     string         `json:"token_hash"`
+	IsUsed        bool           `json:"is_used"`
+	ExpiredAt     time.Time      `json:"expired_at"`
 	CreatedBy     sql.NullString `json:"created_by"`
 	CreatedAt     time.Time      `json:"created_at"`
 	UpdatedBy     sql.NullString `json:"updated_by"`
@@ -31,6 +106,258 @@ type Entry struct {
 	UpdatedBy     sql.NullString `json:"updated_by"`
 	UpdatedAt     time.Time      `json:"updated_at"`
 	MarkForDelete bool           `json:"mark_for_delete"`
+	// transfer that posted the entry
+	TransferID sql.NullInt64 `json:"transfer_id"`
+}
+
+type Hold struct {
+	ID            int64 `json:"id"`
+	FromAccountID int64 `json:"from_account_id"`
+	ToAccountID   int64 `json:"to_account_id"`
+	// must be positive
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+	// active, captured, voided or expired
+	Status         string         `json:"status"`
+	ExpiresAt      time.Time      `json:"expires_at"`
+	CapturedAmount sql.NullInt64  `json:"captured_amount"`
+	TransferID     sql.NullInt64  `json:"transfer_id"`
+	CreatedBy      sql.NullString `json:"created_by"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedBy      sql.NullString `json:"updated_by"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	MarkForDelete  bool           `json:"mark_for_delete"`
+}
+
+type InterestAccrual struct {
+	ID        int64     `json:"id"`
+	AccountID int64     `json:"account_id"`
+	StartDate time.Time `json:"start_date"`
+	// exclusive
+	EndDate time.Time `json:"end_date"`
+	Balance int64     `json:"balance"`
+	// in basis points
+	AnnualRate int64  `json:"annual_rate"`
+	DayCount   string `json:"day_count"`
+	// in millionths of the minor unit
+	Amount int64 `json:"amount"`
+	// capitalization posted at the end of the month
+	TransferID    sql.NullInt64  `json:"transfer_id"`
+	CreatedBy     sql.NullString `json:"created_by"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedBy     sql.NullString `json:"updated_by"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	MarkForDelete bool           `json:"mark_for_delete"`
+}
+
+type LoginAttempt struct {
+	// user:<username> or ip:<address>
+	AttemptKey string `json:"attempt_key"`
+	// failed logins since the counter was last reset
+	Failures      int32          `json:"failures"`
+	LastFailedAt  time.Time      `json:"last_failed_at"`
+	CreatedBy     sql.NullString `json:"created_by"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedBy     sql.NullString `json:"updated_by"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	MarkForDelete bool           `json:"mark_for_delete"`
+}
+
+type Notification struct {
+	ID            int64          `json:"id"`
+	Username      string         `json:"username"`
+	Message       string         `json:"message"`
+	IsRead        bool           `json:"is_read"`
+	CreatedBy     sql.NullString `json:"created_by"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedBy     sql.NullString `json:"updated_by"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	MarkForDelete bool           `json:"mark_for_delete"`
+}
+
+type OauthAuthorizationCode struct {
+	ID          int64  `json:"id"`
+	CodeHash    string `json:"code_hash"`
+	ClientID    string `json:"client_id"`
+	Username    string `json:"username"`
+	RedirectUri string `json:"redirect_uri"`
+	Scopes      string `json:"scopes"`
+	// PKCE S256 challenge, base64url SHA-256 of the code verifier
+	CodeChallenge string         `json:"code_challenge"`
+	ExpiredAt     time.Time      `json:"expired_at"`
+	UsedAt        sql.NullTime   `json:"used_at"`
+	CreatedBy     sql.NullString `json:"created_by"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedBy     sql.NullString `json:"updated_by"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	MarkForDelete bool           `json:"mark_for_delete"`
+}
+
+type OauthClient struct {
+	ID       int64  `json:"id"`
+	ClientID string `json:"client_id"`
+	// SHA-256 of the client secret, null for public clients which rely on PKCE alone
+	SecretHash sql.NullString `json:"secret_hash"`
+	Name       string         `json:"name"`
+	// space separated redirect URIs, matched exactly
+	RedirectUris string `json:"redirect_uris"`
+	// space separated scopes the client may request
+	Scopes        string         `json:"scopes"`
+	IsRevoked     bool           `json:"is_revoked"`
+	CreatedBy     sql.NullString `json:"created_by"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedBy     sql.NullString `json:"updated_by"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	MarkForDelete bool           `json:"mark_for_delete"`
+}
+
+type OauthConsent struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	ClientID string `json:"client_id"`
+	Scopes   string `json:"scopes"`
+	// access tokens issued before are rejected, reset when a revoked consent is granted again or its scopes change
+	GrantedAt     time.Time      `json:"granted_at"`
+	IsRevoked     bool           `json:"is_revoked"`
+	CreatedBy     sql.NullString `json:"created_by"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedBy     sql.NullString `json:"updated_by"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	MarkForDelete bool           `json:"mark_for_delete"`
+}
+
+type OauthRefreshToken struct {
+	ID        int64     `json:"id"`
+	TokenHash string    `json:"token_hash"`
+	ClientID  string    `json:"client_id"`
+	Username  string    `json:"username"`
+	Scopes    string    `json:"scopes"`
+	ExpiredAt time.Time `json:"expired_at"`
+	// set when the token was exchanged for a new one, a rotated token is never accepted again
+	RotatedAt     sql.NullTime   `json:"rotated_at"`
+	IsRevoked     bool           `json:"is_revoked"`
+	CreatedBy     sql.NullString `json:"created_by"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedBy     sql.NullString `json:"updated_by"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	MarkForDelete bool           `json:"mark_for_delete"`
+}
+
+type PasswordReset struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	// SHA-256 of the token, the token itself is only sent by email
+	TokenHash     string         `json:"token_hash"`
+	IsUsed        bool           `json:"is_used"`
+	ExpiredAt     time.Time      `json:"expired_at"`
+	CreatedBy     sql.NullString `json:"created_by"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedBy     sql.NullString `json:"updated_by"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	MarkForDelete bool           `json:"mark_for_delete"`
+}
+
+type PaymentRequest struct {
+	ID          int64  `json:"id"`
+	Requester   string `json:"requester"`
+	Payer       string `json:"payer"`
+	ToAccountID int64  `json:"to_account_id"`
+	// must be positive
+	Amount   int64          `json:"amount"`
+	Currency string         `json:"currency"`
+	Note     sql.NullString `json:"note"`
+	// pending, accepted, declined or expired
+	Status    string    `json:"status"`
+	ExpiresAt time.Time `json:"expires_at"`
+	// account chosen by the payer on accept
+	FromAccountID sql.NullInt64  `json:"from_account_id"`
+	TransferID    sql.NullInt64  `json:"transfer_id"`
+	CreatedBy     sql.NullString `json:"created_by"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedBy     sql.NullString `json:"updated_by"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	MarkForDelete bool           `json:"mark_for_delete"`
+}
+
+type PendingTransfer struct {
+	ID            int64 `json:"id"`
+	FromAccountID int64 `json:"from_account_id"`
+	ToAccountID   int64 `json:"to_account_id"`
+	// must be positive
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+	// pending_approval, approved or rejected
+	Status          string          `json:"status"`
+	RequestedBy     string          `json:"requested_by"`
+	TransferID      sql.NullInt64   `json:"transfer_id"`
+	CreatedBy       sql.NullString  `json:"created_by"`
+	CreatedAt       time.Time       `json:"created_at"`
+	UpdatedBy       sql.NullString  `json:"updated_by"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+	MarkForDelete   bool            `json:"mark_for_delete"`
+	Description     sql.NullString  `json:"description"`
+	ClientReference sql.NullString  `json:"client_reference"`
+	Metadata        json.RawMessage `json:"metadata"`
+}
+
+type RecoveryCode struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	// SHA-256 of the recovery code, the codes are only shown once
+	CodeHash      string         `json:"code_hash"`
+	IsUsed        bool           `json:"is_used"`
+	CreatedBy     sql.NullString `json:"created_by"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedBy     sql.NullString `json:"updated_by"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	MarkForDelete bool           `json:"mark_for_delete"`
+}
+
+type ScheduledTransfer struct {
+	ID            int64 `json:"id"`
+	FromAccountID int64 `json:"from_account_id"`
+	ToAccountID   int64 `json:"to_account_id"`
+	// must be positive
+	Amount    int64     `json:"amount"`
+	Currency  string    `json:"currency"`
+	ExecuteAt time.Time `json:"execute_at"`
+	// pending, executed, failed or cancelled
+	Status        string         `json:"status"`
+	TransferID    sql.NullInt64  `json:"transfer_id"`
+	FailureReason sql.NullString `json:"failure_reason"`
+	ExecutedAt    sql.NullTime   `json:"executed_at"`
+	CreatedBy     sql.NullString `json:"created_by"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedBy     sql.NullString `json:"updated_by"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	MarkForDelete bool           `json:"mark_for_delete"`
+}
+
+type StandingOrder struct {
+	ID            int64  `json:"id"`
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	Amount        int64  `json:"amount"`
+	Currency      string `json:"currency"`
+	// daily, weekly or monthly
+	Frequency string `json:"frequency"`
+	// only used by monthly standing orders
+	DayOfMonth      sql.NullInt32 `json:"day_of_month"`
+	StartAt         time.Time     `json:"start_at"`
+	EndAt           sql.NullTime  `json:"end_at"`
+	MaxExecutions   sql.NullInt32 `json:"max_executions"`
+	ExecutedCount   int32         `json:"executed_count"`
+	NextExecutionAt time.Time     `json:"next_execution_at"`
+	// active, paused or completed
+	Status            string         `json:"status"`
+	RetryCount        int32          `json:"retry_count"`
+	LastFailureReason sql.NullString `json:"last_failure_reason"`
+	LastExecutedAt    sql.NullTime   `json:"last_executed_at"`
+	CreatedBy         sql.NullString `json:"created_by"`
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedBy         sql.NullString `json:"updated_by"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	MarkForDelete     bool           `json:"mark_for_delete"`
 }
 
 type Transfer struct {
@@ -44,6 +371,63 @@ type Transfer struct {
 	UpdatedBy     sql.NullString `json:"updated_by"`
 	UpdatedAt     time.Time      `json:"updated_at"`
 	MarkForDelete bool           `json:"mark_for_delete"`
+	Description   sql.NullString `json:"description"`
+	// reference chosen by the client, not unique
+	ClientReference sql.NullString `json:"client_reference"`
+	// flat map of string keys and values
+	Metadata json.RawMessage `json:"metadata"`
+}
+
+type TransferApproval struct {
+	ID                int64  `json:"id"`
+	PendingTransferID int64  `json:"pending_transfer_id"`
+	Approver          string `json:"approver"`
+	// approved or rejected
+	Decision      string         `json:"decision"`
+	Comment       sql.NullString `json:"comment"`
+	CreatedBy     sql.NullString `json:"created_by"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedBy     sql.NullString `json:"updated_by"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	MarkForDelete bool           `json:"mark_for_delete"`
+}
+
+type TransferBatch struct {
+	ID            int64  `json:"id"`
+	FromAccountID int64  `json:"from_account_id"`
+	Currency      string `json:"currency"`
+	// atomic or best_effort
+	Mode string `json:"mode"`
+	// processing, completed, partially_completed or failed
+	Status        string         `json:"status"`
+	ItemCount     int32          `json:"item_count"`
+	TotalAmount   int64          `json:"total_amount"`
+	ExecutedCount int32          `json:"executed_count"`
+	FailedCount   int32          `json:"failed_count"`
+	CreatedBy     sql.NullString `json:"created_by"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedBy     sql.NullString `json:"updated_by"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	MarkForDelete bool           `json:"mark_for_delete"`
+}
+
+type TransferBatchItem struct {
+	ID              int64          `json:"id"`
+	BatchID         int64          `json:"batch_id"`
+	LineNumber      int32          `json:"line_number"`
+	ToAccountID     int64          `json:"to_account_id"`
+	Amount          int64          `json:"amount"`
+	Description     sql.NullString `json:"description"`
+	ClientReference sql.NullString `json:"client_reference"`
+	// executed or failed
+	Status        string         `json:"status"`
+	FailureReason sql.NullString `json:"failure_reason"`
+	TransferID    sql.NullInt64  `json:"transfer_id"`
+	CreatedBy     sql.NullString `json:"created_by"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedBy     sql.NullString `json:"updated_by"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	MarkForDelete bool           `json:"mark_for_delete"`
 }
 
 type User struct {
@@ -56,5 +440,16 @@ type User struct {
 	CreatedAt         time.Time      `json:"created_at"`
 	UpdatedBy         sql.NullString `json:"updated_by"`
 	UpdatedAt         time.Time      `json:"updated_at"`
-	MarkForDelete     bool           `json:"mark_for_delete"`
+	// the user deleted the profile, personal fields are pseudonymized
+	MarkForDelete bool `json:"mark_for_delete"`
+	// depositor, approver or admin
+	Role string `json:"role"`
+	// access tokens issued before are rejected
+	TokensValidAfter time.Time `json:"tokens_valid_after"`
+	IsEmailVerified  bool      `json:"is_email_verified"`
+	// base32 TOTP secret, set on enrollment and enabled once a code is confirmed
+	TotpSecret    sql.NullString `json:"totp_secret"`
+	IsTotpEnabled bool           `json:"is_totp_enabled"`
+	// time step of the last accepted code, codes of earlier steps cannot be replayed
+	TotpLastUsedStep int64 `json:"totp_last_used_step"`
 }
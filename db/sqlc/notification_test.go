@@ -0,0 +1,47 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/VL-037/go-bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomNotification(t *testing.T, user User) Notification {
+	arg := CreateNotificationParams{
+		Username: user.Username,
+		Message:  util.RandomString(20),
+	}
+
+	notification, err := testQueries.CreateNotification(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, notification)
+
+	require.Equal(t, arg.Username, notification.Username)
+	require.Equal(t, arg.Message, notification.Message)
+	require.False(t, notification.IsRead)
+	require.NotZero(t, notification.ID)
+	require.NotZero(t, notification.CreatedAt)
+
+	return notification
+}
+
+func TestListNotifications(t *testing.T) {
+	user := createRandomUser(t)
+	for i := 0; i < 5; i++ {
+		createRandomNotification(t, user)
+	}
+
+	notifications, err := testQueries.ListNotifications(context.Background(), ListNotificationsParams{
+		Username: user.Username,
+		Limit:    5,
+		Offset:   0,
+	})
+	require.NoError(t, err)
+	require.Len(t, notifications, 5)
+
+	for _, notification := range notifications {
+		require.Equal(t, user.Username, notification.Username)
+	}
+}
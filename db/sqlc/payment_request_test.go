@@ -0,0 +1,83 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/VL-037/go-bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomPaymentRequest(t *testing.T, toAccount Account, payer string, amount int64, expiresAt time.Time) PaymentRequest {
+	arg := CreatePaymentRequestParams{
+		Requester:   toAccount.Owner,
+		Payer:       payer,
+		ToAccountID: toAccount.ID,
+		Amount:      amount,
+		Currency:    toAccount.Currency,
+		Note:        sql.NullString{String: util.RandomString(10), Valid: true},
+		ExpiresAt:   expiresAt,
+	}
+
+	paymentRequest, err := testQueries.CreatePaymentRequest(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, paymentRequest)
+
+	require.Equal(t, arg.Requester, paymentRequest.Requester)
+	require.Equal(t, arg.Payer, paymentRequest.Payer)
+	require.Equal(t, arg.ToAccountID, paymentRequest.ToAccountID)
+	require.Equal(t, arg.Amount, paymentRequest.Amount)
+	require.Equal(t, arg.Note, paymentRequest.Note)
+	require.Equal(t, PAYMENT_REQUEST_STATUS_PENDING, paymentRequest.Status)
+	require.WithinDuration(t, arg.ExpiresAt, paymentRequest.ExpiresAt, time.Second)
+
+	return paymentRequest
+}
+
+func TestCreatePaymentRequest(t *testing.T) {
+	toAccount := createRandomAccount(t)
+	payer := createRandomUser(t)
+	createRandomPaymentRequest(t, toAccount, payer.Username, 10, time.Now().Add(time.Hour))
+}
+
+func TestDeclinePaymentRequest(t *testing.T) {
+	toAccount := createRandomAccount(t)
+	payer := createRandomUser(t)
+	paymentRequest := createRandomPaymentRequest(t, toAccount, payer.Username, 10, time.Now().Add(time.Hour))
+
+	declined, err := testQueries.DeclinePaymentRequest(context.Background(), paymentRequest.ID)
+	require.NoError(t, err)
+	require.Equal(t, PAYMENT_REQUEST_STATUS_DECLINED, declined.Status)
+
+	_, err = testQueries.DeclinePaymentRequest(context.Background(), paymentRequest.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestExpirePaymentRequests(t *testing.T) {
+	toAccount := createRandomAccount(t)
+	payer := createRandomUser(t)
+	expired := createRandomPaymentRequest(t, toAccount, payer.Username, 10, time.Now().Add(-time.Minute))
+	active := createRandomPaymentRequest(t, toAccount, payer.Username, 10, time.Now().Add(time.Hour))
+
+	count, err := testQueries.ExpirePaymentRequests(context.Background())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, count, int64(1))
+
+	expired, err = testQueries.GetPaymentRequest(context.Background(), expired.ID)
+	require.NoError(t, err)
+	require.Equal(t, PAYMENT_REQUEST_STATUS_EXPIRED, expired.Status)
+
+	active, err = testQueries.GetPaymentRequest(context.Background(), active.ID)
+	require.NoError(t, err)
+	require.Equal(t, PAYMENT_REQUEST_STATUS_PENDING, active.Status)
+
+	paymentRequests, err := testQueries.ListIncomingPaymentRequests(context.Background(), ListIncomingPaymentRequestsParams{
+		Payer:  payer.Username,
+		Limit:  5,
+		Offset: 0,
+	})
+	require.NoError(t, err)
+	require.Len(t, paymentRequests, 2)
+}
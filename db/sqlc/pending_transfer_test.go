@@ -0,0 +1,59 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomPendingTransfer(t *testing.T, fromAccount, toAccount Account, amount int64) PendingTransfer {
+	arg := CreatePendingTransferParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        amount,
+		Currency:      fromAccount.Currency,
+		RequestedBy:   fromAccount.Owner,
+		Metadata:      json.RawMessage(`{}`),
+	}
+
+	pendingTransfer, err := testQueries.CreatePendingTransfer(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, pendingTransfer)
+
+	require.Equal(t, arg.FromAccountID, pendingTransfer.FromAccountID)
+	require.Equal(t, arg.ToAccountID, pendingTransfer.ToAccountID)
+	require.Equal(t, arg.Amount, pendingTransfer.Amount)
+	require.Equal(t, arg.RequestedBy, pendingTransfer.RequestedBy)
+	require.Equal(t, PENDING_TRANSFER_STATUS_PENDING_APPROVAL, pendingTransfer.Status)
+	require.False(t, pendingTransfer.TransferID.Valid)
+
+	return pendingTransfer
+}
+
+func TestCreatePendingTransfer(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	createRandomPendingTransfer(t, account1, account2, 10)
+}
+
+func TestListPendingTransfersByRequester(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	for i := 0; i < 3; i++ {
+		createRandomPendingTransfer(t, account1, account2, 10)
+	}
+
+	pendingTransfers, err := testQueries.ListPendingTransfersByRequester(context.Background(), ListPendingTransfersByRequesterParams{
+		RequestedBy: account1.Owner,
+		Limit:       5,
+		Offset:      0,
+	})
+	require.NoError(t, err)
+	require.Len(t, pendingTransfers, 3)
+
+	for _, pendingTransfer := range pendingTransfers {
+		require.Equal(t, account1.Owner, pendingTransfer.RequestedBy)
+	}
+}
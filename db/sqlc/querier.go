@@ -6,24 +6,146 @@ package db
 
 import (
 	"context"
+	"time"
 )
 
 type Querier interface {
+	AcceptPaymentRequest(ctx context.Context, arg AcceptPaymentRequestParams) (PaymentRequest, error)
+	AddAccountAvailableBalance(ctx context.Context, arg AddAccountAvailableBalanceParams) (Account, error)
 	AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error)
+	AdvanceStandingOrder(ctx context.Context, arg AdvanceStandingOrderParams) (StandingOrder, error)
+	CancelAccountScheduledTransfers(ctx context.Context, accountID int64) error
+	CancelScheduledTransfer(ctx context.Context, id int64) (ScheduledTransfer, error)
+	CloseAccount(ctx context.Context, arg CloseAccountParams) (Account, error)
+	CompleteTransferBatch(ctx context.Context, arg CompleteTransferBatchParams) (TransferBatch, error)
+	CountOpenAccounts(ctx context.Context, owner string) (int64, error)
+	CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (ApiKey, error)
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
+	CreateBalanceSnapshots(ctx context.Context, arg CreateBalanceSnapshotsParams) (int64, error)
+	CreateBeneficiary(ctx context.Context, arg CreateBeneficiaryParams) (Beneficiary, error)
+	CreateEmailVerification(ctx context.Context, arg CreateEmailVerificationParams) (EmailVerification, error)
 	CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error)
+	CreateHold(ctx context.Context, arg CreateHoldParams) (Hold, error)
+	CreateInterestAccrual(ctx context.Context, arg CreateInterestAccrualParams) (InterestAccrual, error)
+	CreateNotification(ctx context.Context, arg CreateNotificationParams) (Notification, error)
+	CreateOAuthAuthorizationCode(ctx context.Context, arg CreateOAuthAuthorizationCodeParams) (OauthAuthorizationCode, error)
+	CreateOAuthClient(ctx context.Context, arg CreateOAuthClientParams) (OauthClient, error)
+	CreateOAuthRefreshToken(ctx context.Context, arg CreateOAuthRefreshTokenParams) (OauthRefreshToken, error)
+	CreatePasswordReset(ctx context.Context, arg CreatePasswordResetParams) (PasswordReset, error)
+	CreatePaymentRequest(ctx context.Context, arg CreatePaymentRequestParams) (PaymentRequest, error)
+	CreatePendingTransfer(ctx context.Context, arg CreatePendingTransferParams) (PendingTransfer, error)
+	CreateRecoveryCode(ctx context.Context, arg CreateRecoveryCodeParams) (RecoveryCode, error)
+	CreateScheduledTransfer(ctx context.Context, arg CreateScheduledTransferParams) (ScheduledTransfer, error)
+	CreateStandingOrder(ctx context.Context, arg CreateStandingOrderParams) (StandingOrder, error)
 	CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error)
+	CreateTransferApproval(ctx context.Context, arg CreateTransferApprovalParams) (TransferApproval, error)
+	CreateTransferBatch(ctx context.Context, arg CreateTransferBatchParams) (TransferBatch, error)
+	CreateTransferBatchItem(ctx context.Context, arg CreateTransferBatchItemParams) (TransferBatchItem, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	DeclinePaymentRequest(ctx context.Context, id int64) (PaymentRequest, error)
 	DeleteAccount(ctx context.Context, id int64) error
+	DeleteAccountStandingOrders(ctx context.Context, accountID int64) error
+	DeleteBeneficiary(ctx context.Context, id int64) error
+	DeleteEmailVerifications(ctx context.Context, username string) error
+	DeleteLoginAttempt(ctx context.Context, attemptKey string) error
+	DeleteOwnerBeneficiaries(ctx context.Context, owner string) error
+	DeleteRecoveryCodes(ctx context.Context, username string) error
+	DeleteStandingOrder(ctx context.Context, id int64) error
+	DeleteUser(ctx context.Context, username string) (User, error)
+	DisableUserTOTP(ctx context.Context, username string) (User, error)
+	EnableUserTOTP(ctx context.Context, arg EnableUserTOTPParams) (User, error)
+	ExpirePaymentRequests(ctx context.Context) (int64, error)
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (ApiKey, error)
 	GetAccount(ctx context.Context, id int64) (Account, error)
+	GetAccountByNumber(ctx context.Context, number string) (Account, error)
+	GetAccountByOwner(ctx context.Context, arg GetAccountByOwnerParams) (Account, error)
 	GetAccountForUpdate(ctx context.Context, id int64) (Account, error)
+	GetBeneficiary(ctx context.Context, id int64) (Beneficiary, error)
+	GetBeneficiaryByAccount(ctx context.Context, arg GetBeneficiaryByAccountParams) (Beneficiary, error)
 	GetEntry(ctx context.Context, id int64) (Entry, error)
+	GetHold(ctx context.Context, id int64) (Hold, error)
+	GetHoldForUpdate(ctx context.Context, id int64) (Hold, error)
+	GetLatestBalanceSnapshot(ctx context.Context, arg GetLatestBalanceSnapshotParams) (AccountBalanceSnapshot, error)
+	GetLoginAttempt(ctx context.Context, attemptKey string) (LoginAttempt, error)
+	GetNextDueScheduledTransferForUpdate(ctx context.Context) (ScheduledTransfer, error)
+	GetNextDueStandingOrderForUpdate(ctx context.Context) (StandingOrder, error)
+	GetNextExpiredHoldForUpdate(ctx context.Context) (Hold, error)
+	GetNextInterestAccountForUpdate(ctx context.Context, today time.Time) (Account, error)
+	GetOAuthAuthorizationCodeByHash(ctx context.Context, codeHash string) (OauthAuthorizationCode, error)
+	GetOAuthClient(ctx context.Context, clientID string) (OauthClient, error)
+	GetOAuthConsent(ctx context.Context, arg GetOAuthConsentParams) (OauthConsent, error)
+	GetOAuthRefreshTokenByHash(ctx context.Context, tokenHash string) (OauthRefreshToken, error)
+	GetPasswordReset(ctx context.Context, id int64) (PasswordReset, error)
+	GetPaymentRequest(ctx context.Context, id int64) (PaymentRequest, error)
+	GetPaymentRequestForUpdate(ctx context.Context, id int64) (PaymentRequest, error)
+	GetPendingTransfer(ctx context.Context, id int64) (PendingTransfer, error)
+	GetPendingTransferForUpdate(ctx context.Context, id int64) (PendingTransfer, error)
+	GetScheduledTransfer(ctx context.Context, id int64) (ScheduledTransfer, error)
+	GetStandingOrder(ctx context.Context, id int64) (StandingOrder, error)
 	GetTransfer(ctx context.Context, id int64) (Transfer, error)
+	GetTransferBatch(ctx context.Context, id int64) (TransferBatch, error)
 	GetUser(ctx context.Context, username string) (User, error)
+	GetUserAuthState(ctx context.Context, username string) (GetUserAuthStateRow, error)
+	GetUserByEmail(ctx context.Context, email string) (User, error)
+	InvalidatePasswordResets(ctx context.Context, username string) error
+	ListAPIKeys(ctx context.Context, username string) ([]ApiKey, error)
+	ListAccountActiveHoldsForUpdate(ctx context.Context, accountID int64) ([]Hold, error)
+	ListAccountStatementEntries(ctx context.Context, arg ListAccountStatementEntriesParams) ([]ListAccountStatementEntriesRow, error)
+	ListAccountTransfers(ctx context.Context, arg ListAccountTransfersParams) ([]Transfer, error)
 	ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error)
+	ListAccountsByIDs(ctx context.Context, ids []int64) ([]Account, error)
+	ListAccountsByNumbers(ctx context.Context, numbers []string) ([]Account, error)
+	ListBeneficiaries(ctx context.Context, arg ListBeneficiariesParams) ([]Beneficiary, error)
 	ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error)
+	ListHolds(ctx context.Context, arg ListHoldsParams) ([]Hold, error)
+	ListIncomingPaymentRequests(ctx context.Context, arg ListIncomingPaymentRequestsParams) ([]PaymentRequest, error)
+	ListInterestAccruals(ctx context.Context, arg ListInterestAccrualsParams) ([]InterestAccrual, error)
+	ListNotifications(ctx context.Context, arg ListNotificationsParams) ([]Notification, error)
+	ListOAuthConsents(ctx context.Context, username string) ([]OauthConsent, error)
+	ListOutgoingPaymentRequests(ctx context.Context, arg ListOutgoingPaymentRequestsParams) ([]PaymentRequest, error)
+	ListOwnerAccounts(ctx context.Context, owner string) ([]Account, error)
+	ListOwnerEntries(ctx context.Context, owner string) ([]Entry, error)
+	ListOwnerTransfers(ctx context.Context, owner string) ([]Transfer, error)
+	ListPendingTransfers(ctx context.Context, arg ListPendingTransfersParams) ([]PendingTransfer, error)
+	ListPendingTransfersByRequester(ctx context.Context, arg ListPendingTransfersByRequesterParams) ([]PendingTransfer, error)
+	ListScheduledTransfers(ctx context.Context, arg ListScheduledTransfersParams) ([]ScheduledTransfer, error)
+	ListStandingOrders(ctx context.Context, arg ListStandingOrdersParams) ([]StandingOrder, error)
+	ListTransferApprovals(ctx context.Context, pendingTransferID int64) ([]TransferApproval, error)
+	ListTransferBatchItems(ctx context.Context, batchID int64) ([]TransferBatchItem, error)
 	ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error)
+	MarkScheduledTransferExecuted(ctx context.Context, arg MarkScheduledTransferExecutedParams) (ScheduledTransfer, error)
+	MarkScheduledTransferFailed(ctx context.Context, arg MarkScheduledTransferFailedParams) (ScheduledTransfer, error)
+	PauseStandingOrder(ctx context.Context, id int64) (StandingOrder, error)
+	RecordLoginFailure(ctx context.Context, arg RecordLoginFailureParams) (LoginAttempt, error)
+	RehashUserPassword(ctx context.Context, arg RehashUserPasswordParams) error
+	ResumeStandingOrder(ctx context.Context, arg ResumeStandingOrderParams) (StandingOrder, error)
+	RetryStandingOrder(ctx context.Context, arg RetryStandingOrderParams) (StandingOrder, error)
+	RevokeAPIKey(ctx context.Context, arg RevokeAPIKeyParams) (ApiKey, error)
+	RevokeOAuthConsent(ctx context.Context, arg RevokeOAuthConsentParams) (OauthConsent, error)
+	RevokeOAuthRefreshTokens(ctx context.Context, arg RevokeOAuthRefreshTokensParams) error
+	RevokeOAuthRefreshTokensIssuedBefore(ctx context.Context, arg RevokeOAuthRefreshTokensIssuedBeforeParams) error
+	RevokeUserAPIKeys(ctx context.Context, username string) error
+	RevokeUserOAuthConsents(ctx context.Context, username string) error
+	RevokeUserOAuthRefreshTokens(ctx context.Context, username string) error
+	RotateOAuthRefreshToken(ctx context.Context, id int64) (OauthRefreshToken, error)
+	SetUserTOTPSecret(ctx context.Context, arg SetUserTOTPSecretParams) (User, error)
+	SumAccountEntriesBetween(ctx context.Context, arg SumAccountEntriesBetweenParams) (int64, error)
+	SumAccountEntriesSince(ctx context.Context, arg SumAccountEntriesSinceParams) (int64, error)
+	UpdateAPIKeyLastUsed(ctx context.Context, id int64) error
 	UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error)
+	UpdateAccountInterest(ctx context.Context, arg UpdateAccountInterestParams) (Account, error)
+	UpdateBeneficiary(ctx context.Context, arg UpdateBeneficiaryParams) (Beneficiary, error)
+	UpdateHoldStatus(ctx context.Context, arg UpdateHoldStatusParams) (Hold, error)
+	UpdatePendingTransferStatus(ctx context.Context, arg UpdatePendingTransferStatusParams) (PendingTransfer, error)
+	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
+	UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error)
+	UpsertOAuthConsent(ctx context.Context, arg UpsertOAuthConsentParams) (OauthConsent, error)
+	UseEmailVerification(ctx context.Context, arg UseEmailVerificationParams) (EmailVerification, error)
+	UseOAuthAuthorizationCode(ctx context.Context, id int64) (OauthAuthorizationCode, error)
+	UsePasswordReset(ctx context.Context, arg UsePasswordResetParams) (PasswordReset, error)
+	UseRecoveryCode(ctx context.Context, arg UseRecoveryCodeParams) (RecoveryCode, error)
+	UseUserTOTPStep(ctx context.Context, arg UseUserTOTPStepParams) (int64, error)
+	VerifyUserEmail(ctx context.Context, arg VerifyUserEmailParams) (User, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -0,0 +1,79 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/VL-037/go-bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomScheduledTransfer(t *testing.T, fromAccount, toAccount Account, executeAt time.Time) ScheduledTransfer {
+	arg := CreateScheduledTransferParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        util.RandomMoney() + 1,
+		Currency:      fromAccount.Currency,
+		ExecuteAt:     executeAt,
+	}
+
+	scheduledTransfer, err := testQueries.CreateScheduledTransfer(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, scheduledTransfer)
+
+	require.Equal(t, arg.FromAccountID, scheduledTransfer.FromAccountID)
+	require.Equal(t, arg.ToAccountID, scheduledTransfer.ToAccountID)
+	require.Equal(t, arg.Amount, scheduledTransfer.Amount)
+	require.Equal(t, arg.Currency, scheduledTransfer.Currency)
+	require.WithinDuration(t, arg.ExecuteAt, scheduledTransfer.ExecuteAt, time.Second)
+	require.Equal(t, SCHEDULED_TRANSFER_STATUS_PENDING, scheduledTransfer.Status)
+	require.False(t, scheduledTransfer.TransferID.Valid)
+
+	require.NotZero(t, scheduledTransfer.ID)
+	require.NotZero(t, scheduledTransfer.CreatedAt)
+
+	return scheduledTransfer
+}
+
+func TestCreateScheduledTransfer(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	createRandomScheduledTransfer(t, account1, account2, time.Now().Add(time.Hour))
+}
+
+func TestListScheduledTransfers(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	for i := 0; i < 5; i++ {
+		createRandomScheduledTransfer(t, account1, account2, time.Now().Add(time.Hour))
+	}
+
+	scheduledTransfers, err := testQueries.ListScheduledTransfers(context.Background(), ListScheduledTransfersParams{
+		Owner:  account1.Owner,
+		Limit:  5,
+		Offset: 0,
+	})
+	require.NoError(t, err)
+	require.Len(t, scheduledTransfers, 5)
+
+	for _, scheduledTransfer := range scheduledTransfers {
+		require.Equal(t, account1.ID, scheduledTransfer.FromAccountID)
+	}
+}
+
+func TestCancelScheduledTransfer(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	savedScheduledTransfer := createRandomScheduledTransfer(t, account1, account2, time.Now().Add(time.Hour))
+
+	scheduledTransfer, err := testQueries.CancelScheduledTransfer(context.Background(), savedScheduledTransfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, SCHEDULED_TRANSFER_STATUS_CANCELLED, scheduledTransfer.Status)
+
+	// a cancelled scheduled transfer cannot be cancelled again
+	_, err = testQueries.CancelScheduledTransfer(context.Background(), savedScheduledTransfer.ID)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+}
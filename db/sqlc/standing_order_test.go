@@ -0,0 +1,80 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/VL-037/go-bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomStandingOrder(t *testing.T, fromAccount, toAccount Account, nextExecutionAt time.Time) StandingOrder {
+	arg := CreateStandingOrderParams{
+		FromAccountID:   fromAccount.ID,
+		ToAccountID:     toAccount.ID,
+		Amount:          util.RandomMoney() + 1,
+		Currency:        fromAccount.Currency,
+		Frequency:       util.DAILY,
+		StartAt:         nextExecutionAt,
+		MaxExecutions:   sql.NullInt32{Int32: 2, Valid: true},
+		NextExecutionAt: nextExecutionAt,
+	}
+
+	standingOrder, err := testQueries.CreateStandingOrder(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, standingOrder)
+
+	require.Equal(t, arg.FromAccountID, standingOrder.FromAccountID)
+	require.Equal(t, arg.ToAccountID, standingOrder.ToAccountID)
+	require.Equal(t, arg.Amount, standingOrder.Amount)
+	require.Equal(t, arg.Frequency, standingOrder.Frequency)
+	require.Equal(t, arg.MaxExecutions, standingOrder.MaxExecutions)
+	require.WithinDuration(t, arg.NextExecutionAt, standingOrder.NextExecutionAt, time.Second)
+	require.Equal(t, STANDING_ORDER_STATUS_ACTIVE, standingOrder.Status)
+	require.Zero(t, standingOrder.ExecutedCount)
+	require.Zero(t, standingOrder.RetryCount)
+
+	return standingOrder
+}
+
+func TestCreateStandingOrder(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	createRandomStandingOrder(t, account1, account2, time.Now().Add(time.Hour))
+}
+
+func TestPauseAndResumeStandingOrder(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	savedStandingOrder := createRandomStandingOrder(t, account1, account2, time.Now().Add(time.Hour))
+
+	standingOrder, err := testQueries.PauseStandingOrder(context.Background(), savedStandingOrder.ID)
+	require.NoError(t, err)
+	require.Equal(t, STANDING_ORDER_STATUS_PAUSED, standingOrder.Status)
+
+	_, err = testQueries.PauseStandingOrder(context.Background(), savedStandingOrder.ID)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+
+	nextExecutionAt := time.Now().Add(2 * time.Hour)
+	standingOrder, err = testQueries.ResumeStandingOrder(context.Background(), ResumeStandingOrderParams{
+		ID:              savedStandingOrder.ID,
+		NextExecutionAt: nextExecutionAt,
+	})
+	require.NoError(t, err)
+	require.Equal(t, STANDING_ORDER_STATUS_ACTIVE, standingOrder.Status)
+	require.WithinDuration(t, nextExecutionAt, standingOrder.NextExecutionAt, time.Second)
+}
+
+func TestDeleteStandingOrder(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	savedStandingOrder := createRandomStandingOrder(t, account1, account2, time.Now().Add(time.Hour))
+
+	err := testQueries.DeleteStandingOrder(context.Background(), savedStandingOrder.ID)
+	require.NoError(t, err)
+
+	_, err = testQueries.GetStandingOrder(context.Background(), savedStandingOrder.ID)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+}
@@ -3,13 +3,38 @@ package db
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 )
 
+var ErrAccountClosed = errors.New("account is closed")
+
 // Store provides all functions to execute db queries and transactions
 type Store interface {
 	Querier
 	TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResponse, error)
+	ExecuteScheduledTransferTx(ctx context.Context, arg ExecuteScheduledTransferTxParams) (ExecuteScheduledTransferTxResponse, error)
+	ExecuteStandingOrderTx(ctx context.Context, arg ExecuteStandingOrderTxParams) (ExecuteStandingOrderTxResponse, error)
+	CreateHoldTx(ctx context.Context, arg CreateHoldTxParams) (HoldTxResponse, error)
+	CaptureHoldTx(ctx context.Context, arg CaptureHoldTxParams) (HoldTxResponse, error)
+	VoidHoldTx(ctx context.Context, holdID int64) (HoldTxResponse, error)
+	ExpireHoldTx(ctx context.Context) (HoldTxResponse, error)
+	ApproveTransferTx(ctx context.Context, arg DecideTransferTxParams) (DecideTransferTxResponse, error)
+	RejectTransferTx(ctx context.Context, arg DecideTransferTxParams) (DecideTransferTxResponse, error)
+	TransferBatchTx(ctx context.Context, arg TransferBatchTxParams) (TransferBatchTxResponse, error)
+	AcceptPaymentRequestTx(ctx context.Context, arg AcceptPaymentRequestTxParams) (AcceptPaymentRequestTxResponse, error)
+	AccrueInterestTx(ctx context.Context, arg AccrueInterestTxParams) (AccrueInterestTxResponse, error)
+	VerifyEmailTx(ctx context.Context, arg VerifyEmailTxParams) (VerifyEmailTxResponse, error)
+	ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams) (ResetPasswordTxResponse, error)
+	ChangePasswordTx(ctx context.Context, arg ChangePasswordTxParams) (ChangePasswordTxResponse, error)
+	EnableTOTPTx(ctx context.Context, arg EnableTOTPTxParams) (EnableTOTPTxResponse, error)
+	CloseAccountTx(ctx context.Context, accountID int64) (CloseAccountTxResponse, error)
+	DeleteUserTx(ctx context.Context, username string) (DeleteUserTxResponse, error)
+	ExchangeOAuthCodeTx(ctx context.Context, arg ExchangeOAuthCodeTxParams) (ExchangeOAuthCodeTxResponse, error)
+	RotateOAuthRefreshTokenTx(ctx context.Context, arg RotateOAuthRefreshTokenTxParams) (RotateOAuthRefreshTokenTxResponse, error)
+	UpsertOAuthConsentTx(ctx context.Context, arg UpsertOAuthConsentParams) (UpsertOAuthConsentTxResponse, error)
+	RevokeOAuthConsentTx(ctx context.Context, arg RevokeOAuthConsentParams) (RevokeOAuthConsentTxResponse, error)
 }
 
 // SQLStore provides all functions to execute db queries and transactions
@@ -46,9 +71,12 @@ func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) erro
 }
 
 type TransferTxParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Amount        int64 `json: "amount"`
+	FromAccountID   int64           `json:"from_account_id"`
+	ToAccountID     int64           `json:"to_account_id"`
+	Amount          int64           `json:"amount"`
+	Description     string          `json:"description"`
+	ClientReference string          `json:"client_reference"`
+	Metadata        json.RawMessage `json:"metadata"`
 }
 
 type TransferTxResponse struct {
@@ -60,48 +88,89 @@ type TransferTxResponse struct {
 }
 
 // TransferTx performs a money transfer from one account to the other
-// It creates a transfer record, add account entries, and update accounts' balance within a single DB transaction
+// It creates a transfer record, add account entries, and update accounts' balance within a single DB transaction.
+// Funds reserved by holds cannot be transferred, so the available balance of the from account must cover the amount
 func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResponse, error) {
 	var response TransferTxResponse
 
 	err := store.execTx(ctx, func(q *Queries) error {
-		var err error
-		response.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
-			FromAccountID: arg.FromAccountID,
-			ToAccountID:   arg.ToAccountID,
-			Amount:        arg.Amount,
-		})
+		fromAccount, err := q.GetAccountForUpdate(ctx, arg.FromAccountID)
 		if err != nil {
 			return err
 		}
 
-		response.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.FromAccountID,
-			Amount:    -arg.Amount,
-		})
-		if err != nil {
-			return err
+		if fromAccount.AvailableBalance < arg.Amount {
+			return ErrInsufficientFunds
 		}
 
-		response.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.ToAccountID,
-			Amount:    arg.Amount,
-		})
-		if err != nil {
-			return err
-		}
+		response, err = transfer(ctx, q, arg)
+		return err
+	})
+	return response, err
+}
 
-		response.FromAccount, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
-			ID:     arg.FromAccountID,
-			Amount: -arg.Amount,
-		})
+// transfer moves money between two accounts using the given queries, so it can be reused by other transactions.
+// Closed accounts can neither send nor receive money, the caller rolls back on ErrAccountClosed
+func transfer(ctx context.Context, q *Queries, arg TransferTxParams) (TransferTxResponse, error) {
+	var response TransferTxResponse
+	var err error
+
+	response.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
+		FromAccountID:   arg.FromAccountID,
+		ToAccountID:     arg.ToAccountID,
+		Amount:          arg.Amount,
+		Description:     sql.NullString{String: arg.Description, Valid: arg.Description != ""},
+		ClientReference: sql.NullString{String: arg.ClientReference, Valid: arg.ClientReference != ""},
+		Metadata:        transferMetadata(arg.Metadata),
+	})
+	if err != nil {
+		return response, err
+	}
 
-		response.ToAccount, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
-			ID:     arg.ToAccountID,
-			Amount: arg.Amount,
-		})
+	response.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:  arg.FromAccountID,
+		Amount:     -arg.Amount,
+		TransferID: sql.NullInt64{Int64: response.Transfer.ID, Valid: true},
+	})
+	if err != nil {
+		return response, err
+	}
 
-		return nil
+	response.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:  arg.ToAccountID,
+		Amount:     arg.Amount,
+		TransferID: sql.NullInt64{Int64: response.Transfer.ID, Valid: true},
 	})
-	return response, err
+	if err != nil {
+		return response, err
+	}
+
+	response.FromAccount, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
+		ID:     arg.FromAccountID,
+		Amount: -arg.Amount,
+	})
+	if err != nil {
+		return response, err
+	}
+
+	response.ToAccount, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
+		ID:     arg.ToAccountID,
+		Amount: arg.Amount,
+	})
+	if err != nil {
+		return response, err
+	}
+
+	if response.FromAccount.MarkForDelete || response.ToAccount.MarkForDelete {
+		return response, ErrAccountClosed
+	}
+	return response, nil
+}
+
+// transferMetadata defaults missing metadata to an empty JSON object, as the column is not nullable
+func transferMetadata(metadata json.RawMessage) json.RawMessage {
+	if len(metadata) == 0 {
+		return json.RawMessage("{}")
+	}
+	return metadata
 }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/stretchr/testify/require"
 	"testing"
+	"time"
 )
 
 func TestTransferTx(t *testing.T) {
@@ -12,6 +13,8 @@ func TestTransferTx(t *testing.T) {
 
 	account1 := createRandomAccount(t)
 	account2 := createRandomAccount(t)
+	account1, err := testQueries.UpdateAccount(context.Background(), UpdateAccountParams{ID: account1.ID, Balance: 1000})
+	require.NoError(t, err)
 	fmt.Println(">> before:", account1.Balance, account2.Balance)
 
 	// to make transaction runs well (concurrency issue), run n concurrent go routines (transfer transactions)
@@ -62,6 +65,7 @@ func TestTransferTx(t *testing.T) {
 		require.NotEmpty(t, fromEntry)
 		require.Equal(t, account1.ID, fromEntry.AccountID)
 		require.Equal(t, -amount, fromEntry.Amount) // negative since account1 transfer to account2
+		require.Equal(t, transfer.ID, fromEntry.TransferID.Int64)
 		require.NotZero(t, fromEntry.ID)
 		require.NotZero(t, fromEntry.CreatedAt)
 		require.NotZero(t, fromEntry.UpdatedAt)
@@ -74,6 +78,7 @@ func TestTransferTx(t *testing.T) {
 		require.NotEmpty(t, toEntry)
 		require.Equal(t, account2.ID, toEntry.AccountID)
 		require.Equal(t, amount, toEntry.Amount)
+		require.Equal(t, transfer.ID, toEntry.TransferID.Int64)
 		require.NotZero(t, toEntry.ID)
 		require.NotZero(t, toEntry.CreatedAt)
 		require.NotZero(t, toEntry.UpdatedAt)
@@ -116,3 +121,26 @@ func TestTransferTx(t *testing.T) {
 	require.Equal(t, account1.Balance-(int64(n)*amount), updatedAccount1.Balance)
 	require.Equal(t, account2.Balance+(int64(n)*amount), updatedAccount2.Balance)
 }
+
+func TestTransferTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	account1, err := testQueries.UpdateAccount(context.Background(), UpdateAccountParams{ID: account1.ID, Balance: 100})
+	require.NoError(t, err)
+
+	// funds reserved by a hold cannot be transferred
+	createRandomHold(t, store, account1, account2, 60, time.Now().Add(time.Hour))
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        50,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	updatedAccount1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(100), updatedAccount1.Balance)
+}
@@ -7,23 +7,38 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
 )
 
 const createTransfer = `-- name: CreateTransfer :one
 INSERT INTO transfers (from_account_id,
                        to_account_id,
-                       amount)
-VALUES ($1, $2, $3) RETURNING id, from_account_id, to_account_id, amount, created_by, created_at, updated_by, updated_at, mark_for_delete
+                       amount,
+                       description,
+                       client_reference,
+                       metadata)
+VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, from_account_id, to_account_id, amount, created_by, created_at, updated_by, updated_at, mark_for_delete, description, client_reference, metadata
 `
 
 type CreateTransferParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Amount        int64 `json:"amount"`
+	FromAccountID   int64           `json:"from_account_id"`
+	ToAccountID     int64           `json:"to_account_id"`
+	Amount          int64           `json:"amount"`
+	Description     sql.NullString  `json:"description"`
+	ClientReference sql.NullString  `json:"client_reference"`
+	Metadata        json.RawMessage `json:"metadata"`
 }
 
 func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
-	row := q.db.QueryRowContext(ctx, createTransfer, arg.FromAccountID, arg.ToAccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.Description,
+		arg.ClientReference,
+		arg.Metadata,
+	)
 	var i Transfer
 	err := row.Scan(
 		&i.ID,
@@ -35,12 +50,15 @@ func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams)
 		&i.UpdatedBy,
 		&i.UpdatedAt,
 		&i.MarkForDelete,
+		&i.Description,
+		&i.ClientReference,
+		&i.Metadata,
 	)
 	return i, err
 }
 
 const getTransfer = `-- name: GetTransfer :one
-SELECT id, from_account_id, to_account_id, amount, created_by, created_at, updated_by, updated_at, mark_for_delete
+SELECT id, from_account_id, to_account_id, amount, created_by, created_at, updated_by, updated_at, mark_for_delete, description, client_reference, metadata
 FROM transfers
 WHERE id = $1 LIMIT 1
 `
@@ -58,12 +76,121 @@ func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
 		&i.UpdatedBy,
 		&i.UpdatedAt,
 		&i.MarkForDelete,
+		&i.Description,
+		&i.ClientReference,
+		&i.Metadata,
 	)
 	return i, err
 }
 
+const listAccountTransfers = `-- name: ListAccountTransfers :many
+SELECT id, from_account_id, to_account_id, amount, created_by, created_at, updated_by, updated_at, mark_for_delete, description, client_reference, metadata
+FROM transfers
+WHERE (from_account_id = $1 OR to_account_id = $1)
+  AND ($2::varchar IS NULL OR client_reference = $2)
+  AND ($3::varchar IS NULL OR metadata ->> $3 = $4)
+ORDER BY id DESC LIMIT $5
+OFFSET $6
+`
+
+type ListAccountTransfersParams struct {
+	AccountID       int64          `json:"account_id"`
+	ClientReference sql.NullString `json:"client_reference"`
+	MetadataKey     sql.NullString `json:"metadata_key"`
+	MetadataValue   sql.NullString `json:"metadata_value"`
+	PageLimit       int32          `json:"page_limit"`
+	PageOffset      int32          `json:"page_offset"`
+}
+
+func (q *Queries) ListAccountTransfers(ctx context.Context, arg ListAccountTransfersParams) ([]Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountTransfers,
+		arg.AccountID,
+		arg.ClientReference,
+		arg.MetadataKey,
+		arg.MetadataValue,
+		arg.PageLimit,
+		arg.PageOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedBy,
+			&i.UpdatedAt,
+			&i.MarkForDelete,
+			&i.Description,
+			&i.ClientReference,
+			&i.Metadata,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOwnerTransfers = `-- name: ListOwnerTransfers :many
+SELECT id, from_account_id, to_account_id, amount, created_by, created_at, updated_by, updated_at, mark_for_delete, description, client_reference, metadata
+FROM transfers
+WHERE from_account_id IN (SELECT id FROM accounts WHERE owner = $1)
+   OR to_account_id IN (SELECT id FROM accounts WHERE owner = $1)
+ORDER BY id
+`
+
+func (q *Queries) ListOwnerTransfers(ctx context.Context, owner string) ([]Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, listOwnerTransfers, owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedBy,
+			&i.UpdatedAt,
+			&i.MarkForDelete,
+			&i.Description,
+			&i.ClientReference,
+			&i.Metadata,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listTransfers = `-- name: ListTransfers :many
-SELECT id, from_account_id, to_account_id, amount, created_by, created_at, updated_by, updated_at, mark_for_delete
+SELECT id, from_account_id, to_account_id, amount, created_by, created_at, updated_by, updated_at, mark_for_delete, description, client_reference, metadata
 FROM transfers
 WHERE from_account_id = $1
    OR to_account_id = $2
@@ -102,6 +229,9 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 			&i.UpdatedBy,
 			&i.UpdatedAt,
 			&i.MarkForDelete,
+			&i.Description,
+			&i.ClientReference,
+			&i.Metadata,
 		); err != nil {
 			return nil, err
 		}
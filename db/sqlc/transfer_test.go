@@ -2,6 +2,8 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
 	"testing"
 	"time"
 
@@ -14,6 +16,7 @@ func createRandomTransfer(t *testing.T, fromAccount, toAccount Account) Transfer
 		FromAccountID: fromAccount.ID,
 		ToAccountID:   toAccount.ID,
 		Amount:        util.RandomMoney(),
+		Metadata:      json.RawMessage(`{}`),
 	}
 
 	transfer, err := testQueries.CreateTransfer(context.Background(), arg)
@@ -77,3 +80,57 @@ func TestListTransfer(t *testing.T) {
 		require.True(t, transfer.FromAccountID == fromAccount.ID || transfer.ToAccountID == fromAccount.ID)
 	}
 }
+
+func TestListAccountTransfers(t *testing.T) {
+	fromAccount := createRandomAccount(t)
+	toAccount := createRandomAccount(t)
+
+	for i := 0; i < 3; i++ {
+		createRandomTransfer(t, fromAccount, toAccount)
+	}
+
+	reference := util.RandomString(12)
+	arg := CreateTransferParams{
+		FromAccountID:   toAccount.ID,
+		ToAccountID:     fromAccount.ID,
+		Amount:          util.RandomMoney(),
+		Description:     sql.NullString{String: "rent", Valid: true},
+		ClientReference: sql.NullString{String: reference, Valid: true},
+		Metadata:        json.RawMessage(`{"invoice": "INV-1"}`),
+	}
+	savedTransfer, err := testQueries.CreateTransfer(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Description, savedTransfer.Description)
+	require.Equal(t, arg.ClientReference, savedTransfer.ClientReference)
+	require.JSONEq(t, string(arg.Metadata), string(savedTransfer.Metadata))
+
+	transfers, err := testQueries.ListAccountTransfers(context.Background(), ListAccountTransfersParams{
+		AccountID:  fromAccount.ID,
+		PageLimit:  10,
+		PageOffset: 0,
+	})
+	require.NoError(t, err)
+	require.Len(t, transfers, 4)
+	require.Equal(t, savedTransfer.ID, transfers[0].ID)
+
+	transfers, err = testQueries.ListAccountTransfers(context.Background(), ListAccountTransfersParams{
+		AccountID:       fromAccount.ID,
+		ClientReference: sql.NullString{String: reference, Valid: true},
+		PageLimit:       10,
+		PageOffset:      0,
+	})
+	require.NoError(t, err)
+	require.Len(t, transfers, 1)
+	require.Equal(t, savedTransfer.ID, transfers[0].ID)
+
+	transfers, err = testQueries.ListAccountTransfers(context.Background(), ListAccountTransfersParams{
+		AccountID:     fromAccount.ID,
+		MetadataKey:   sql.NullString{String: "invoice", Valid: true},
+		MetadataValue: sql.NullString{String: "INV-1", Valid: true},
+		PageLimit:     10,
+		PageOffset:    0,
+	})
+	require.NoError(t, err)
+	require.Len(t, transfers, 1)
+	require.Equal(t, savedTransfer.ID, transfers[0].ID)
+}
@@ -0,0 +1,56 @@
+package db
+
+import (
+	"context"
+	"github.com/VL-037/go-bank/util"
+)
+
+type CloseAccountTxResponse struct {
+	Account Account `json:"account"`
+	Holds   []Hold  `json:"holds"`
+}
+
+// CloseAccountTx closes an empty account together with everything that would still move money through it:
+// its standing orders are deleted, its pending scheduled transfers are cancelled and its active holds are voided.
+// Interest below one minor unit can never be capitalized, so that fraction is forfeited.
+// It returns sql.ErrNoRows when the account is not empty or already closed
+func (store *SQLStore) CloseAccountTx(ctx context.Context, accountID int64) (CloseAccountTxResponse, error) {
+	var response CloseAccountTxResponse
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		response.Account, err = q.CloseAccount(ctx, CloseAccountParams{
+			ID:            accountID,
+			InterestScale: util.INTEREST_SCALE,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = q.DeleteAccountStandingOrders(ctx, accountID)
+		if err != nil {
+			return err
+		}
+
+		err = q.CancelAccountScheduledTransfers(ctx, accountID)
+		if err != nil {
+			return err
+		}
+
+		holds, err := q.ListAccountActiveHoldsForUpdate(ctx, accountID)
+		if err != nil {
+			return err
+		}
+
+		for _, hold := range holds {
+			voided, err := releaseHold(ctx, q, hold, HOLD_STATUS_VOIDED)
+			if err != nil {
+				return err
+			}
+			response.Holds = append(response.Holds, voided.Hold)
+		}
+		return nil
+	})
+	return response, err
+}
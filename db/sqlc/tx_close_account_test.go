@@ -0,0 +1,84 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/VL-037/go-bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloseAccountTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createRandomAccount(t)
+	otherAccount := createRandomAccount(t)
+	otherAccount, err := testQueries.UpdateAccount(context.Background(), UpdateAccountParams{ID: otherAccount.ID, Balance: 100})
+	require.NoError(t, err)
+
+	// an account with money left cannot be closed
+	_, err = testQueries.UpdateAccount(context.Background(), UpdateAccountParams{ID: account.ID, Balance: 10})
+	require.NoError(t, err)
+
+	_, err = store.CloseAccountTx(context.Background(), account.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	account, err = testQueries.UpdateAccount(context.Background(), UpdateAccountParams{ID: account.ID, Balance: 0})
+	require.NoError(t, err)
+
+	// a whole unit of accrued interest is still owed to the owner
+	_, err = testQueries.UpdateAccountInterest(context.Background(), UpdateAccountInterestParams{
+		ID:                   account.ID,
+		AccruedInterest:      util.INTEREST_SCALE,
+		InterestAccruedUntil: account.InterestAccruedUntil,
+	})
+	require.NoError(t, err)
+
+	_, err = store.CloseAccountTx(context.Background(), account.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// while a fraction of a unit can never be paid out
+	_, err = testQueries.UpdateAccountInterest(context.Background(), UpdateAccountInterestParams{
+		ID:                   account.ID,
+		AccruedInterest:      util.INTEREST_SCALE - 1,
+		InterestAccruedUntil: account.InterestAccruedUntil,
+	})
+	require.NoError(t, err)
+
+	standingOrder := createRandomStandingOrder(t, otherAccount, account, time.Now().Add(time.Hour))
+	scheduledTransfer := createRandomScheduledTransfer(t, account, otherAccount, time.Now().Add(time.Hour))
+	hold := createRandomHold(t, store, otherAccount, account, 60, time.Now().Add(time.Hour))
+
+	response, err := store.CloseAccountTx(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.True(t, response.Account.MarkForDelete)
+	require.Zero(t, response.Account.AccruedInterest)
+	require.Len(t, response.Holds, 1)
+	require.Equal(t, hold.ID, response.Holds[0].ID)
+	require.Equal(t, HOLD_STATUS_VOIDED, response.Holds[0].Status)
+
+	// the voided hold gives the funds back to the payer
+	updatedOtherAccount, err := testQueries.GetAccount(context.Background(), otherAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(100), updatedOtherAccount.AvailableBalance)
+
+	_, err = testQueries.GetStandingOrder(context.Background(), standingOrder.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	updatedScheduledTransfer, err := testQueries.GetScheduledTransfer(context.Background(), scheduledTransfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, SCHEDULED_TRANSFER_STATUS_CANCELLED, updatedScheduledTransfer.Status)
+
+	// a closed account can neither receive nor send money
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: otherAccount.ID,
+		ToAccountID:   account.ID,
+		Amount:        10,
+	})
+	require.ErrorIs(t, err, ErrAccountClosed)
+
+	_, err = store.CloseAccountTx(context.Background(), account.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
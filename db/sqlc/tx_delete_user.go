@@ -0,0 +1,74 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+var (
+	ErrOpenAccounts = errors.New("all accounts must be closed before the user is deleted")
+	ErrUserDeleted  = errors.New("user is already deleted")
+)
+
+type DeleteUserTxResponse struct {
+	User User `json:"user"`
+}
+
+// DeleteUserTx soft-deletes a user whose accounts are all closed. Personal fields are pseudonymized,
+// credentials, API keys, OAuth grants, pending verifications and saved beneficiaries are removed,
+// while accounts, transfers and entries stay in place so the ledger remains balanced
+func (store *SQLStore) DeleteUserTx(ctx context.Context, username string) (DeleteUserTxResponse, error) {
+	var response DeleteUserTxResponse
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		openAccounts, err := q.CountOpenAccounts(ctx, username)
+		if err != nil {
+			return err
+		}
+		if openAccounts > 0 {
+			return ErrOpenAccounts
+		}
+
+		response.User, err = q.DeleteUser(ctx, username)
+		if err == sql.ErrNoRows {
+			return ErrUserDeleted
+		}
+		if err != nil {
+			return err
+		}
+
+		err = q.DeleteRecoveryCodes(ctx, username)
+		if err != nil {
+			return err
+		}
+
+		err = q.InvalidatePasswordResets(ctx, username)
+		if err != nil {
+			return err
+		}
+
+		err = q.DeleteEmailVerifications(ctx, username)
+		if err != nil {
+			return err
+		}
+
+		err = q.RevokeUserAPIKeys(ctx, username)
+		if err != nil {
+			return err
+		}
+
+		err = q.RevokeUserOAuthConsents(ctx, username)
+		if err != nil {
+			return err
+		}
+
+		err = q.RevokeUserOAuthRefreshTokens(ctx, username)
+		if err != nil {
+			return err
+		}
+
+		return q.DeleteOwnerBeneficiaries(ctx, username)
+	})
+	return response, err
+}
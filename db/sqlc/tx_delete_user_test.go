@@ -0,0 +1,70 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteUserTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createRandomAccount(t)
+	otherAccount := createRandomAccount(t)
+	transfer := createRandomTransfer(t, account, otherAccount)
+	entry := createRandomEntry(t, account)
+	createRandomBeneficiary(t, account.Owner)
+
+	_, err := store.DeleteUserTx(context.Background(), account.Owner)
+	require.ErrorIs(t, err, ErrOpenAccounts)
+
+	// an account with money left cannot be closed
+	_, err = store.CloseAccountTx(context.Background(), account.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	_, err = testQueries.UpdateAccount(context.Background(), UpdateAccountParams{
+		ID:      account.ID,
+		Balance: 0,
+	})
+	require.NoError(t, err)
+
+	closed, err := store.CloseAccountTx(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.True(t, closed.Account.MarkForDelete)
+
+	response, err := store.DeleteUserTx(context.Background(), account.Owner)
+	require.NoError(t, err)
+
+	user := response.User
+	require.Equal(t, account.Owner, user.Username)
+	require.True(t, user.MarkForDelete)
+	require.Empty(t, user.FullName)
+	require.Empty(t, user.HashedPassword)
+	require.True(t, strings.HasSuffix(user.Email, "@deleted.invalid"))
+	require.False(t, user.IsTotpEnabled)
+	require.False(t, user.TotpSecret.Valid)
+
+	beneficiaries, err := testQueries.ListBeneficiaries(context.Background(), ListBeneficiariesParams{
+		Owner: user.Username,
+		Limit: 5,
+	})
+	require.NoError(t, err)
+	require.Empty(t, beneficiaries)
+
+	// the ledger is untouched
+	transfers, err := testQueries.ListOwnerTransfers(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Len(t, transfers, 1)
+	require.Equal(t, transfer.ID, transfers[0].ID)
+
+	entries, err := testQueries.ListOwnerEntries(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, entry.ID, entries[0].ID)
+
+	_, err = store.DeleteUserTx(context.Background(), user.Username)
+	require.ErrorIs(t, err, ErrUserDeleted)
+}
@@ -0,0 +1,52 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+var (
+	ErrInvalidEmailVerification = errors.New("email verification is invalid, used or expired")
+	ErrEmailChanged             = errors.New("email address has changed since the verification was sent")
+)
+
+type VerifyEmailTxParams struct {
+	ID        int64  `json:"id"`
+	TokenHash string `json:"token_hash"`
+}
+
+type VerifyEmailTxResponse struct {
+	EmailVerification EmailVerification `json:"email_verification"`
+	User              User              `json:"user"`
+}
+
+// VerifyEmailTx uses an email verification and marks the address it was sent to as verified
+func (store *SQLStore) VerifyEmailTx(ctx context.Context, arg VerifyEmailTxParams) (VerifyEmailTxResponse, error) {
+	var response VerifyEmailTxResponse
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		response.EmailVerification, err = q.UseEmailVerification(ctx, UseEmailVerificationParams{
+			ID:        arg.ID,
+			TokenHash: arg.TokenHash,
+		})
+		if err == sql.ErrNoRows {
+			return ErrInvalidEmailVerification
+		}
+		if err != nil {
+			return err
+		}
+
+		response.User, err = q.VerifyUserEmail(ctx, VerifyUserEmailParams{
+			Username: response.EmailVerification.Username,
+			Email:    response.EmailVerification.Email,
+		})
+		if err == sql.ErrNoRows {
+			return ErrEmailChanged
+		}
+		return err
+	})
+	return response, err
+}
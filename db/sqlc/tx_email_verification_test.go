@@ -0,0 +1,93 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/VL-037/go-bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomEmailVerification(t *testing.T, user User, token string, expiredAt time.Time) EmailVerification {
+	emailVerification, err := testQueries.CreateEmailVerification(context.Background(), CreateEmailVerificationParams{
+		Username:  user.Username,
+		Email:     user.Email,
+		TokenHash: util.HashToken(token),
+		ExpiredAt: expiredAt,
+	})
+	require.NoError(t, err)
+	require.Equal(t, user.Username, emailVerification.Username)
+	require.Equal(t, user.Email, emailVerification.Email)
+	require.False(t, emailVerification.IsUsed)
+
+	return emailVerification
+}
+
+func TestVerifyEmailTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	user := createRandomUser(t)
+	token := util.RandomString(32)
+	emailVerification := createRandomEmailVerification(t, user, token, time.Now().Add(time.Hour))
+
+	_, err := store.VerifyEmailTx(context.Background(), VerifyEmailTxParams{
+		ID:        emailVerification.ID,
+		TokenHash: util.HashToken(util.RandomString(32)),
+	})
+	require.ErrorIs(t, err, ErrInvalidEmailVerification)
+
+	arg := VerifyEmailTxParams{
+		ID:        emailVerification.ID,
+		TokenHash: util.HashToken(token),
+	}
+
+	response, err := store.VerifyEmailTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.True(t, response.EmailVerification.IsUsed)
+	require.True(t, response.User.IsEmailVerified)
+	require.Equal(t, user.Username, response.User.Username)
+
+	_, err = store.VerifyEmailTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrInvalidEmailVerification)
+}
+
+func TestVerifyEmailTxExpired(t *testing.T) {
+	store := NewStore(testDB)
+
+	user := createRandomUser(t)
+	token := util.RandomString(32)
+	emailVerification := createRandomEmailVerification(t, user, token, time.Now().Add(-time.Minute))
+
+	_, err := store.VerifyEmailTx(context.Background(), VerifyEmailTxParams{
+		ID:        emailVerification.ID,
+		TokenHash: util.HashToken(token),
+	})
+	require.ErrorIs(t, err, ErrInvalidEmailVerification)
+}
+
+func TestVerifyEmailTxEmailChanged(t *testing.T) {
+	store := NewStore(testDB)
+
+	user := createRandomUser(t)
+	token := util.RandomString(32)
+	emailVerification := createRandomEmailVerification(t, user, token, time.Now().Add(time.Hour))
+
+	_, err := testQueries.UpdateUser(context.Background(), UpdateUserParams{
+		Username: user.Username,
+		Email:    sql.NullString{String: util.RandomEmail(), Valid: true},
+	})
+	require.NoError(t, err)
+
+	_, err = store.VerifyEmailTx(context.Background(), VerifyEmailTxParams{
+		ID:        emailVerification.ID,
+		TokenHash: util.HashToken(token),
+	})
+	require.ErrorIs(t, err, ErrEmailChanged)
+
+	// the rollback leaves the verification unused
+	authState, err := testQueries.GetUserAuthState(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.False(t, authState.IsEmailVerified)
+}
@@ -0,0 +1,181 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// Statuses of a hold
+const (
+	HOLD_STATUS_ACTIVE   = "active"
+	HOLD_STATUS_CAPTURED = "captured"
+	HOLD_STATUS_VOIDED   = "voided"
+	HOLD_STATUS_EXPIRED  = "expired"
+)
+
+// Different types of error returned by the hold transactions
+var (
+	ErrInsufficientFunds = errors.New(INSUFFICIENT_FUNDS_REASON)
+	ErrHoldNotActive     = errors.New("hold is not active")
+	ErrInvalidCapture    = errors.New("capture amount exceeds the held amount")
+)
+
+type CreateHoldTxParams struct {
+	FromAccountID int64     `json:"from_account_id"`
+	ToAccountID   int64     `json:"to_account_id"`
+	Amount        int64     `json:"amount"`
+	Currency      string    `json:"currency"`
+	ExpiresAt     time.Time `json:"expires_at"`
+}
+
+type HoldTxResponse struct {
+	Hold        Hold               `json:"hold"`
+	FromAccount Account            `json:"from_account"`
+	Transfer    TransferTxResponse `json:"transfer"`
+}
+
+// CreateHoldTx reserves funds of the from account by lowering its available balance
+func (store *SQLStore) CreateHoldTx(ctx context.Context, arg CreateHoldTxParams) (HoldTxResponse, error) {
+	var response HoldTxResponse
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		fromAccount, err := q.GetAccountForUpdate(ctx, arg.FromAccountID)
+		if err != nil {
+			return err
+		}
+
+		if fromAccount.AvailableBalance < arg.Amount {
+			return ErrInsufficientFunds
+		}
+
+		response.FromAccount, err = q.AddAccountAvailableBalance(ctx, AddAccountAvailableBalanceParams{
+			ID:     arg.FromAccountID,
+			Amount: -arg.Amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		response.Hold, err = q.CreateHold(ctx, CreateHoldParams{
+			FromAccountID: arg.FromAccountID,
+			ToAccountID:   arg.ToAccountID,
+			Amount:        arg.Amount,
+			Currency:      arg.Currency,
+			ExpiresAt:     arg.ExpiresAt,
+		})
+		return err
+	})
+	return response, err
+}
+
+type CaptureHoldTxParams struct {
+	HoldID int64 `json:"hold_id"`
+	Amount int64 `json:"amount"`
+}
+
+// CaptureHoldTx releases an active hold and transfers the captured amount to the to account.
+// Capturing less than the held amount releases the remainder
+func (store *SQLStore) CaptureHoldTx(ctx context.Context, arg CaptureHoldTxParams) (HoldTxResponse, error) {
+	var response HoldTxResponse
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		hold, err := q.GetHoldForUpdate(ctx, arg.HoldID)
+		if err != nil {
+			return err
+		}
+
+		if hold.Status != HOLD_STATUS_ACTIVE || !hold.ExpiresAt.After(time.Now()) {
+			return ErrHoldNotActive
+		}
+
+		if arg.Amount > hold.Amount {
+			return ErrInvalidCapture
+		}
+
+		_, err = q.AddAccountAvailableBalance(ctx, AddAccountAvailableBalanceParams{
+			ID:     hold.FromAccountID,
+			Amount: hold.Amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		response.Transfer, err = transfer(ctx, q, TransferTxParams{
+			FromAccountID: hold.FromAccountID,
+			ToAccountID:   hold.ToAccountID,
+			Amount:        arg.Amount,
+		})
+		if err != nil {
+			return err
+		}
+		response.FromAccount = response.Transfer.FromAccount
+
+		response.Hold, err = q.UpdateHoldStatus(ctx, UpdateHoldStatusParams{
+			ID:             hold.ID,
+			Status:         HOLD_STATUS_CAPTURED,
+			CapturedAmount: sql.NullInt64{Int64: arg.Amount, Valid: true},
+			TransferID:     sql.NullInt64{Int64: response.Transfer.Transfer.ID, Valid: true},
+		})
+		return err
+	})
+	return response, err
+}
+
+// VoidHoldTx releases an active hold without moving any money
+func (store *SQLStore) VoidHoldTx(ctx context.Context, holdID int64) (HoldTxResponse, error) {
+	var response HoldTxResponse
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		hold, err := q.GetHoldForUpdate(ctx, holdID)
+		if err != nil {
+			return err
+		}
+
+		if hold.Status != HOLD_STATUS_ACTIVE {
+			return ErrHoldNotActive
+		}
+
+		response, err = releaseHold(ctx, q, hold, HOLD_STATUS_VOIDED)
+		return err
+	})
+	return response, err
+}
+
+// ExpireHoldTx releases the next active hold whose expiry has passed.
+// It returns sql.ErrNoRows when there is no expired hold left
+func (store *SQLStore) ExpireHoldTx(ctx context.Context) (HoldTxResponse, error) {
+	var response HoldTxResponse
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		hold, err := q.GetNextExpiredHoldForUpdate(ctx)
+		if err != nil {
+			return err
+		}
+
+		response, err = releaseHold(ctx, q, hold, HOLD_STATUS_EXPIRED)
+		return err
+	})
+	return response, err
+}
+
+// releaseHold gives the held amount back to the available balance of the from account
+func releaseHold(ctx context.Context, q *Queries, hold Hold, status string) (HoldTxResponse, error) {
+	var response HoldTxResponse
+	var err error
+
+	response.FromAccount, err = q.AddAccountAvailableBalance(ctx, AddAccountAvailableBalanceParams{
+		ID:     hold.FromAccountID,
+		Amount: hold.Amount,
+	})
+	if err != nil {
+		return response, err
+	}
+
+	response.Hold, err = q.UpdateHoldStatus(ctx, UpdateHoldStatusParams{
+		ID:     hold.ID,
+		Status: status,
+	})
+	return response, err
+}
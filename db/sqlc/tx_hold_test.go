@@ -0,0 +1,105 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomHold(t *testing.T, store Store, fromAccount, toAccount Account, amount int64, expiresAt time.Time) Hold {
+	response, err := store.CreateHoldTx(context.Background(), CreateHoldTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        amount,
+		Currency:      fromAccount.Currency,
+		ExpiresAt:     expiresAt,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, HOLD_STATUS_ACTIVE, response.Hold.Status)
+	require.Equal(t, amount, response.Hold.Amount)
+	require.Equal(t, fromAccount.Balance, response.FromAccount.Balance)
+	require.Equal(t, fromAccount.AvailableBalance-amount, response.FromAccount.AvailableBalance)
+
+	return response.Hold
+}
+
+func TestCreateHoldTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	_, err := store.CreateHoldTx(context.Background(), CreateHoldTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        account1.AvailableBalance + 1,
+		Currency:      account1.Currency,
+		ExpiresAt:     time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+}
+
+func TestCaptureHoldTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	account1, err := testQueries.UpdateAccount(context.Background(), UpdateAccountParams{ID: account1.ID, Balance: 100})
+	require.NoError(t, err)
+
+	hold := createRandomHold(t, store, account1, account2, 60, time.Now().Add(time.Hour))
+
+	response, err := store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{HoldID: hold.ID, Amount: 40})
+	require.NoError(t, err)
+	require.Equal(t, HOLD_STATUS_CAPTURED, response.Hold.Status)
+	require.Equal(t, int64(40), response.Hold.CapturedAmount.Int64)
+	require.Equal(t, response.Transfer.Transfer.ID, response.Hold.TransferID.Int64)
+
+	// the uncaptured remainder is released
+	require.Equal(t, int64(60), response.FromAccount.Balance)
+	require.Equal(t, int64(60), response.FromAccount.AvailableBalance)
+
+	_, err = store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{HoldID: hold.ID, Amount: 10})
+	require.ErrorIs(t, err, ErrHoldNotActive)
+}
+
+func TestVoidHoldTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	account1, err := testQueries.UpdateAccount(context.Background(), UpdateAccountParams{ID: account1.ID, Balance: 100})
+	require.NoError(t, err)
+
+	hold := createRandomHold(t, store, account1, account2, 60, time.Now().Add(time.Hour))
+
+	response, err := store.VoidHoldTx(context.Background(), hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, HOLD_STATUS_VOIDED, response.Hold.Status)
+	require.Equal(t, int64(100), response.FromAccount.AvailableBalance)
+}
+
+func TestExpireHoldTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	account1, err := testQueries.UpdateAccount(context.Background(), UpdateAccountParams{ID: account1.ID, Balance: 100})
+	require.NoError(t, err)
+
+	hold := createRandomHold(t, store, account1, account2, 60, time.Now().Add(-time.Minute))
+
+	for {
+		response, err := store.ExpireHoldTx(context.Background())
+		require.NoError(t, err)
+
+		if response.Hold.ID == hold.ID {
+			require.Equal(t, HOLD_STATUS_EXPIRED, response.Hold.Status)
+			require.Equal(t, int64(100), response.FromAccount.AvailableBalance)
+			break
+		}
+	}
+}
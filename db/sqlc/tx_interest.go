@@ -0,0 +1,146 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/VL-037/go-bank/util"
+	"time"
+)
+
+// INTEREST_EXPENSE_OWNER owns the internal accounts interest is paid from, one per currency
+const INTEREST_EXPENSE_OWNER = "interest_expense"
+
+type AccrueInterestTxParams struct {
+	// Today is the date interest is accrued up to, excluded
+	Today time.Time `json:"today"`
+	// Rates are annual rates in basis points per currency, currencies without a rate earn nothing
+	Rates    map[string]int64 `json:"rates"`
+	DayCount string           `json:"day_count"`
+}
+
+type AccrueInterestTxResponse struct {
+	Account         Account              `json:"account"`
+	Accruals        []InterestAccrual    `json:"accruals"`
+	Capitalizations []TransferTxResponse `json:"capitalizations"`
+}
+
+// AccrueInterestTx claims the next savings account whose interest is not accrued up to today and accrues it
+// within a single DB transaction. Every day accrues on its closing balance, so missed days are caught up on the
+// balances they had. The days are split at month ends and where the balance changed, and at the end of every month
+// the accrued interest is capitalized as a transfer from the interest expense account, keeping the fraction of
+// the minor unit. It returns sql.ErrNoRows when every savings account is up to date
+func (store *SQLStore) AccrueInterestTx(ctx context.Context, arg AccrueInterestTxParams) (AccrueInterestTxResponse, error) {
+	var response AccrueInterestTxResponse
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		account, err := q.GetNextInterestAccountForUpdate(ctx, arg.Today)
+		if err != nil {
+			return err
+		}
+
+		rate := arg.Rates[account.Currency]
+		accrued := account.AccruedInterest
+		// capitalizations are posted now, but count towards the balance from the month end they are paid for
+		var capitalized int64
+		for start := account.InterestAccruedUntil; start.Before(arg.Today); {
+			end := arg.Today
+			monthEnd := time.Date(start.Year(), start.Month()+1, 1, 0, 0, 0, 0, start.Location())
+			if !monthEnd.After(arg.Today) {
+				end = monthEnd
+			}
+
+			balance, err := closingBalance(ctx, q, account, start)
+			if err != nil {
+				return err
+			}
+
+			for day := start.AddDate(0, 0, 1); day.Before(end); day = day.AddDate(0, 0, 1) {
+				dayBalance, err := closingBalance(ctx, q, account, day)
+				if err != nil {
+					return err
+				}
+
+				if dayBalance != balance {
+					end = day
+					break
+				}
+			}
+			balance += capitalized
+
+			amount := util.AccrueInterest(balance, rate, arg.DayCount, start, end)
+			accrued += amount
+
+			var transferID sql.NullInt64
+			if end.Equal(monthEnd) && accrued >= util.INTEREST_SCALE {
+				capitalization, err := capitalizeInterest(ctx, q, account, accrued/util.INTEREST_SCALE, start)
+				if err != nil {
+					return err
+				}
+
+				accrued %= util.INTEREST_SCALE
+				capitalized += capitalization.Transfer.Amount
+				account = capitalization.ToAccount
+				transferID = sql.NullInt64{Int64: capitalization.Transfer.ID, Valid: true}
+				response.Capitalizations = append(response.Capitalizations, capitalization)
+			}
+
+			accrual, err := q.CreateInterestAccrual(ctx, CreateInterestAccrualParams{
+				AccountID:  account.ID,
+				StartDate:  start,
+				EndDate:    end,
+				Balance:    balance,
+				AnnualRate: rate,
+				DayCount:   arg.DayCount,
+				Amount:     amount,
+				TransferID: transferID,
+			})
+			if err != nil {
+				return err
+			}
+			response.Accruals = append(response.Accruals, accrual)
+
+			start = end
+		}
+
+		response.Account, err = q.UpdateAccountInterest(ctx, UpdateAccountInterestParams{
+			ID:                   account.ID,
+			AccruedInterest:      accrued,
+			InterestAccruedUntil: arg.Today,
+		})
+		return err
+	})
+	return response, err
+}
+
+// closingBalance is the balance of the account at the end of the day, the entries posted since are taken off
+// its current balance. The account is locked, so none is posted in between
+func closingBalance(ctx context.Context, q *Queries, account Account, day time.Time) (int64, error) {
+	sinceClose, err := q.SumAccountEntriesSince(ctx, SumAccountEntriesSinceParams{
+		AccountID: account.ID,
+		Since:     day.AddDate(0, 0, 1),
+	})
+	if err != nil {
+		return 0, err
+	}
+	return account.Balance - sinceClose, nil
+}
+
+// capitalizeInterest pays the interest of a month into the account from the interest expense account of its currency
+func capitalizeInterest(ctx context.Context, q *Queries, account Account, amount int64, month time.Time) (TransferTxResponse, error) {
+	expenseAccount, err := q.GetAccountByOwner(ctx, GetAccountByOwnerParams{
+		Owner:    INTEREST_EXPENSE_OWNER,
+		Currency: account.Currency,
+		Type:     util.INTERNAL_ACCOUNT_TYPE,
+	})
+	if err != nil {
+		return TransferTxResponse{}, err
+	}
+
+	return transfer(ctx, q, TransferTxParams{
+		FromAccountID: expenseAccount.ID,
+		ToAccountID:   account.ID,
+		Amount:        amount,
+		Description:   fmt.Sprintf("interest %s", month.Format("2006-01")),
+	})
+}
@@ -0,0 +1,144 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/VL-037/go-bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccrueInterestTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	user := createRandomUser(t)
+	account, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    user.Username,
+		Balance:  1_000_000,
+		Currency: util.USD,
+		Number:   util.NewAccountNumber(),
+		Type:     util.SAVINGS_ACCOUNT_TYPE,
+	})
+	require.NoError(t, err)
+
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	lastMonth := time.Date(now.Year(), now.Month()-1, 1, 0, 0, 0, 0, time.UTC)
+	thisMonth := lastMonth.AddDate(0, 1, 0)
+
+	account, err = testQueries.UpdateAccountInterest(context.Background(), UpdateAccountInterestParams{
+		ID:                   account.ID,
+		AccruedInterest:      0,
+		InterestAccruedUntil: lastMonth,
+	})
+	require.NoError(t, err)
+
+	arg := AccrueInterestTxParams{
+		Today:    today,
+		Rates:    map[string]int64{util.USD: 500},
+		DayCount: util.DAY_COUNT_ACT_365,
+	}
+
+	// other savings accounts may be due as well
+	var response AccrueInterestTxResponse
+	for response.Account.ID != account.ID {
+		response, err = store.AccrueInterestTx(context.Background(), arg)
+		require.NoError(t, err)
+	}
+
+	monthlyInterest := util.AccrueInterest(account.Balance, 500, util.DAY_COUNT_ACT_365, lastMonth, thisMonth)
+	require.Len(t, response.Capitalizations, 1)
+
+	capitalization := response.Capitalizations[0]
+	require.Equal(t, monthlyInterest/util.INTEREST_SCALE, capitalization.Transfer.Amount)
+	require.Equal(t, account.ID, capitalization.Transfer.ToAccountID)
+	require.Equal(t, util.INTERNAL_ACCOUNT_TYPE, capitalization.FromAccount.Type)
+	require.Equal(t, INTEREST_EXPENSE_OWNER, capitalization.FromAccount.Owner)
+
+	require.Equal(t, account.Balance+capitalization.Transfer.Amount, response.Account.Balance)
+	require.True(t, today.Equal(response.Account.InterestAccruedUntil))
+
+	// the fraction of the minor unit is kept, and the current month accrues on the capitalized balance
+	currentInterest := util.AccrueInterest(response.Account.Balance, 500, util.DAY_COUNT_ACT_365, thisMonth, today)
+	require.Equal(t, monthlyInterest%util.INTEREST_SCALE+currentInterest, response.Account.AccruedInterest)
+
+	require.NotEmpty(t, response.Accruals)
+	require.True(t, lastMonth.Equal(response.Accruals[0].StartDate))
+	require.Equal(t, capitalization.Transfer.ID, response.Accruals[0].TransferID.Int64)
+
+	// the account is up to date, running the job again does not accrue twice
+	for {
+		response, err = store.AccrueInterestTx(context.Background(), arg)
+		if err == sql.ErrNoRows {
+			break
+		}
+		require.NoError(t, err)
+		require.NotEqual(t, account.ID, response.Account.ID)
+	}
+}
+
+func TestAccrueInterestTxCatchUpDeposit(t *testing.T) {
+	store := NewStore(testDB)
+
+	user := createRandomUser(t)
+	account, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    user.Username,
+		Balance:  1_000_000,
+		Currency: util.USD,
+		Number:   util.NewAccountNumber(),
+		Type:     util.SAVINGS_ACCOUNT_TYPE,
+	})
+	require.NoError(t, err)
+
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	account, err = testQueries.UpdateAccountInterest(context.Background(), UpdateAccountInterestParams{
+		ID:                   account.ID,
+		AccruedInterest:      0,
+		InterestAccruedUntil: today.AddDate(0, 0, -2),
+	})
+	require.NoError(t, err)
+
+	// the deposit is posted today, in the middle of the days to catch up
+	deposit := int64(500_000)
+	_, err = testQueries.CreateEntry(context.Background(), CreateEntryParams{
+		AccountID: account.ID,
+		Amount:    deposit,
+	})
+	require.NoError(t, err)
+
+	_, err = testQueries.AddAccountBalance(context.Background(), AddAccountBalanceParams{
+		ID:     account.ID,
+		Amount: deposit,
+	})
+	require.NoError(t, err)
+
+	arg := AccrueInterestTxParams{
+		Today:    today.AddDate(0, 0, 3),
+		Rates:    map[string]int64{util.USD: 500},
+		DayCount: util.DAY_COUNT_ACT_365,
+	}
+
+	var response AccrueInterestTxResponse
+	for response.Account.ID != account.ID {
+		response, err = store.AccrueInterestTx(context.Background(), arg)
+		require.NoError(t, err)
+	}
+
+	// the days before the deposit accrue on the balance they closed on, not on today's
+	var splitAtDeposit bool
+	for _, accrual := range response.Accruals {
+		if !accrual.EndDate.After(today) {
+			require.Equal(t, account.Balance, accrual.Balance)
+		} else {
+			require.False(t, accrual.StartDate.Before(today))
+			require.GreaterOrEqual(t, accrual.Balance, account.Balance+deposit)
+		}
+		splitAtDeposit = splitAtDeposit || accrual.EndDate.Equal(today)
+	}
+	require.True(t, splitAtDeposit)
+	require.True(t, arg.Today.Equal(response.Account.InterestAccruedUntil))
+}
@@ -0,0 +1,181 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+var (
+	ErrOAuthGrantUsed      = errors.New("authorization grant is invalid, used or expired")
+	ErrOAuthConsentRevoked = errors.New("consent has been revoked")
+	ErrOAuthGrantRevoked   = errors.New("authorization grant has been revoked by the user")
+)
+
+type ExchangeOAuthCodeTxParams struct {
+	CodeID                int64     `json:"code_id"`
+	RefreshTokenHash      string    `json:"refresh_token_hash"`
+	RefreshTokenExpiredAt time.Time `json:"refresh_token_expired_at"`
+}
+
+type ExchangeOAuthCodeTxResponse struct {
+	AuthorizationCode OauthAuthorizationCode `json:"authorization_code"`
+	RefreshToken      OauthRefreshToken      `json:"refresh_token"`
+}
+
+// ExchangeOAuthCodeTx uses an authorization code once and issues the first refresh token of the grant,
+// the consent of the user must still be in place
+func (store *SQLStore) ExchangeOAuthCodeTx(ctx context.Context, arg ExchangeOAuthCodeTxParams) (ExchangeOAuthCodeTxResponse, error) {
+	var response ExchangeOAuthCodeTxResponse
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		response.AuthorizationCode, err = q.UseOAuthAuthorizationCode(ctx, arg.CodeID)
+		if err == sql.ErrNoRows {
+			return ErrOAuthGrantUsed
+		}
+		if err != nil {
+			return err
+		}
+
+		code := response.AuthorizationCode
+		if err := checkOAuthUser(ctx, q, code.Username, code.CreatedAt); err != nil {
+			return err
+		}
+		if err := checkOAuthConsent(ctx, q, code.Username, code.ClientID, code.CreatedAt); err != nil {
+			return err
+		}
+
+		response.RefreshToken, err = q.CreateOAuthRefreshToken(ctx, CreateOAuthRefreshTokenParams{
+			TokenHash: arg.RefreshTokenHash,
+			ClientID:  code.ClientID,
+			Username:  code.Username,
+			Scopes:    code.Scopes,
+			ExpiredAt: arg.RefreshTokenExpiredAt,
+		})
+		return err
+	})
+	return response, err
+}
+
+type RotateOAuthRefreshTokenTxParams struct {
+	ID        int64     `json:"id"`
+	TokenHash string    `json:"token_hash"`
+	ExpiredAt time.Time `json:"expired_at"`
+}
+
+type RotateOAuthRefreshTokenTxResponse struct {
+	RotatedRefreshToken OauthRefreshToken `json:"rotated_refresh_token"`
+	RefreshToken        OauthRefreshToken `json:"refresh_token"`
+}
+
+// RotateOAuthRefreshTokenTx replaces a refresh token by a new one with the same grant while the user and the consent
+// still allow it, the old token is marked as rotated so presenting it again can be told apart from an unknown token
+func (store *SQLStore) RotateOAuthRefreshTokenTx(ctx context.Context, arg RotateOAuthRefreshTokenTxParams) (RotateOAuthRefreshTokenTxResponse, error) {
+	var response RotateOAuthRefreshTokenTxResponse
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		response.RotatedRefreshToken, err = q.RotateOAuthRefreshToken(ctx, arg.ID)
+		if err == sql.ErrNoRows {
+			return ErrOAuthGrantUsed
+		}
+		if err != nil {
+			return err
+		}
+
+		rotated := response.RotatedRefreshToken
+		if err := checkOAuthUser(ctx, q, rotated.Username, rotated.CreatedAt); err != nil {
+			return err
+		}
+		if err := checkOAuthConsent(ctx, q, rotated.Username, rotated.ClientID, rotated.CreatedAt); err != nil {
+			return err
+		}
+
+		response.RefreshToken, err = q.CreateOAuthRefreshToken(ctx, CreateOAuthRefreshTokenParams{
+			TokenHash: arg.TokenHash,
+			ClientID:  rotated.ClientID,
+			Username:  rotated.Username,
+			Scopes:    rotated.Scopes,
+			ExpiredAt: arg.ExpiredAt,
+		})
+		return err
+	})
+	return response, err
+}
+
+type UpsertOAuthConsentTxResponse struct {
+	Consent OauthConsent `json:"consent"`
+}
+
+// UpsertOAuthConsentTx records the consent of a user to a client. A consent given again with other scopes
+// starts over, so the refresh tokens and codes issued under the previous scopes are revoked with it
+func (store *SQLStore) UpsertOAuthConsentTx(ctx context.Context, arg UpsertOAuthConsentParams) (UpsertOAuthConsentTxResponse, error) {
+	var response UpsertOAuthConsentTxResponse
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		response.Consent, err = q.UpsertOAuthConsent(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		return q.RevokeOAuthRefreshTokensIssuedBefore(ctx, RevokeOAuthRefreshTokensIssuedBeforeParams{
+			Username:     arg.Username,
+			ClientID:     arg.ClientID,
+			IssuedBefore: response.Consent.GrantedAt,
+		})
+	})
+	return response, err
+}
+
+type RevokeOAuthConsentTxResponse struct {
+	Consent OauthConsent `json:"consent"`
+}
+
+// RevokeOAuthConsentTx withdraws the consent of a user to a client together with the refresh tokens of the client,
+// access tokens already issued are rejected by their consent check
+func (store *SQLStore) RevokeOAuthConsentTx(ctx context.Context, arg RevokeOAuthConsentParams) (RevokeOAuthConsentTxResponse, error) {
+	var response RevokeOAuthConsentTxResponse
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		response.Consent, err = q.RevokeOAuthConsent(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		return q.RevokeOAuthRefreshTokens(ctx, RevokeOAuthRefreshTokensParams{
+			Username: arg.Username,
+			ClientID: arg.ClientID,
+		})
+	})
+	return response, err
+}
+
+// checkOAuthUser rejects grants of deleted users and grants issued before the user revoked every token,
+// e.g. by changing the password, like authMiddleware does for access tokens
+func checkOAuthUser(ctx context.Context, q *Queries, username string, issuedAt time.Time) error {
+	user, err := q.GetUser(ctx, username)
+	if err == sql.ErrNoRows || (err == nil && (user.MarkForDelete || issuedAt.Before(user.TokensValidAfter))) {
+		return ErrOAuthGrantRevoked
+	}
+	return err
+}
+
+// checkOAuthConsent rejects grants issued before the consent was last given, its scopes may have changed since
+func checkOAuthConsent(ctx context.Context, q *Queries, username string, clientID string, issuedAt time.Time) error {
+	consent, err := q.GetOAuthConsent(ctx, GetOAuthConsentParams{
+		Username: username,
+		ClientID: clientID,
+	})
+	if err == sql.ErrNoRows || (err == nil && (consent.IsRevoked || issuedAt.Before(consent.GrantedAt))) {
+		return ErrOAuthConsentRevoked
+	}
+	return err
+}
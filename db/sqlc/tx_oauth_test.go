@@ -0,0 +1,292 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/VL-037/go-bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomOAuthClient(t *testing.T) OauthClient {
+	arg := CreateOAuthClientParams{
+		ClientID:     util.RandomString(16),
+		SecretHash:   sql.NullString{String: util.HashToken(util.RandomString(32)), Valid: true},
+		Name:         util.RandomOwner(),
+		RedirectUris: "https://partner.example.com/callback",
+		Scopes:       "read:accounts",
+	}
+
+	client, err := testQueries.CreateOAuthClient(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.ClientID, client.ClientID)
+	require.Equal(t, arg.SecretHash, client.SecretHash)
+	require.Equal(t, arg.RedirectUris, client.RedirectUris)
+	require.Equal(t, arg.Scopes, client.Scopes)
+	require.False(t, client.IsRevoked)
+
+	return client
+}
+
+func createRandomOAuthCode(t *testing.T, user User, client OauthClient, expiredAt time.Time) OauthAuthorizationCode {
+	_, err := testQueries.UpsertOAuthConsent(context.Background(), UpsertOAuthConsentParams{
+		Username: user.Username,
+		ClientID: client.ClientID,
+		Scopes:   client.Scopes,
+	})
+	require.NoError(t, err)
+
+	code, err := testQueries.CreateOAuthAuthorizationCode(context.Background(), CreateOAuthAuthorizationCodeParams{
+		CodeHash:      util.HashToken(util.RandomString(32)),
+		ClientID:      client.ClientID,
+		Username:      user.Username,
+		RedirectUri:   client.RedirectUris,
+		Scopes:        client.Scopes,
+		CodeChallenge: util.RandomString(43),
+		ExpiredAt:     expiredAt,
+	})
+	require.NoError(t, err)
+	require.False(t, code.UsedAt.Valid)
+
+	return code
+}
+
+func TestUpsertOAuthConsent(t *testing.T) {
+	user := createRandomUser(t)
+	client := createRandomOAuthClient(t)
+	arg := UpsertOAuthConsentParams{
+		Username: user.Username,
+		ClientID: client.ClientID,
+		Scopes:   client.Scopes,
+	}
+
+	consent1, err := testQueries.UpsertOAuthConsent(context.Background(), arg)
+	require.NoError(t, err)
+
+	// granting again keeps the grant time so issued tokens stay valid
+	consent2, err := testQueries.UpsertOAuthConsent(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, consent1.ID, consent2.ID)
+	require.Equal(t, consent1.GrantedAt, consent2.GrantedAt)
+
+	// granting other scopes starts over so tokens of the previous scopes stop working
+	arg.Scopes = "read:accounts write:transfers"
+	consent2, err = testQueries.UpsertOAuthConsent(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Scopes, consent2.Scopes)
+	require.True(t, consent2.GrantedAt.After(consent1.GrantedAt))
+
+	_, err = testQueries.RevokeOAuthConsent(context.Background(), RevokeOAuthConsentParams{
+		Username: user.Username,
+		ClientID: client.ClientID,
+	})
+	require.NoError(t, err)
+
+	// granting after a revocation starts over
+	consent3, err := testQueries.UpsertOAuthConsent(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, consent1.ID, consent3.ID)
+	require.False(t, consent3.IsRevoked)
+	require.True(t, consent3.GrantedAt.After(consent2.GrantedAt))
+}
+
+func TestUpsertOAuthConsentTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	user := createRandomUser(t)
+	client := createRandomOAuthClient(t)
+	code := createRandomOAuthCode(t, user, client, time.Now().Add(time.Minute))
+	otherCode := createRandomOAuthCode(t, user, client, time.Now().Add(time.Minute))
+
+	exchanged, err := store.ExchangeOAuthCodeTx(context.Background(), ExchangeOAuthCodeTxParams{
+		CodeID:                code.ID,
+		RefreshTokenHash:      util.HashToken(util.RandomString(32)),
+		RefreshTokenExpiredAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	arg := UpsertOAuthConsentParams{
+		Username: user.Username,
+		ClientID: client.ClientID,
+		Scopes:   client.Scopes,
+	}
+
+	// the same scopes keep the grant
+	_, err = store.UpsertOAuthConsentTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	refreshToken, err := testQueries.GetOAuthRefreshTokenByHash(context.Background(), exchanged.RefreshToken.TokenHash)
+	require.NoError(t, err)
+	require.False(t, refreshToken.IsRevoked)
+
+	// narrower scopes revoke what was issued under the wider ones
+	arg.Scopes = "read:transfers"
+	response, err := store.UpsertOAuthConsentTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Scopes, response.Consent.Scopes)
+
+	refreshToken, err = testQueries.GetOAuthRefreshTokenByHash(context.Background(), exchanged.RefreshToken.TokenHash)
+	require.NoError(t, err)
+	require.True(t, refreshToken.IsRevoked)
+
+	_, err = store.ExchangeOAuthCodeTx(context.Background(), ExchangeOAuthCodeTxParams{
+		CodeID:                otherCode.ID,
+		RefreshTokenHash:      util.HashToken(util.RandomString(32)),
+		RefreshTokenExpiredAt: time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, ErrOAuthConsentRevoked)
+}
+
+func TestExchangeOAuthCodeTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	user := createRandomUser(t)
+	client := createRandomOAuthClient(t)
+	code := createRandomOAuthCode(t, user, client, time.Now().Add(time.Minute))
+
+	arg := ExchangeOAuthCodeTxParams{
+		CodeID:                code.ID,
+		RefreshTokenHash:      util.HashToken(util.RandomString(32)),
+		RefreshTokenExpiredAt: time.Now().Add(time.Hour),
+	}
+	response, err := store.ExchangeOAuthCodeTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.True(t, response.AuthorizationCode.UsedAt.Valid)
+
+	refreshToken := response.RefreshToken
+	require.Equal(t, arg.RefreshTokenHash, refreshToken.TokenHash)
+	require.Equal(t, user.Username, refreshToken.Username)
+	require.Equal(t, client.ClientID, refreshToken.ClientID)
+	require.Equal(t, code.Scopes, refreshToken.Scopes)
+
+	// a code is used once
+	arg.RefreshTokenHash = util.HashToken(util.RandomString(32))
+	_, err = store.ExchangeOAuthCodeTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrOAuthGrantUsed)
+
+	expiredCode := createRandomOAuthCode(t, user, client, time.Now().Add(-time.Second))
+	arg.CodeID = expiredCode.ID
+	_, err = store.ExchangeOAuthCodeTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrOAuthGrantUsed)
+}
+
+func TestRotateOAuthRefreshTokenTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	user := createRandomUser(t)
+	client := createRandomOAuthClient(t)
+	code := createRandomOAuthCode(t, user, client, time.Now().Add(time.Minute))
+
+	exchanged, err := store.ExchangeOAuthCodeTx(context.Background(), ExchangeOAuthCodeTxParams{
+		CodeID:                code.ID,
+		RefreshTokenHash:      util.HashToken(util.RandomString(32)),
+		RefreshTokenExpiredAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	arg := RotateOAuthRefreshTokenTxParams{
+		ID:        exchanged.RefreshToken.ID,
+		TokenHash: util.HashToken(util.RandomString(32)),
+		ExpiredAt: time.Now().Add(time.Hour),
+	}
+	response, err := store.RotateOAuthRefreshTokenTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.True(t, response.RotatedRefreshToken.RotatedAt.Valid)
+	require.Equal(t, arg.TokenHash, response.RefreshToken.TokenHash)
+	require.Equal(t, exchanged.RefreshToken.Scopes, response.RefreshToken.Scopes)
+	require.False(t, response.RefreshToken.RotatedAt.Valid)
+
+	// a rotated token cannot be rotated again
+	arg.TokenHash = util.HashToken(util.RandomString(32))
+	_, err = store.RotateOAuthRefreshTokenTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrOAuthGrantUsed)
+
+	_, err = store.RevokeOAuthConsentTx(context.Background(), RevokeOAuthConsentParams{
+		Username: user.Username,
+		ClientID: client.ClientID,
+	})
+	require.NoError(t, err)
+
+	// the refresh tokens of a revoked consent are revoked with it
+	refreshToken, err := testQueries.GetOAuthRefreshTokenByHash(context.Background(), response.RefreshToken.TokenHash)
+	require.NoError(t, err)
+	require.True(t, refreshToken.IsRevoked)
+
+	arg.ID = refreshToken.ID
+	_, err = store.RotateOAuthRefreshTokenTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrOAuthGrantUsed)
+}
+
+func TestRotateOAuthRefreshTokenTxRevokedByUser(t *testing.T) {
+	store := NewStore(testDB)
+
+	client := createRandomOAuthClient(t)
+	exchange := func(user User) OauthRefreshToken {
+		code := createRandomOAuthCode(t, user, client, time.Now().Add(time.Minute))
+		exchanged, err := store.ExchangeOAuthCodeTx(context.Background(), ExchangeOAuthCodeTxParams{
+			CodeID:                code.ID,
+			RefreshTokenHash:      util.HashToken(util.RandomString(32)),
+			RefreshTokenExpiredAt: time.Now().Add(time.Hour),
+		})
+		require.NoError(t, err)
+		return exchanged.RefreshToken
+	}
+	rotate := func(refreshToken OauthRefreshToken) error {
+		_, err := store.RotateOAuthRefreshTokenTx(context.Background(), RotateOAuthRefreshTokenTxParams{
+			ID:        refreshToken.ID,
+			TokenHash: util.HashToken(util.RandomString(32)),
+			ExpiredAt: time.Now().Add(time.Hour),
+		})
+		return err
+	}
+
+	// a password change revokes the refresh tokens issued before it
+	user := createRandomUser(t)
+	refreshToken := exchange(user)
+	_, err := testQueries.UpdateUserPassword(context.Background(), UpdateUserPasswordParams{
+		Username:       user.Username,
+		HashedPassword: randomHashedPassword(t),
+	})
+	require.NoError(t, err)
+	require.ErrorIs(t, rotate(refreshToken), ErrOAuthGrantRevoked)
+
+	// and a deleted user keeps no grant
+	deletedUser := createRandomUser(t)
+	refreshToken = exchange(deletedUser)
+	_, err = testQueries.DeleteUser(context.Background(), deletedUser.Username)
+	require.NoError(t, err)
+	require.ErrorIs(t, rotate(refreshToken), ErrOAuthGrantRevoked)
+}
+
+func TestRevokeOAuthConsentTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	user := createRandomUser(t)
+	client := createRandomOAuthClient(t)
+	code := createRandomOAuthCode(t, user, client, time.Now().Add(time.Minute))
+
+	arg := RevokeOAuthConsentParams{
+		Username: user.Username,
+		ClientID: client.ClientID,
+	}
+	response, err := store.RevokeOAuthConsentTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.True(t, response.Consent.IsRevoked)
+
+	_, err = store.RevokeOAuthConsentTx(context.Background(), arg)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// codes issued before the revocation cannot be exchanged
+	_, err = store.ExchangeOAuthCodeTx(context.Background(), ExchangeOAuthCodeTxParams{
+		CodeID:                code.ID,
+		RefreshTokenHash:      util.HashToken(util.RandomString(32)),
+		RefreshTokenExpiredAt: time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, ErrOAuthConsentRevoked)
+
+	consents, err := testQueries.ListOAuthConsents(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Empty(t, consents)
+}
@@ -0,0 +1,91 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+var ErrInvalidPasswordReset = errors.New("password reset is invalid, used or expired")
+
+type ResetPasswordTxParams struct {
+	ID             int64  `json:"id"`
+	TokenHash      string `json:"token_hash"`
+	HashedPassword string `json:"hashed_password"`
+}
+
+type ResetPasswordTxResponse struct {
+	PasswordReset PasswordReset `json:"password_reset"`
+	User          User          `json:"user"`
+}
+
+// ResetPasswordTx uses a password reset to set a new password, the other resets of the user
+// are invalidated and every access token, API key and OAuth refresh token issued before is revoked
+func (store *SQLStore) ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams) (ResetPasswordTxResponse, error) {
+	var response ResetPasswordTxResponse
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		response.PasswordReset, err = q.UsePasswordReset(ctx, UsePasswordResetParams{
+			ID:        arg.ID,
+			TokenHash: arg.TokenHash,
+		})
+		if err == sql.ErrNoRows {
+			return ErrInvalidPasswordReset
+		}
+		if err != nil {
+			return err
+		}
+
+		err = q.InvalidatePasswordResets(ctx, response.PasswordReset.Username)
+		if err != nil {
+			return err
+		}
+
+		response.User, err = setUserPassword(ctx, q, response.PasswordReset.Username, arg.HashedPassword)
+		return err
+	})
+	return response, err
+}
+
+type ChangePasswordTxParams struct {
+	Username       string `json:"username"`
+	HashedPassword string `json:"hashed_password"`
+}
+
+type ChangePasswordTxResponse struct {
+	User User `json:"user"`
+}
+
+// ChangePasswordTx sets a new password the user chose knowing the current one,
+// every access token, API key and OAuth refresh token issued before is revoked like on a reset
+func (store *SQLStore) ChangePasswordTx(ctx context.Context, arg ChangePasswordTxParams) (ChangePasswordTxResponse, error) {
+	var response ChangePasswordTxResponse
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		response.User, err = setUserPassword(ctx, q, arg.Username, arg.HashedPassword)
+		return err
+	})
+	return response, err
+}
+
+// setUserPassword updates the password and revokes the credentials issued before it
+func setUserPassword(ctx context.Context, q *Queries, username string, hashedPassword string) (User, error) {
+	user, err := q.UpdateUserPassword(ctx, UpdateUserPasswordParams{
+		Username:       username,
+		HashedPassword: hashedPassword,
+	})
+	if err != nil {
+		return user, err
+	}
+
+	// whoever knew the old password may have created long lived credentials with it
+	err = q.RevokeUserAPIKeys(ctx, user.Username)
+	if err != nil {
+		return user, err
+	}
+
+	return user, q.RevokeUserOAuthRefreshTokens(ctx, user.Username)
+}
@@ -0,0 +1,138 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/VL-037/go-bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomPasswordReset(t *testing.T, user User, token string, expiredAt time.Time) PasswordReset {
+	passwordReset, err := testQueries.CreatePasswordReset(context.Background(), CreatePasswordResetParams{
+		Username:  user.Username,
+		TokenHash: util.HashToken(token),
+		ExpiredAt: expiredAt,
+	})
+	require.NoError(t, err)
+	require.Equal(t, user.Username, passwordReset.Username)
+	require.False(t, passwordReset.IsUsed)
+
+	return passwordReset
+}
+
+func TestResetPasswordTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	user := createRandomUser(t)
+	token := util.RandomString(32)
+	passwordReset := createRandomPasswordReset(t, user, token, time.Now().Add(time.Hour))
+	otherToken := util.RandomString(32)
+	otherPasswordReset := createRandomPasswordReset(t, user, otherToken, time.Now().Add(time.Hour))
+
+	hashedPassword := randomHashedPassword(t)
+
+	_, err := store.ResetPasswordTx(context.Background(), ResetPasswordTxParams{
+		ID:             passwordReset.ID,
+		TokenHash:      util.HashToken(otherToken),
+		HashedPassword: hashedPassword,
+	})
+	require.ErrorIs(t, err, ErrInvalidPasswordReset)
+
+	arg := ResetPasswordTxParams{
+		ID:             passwordReset.ID,
+		TokenHash:      util.HashToken(token),
+		HashedPassword: hashedPassword,
+	}
+
+	apiKey := createRandomAPIKey(t, user.Username)
+	client := createRandomOAuthClient(t)
+	code := createRandomOAuthCode(t, user, client, time.Now().Add(time.Minute))
+	exchanged, err := store.ExchangeOAuthCodeTx(context.Background(), ExchangeOAuthCodeTxParams{
+		CodeID:                code.ID,
+		RefreshTokenHash:      util.HashToken(util.RandomString(32)),
+		RefreshTokenExpiredAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	response, err := store.ResetPasswordTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.True(t, response.PasswordReset.IsUsed)
+	require.Equal(t, hashedPassword, response.User.HashedPassword)
+
+	// long lived credentials do not survive the reset
+	revokedAPIKey, err := testQueries.GetAPIKeyByHash(context.Background(), apiKey.KeyHash)
+	require.NoError(t, err)
+	require.True(t, revokedAPIKey.IsRevoked)
+
+	refreshToken, err := testQueries.GetOAuthRefreshTokenByHash(context.Background(), exchanged.RefreshToken.TokenHash)
+	require.NoError(t, err)
+	require.True(t, refreshToken.IsRevoked)
+
+	authState, err := testQueries.GetUserAuthState(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.WithinDuration(t, response.User.PasswordUpdatedAt, authState.TokensValidAfter, time.Millisecond)
+
+	// both the used reset and the other outstanding one are spent
+	_, err = store.ResetPasswordTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrInvalidPasswordReset)
+
+	_, err = store.ResetPasswordTx(context.Background(), ResetPasswordTxParams{
+		ID:             otherPasswordReset.ID,
+		TokenHash:      util.HashToken(otherToken),
+		HashedPassword: hashedPassword,
+	})
+	require.ErrorIs(t, err, ErrInvalidPasswordReset)
+}
+
+func TestResetPasswordTxExpired(t *testing.T) {
+	store := NewStore(testDB)
+
+	user := createRandomUser(t)
+	token := util.RandomString(32)
+	passwordReset := createRandomPasswordReset(t, user, token, time.Now().Add(-time.Minute))
+
+	_, err := store.ResetPasswordTx(context.Background(), ResetPasswordTxParams{
+		ID:             passwordReset.ID,
+		TokenHash:      util.HashToken(token),
+		HashedPassword: user.HashedPassword,
+	})
+	require.ErrorIs(t, err, ErrInvalidPasswordReset)
+}
+
+func TestChangePasswordTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	user := createRandomUser(t)
+	apiKey := createRandomAPIKey(t, user.Username)
+	client := createRandomOAuthClient(t)
+	code := createRandomOAuthCode(t, user, client, time.Now().Add(time.Minute))
+	exchanged, err := store.ExchangeOAuthCodeTx(context.Background(), ExchangeOAuthCodeTxParams{
+		CodeID:                code.ID,
+		RefreshTokenHash:      util.HashToken(util.RandomString(32)),
+		RefreshTokenExpiredAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	hashedPassword := randomHashedPassword(t)
+	response, err := store.ChangePasswordTx(context.Background(), ChangePasswordTxParams{
+		Username:       user.Username,
+		HashedPassword: hashedPassword,
+	})
+	require.NoError(t, err)
+	require.Equal(t, hashedPassword, response.User.HashedPassword)
+
+	// long lived credentials do not survive the change
+	revokedAPIKey, err := testQueries.GetAPIKeyByHash(context.Background(), apiKey.KeyHash)
+	require.NoError(t, err)
+	require.True(t, revokedAPIKey.IsRevoked)
+
+	refreshToken, err := testQueries.GetOAuthRefreshTokenByHash(context.Background(), exchanged.RefreshToken.TokenHash)
+	require.NoError(t, err)
+	require.True(t, refreshToken.IsRevoked)
+
+	authState, err := testQueries.GetUserAuthState(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.True(t, response.User.PasswordUpdatedAt.Equal(authState.TokensValidAfter))
+}
@@ -0,0 +1,71 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// Statuses of a payment request
+const (
+	PAYMENT_REQUEST_STATUS_PENDING  = "pending"
+	PAYMENT_REQUEST_STATUS_ACCEPTED = "accepted"
+	PAYMENT_REQUEST_STATUS_DECLINED = "declined"
+	PAYMENT_REQUEST_STATUS_EXPIRED  = "expired"
+)
+
+var ErrPaymentRequestNotPending = errors.New("payment request is not pending")
+
+type AcceptPaymentRequestTxParams struct {
+	PaymentRequestID int64 `json:"payment_request_id"`
+	FromAccountID    int64 `json:"from_account_id"`
+}
+
+type AcceptPaymentRequestTxResponse struct {
+	PaymentRequest PaymentRequest     `json:"payment_request"`
+	Transfer       TransferTxResponse `json:"transfer"`
+}
+
+// AcceptPaymentRequestTx pays a pending payment request from the account chosen by the payer
+func (store *SQLStore) AcceptPaymentRequestTx(ctx context.Context, arg AcceptPaymentRequestTxParams) (AcceptPaymentRequestTxResponse, error) {
+	var response AcceptPaymentRequestTxResponse
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		paymentRequest, err := q.GetPaymentRequestForUpdate(ctx, arg.PaymentRequestID)
+		if err != nil {
+			return err
+		}
+
+		if paymentRequest.Status != PAYMENT_REQUEST_STATUS_PENDING || !paymentRequest.ExpiresAt.After(time.Now()) {
+			return ErrPaymentRequestNotPending
+		}
+
+		fromAccount, err := q.GetAccountForUpdate(ctx, arg.FromAccountID)
+		if err != nil {
+			return err
+		}
+
+		if fromAccount.AvailableBalance < paymentRequest.Amount {
+			return ErrInsufficientFunds
+		}
+
+		response.Transfer, err = transfer(ctx, q, TransferTxParams{
+			FromAccountID: arg.FromAccountID,
+			ToAccountID:   paymentRequest.ToAccountID,
+			Amount:        paymentRequest.Amount,
+			Description:   paymentRequest.Note.String,
+		})
+		if err != nil {
+			return err
+		}
+
+		response.PaymentRequest, err = q.AcceptPaymentRequest(ctx, AcceptPaymentRequestParams{
+			ID:            paymentRequest.ID,
+			FromAccountID: sql.NullInt64{Int64: arg.FromAccountID, Valid: true},
+			TransferID:    sql.NullInt64{Int64: response.Transfer.Transfer.ID, Valid: true},
+		})
+		return err
+	})
+	return response, err
+}
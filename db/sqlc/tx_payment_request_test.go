@@ -0,0 +1,52 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcceptPaymentRequestTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	toAccount := createRandomAccount(t)
+	fromAccount := createRandomAccount(t)
+	fromAccount, err := testQueries.UpdateAccount(context.Background(), UpdateAccountParams{ID: fromAccount.ID, Balance: 100})
+	require.NoError(t, err)
+
+	paymentRequest := createRandomPaymentRequest(t, toAccount, fromAccount.Owner, 60, time.Now().Add(time.Hour))
+
+	arg := AcceptPaymentRequestTxParams{
+		PaymentRequestID: paymentRequest.ID,
+		FromAccountID:    fromAccount.ID,
+	}
+
+	response, err := store.AcceptPaymentRequestTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, PAYMENT_REQUEST_STATUS_ACCEPTED, response.PaymentRequest.Status)
+	require.Equal(t, fromAccount.ID, response.PaymentRequest.FromAccountID.Int64)
+	require.Equal(t, response.Transfer.Transfer.ID, response.PaymentRequest.TransferID.Int64)
+	require.Equal(t, paymentRequest.Note, response.Transfer.Transfer.Description)
+	require.Equal(t, int64(40), response.Transfer.FromAccount.Balance)
+	require.Equal(t, toAccount.Balance+60, response.Transfer.ToAccount.Balance)
+
+	_, err = store.AcceptPaymentRequestTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrPaymentRequestNotPending)
+}
+
+func TestAcceptPaymentRequestTxExpired(t *testing.T) {
+	store := NewStore(testDB)
+
+	toAccount := createRandomAccount(t)
+	fromAccount := createRandomAccount(t)
+
+	paymentRequest := createRandomPaymentRequest(t, toAccount, fromAccount.Owner, 1, time.Now().Add(-time.Minute))
+
+	_, err := store.AcceptPaymentRequestTx(context.Background(), AcceptPaymentRequestTxParams{
+		PaymentRequestID: paymentRequest.ID,
+		FromAccountID:    fromAccount.ID,
+	})
+	require.ErrorIs(t, err, ErrPaymentRequestNotPending)
+}
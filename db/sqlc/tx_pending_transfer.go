@@ -0,0 +1,127 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// Statuses of a pending transfer, also used as the decision of a transfer approval
+const (
+	PENDING_TRANSFER_STATUS_PENDING_APPROVAL = "pending_approval"
+	PENDING_TRANSFER_STATUS_APPROVED         = "approved"
+	PENDING_TRANSFER_STATUS_REJECTED         = "rejected"
+)
+
+// Different types of error returned by the approval transactions
+var (
+	ErrTransferNotPending = errors.New("transfer is not pending approval")
+	ErrSelfApproval       = errors.New("transfer cannot be approved by its requester")
+)
+
+type DecideTransferTxParams struct {
+	PendingTransferID int64  `json:"pending_transfer_id"`
+	Approver          string `json:"approver"`
+	Comment           string `json:"comment"`
+}
+
+type DecideTransferTxResponse struct {
+	PendingTransfer PendingTransfer    `json:"pending_transfer"`
+	Approval        TransferApproval   `json:"approval"`
+	Transfer        TransferTxResponse `json:"transfer"`
+}
+
+// ApproveTransferTx moves the funds of a pending transfer and records who approved it.
+// The requester of the transfer cannot approve it
+func (store *SQLStore) ApproveTransferTx(ctx context.Context, arg DecideTransferTxParams) (DecideTransferTxResponse, error) {
+	var response DecideTransferTxResponse
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		pendingTransfer, err := pendingTransferForDecision(ctx, q, arg.PendingTransferID)
+		if err != nil {
+			return err
+		}
+
+		if pendingTransfer.RequestedBy == arg.Approver {
+			return ErrSelfApproval
+		}
+
+		fromAccount, err := q.GetAccountForUpdate(ctx, pendingTransfer.FromAccountID)
+		if err != nil {
+			return err
+		}
+
+		if fromAccount.AvailableBalance < pendingTransfer.Amount {
+			return ErrInsufficientFunds
+		}
+
+		response.Transfer, err = transfer(ctx, q, TransferTxParams{
+			FromAccountID:   pendingTransfer.FromAccountID,
+			ToAccountID:     pendingTransfer.ToAccountID,
+			Amount:          pendingTransfer.Amount,
+			Description:     pendingTransfer.Description.String,
+			ClientReference: pendingTransfer.ClientReference.String,
+			Metadata:        pendingTransfer.Metadata,
+		})
+		if err != nil {
+			return err
+		}
+
+		response.PendingTransfer, response.Approval, err = decideTransfer(ctx, q, pendingTransfer, arg, PENDING_TRANSFER_STATUS_APPROVED, sql.NullInt64{
+			Int64: response.Transfer.Transfer.ID,
+			Valid: true,
+		})
+		return err
+	})
+	return response, err
+}
+
+// RejectTransferTx closes a pending transfer without moving any money and records who rejected it
+func (store *SQLStore) RejectTransferTx(ctx context.Context, arg DecideTransferTxParams) (DecideTransferTxResponse, error) {
+	var response DecideTransferTxResponse
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		pendingTransfer, err := pendingTransferForDecision(ctx, q, arg.PendingTransferID)
+		if err != nil {
+			return err
+		}
+
+		response.PendingTransfer, response.Approval, err = decideTransfer(ctx, q, pendingTransfer, arg, PENDING_TRANSFER_STATUS_REJECTED, sql.NullInt64{})
+		return err
+	})
+	return response, err
+}
+
+// pendingTransferForDecision locks a pending transfer and makes sure it is still waiting for a decision
+func pendingTransferForDecision(ctx context.Context, q *Queries, id int64) (PendingTransfer, error) {
+	pendingTransfer, err := q.GetPendingTransferForUpdate(ctx, id)
+	if err != nil {
+		return pendingTransfer, err
+	}
+
+	if pendingTransfer.Status != PENDING_TRANSFER_STATUS_PENDING_APPROVAL {
+		return pendingTransfer, ErrTransferNotPending
+	}
+
+	return pendingTransfer, nil
+}
+
+// decideTransfer updates the status of a pending transfer and appends the decision to its approval history
+func decideTransfer(ctx context.Context, q *Queries, pendingTransfer PendingTransfer, arg DecideTransferTxParams, decision string, transferID sql.NullInt64) (PendingTransfer, TransferApproval, error) {
+	pendingTransfer, err := q.UpdatePendingTransferStatus(ctx, UpdatePendingTransferStatusParams{
+		ID:         pendingTransfer.ID,
+		Status:     decision,
+		TransferID: transferID,
+	})
+	if err != nil {
+		return pendingTransfer, TransferApproval{}, err
+	}
+
+	approval, err := q.CreateTransferApproval(ctx, CreateTransferApprovalParams{
+		PendingTransferID: pendingTransfer.ID,
+		Approver:          arg.Approver,
+		Decision:          decision,
+		Comment:           sql.NullString{String: arg.Comment, Valid: arg.Comment != ""},
+	})
+	return pendingTransfer, approval, err
+}
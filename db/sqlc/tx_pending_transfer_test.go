@@ -0,0 +1,94 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestApproveTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	approver := createRandomUser(t)
+	account1, err := testQueries.UpdateAccount(context.Background(), UpdateAccountParams{ID: account1.ID, Balance: 100})
+	require.NoError(t, err)
+
+	pendingTransfer := createRandomPendingTransfer(t, account1, account2, 60)
+
+	_, err = store.ApproveTransferTx(context.Background(), DecideTransferTxParams{
+		PendingTransferID: pendingTransfer.ID,
+		Approver:          account1.Owner,
+	})
+	require.ErrorIs(t, err, ErrSelfApproval)
+
+	response, err := store.ApproveTransferTx(context.Background(), DecideTransferTxParams{
+		PendingTransferID: pendingTransfer.ID,
+		Approver:          approver.Username,
+		Comment:           "verified",
+	})
+	require.NoError(t, err)
+	require.Equal(t, PENDING_TRANSFER_STATUS_APPROVED, response.PendingTransfer.Status)
+	require.Equal(t, response.Transfer.Transfer.ID, response.PendingTransfer.TransferID.Int64)
+	require.Equal(t, int64(40), response.Transfer.FromAccount.Balance)
+
+	require.Equal(t, approver.Username, response.Approval.Approver)
+	require.Equal(t, PENDING_TRANSFER_STATUS_APPROVED, response.Approval.Decision)
+	require.Equal(t, "verified", response.Approval.Comment.String)
+
+	_, err = store.RejectTransferTx(context.Background(), DecideTransferTxParams{
+		PendingTransferID: pendingTransfer.ID,
+		Approver:          approver.Username,
+	})
+	require.ErrorIs(t, err, ErrTransferNotPending)
+
+	approvals, err := testQueries.ListTransferApprovals(context.Background(), pendingTransfer.ID)
+	require.NoError(t, err)
+	require.Len(t, approvals, 1)
+}
+
+func TestApproveTransferTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	approver := createRandomUser(t)
+
+	pendingTransfer := createRandomPendingTransfer(t, account1, account2, account1.AvailableBalance+1)
+
+	_, err := store.ApproveTransferTx(context.Background(), DecideTransferTxParams{
+		PendingTransferID: pendingTransfer.ID,
+		Approver:          approver.Username,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	pendingTransfer, err = testQueries.GetPendingTransfer(context.Background(), pendingTransfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, PENDING_TRANSFER_STATUS_PENDING_APPROVAL, pendingTransfer.Status)
+}
+
+func TestRejectTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	approver := createRandomUser(t)
+
+	pendingTransfer := createRandomPendingTransfer(t, account1, account2, 10)
+
+	response, err := store.RejectTransferTx(context.Background(), DecideTransferTxParams{
+		PendingTransferID: pendingTransfer.ID,
+		Approver:          approver.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, PENDING_TRANSFER_STATUS_REJECTED, response.PendingTransfer.Status)
+	require.False(t, response.PendingTransfer.TransferID.Valid)
+	require.Equal(t, PENDING_TRANSFER_STATUS_REJECTED, response.Approval.Decision)
+	require.False(t, response.Approval.Comment.Valid)
+
+	account1After, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, account1After.Balance)
+}
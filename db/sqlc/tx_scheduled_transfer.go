@@ -0,0 +1,130 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Statuses of a scheduled transfer
+const (
+	SCHEDULED_TRANSFER_STATUS_PENDING   = "pending"
+	SCHEDULED_TRANSFER_STATUS_EXECUTED  = "executed"
+	SCHEDULED_TRANSFER_STATUS_FAILED    = "failed"
+	SCHEDULED_TRANSFER_STATUS_CANCELLED = "cancelled"
+)
+
+// Reasons a scheduled payment is not executed
+const (
+	INSUFFICIENT_FUNDS_REASON = "insufficient funds"
+	APPROVAL_REQUIRED_REASON  = "amount requires approval"
+	ACCOUNT_CLOSED_REASON     = "account is closed"
+	CURRENCY_MISMATCH_REASON  = "account currency does not match"
+)
+
+type ExecuteScheduledTransferTxParams struct {
+	// ApprovalThresholds are the amounts per currency above which a transfer needs a second approver
+	ApprovalThresholds map[string]int64 `json:"approval_thresholds"`
+}
+
+type ExecuteScheduledTransferTxResponse struct {
+	ScheduledTransfer ScheduledTransfer  `json:"scheduled_transfer"`
+	Transfer          TransferTxResponse `json:"transfer"`
+}
+
+// ExecuteScheduledTransferTx claims the next due scheduled transfer and executes it within a single DB transaction
+// Rows locked by other executors are skipped, so several workers can run concurrently.
+// A transfer that cannot succeed, for want of funds, a closed account or a currency mismatch, is rolled back
+// and the scheduled transfer is marked failed, so it does not block the queue.
+// Amounts above the approval threshold are never executed unattended and fail as well.
+// Any other error, like a deadlock or a cancelled context, is returned with the scheduled transfer left pending
+// for the next run. It returns sql.ErrNoRows when there is no due scheduled transfer left
+func (store *SQLStore) ExecuteScheduledTransferTx(ctx context.Context, arg ExecuteScheduledTransferTxParams) (ExecuteScheduledTransferTxResponse, error) {
+	var response ExecuteScheduledTransferTxResponse
+	var transferErr error
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		response.ScheduledTransfer, err = q.GetNextDueScheduledTransferForUpdate(ctx)
+		if err != nil {
+			return err
+		}
+
+		scheduledTransfer := response.ScheduledTransfer
+		if requiresApproval(arg.ApprovalThresholds, scheduledTransfer.Currency, scheduledTransfer.Amount) {
+			response.ScheduledTransfer, err = q.MarkScheduledTransferFailed(ctx, MarkScheduledTransferFailedParams{
+				ID:            scheduledTransfer.ID,
+				FailureReason: sql.NullString{String: APPROVAL_REQUIRED_REASON, Valid: true},
+			})
+			return err
+		}
+
+		fromAccount, err := q.GetAccountForUpdate(ctx, response.ScheduledTransfer.FromAccountID)
+		if err != nil {
+			return err
+		}
+
+		toAccount, err := q.GetAccount(ctx, response.ScheduledTransfer.ToAccountID)
+		if err != nil {
+			return err
+		}
+
+		if fromAccount.Currency != scheduledTransfer.Currency || toAccount.Currency != scheduledTransfer.Currency {
+			response.ScheduledTransfer, err = q.MarkScheduledTransferFailed(ctx, MarkScheduledTransferFailedParams{
+				ID:            scheduledTransfer.ID,
+				FailureReason: sql.NullString{String: CURRENCY_MISMATCH_REASON, Valid: true},
+			})
+			return err
+		}
+
+		if fromAccount.AvailableBalance < response.ScheduledTransfer.Amount {
+			response.ScheduledTransfer, err = q.MarkScheduledTransferFailed(ctx, MarkScheduledTransferFailedParams{
+				ID:            response.ScheduledTransfer.ID,
+				FailureReason: sql.NullString{String: INSUFFICIENT_FUNDS_REASON, Valid: true},
+			})
+			return err
+		}
+
+		response.Transfer, err = transfer(ctx, q, TransferTxParams{
+			FromAccountID: response.ScheduledTransfer.FromAccountID,
+			ToAccountID:   response.ScheduledTransfer.ToAccountID,
+			Amount:        response.ScheduledTransfer.Amount,
+		})
+		if err != nil {
+			transferErr = err
+			return err
+		}
+
+		response.ScheduledTransfer, err = q.MarkScheduledTransferExecuted(ctx, MarkScheduledTransferExecutedParams{
+			ID:         response.ScheduledTransfer.ID,
+			TransferID: sql.NullInt64{Int64: response.Transfer.Transfer.ID, Valid: true},
+		})
+		return err
+	})
+	if reason, failed := transferFailureReason(transferErr); failed {
+		// the failed transfer was rolled back, so only the failure is recorded
+		response.Transfer = TransferTxResponse{}
+		response.ScheduledTransfer, err = store.MarkScheduledTransferFailed(ctx, MarkScheduledTransferFailedParams{
+			ID:            response.ScheduledTransfer.ID,
+			FailureReason: sql.NullString{String: reason, Valid: true},
+		})
+	}
+	return response, err
+}
+
+// transferFailureReason gives the reason to record for a transfer which cannot succeed on a later run,
+// other errors are transient and not a reason to fail
+func transferFailureReason(err error) (string, bool) {
+	switch err {
+	case ErrInsufficientFunds:
+		return INSUFFICIENT_FUNDS_REASON, true
+	case ErrAccountClosed:
+		return ACCOUNT_CLOSED_REASON, true
+	}
+	return "", false
+}
+
+// requiresApproval reports whether an amount is above the approval threshold of its currency
+func requiresApproval(thresholds map[string]int64, currency string, amount int64) bool {
+	threshold, ok := thresholds[currency]
+	return ok && amount > threshold
+}
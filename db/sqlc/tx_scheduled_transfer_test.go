@@ -0,0 +1,195 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/VL-037/go-bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecuteScheduledTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+
+	account1, err := testQueries.UpdateAccount(context.Background(), UpdateAccountParams{
+		ID:      account1.ID,
+		Balance: 1_000_000,
+	})
+	require.NoError(t, err)
+
+	createRandomScheduledTransfer(t, account1, account2, time.Now().Add(-time.Minute))
+
+	// other tests may leave due scheduled transfers behind, so drain the queue until ours is executed
+	for {
+		response, err := store.ExecuteScheduledTransferTx(context.Background(), ExecuteScheduledTransferTxParams{})
+		require.NoError(t, err)
+
+		if response.ScheduledTransfer.FromAccountID != account1.ID {
+			continue
+		}
+
+		require.Equal(t, SCHEDULED_TRANSFER_STATUS_EXECUTED, response.ScheduledTransfer.Status)
+		require.True(t, response.ScheduledTransfer.TransferID.Valid)
+		require.Equal(t, response.Transfer.Transfer.ID, response.ScheduledTransfer.TransferID.Int64)
+		require.Equal(t, account1.Balance-response.ScheduledTransfer.Amount, response.Transfer.FromAccount.Balance)
+		break
+	}
+}
+
+func TestExecuteScheduledTransferTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+
+	account1, err := testQueries.UpdateAccount(context.Background(), UpdateAccountParams{
+		ID:      account1.ID,
+		Balance: 0,
+	})
+	require.NoError(t, err)
+
+	createRandomScheduledTransfer(t, account1, account2, time.Now().Add(-time.Minute))
+
+	for {
+		response, err := store.ExecuteScheduledTransferTx(context.Background(), ExecuteScheduledTransferTxParams{})
+		require.NoError(t, err)
+
+		if response.ScheduledTransfer.FromAccountID != account1.ID {
+			continue
+		}
+
+		require.Equal(t, SCHEDULED_TRANSFER_STATUS_FAILED, response.ScheduledTransfer.Status)
+		require.Equal(t, INSUFFICIENT_FUNDS_REASON, response.ScheduledTransfer.FailureReason.String)
+		require.Empty(t, response.Transfer.Transfer)
+		break
+	}
+}
+
+func TestExecuteScheduledTransferTxFailedTransfer(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+
+	account1, err := testQueries.UpdateAccount(context.Background(), UpdateAccountParams{
+		ID:      account1.ID,
+		Balance: 1_000_000,
+	})
+	require.NoError(t, err)
+
+	_, err = testQueries.UpdateAccount(context.Background(), UpdateAccountParams{
+		ID:      account2.ID,
+		Balance: 0,
+	})
+	require.NoError(t, err)
+
+	_, err = store.CloseAccountTx(context.Background(), account2.ID)
+	require.NoError(t, err)
+
+	// the transfer to the closed account fails after the scheduled transfer was claimed
+	scheduledTransfer := createRandomScheduledTransfer(t, account1, account2, time.Now().Add(-time.Minute))
+
+	for {
+		response, err := store.ExecuteScheduledTransferTx(context.Background(), ExecuteScheduledTransferTxParams{})
+		require.NoError(t, err)
+
+		if response.ScheduledTransfer.ID != scheduledTransfer.ID {
+			continue
+		}
+
+		require.Equal(t, SCHEDULED_TRANSFER_STATUS_FAILED, response.ScheduledTransfer.Status)
+		require.Equal(t, ACCOUNT_CLOSED_REASON, response.ScheduledTransfer.FailureReason.String)
+		require.Empty(t, response.Transfer.Transfer)
+		break
+	}
+
+	// the failed transfer was rolled back
+	updatedAccount1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, updatedAccount1.Balance)
+}
+
+func TestExecuteScheduledTransferTxRequiresApproval(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+
+	account1, err := testQueries.UpdateAccount(context.Background(), UpdateAccountParams{
+		ID:      account1.ID,
+		Balance: 1_000_000,
+	})
+	require.NoError(t, err)
+
+	scheduledTransfer := createRandomScheduledTransfer(t, account1, account2, time.Now().Add(-time.Minute))
+
+	arg := ExecuteScheduledTransferTxParams{
+		ApprovalThresholds: map[string]int64{account1.Currency: 0},
+	}
+	for {
+		response, err := store.ExecuteScheduledTransferTx(context.Background(), arg)
+		require.NoError(t, err)
+
+		if response.ScheduledTransfer.ID != scheduledTransfer.ID {
+			continue
+		}
+
+		require.Equal(t, SCHEDULED_TRANSFER_STATUS_FAILED, response.ScheduledTransfer.Status)
+		require.Equal(t, APPROVAL_REQUIRED_REASON, response.ScheduledTransfer.FailureReason.String)
+		require.Empty(t, response.Transfer.Transfer)
+		break
+	}
+}
+
+func TestExecuteScheduledTransferTxCurrencyMismatch(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountWithCurrency(t, util.USD)
+	account2 := createRandomAccountWithCurrency(t, util.EUR)
+
+	account1, err := testQueries.UpdateAccount(context.Background(), UpdateAccountParams{
+		ID:      account1.ID,
+		Balance: 1_000_000,
+	})
+	require.NoError(t, err)
+
+	scheduledTransfer := createRandomScheduledTransfer(t, account1, account2, time.Now().Add(-time.Minute))
+
+	for {
+		response, err := store.ExecuteScheduledTransferTx(context.Background(), ExecuteScheduledTransferTxParams{})
+		require.NoError(t, err)
+
+		if response.ScheduledTransfer.ID != scheduledTransfer.ID {
+			continue
+		}
+
+		require.Equal(t, SCHEDULED_TRANSFER_STATUS_FAILED, response.ScheduledTransfer.Status)
+		require.Equal(t, CURRENCY_MISMATCH_REASON, response.ScheduledTransfer.FailureReason.String)
+		require.Empty(t, response.Transfer.Transfer)
+		break
+	}
+}
+
+func TestExecuteScheduledTransferTxCancelled(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+	scheduledTransfer := createRandomScheduledTransfer(t, account1, account2, time.Now().Add(-time.Minute))
+
+	// a transient error is returned and the scheduled transfer stays pending for the next run
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := store.ExecuteScheduledTransferTx(ctx, ExecuteScheduledTransferTxParams{})
+	require.ErrorIs(t, err, context.Canceled)
+
+	savedScheduledTransfer, err := testQueries.GetScheduledTransfer(context.Background(), scheduledTransfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, SCHEDULED_TRANSFER_STATUS_PENDING, savedScheduledTransfer.Status)
+	require.False(t, savedScheduledTransfer.FailureReason.Valid)
+}
@@ -0,0 +1,134 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/VL-037/go-bank/util"
+	"time"
+)
+
+// Statuses of a standing order
+const (
+	STANDING_ORDER_STATUS_ACTIVE    = "active"
+	STANDING_ORDER_STATUS_PAUSED    = "paused"
+	STANDING_ORDER_STATUS_COMPLETED = "completed"
+)
+
+type ExecuteStandingOrderTxParams struct {
+	MaxRetries    int32         `json:"max_retries"`
+	RetryInterval time.Duration `json:"retry_interval"`
+	// ApprovalThresholds are the amounts per currency above which a transfer needs a second approver
+	ApprovalThresholds map[string]int64 `json:"approval_thresholds"`
+}
+
+type ExecuteStandingOrderTxResponse struct {
+	StandingOrder StandingOrder      `json:"standing_order"`
+	Transfer      TransferTxResponse `json:"transfer"`
+	Notification  Notification       `json:"notification"`
+}
+
+// ExecuteStandingOrderTx claims the next due standing order and executes it within a single DB transaction
+// When the from account has insufficient funds the execution is retried after RetryInterval up to MaxRetries times,
+// then the occurrence is skipped. Occurrences above the approval threshold are skipped right away.
+// The account owner is notified of every failure.
+// It returns sql.ErrNoRows when there is no due standing order left
+func (store *SQLStore) ExecuteStandingOrderTx(ctx context.Context, arg ExecuteStandingOrderTxParams) (ExecuteStandingOrderTxResponse, error) {
+	var response ExecuteStandingOrderTxResponse
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		order, err := q.GetNextDueStandingOrderForUpdate(ctx)
+		if err != nil {
+			return err
+		}
+
+		fromAccount, err := q.GetAccountForUpdate(ctx, order.FromAccountID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if requiresApproval(arg.ApprovalThresholds, order.Currency, order.Amount) {
+			state := nextStandingOrderState(order, now, false)
+			state.LastFailureReason = sql.NullString{String: APPROVAL_REQUIRED_REASON, Valid: true}
+			response.StandingOrder, err = q.AdvanceStandingOrder(ctx, state)
+			if err != nil {
+				return err
+			}
+
+			response.Notification, err = q.CreateNotification(ctx, CreateNotificationParams{
+				Username: fromAccount.Owner,
+				Message: fmt.Sprintf("standing order [%d] failed: %s, this occurrence is skipped",
+					order.ID, APPROVAL_REQUIRED_REASON),
+			})
+			return err
+		}
+
+		if fromAccount.AvailableBalance < order.Amount {
+			var message string
+			if order.RetryCount < arg.MaxRetries {
+				retryAt := now.Add(arg.RetryInterval)
+				response.StandingOrder, err = q.RetryStandingOrder(ctx, RetryStandingOrderParams{
+					ID:                order.ID,
+					NextExecutionAt:   retryAt,
+					LastFailureReason: sql.NullString{String: INSUFFICIENT_FUNDS_REASON, Valid: true},
+				})
+				message = fmt.Sprintf("standing order [%d] failed: %s, retrying at %s",
+					order.ID, INSUFFICIENT_FUNDS_REASON, retryAt.Format(time.RFC3339))
+			} else {
+				response.StandingOrder, err = q.AdvanceStandingOrder(ctx, nextStandingOrderState(order, now, false))
+				message = fmt.Sprintf("standing order [%d] failed: %s, this occurrence is skipped",
+					order.ID, INSUFFICIENT_FUNDS_REASON)
+			}
+			if err != nil {
+				return err
+			}
+
+			response.Notification, err = q.CreateNotification(ctx, CreateNotificationParams{
+				Username: fromAccount.Owner,
+				Message:  message,
+			})
+			return err
+		}
+
+		response.Transfer, err = transfer(ctx, q, TransferTxParams{
+			FromAccountID: order.FromAccountID,
+			ToAccountID:   order.ToAccountID,
+			Amount:        order.Amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		response.StandingOrder, err = q.AdvanceStandingOrder(ctx, nextStandingOrderState(order, now, true))
+		return err
+	})
+	return response, err
+}
+
+// nextStandingOrderState moves a standing order to its next occurrence, completing it once its end date or count is reached
+func nextStandingOrderState(order StandingOrder, now time.Time, executed bool) AdvanceStandingOrderParams {
+	arg := AdvanceStandingOrderParams{
+		ID:                order.ID,
+		NextExecutionAt:   util.NextOccurrence(order.Frequency, int(order.DayOfMonth.Int32), order.StartAt, now),
+		Status:            STANDING_ORDER_STATUS_ACTIVE,
+		ExecutedCount:     order.ExecutedCount,
+		LastExecutedAt:    order.LastExecutedAt,
+		LastFailureReason: order.LastFailureReason,
+	}
+
+	if executed {
+		arg.ExecutedCount++
+		arg.LastExecutedAt = sql.NullTime{Time: now, Valid: true}
+		arg.LastFailureReason = sql.NullString{}
+	}
+
+	if order.MaxExecutions.Valid && arg.ExecutedCount >= order.MaxExecutions.Int32 {
+		arg.Status = STANDING_ORDER_STATUS_COMPLETED
+	}
+	if order.EndAt.Valid && arg.NextExecutionAt.After(order.EndAt.Time) {
+		arg.Status = STANDING_ORDER_STATUS_COMPLETED
+	}
+
+	return arg
+}
@@ -0,0 +1,123 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// executeStandingOrderOf drains due standing orders until the one of the given account is executed
+func executeStandingOrderOf(t *testing.T, store Store, arg ExecuteStandingOrderTxParams, account Account) ExecuteStandingOrderTxResponse {
+	for {
+		response, err := store.ExecuteStandingOrderTx(context.Background(), arg)
+		require.NoError(t, err)
+
+		if response.StandingOrder.FromAccountID == account.ID {
+			return response
+		}
+	}
+}
+
+func TestExecuteStandingOrderTx(t *testing.T) {
+	store := NewStore(testDB)
+	arg := ExecuteStandingOrderTxParams{MaxRetries: 1, RetryInterval: -time.Minute}
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	account1, err := testQueries.UpdateAccount(context.Background(), UpdateAccountParams{
+		ID:      account1.ID,
+		Balance: 1_000_000,
+	})
+	require.NoError(t, err)
+
+	createRandomStandingOrder(t, account1, account2, time.Now().Add(-time.Minute))
+
+	response := executeStandingOrderOf(t, store, arg, account1)
+	require.Equal(t, STANDING_ORDER_STATUS_ACTIVE, response.StandingOrder.Status)
+	require.Equal(t, int32(1), response.StandingOrder.ExecutedCount)
+	require.True(t, response.StandingOrder.NextExecutionAt.After(time.Now()))
+	require.NotZero(t, response.Transfer.Transfer.ID)
+}
+
+func TestExecuteStandingOrderTxRetry(t *testing.T) {
+	store := NewStore(testDB)
+	// a negative retry interval makes the retry due immediately
+	arg := ExecuteStandingOrderTxParams{MaxRetries: 1, RetryInterval: -time.Minute}
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	account1, err := testQueries.UpdateAccount(context.Background(), UpdateAccountParams{
+		ID:      account1.ID,
+		Balance: 0,
+	})
+	require.NoError(t, err)
+
+	createRandomStandingOrder(t, account1, account2, time.Now().Add(-time.Minute))
+
+	response := executeStandingOrderOf(t, store, arg, account1)
+	require.Equal(t, int32(1), response.StandingOrder.RetryCount)
+	require.Equal(t, INSUFFICIENT_FUNDS_REASON, response.StandingOrder.LastFailureReason.String)
+	require.Equal(t, account1.Owner, response.Notification.Username)
+
+	// retries are exhausted, so the occurrence is skipped
+	response = executeStandingOrderOf(t, store, arg, account1)
+	require.Zero(t, response.StandingOrder.RetryCount)
+	require.Zero(t, response.StandingOrder.ExecutedCount)
+	require.True(t, response.StandingOrder.NextExecutionAt.After(time.Now()))
+	require.Equal(t, account1.Owner, response.Notification.Username)
+}
+
+func TestExecuteStandingOrderTxRequiresApproval(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	account1, err := testQueries.UpdateAccount(context.Background(), UpdateAccountParams{
+		ID:      account1.ID,
+		Balance: 1_000_000,
+	})
+	require.NoError(t, err)
+
+	createRandomStandingOrder(t, account1, account2, time.Now().Add(-time.Minute))
+
+	arg := ExecuteStandingOrderTxParams{
+		MaxRetries:         1,
+		RetryInterval:      -time.Minute,
+		ApprovalThresholds: map[string]int64{account1.Currency: 0},
+	}
+	response := executeStandingOrderOf(t, store, arg, account1)
+	require.Zero(t, response.StandingOrder.ExecutedCount)
+	require.Equal(t, APPROVAL_REQUIRED_REASON, response.StandingOrder.LastFailureReason.String)
+	require.True(t, response.StandingOrder.NextExecutionAt.After(time.Now()))
+	require.Empty(t, response.Transfer.Transfer)
+	require.Equal(t, account1.Owner, response.Notification.Username)
+}
+
+func TestNextStandingOrderState(t *testing.T) {
+	now := time.Now()
+	order := StandingOrder{
+		ID:            1,
+		Frequency:     "daily",
+		StartAt:       now.Add(-time.Hour),
+		ExecutedCount: 1,
+		MaxExecutions: sql.NullInt32{Int32: 2, Valid: true},
+	}
+
+	arg := nextStandingOrderState(order, now, false)
+	require.Equal(t, STANDING_ORDER_STATUS_ACTIVE, arg.Status)
+	require.Equal(t, int32(1), arg.ExecutedCount)
+	require.True(t, arg.NextExecutionAt.After(now))
+
+	arg = nextStandingOrderState(order, now, true)
+	require.Equal(t, STANDING_ORDER_STATUS_COMPLETED, arg.Status)
+	require.Equal(t, int32(2), arg.ExecutedCount)
+	require.True(t, arg.LastExecutedAt.Valid)
+
+	order.MaxExecutions = sql.NullInt32{}
+	order.EndAt = sql.NullTime{Time: now.Add(time.Hour), Valid: true}
+	arg = nextStandingOrderState(order, now, true)
+	require.Equal(t, STANDING_ORDER_STATUS_COMPLETED, arg.Status)
+}
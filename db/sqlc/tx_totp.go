@@ -0,0 +1,53 @@
+package db
+
+import (
+	"context"
+)
+
+type EnableTOTPTxParams struct {
+	Username string `json:"username"`
+	// Step is the time step of the code confirming the enrollment
+	Step               int64    `json:"step"`
+	RecoveryCodeHashes []string `json:"recovery_code_hashes"`
+}
+
+type EnableTOTPTxResponse struct {
+	User          User           `json:"user"`
+	RecoveryCodes []RecoveryCode `json:"recovery_codes"`
+}
+
+// EnableTOTPTx turns on two-factor authentication and replaces the recovery codes of the user
+func (store *SQLStore) EnableTOTPTx(ctx context.Context, arg EnableTOTPTxParams) (EnableTOTPTxResponse, error) {
+	var response EnableTOTPTxResponse
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		response.User, err = q.EnableUserTOTP(ctx, EnableUserTOTPParams{
+			Username:         arg.Username,
+			TotpLastUsedStep: arg.Step,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = q.DeleteRecoveryCodes(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+
+		for _, codeHash := range arg.RecoveryCodeHashes {
+			recoveryCode, err := q.CreateRecoveryCode(ctx, CreateRecoveryCodeParams{
+				Username: arg.Username,
+				CodeHash: codeHash,
+			})
+			if err != nil {
+				return err
+			}
+			response.RecoveryCodes = append(response.RecoveryCodes, recoveryCode)
+		}
+
+		return nil
+	})
+	return response, err
+}
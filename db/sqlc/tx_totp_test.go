@@ -0,0 +1,71 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/VL-037/go-bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnableTOTPTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+
+	_, err := store.EnableTOTPTx(context.Background(), EnableTOTPTxParams{Username: user.Username, Step: 1})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	user, err = testQueries.SetUserTOTPSecret(context.Background(), SetUserTOTPSecretParams{
+		Username:   user.Username,
+		TotpSecret: sql.NullString{String: "JBSWY3DPEHPK3PXP", Valid: true},
+	})
+	require.NoError(t, err)
+	require.False(t, user.IsTotpEnabled)
+
+	codes := []string{util.RandomString(10), util.RandomString(10)}
+	arg := EnableTOTPTxParams{
+		Username:           user.Username,
+		Step:               100,
+		RecoveryCodeHashes: []string{util.HashToken(codes[0]), util.HashToken(codes[1])},
+	}
+
+	response, err := store.EnableTOTPTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.True(t, response.User.IsTotpEnabled)
+	require.Equal(t, int64(100), response.User.TotpLastUsedStep)
+	require.Len(t, response.RecoveryCodes, 2)
+
+	// the secret cannot be replaced while enabled
+	_, err = testQueries.SetUserTOTPSecret(context.Background(), SetUserTOTPSecretParams{
+		Username:   user.Username,
+		TotpSecret: sql.NullString{String: "KRSXG5CTMVRXEZLU", Valid: true},
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// a code is accepted once, earlier steps are rejected
+	rows, err := testQueries.UseUserTOTPStep(context.Background(), UseUserTOTPStepParams{Username: user.Username, TotpLastUsedStep: 100})
+	require.NoError(t, err)
+	require.Zero(t, rows)
+	rows, err = testQueries.UseUserTOTPStep(context.Background(), UseUserTOTPStepParams{Username: user.Username, TotpLastUsedStep: 101})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rows)
+
+	recoveryCode, err := testQueries.UseRecoveryCode(context.Background(), UseRecoveryCodeParams{
+		Username: user.Username,
+		CodeHash: util.HashToken(codes[0]),
+	})
+	require.NoError(t, err)
+	require.True(t, recoveryCode.IsUsed)
+
+	_, err = testQueries.UseRecoveryCode(context.Background(), UseRecoveryCodeParams{
+		Username: user.Username,
+		CodeHash: util.HashToken(codes[0]),
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	user, err = testQueries.DisableUserTOTP(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.False(t, user.IsTotpEnabled)
+	require.False(t, user.TotpSecret.Valid)
+}
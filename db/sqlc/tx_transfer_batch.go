@@ -0,0 +1,219 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+)
+
+var ErrTransferBatchTotalOverflow = errors.New("transfer batch total amount is too large")
+
+// TRANSFER_FAILED_REASON is recorded for items failing on something else than a business error,
+// whose details are kept out of the batch
+const TRANSFER_FAILED_REASON = "transfer failed"
+
+// Modes of a transfer batch
+const (
+	TRANSFER_BATCH_MODE_ATOMIC      = "atomic"
+	TRANSFER_BATCH_MODE_BEST_EFFORT = "best_effort"
+)
+
+// Statuses of a transfer batch
+const (
+	TRANSFER_BATCH_STATUS_PROCESSING          = "processing"
+	TRANSFER_BATCH_STATUS_COMPLETED           = "completed"
+	TRANSFER_BATCH_STATUS_PARTIALLY_COMPLETED = "partially_completed"
+	TRANSFER_BATCH_STATUS_FAILED              = "failed"
+)
+
+// Statuses of a transfer batch item
+const (
+	TRANSFER_BATCH_ITEM_STATUS_EXECUTED = "executed"
+	TRANSFER_BATCH_ITEM_STATUS_FAILED   = "failed"
+)
+
+type TransferBatchItemParams struct {
+	ToAccountID     int64  `json:"to_account_id"`
+	Amount          int64  `json:"amount"`
+	Description     string `json:"description"`
+	ClientReference string `json:"client_reference"`
+}
+
+type TransferBatchTxParams struct {
+	FromAccountID int64                     `json:"from_account_id"`
+	Currency      string                    `json:"currency"`
+	Mode          string                    `json:"mode"`
+	Items         []TransferBatchItemParams `json:"items"`
+}
+
+type TransferBatchTxResponse struct {
+	Batch TransferBatch       `json:"batch"`
+	Items []TransferBatchItem `json:"items"`
+}
+
+// TransferBatchTx executes a list of transfers from one account.
+// In atomic mode all transfers run in a single DB transaction and any failure rolls back the whole batch.
+// In best effort mode every transfer runs in its own DB transaction and failures are recorded per item
+func (store *SQLStore) TransferBatchTx(ctx context.Context, arg TransferBatchTxParams) (TransferBatchTxResponse, error) {
+	if arg.Mode == TRANSFER_BATCH_MODE_ATOMIC {
+		return store.atomicTransferBatch(ctx, arg)
+	}
+	return store.bestEffortTransferBatch(ctx, arg)
+}
+
+func (store *SQLStore) atomicTransferBatch(ctx context.Context, arg TransferBatchTxParams) (TransferBatchTxResponse, error) {
+	var response TransferBatchTxResponse
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		batch, err := newTransferBatch(ctx, q, arg)
+		if err != nil {
+			return err
+		}
+
+		fromAccount, err := q.GetAccountForUpdate(ctx, arg.FromAccountID)
+		if err != nil {
+			return err
+		}
+
+		if fromAccount.AvailableBalance < batch.TotalAmount {
+			return ErrInsufficientFunds
+		}
+
+		for i, item := range arg.Items {
+			batchItem, err := executeTransferBatchItem(ctx, q, batch, int32(i+1), item)
+			if err != nil {
+				return err
+			}
+			response.Items = append(response.Items, batchItem)
+		}
+
+		response.Batch, err = q.CompleteTransferBatch(ctx, CompleteTransferBatchParams{
+			ID:            batch.ID,
+			Status:        TRANSFER_BATCH_STATUS_COMPLETED,
+			ExecutedCount: batch.ItemCount,
+		})
+		return err
+	})
+	return response, err
+}
+
+// bestEffortTransferBatch always completes the batch once it is stored: items which cannot be recorded stop the batch
+// and count as failed with the items left, so it is not left processing. Callers should not cancel ctx halfway
+func (store *SQLStore) bestEffortTransferBatch(ctx context.Context, arg TransferBatchTxParams) (TransferBatchTxResponse, error) {
+	var response TransferBatchTxResponse
+
+	batch, err := newTransferBatch(ctx, store.Queries, arg)
+	if err != nil {
+		return response, err
+	}
+
+	var executedCount, failedCount int32
+	var recordErr error
+	for i, item := range arg.Items {
+		lineNumber := int32(i + 1)
+
+		var batchItem TransferBatchItem
+		err := store.execTx(ctx, func(q *Queries) error {
+			fromAccount, err := q.GetAccountForUpdate(ctx, arg.FromAccountID)
+			if err != nil {
+				return err
+			}
+
+			if fromAccount.AvailableBalance < item.Amount {
+				return ErrInsufficientFunds
+			}
+
+			batchItem, err = executeTransferBatchItem(ctx, q, batch, lineNumber, item)
+			return err
+		})
+		if err != nil {
+			// the failed transfer was rolled back, so only the failure is recorded
+			failureReason, failed := transferFailureReason(err)
+			if !failed {
+				failureReason = TRANSFER_FAILED_REASON
+			}
+
+			batchItem, err = store.CreateTransferBatchItem(ctx, transferBatchItemParams(batch, lineNumber, item, TRANSFER_BATCH_ITEM_STATUS_FAILED, failureReason, sql.NullInt64{}))
+			if err != nil {
+				recordErr = err
+				failedCount += int32(len(arg.Items)) - lineNumber + 1
+				break
+			}
+			failedCount++
+		} else {
+			executedCount++
+		}
+		response.Items = append(response.Items, batchItem)
+	}
+
+	status := TRANSFER_BATCH_STATUS_COMPLETED
+	if executedCount == 0 {
+		status = TRANSFER_BATCH_STATUS_FAILED
+	} else if failedCount > 0 {
+		status = TRANSFER_BATCH_STATUS_PARTIALLY_COMPLETED
+	}
+
+	response.Batch, err = store.CompleteTransferBatch(ctx, CompleteTransferBatchParams{
+		ID:            batch.ID,
+		Status:        status,
+		ExecutedCount: executedCount,
+		FailedCount:   failedCount,
+	})
+	if recordErr != nil {
+		return response, recordErr
+	}
+	return response, err
+}
+
+// newTransferBatch stores a batch with the item count and total amount of its items
+func newTransferBatch(ctx context.Context, q *Queries, arg TransferBatchTxParams) (TransferBatch, error) {
+	var totalAmount int64
+	for _, item := range arg.Items {
+		if item.Amount > math.MaxInt64-totalAmount {
+			return TransferBatch{}, ErrTransferBatchTotalOverflow
+		}
+		totalAmount += item.Amount
+	}
+
+	return q.CreateTransferBatch(ctx, CreateTransferBatchParams{
+		FromAccountID: arg.FromAccountID,
+		Currency:      arg.Currency,
+		Mode:          arg.Mode,
+		ItemCount:     int32(len(arg.Items)),
+		TotalAmount:   totalAmount,
+	})
+}
+
+// executeTransferBatchItem moves the money of one item and records it as executed
+func executeTransferBatchItem(ctx context.Context, q *Queries, batch TransferBatch, lineNumber int32, item TransferBatchItemParams) (TransferBatchItem, error) {
+	response, err := transfer(ctx, q, TransferTxParams{
+		FromAccountID:   batch.FromAccountID,
+		ToAccountID:     item.ToAccountID,
+		Amount:          item.Amount,
+		Description:     item.Description,
+		ClientReference: item.ClientReference,
+	})
+	if err != nil {
+		return TransferBatchItem{}, err
+	}
+
+	return q.CreateTransferBatchItem(ctx, transferBatchItemParams(batch, lineNumber, item, TRANSFER_BATCH_ITEM_STATUS_EXECUTED, "", sql.NullInt64{
+		Int64: response.Transfer.ID,
+		Valid: true,
+	}))
+}
+
+func transferBatchItemParams(batch TransferBatch, lineNumber int32, item TransferBatchItemParams, status string, failureReason string, transferID sql.NullInt64) CreateTransferBatchItemParams {
+	return CreateTransferBatchItemParams{
+		BatchID:         batch.ID,
+		LineNumber:      lineNumber,
+		ToAccountID:     item.ToAccountID,
+		Amount:          item.Amount,
+		Description:     sql.NullString{String: item.Description, Valid: item.Description != ""},
+		ClientReference: sql.NullString{String: item.ClientReference, Valid: item.ClientReference != ""},
+		Status:          status,
+		FailureReason:   sql.NullString{String: failureReason, Valid: failureReason != ""},
+		TransferID:      transferID,
+	}
+}
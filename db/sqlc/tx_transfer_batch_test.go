@@ -0,0 +1,146 @@
+package db
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransferBatchTxAtomic(t *testing.T) {
+	store := NewStore(testDB)
+
+	fromAccount := createRandomAccount(t)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	fromAccount, err := testQueries.UpdateAccount(context.Background(), UpdateAccountParams{ID: fromAccount.ID, Balance: 100})
+	require.NoError(t, err)
+
+	arg := TransferBatchTxParams{
+		FromAccountID: fromAccount.ID,
+		Currency:      fromAccount.Currency,
+		Mode:          TRANSFER_BATCH_MODE_ATOMIC,
+		Items: []TransferBatchItemParams{
+			{ToAccountID: account1.ID, Amount: 30, Description: "salary"},
+			{ToAccountID: account2.ID, Amount: 40},
+		},
+	}
+
+	response, err := store.TransferBatchTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, TRANSFER_BATCH_STATUS_COMPLETED, response.Batch.Status)
+	require.Equal(t, int64(70), response.Batch.TotalAmount)
+	require.Equal(t, int32(2), response.Batch.ExecutedCount)
+	require.Len(t, response.Items, 2)
+
+	for i, item := range response.Items {
+		require.Equal(t, int32(i+1), item.LineNumber)
+		require.Equal(t, TRANSFER_BATCH_ITEM_STATUS_EXECUTED, item.Status)
+		require.True(t, item.TransferID.Valid)
+	}
+
+	fromAccount, err = testQueries.GetAccount(context.Background(), fromAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(30), fromAccount.Balance)
+
+	// the whole batch is rolled back when the funds don't cover it
+	_, err = store.TransferBatchTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	fromAccount, err = testQueries.GetAccount(context.Background(), fromAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(30), fromAccount.Balance)
+}
+
+func TestTransferBatchTxBestEffort(t *testing.T) {
+	store := NewStore(testDB)
+
+	fromAccount := createRandomAccount(t)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	fromAccount, err := testQueries.UpdateAccount(context.Background(), UpdateAccountParams{ID: fromAccount.ID, Balance: 50})
+	require.NoError(t, err)
+
+	response, err := store.TransferBatchTx(context.Background(), TransferBatchTxParams{
+		FromAccountID: fromAccount.ID,
+		Currency:      fromAccount.Currency,
+		Mode:          TRANSFER_BATCH_MODE_BEST_EFFORT,
+		Items: []TransferBatchItemParams{
+			{ToAccountID: account1.ID, Amount: 30},
+			{ToAccountID: account2.ID, Amount: 40},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, TRANSFER_BATCH_STATUS_PARTIALLY_COMPLETED, response.Batch.Status)
+	require.Equal(t, int32(1), response.Batch.ExecutedCount)
+	require.Equal(t, int32(1), response.Batch.FailedCount)
+
+	require.Equal(t, TRANSFER_BATCH_ITEM_STATUS_EXECUTED, response.Items[0].Status)
+	require.Equal(t, TRANSFER_BATCH_ITEM_STATUS_FAILED, response.Items[1].Status)
+	require.Equal(t, INSUFFICIENT_FUNDS_REASON, response.Items[1].FailureReason.String)
+	require.False(t, response.Items[1].TransferID.Valid)
+
+	items, err := testQueries.ListTransferBatchItems(context.Background(), response.Batch.ID)
+	require.NoError(t, err)
+	require.Equal(t, response.Items, items)
+}
+
+func TestTransferBatchTxBestEffortUnrecordedItem(t *testing.T) {
+	store := NewStore(testDB)
+
+	fromAccount := createRandomAccount(t)
+	account1 := createRandomAccount(t)
+	closedAccount := createRandomAccount(t)
+	fromAccount, err := testQueries.UpdateAccount(context.Background(), UpdateAccountParams{ID: fromAccount.ID, Balance: 50})
+	require.NoError(t, err)
+
+	_, err = testQueries.UpdateAccount(context.Background(), UpdateAccountParams{ID: closedAccount.ID, Balance: 0})
+	require.NoError(t, err)
+	_, err = store.CloseAccountTx(context.Background(), closedAccount.ID)
+	require.NoError(t, err)
+
+	// an item to a missing account cannot even be recorded as failed, the batch stops there
+	response, err := store.TransferBatchTx(context.Background(), TransferBatchTxParams{
+		FromAccountID: fromAccount.ID,
+		Currency:      fromAccount.Currency,
+		Mode:          TRANSFER_BATCH_MODE_BEST_EFFORT,
+		Items: []TransferBatchItemParams{
+			{ToAccountID: account1.ID, Amount: 10},
+			{ToAccountID: closedAccount.ID, Amount: 10},
+			{ToAccountID: math.MaxInt64, Amount: 10},
+			{ToAccountID: account1.ID, Amount: 10},
+		},
+	})
+	require.Error(t, err)
+	require.Len(t, response.Items, 2)
+	require.Equal(t, TRANSFER_BATCH_ITEM_STATUS_FAILED, response.Items[1].Status)
+	require.Equal(t, ACCOUNT_CLOSED_REASON, response.Items[1].FailureReason.String)
+
+	// yet the batch is completed, with the items left counted as failed
+	batch, err := testQueries.GetTransferBatch(context.Background(), response.Batch.ID)
+	require.NoError(t, err)
+	require.Equal(t, TRANSFER_BATCH_STATUS_PARTIALLY_COMPLETED, batch.Status)
+	require.Equal(t, int32(1), batch.ExecutedCount)
+	require.Equal(t, int32(3), batch.FailedCount)
+}
+
+func TestTransferBatchTxTotalOverflow(t *testing.T) {
+	store := NewStore(testDB)
+
+	fromAccount := createRandomAccount(t)
+	account1 := createRandomAccount(t)
+
+	for _, mode := range []string{TRANSFER_BATCH_MODE_ATOMIC, TRANSFER_BATCH_MODE_BEST_EFFORT} {
+		_, err := store.TransferBatchTx(context.Background(), TransferBatchTxParams{
+			FromAccountID: fromAccount.ID,
+			Currency:      fromAccount.Currency,
+			Mode:          mode,
+			Items: []TransferBatchItemParams{
+				{ToAccountID: account1.ID, Amount: math.MaxInt64},
+				{ToAccountID: account1.ID, Amount: 1},
+			},
+		})
+		require.ErrorIs(t, err, ErrTransferBatchTotalOverflow)
+	}
+}
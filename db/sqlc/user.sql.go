@@ -7,6 +7,8 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
 const createUser = `-- name: CreateUser :one
@@ -14,7 +16,7 @@ INSERT INTO users (username,
                    hashed_password,
                    full_name,
                    email)
-VALUES ($1, $2, $3, $4) RETURNING username, hashed_password, full_name, email, password_updated_at, created_by, created_at, updated_by, updated_at, mark_for_delete
+VALUES ($1, $2, $3, $4) RETURNING username, hashed_password, full_name, email, password_updated_at, created_by, created_at, updated_by, updated_at, mark_for_delete, role, tokens_valid_after, is_email_verified, totp_secret, is_totp_enabled, totp_last_used_step
 `
 
 type CreateUserParams struct {
@@ -43,12 +45,128 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.UpdatedBy,
 		&i.UpdatedAt,
 		&i.MarkForDelete,
+		&i.Role,
+		&i.TokensValidAfter,
+		&i.IsEmailVerified,
+		&i.TotpSecret,
+		&i.IsTotpEnabled,
+		&i.TotpLastUsedStep,
+	)
+	return i, err
+}
+
+const deleteUser = `-- name: DeleteUser :one
+UPDATE users
+SET full_name          = '',
+    email              = 'deleted-' || gen_random_uuid() || '@deleted.invalid',
+    hashed_password    = '',
+    is_email_verified  = false,
+    totp_secret        = NULL,
+    is_totp_enabled    = false,
+    tokens_valid_after = now(),
+    mark_for_delete    = true,
+    updated_at         = now()
+WHERE username = $1
+  AND mark_for_delete = false RETURNING username, hashed_password, full_name, email, password_updated_at, created_by, created_at, updated_by, updated_at, mark_for_delete, role, tokens_valid_after, is_email_verified, totp_secret, is_totp_enabled, totp_last_used_step
+`
+
+func (q *Queries) DeleteUser(ctx context.Context, username string) (User, error) {
+	row := q.db.QueryRowContext(ctx, deleteUser, username)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordUpdatedAt,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedBy,
+		&i.UpdatedAt,
+		&i.MarkForDelete,
+		&i.Role,
+		&i.TokensValidAfter,
+		&i.IsEmailVerified,
+		&i.TotpSecret,
+		&i.IsTotpEnabled,
+		&i.TotpLastUsedStep,
+	)
+	return i, err
+}
+
+const disableUserTOTP = `-- name: DisableUserTOTP :one
+UPDATE users
+SET totp_secret         = NULL,
+    is_totp_enabled     = false,
+    totp_last_used_step = 0,
+    updated_at          = now()
+WHERE username = $1 RETURNING username, hashed_password, full_name, email, password_updated_at, created_by, created_at, updated_by, updated_at, mark_for_delete, role, tokens_valid_after, is_email_verified, totp_secret, is_totp_enabled, totp_last_used_step
+`
+
+func (q *Queries) DisableUserTOTP(ctx context.Context, username string) (User, error) {
+	row := q.db.QueryRowContext(ctx, disableUserTOTP, username)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordUpdatedAt,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedBy,
+		&i.UpdatedAt,
+		&i.MarkForDelete,
+		&i.Role,
+		&i.TokensValidAfter,
+		&i.IsEmailVerified,
+		&i.TotpSecret,
+		&i.IsTotpEnabled,
+		&i.TotpLastUsedStep,
+	)
+	return i, err
+}
+
+const enableUserTOTP = `-- name: EnableUserTOTP :one
+UPDATE users
+SET is_totp_enabled     = true,
+    totp_last_used_step = $2,
+    updated_at          = now()
+WHERE username = $1
+  AND totp_secret IS NOT NULL RETURNING username, hashed_password, full_name, email, password_updated_at, created_by, created_at, updated_by, updated_at, mark_for_delete, role, tokens_valid_after, is_email_verified, totp_secret, is_totp_enabled, totp_last_used_step
+`
+
+type EnableUserTOTPParams struct {
+	Username         string `json:"username"`
+	TotpLastUsedStep int64  `json:"totp_last_used_step"`
+}
+
+func (q *Queries) EnableUserTOTP(ctx context.Context, arg EnableUserTOTPParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, enableUserTOTP, arg.Username, arg.TotpLastUsedStep)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordUpdatedAt,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedBy,
+		&i.UpdatedAt,
+		&i.MarkForDelete,
+		&i.Role,
+		&i.TokensValidAfter,
+		&i.IsEmailVerified,
+		&i.TotpSecret,
+		&i.IsTotpEnabled,
+		&i.TotpLastUsedStep,
 	)
 	return i, err
 }
 
 const getUser = `-- name: GetUser :one
-SELECT username, hashed_password, full_name, email, password_updated_at, created_by, created_at, updated_by, updated_at, mark_for_delete
+SELECT username, hashed_password, full_name, email, password_updated_at, created_by, created_at, updated_by, updated_at, mark_for_delete, role, tokens_valid_after, is_email_verified, totp_secret, is_totp_enabled, totp_last_used_step
 FROM users
 WHERE username = $1 LIMIT 1
 `
@@ -67,6 +185,253 @@ func (q *Queries) GetUser(ctx context.Context, username string) (User, error) {
 		&i.UpdatedBy,
 		&i.UpdatedAt,
 		&i.MarkForDelete,
+		&i.Role,
+		&i.TokensValidAfter,
+		&i.IsEmailVerified,
+		&i.TotpSecret,
+		&i.IsTotpEnabled,
+		&i.TotpLastUsedStep,
+	)
+	return i, err
+}
+
+const getUserAuthState = `-- name: GetUserAuthState :one
+SELECT tokens_valid_after, is_email_verified
+FROM users
+WHERE username = $1 LIMIT 1
+`
+
+type GetUserAuthStateRow struct {
+	TokensValidAfter time.Time `json:"tokens_valid_after"`
+	IsEmailVerified  bool      `json:"is_email_verified"`
+}
+
+func (q *Queries) GetUserAuthState(ctx context.Context, username string) (GetUserAuthStateRow, error) {
+	row := q.db.QueryRowContext(ctx, getUserAuthState, username)
+	var i GetUserAuthStateRow
+	err := row.Scan(
+		&i.TokensValidAfter,
+		&i.IsEmailVerified,
+	)
+	return i, err
+}
+
+const getUserByEmail = `-- name: GetUserByEmail :one
+SELECT username, hashed_password, full_name, email, password_updated_at, created_by, created_at, updated_by, updated_at, mark_for_delete, role, tokens_valid_after, is_email_verified, totp_secret, is_totp_enabled, totp_last_used_step
+FROM users
+WHERE email = $1 LIMIT 1
+`
+
+func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByEmail, email)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordUpdatedAt,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedBy,
+		&i.UpdatedAt,
+		&i.MarkForDelete,
+		&i.Role,
+		&i.TokensValidAfter,
+		&i.IsEmailVerified,
+		&i.TotpSecret,
+		&i.IsTotpEnabled,
+		&i.TotpLastUsedStep,
+	)
+	return i, err
+}
+
+const rehashUserPassword = `-- name: RehashUserPassword :exec
+UPDATE users
+SET hashed_password = $1
+WHERE username = $2
+  AND hashed_password = $3
+`
+
+type RehashUserPasswordParams struct {
+	NewHashedPassword string `json:"new_hashed_password"`
+	Username          string `json:"username"`
+	HashedPassword    string `json:"hashed_password"`
+}
+
+func (q *Queries) RehashUserPassword(ctx context.Context, arg RehashUserPasswordParams) error {
+	_, err := q.db.ExecContext(ctx, rehashUserPassword, arg.NewHashedPassword, arg.Username, arg.HashedPassword)
+	return err
+}
+
+const setUserTOTPSecret = `-- name: SetUserTOTPSecret :one
+UPDATE users
+SET totp_secret = $2,
+    updated_at  = now()
+WHERE username = $1
+  AND is_totp_enabled = false RETURNING username, hashed_password, full_name, email, password_updated_at, created_by, created_at, updated_by, updated_at, mark_for_delete, role, tokens_valid_after, is_email_verified, totp_secret, is_totp_enabled, totp_last_used_step
+`
+
+type SetUserTOTPSecretParams struct {
+	Username   string         `json:"username"`
+	TotpSecret sql.NullString `json:"totp_secret"`
+}
+
+func (q *Queries) SetUserTOTPSecret(ctx context.Context, arg SetUserTOTPSecretParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, setUserTOTPSecret, arg.Username, arg.TotpSecret)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordUpdatedAt,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedBy,
+		&i.UpdatedAt,
+		&i.MarkForDelete,
+		&i.Role,
+		&i.TokensValidAfter,
+		&i.IsEmailVerified,
+		&i.TotpSecret,
+		&i.IsTotpEnabled,
+		&i.TotpLastUsedStep,
+	)
+	return i, err
+}
+
+const updateUser = `-- name: UpdateUser :one
+UPDATE users
+SET full_name         = COALESCE($1, full_name),
+    email             = COALESCE($2, email),
+    -- a new email address has to be verified again
+    is_email_verified = is_email_verified AND ($2::varchar IS NULL OR $2 = email),
+    updated_at        = now()
+WHERE username = $3 RETURNING username, hashed_password, full_name, email, password_updated_at, created_by, created_at, updated_by, updated_at, mark_for_delete, role, tokens_valid_after, is_email_verified, totp_secret, is_totp_enabled, totp_last_used_step
+`
+
+type UpdateUserParams struct {
+	FullName sql.NullString `json:"full_name"`
+	Email    sql.NullString `json:"email"`
+	Username string         `json:"username"`
+}
+
+func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUser, arg.FullName, arg.Email, arg.Username)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordUpdatedAt,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedBy,
+		&i.UpdatedAt,
+		&i.MarkForDelete,
+		&i.Role,
+		&i.TokensValidAfter,
+		&i.IsEmailVerified,
+		&i.TotpSecret,
+		&i.IsTotpEnabled,
+		&i.TotpLastUsedStep,
+	)
+	return i, err
+}
+
+const updateUserPassword = `-- name: UpdateUserPassword :one
+UPDATE users
+SET hashed_password     = $1,
+    password_updated_at = now(),
+    tokens_valid_after  = now(),
+    updated_at          = now()
+WHERE username = $2 RETURNING username, hashed_password, full_name, email, password_updated_at, created_by, created_at, updated_by, updated_at, mark_for_delete, role, tokens_valid_after, is_email_verified, totp_secret, is_totp_enabled, totp_last_used_step
+`
+
+type UpdateUserPasswordParams struct {
+	HashedPassword string `json:"hashed_password"`
+	Username       string `json:"username"`
+}
+
+func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserPassword, arg.HashedPassword, arg.Username)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordUpdatedAt,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedBy,
+		&i.UpdatedAt,
+		&i.MarkForDelete,
+		&i.Role,
+		&i.TokensValidAfter,
+		&i.IsEmailVerified,
+		&i.TotpSecret,
+		&i.IsTotpEnabled,
+		&i.TotpLastUsedStep,
+	)
+	return i, err
+}
+
+const useUserTOTPStep = `-- name: UseUserTOTPStep :execrows
+UPDATE users
+SET totp_last_used_step = $2
+WHERE username = $1
+  AND totp_last_used_step < $2
+`
+
+type UseUserTOTPStepParams struct {
+	Username         string `json:"username"`
+	TotpLastUsedStep int64  `json:"totp_last_used_step"`
+}
+
+func (q *Queries) UseUserTOTPStep(ctx context.Context, arg UseUserTOTPStepParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, useUserTOTPStep, arg.Username, arg.TotpLastUsedStep)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const verifyUserEmail = `-- name: VerifyUserEmail :one
+UPDATE users
+SET is_email_verified = true,
+    updated_at        = now()
+WHERE username = $1
+  AND email = $2 RETURNING username, hashed_password, full_name, email, password_updated_at, created_by, created_at, updated_by, updated_at, mark_for_delete, role, tokens_valid_after, is_email_verified, totp_secret, is_totp_enabled, totp_last_used_step
+`
+
+type VerifyUserEmailParams struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+}
+
+func (q *Queries) VerifyUserEmail(ctx context.Context, arg VerifyUserEmailParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, verifyUserEmail, arg.Username, arg.Email)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordUpdatedAt,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedBy,
+		&i.UpdatedAt,
+		&i.MarkForDelete,
+		&i.Role,
+		&i.TokensValidAfter,
+		&i.IsEmailVerified,
+		&i.TotpSecret,
+		&i.IsTotpEnabled,
+		&i.TotpLastUsedStep,
 	)
 	return i, err
 }
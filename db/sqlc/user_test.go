@@ -2,16 +2,26 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"github.com/VL-037/go-bank/util"
 	"github.com/stretchr/testify/require"
 	"testing"
 	"time"
 )
 
-func createRandomUser(t *testing.T) User {
-	hashedPassword, err := util.HashPassword(util.RandomString(6))
+func randomHashedPassword(t *testing.T) string {
+	hasher, err := util.NewBcryptHasher(0)
+	require.NoError(t, err)
+
+	hashedPassword, err := hasher.HashPassword(util.RandomString(8))
 	require.NoError(t, err)
 
+	return hashedPassword
+}
+
+func createRandomUser(t *testing.T) User {
+	hashedPassword := randomHashedPassword(t)
+
 	arg := CreateUserParams{
 		Username:       util.RandomOwner(),
 		HashedPassword: hashedPassword,
@@ -27,6 +37,7 @@ func createRandomUser(t *testing.T) User {
 	require.Equal(t, arg.HashedPassword, user.HashedPassword)
 	require.Equal(t, arg.FullName, user.FullName)
 	require.Equal(t, arg.Email, user.Email)
+	require.Equal(t, util.DEPOSITOR_ROLE, user.Role)
 
 	require.NotZero(t, user.CreatedAt)
 	require.NotZero(t, user.UpdatedAt)
@@ -55,3 +66,98 @@ func TestGetUser(t *testing.T) {
 	require.WithinDuration(t, savedUser.UpdatedAt, user.UpdatedAt, time.Second)
 	require.Equal(t, savedUser.MarkForDelete, user.MarkForDelete)
 }
+
+func TestGetUserByEmail(t *testing.T) {
+	savedUser := createRandomUser(t)
+	user, err := testQueries.GetUserByEmail(context.Background(), savedUser.Email)
+
+	require.NoError(t, err)
+	require.Equal(t, savedUser.Username, user.Username)
+	require.Equal(t, savedUser.Email, user.Email)
+}
+
+func TestUpdateUser(t *testing.T) {
+	savedUser := createRandomUser(t)
+	savedUser, err := testQueries.VerifyUserEmail(context.Background(), VerifyUserEmailParams{
+		Username: savedUser.Username,
+		Email:    savedUser.Email,
+	})
+	require.NoError(t, err)
+	require.True(t, savedUser.IsEmailVerified)
+
+	newFullName := util.RandomOwner()
+
+	user, err := testQueries.UpdateUser(context.Background(), UpdateUserParams{
+		Username: savedUser.Username,
+		FullName: sql.NullString{String: newFullName, Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, newFullName, user.FullName)
+	require.Equal(t, savedUser.Email, user.Email)
+	require.True(t, user.IsEmailVerified)
+
+	newEmail := util.RandomEmail()
+	user, err = testQueries.UpdateUser(context.Background(), UpdateUserParams{
+		Username: savedUser.Username,
+		Email:    sql.NullString{String: newEmail, Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, newFullName, user.FullName)
+	require.Equal(t, newEmail, user.Email)
+	require.False(t, user.IsEmailVerified)
+}
+
+func TestUpdateUserPassword(t *testing.T) {
+	savedUser := createRandomUser(t)
+
+	authState, err := testQueries.GetUserAuthState(context.Background(), savedUser.Username)
+	require.NoError(t, err)
+	require.True(t, authState.TokensValidAfter.IsZero())
+	require.False(t, authState.IsEmailVerified)
+
+	hashedPassword := randomHashedPassword(t)
+
+	user, err := testQueries.UpdateUserPassword(context.Background(), UpdateUserPasswordParams{
+		Username:       savedUser.Username,
+		HashedPassword: hashedPassword,
+	})
+	require.NoError(t, err)
+	require.Equal(t, hashedPassword, user.HashedPassword)
+	require.WithinDuration(t, time.Now(), user.PasswordUpdatedAt, time.Second)
+
+	// both times come from the database clock
+	authState, err = testQueries.GetUserAuthState(context.Background(), savedUser.Username)
+	require.NoError(t, err)
+	require.True(t, user.PasswordUpdatedAt.Equal(authState.TokensValidAfter))
+}
+
+func TestRehashUserPassword(t *testing.T) {
+	savedUser := createRandomUser(t)
+
+	newHashedPassword, err := util.NewArgon2idHasher(1, 1024, 1).HashPassword(util.RandomString(8))
+	require.NoError(t, err)
+
+	// a stale hash does not overwrite a password changed in the meantime
+	err = testQueries.RehashUserPassword(context.Background(), RehashUserPasswordParams{
+		NewHashedPassword: newHashedPassword,
+		Username:          savedUser.Username,
+		HashedPassword:    util.RandomString(60),
+	})
+	require.NoError(t, err)
+
+	user, err := testQueries.GetUser(context.Background(), savedUser.Username)
+	require.NoError(t, err)
+	require.Equal(t, savedUser.HashedPassword, user.HashedPassword)
+
+	err = testQueries.RehashUserPassword(context.Background(), RehashUserPasswordParams{
+		NewHashedPassword: newHashedPassword,
+		Username:          savedUser.Username,
+		HashedPassword:    savedUser.HashedPassword,
+	})
+	require.NoError(t, err)
+
+	user, err = testQueries.GetUser(context.Background(), savedUser.Username)
+	require.NoError(t, err)
+	require.Equal(t, newHashedPassword, user.HashedPassword)
+	require.Equal(t, savedUser.PasswordUpdatedAt, user.PasswordUpdatedAt)
+}
@@ -0,0 +1,133 @@
+package lockout
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Counter holds the failed logins of a username or an address
+type Counter struct {
+	Failures     int32
+	LastFailedAt time.Time
+}
+
+// Store keeps failed login counters
+type Store interface {
+	// RecordFailure counts a failed login, counters whose last failure is before windowStart restart at one
+	RecordFailure(ctx context.Context, key string, windowStart time.Time) (Counter, error)
+	// Get returns the counter of the key, a zero counter when there is none
+	Get(ctx context.Context, key string) (Counter, error)
+	Reset(ctx context.Context, key string) error
+}
+
+// Policy decides how long a key is blocked after failed logins
+type Policy struct {
+	// DelayAfter is the number of failures allowed before every further attempt has to wait,
+	// the wait starts at BaseDelay and doubles with each failure
+	DelayAfter int32
+	BaseDelay  time.Duration
+	// MaxFailures locks the key for LockoutDuration, zero disables the policy
+	MaxFailures     int32
+	LockoutDuration time.Duration
+}
+
+// BlockedUntil returns when the key of the counter may try to log in again
+func (policy Policy) BlockedUntil(counter Counter) time.Time {
+	if policy.MaxFailures <= 0 || counter.Failures == 0 {
+		return time.Time{}
+	}
+
+	if counter.Failures >= policy.MaxFailures {
+		return counter.LastFailedAt.Add(policy.LockoutDuration)
+	}
+
+	if counter.Failures < policy.DelayAfter || policy.BaseDelay <= 0 {
+		return time.Time{}
+	}
+
+	delay := policy.BaseDelay
+	for i := policy.DelayAfter; i < counter.Failures && delay < policy.LockoutDuration; i++ {
+		delay *= 2
+	}
+	if delay > policy.LockoutDuration {
+		delay = policy.LockoutDuration
+	}
+
+	return counter.LastFailedAt.Add(delay)
+}
+
+// Limiter throttles logins per username and per client address
+type Limiter struct {
+	store          Store
+	usernamePolicy Policy
+	addressPolicy  Policy
+}
+
+func NewLimiter(store Store, usernamePolicy Policy, addressPolicy Policy) *Limiter {
+	return &Limiter{
+		store:          store,
+		usernamePolicy: usernamePolicy,
+		addressPolicy:  addressPolicy,
+	}
+}
+
+// Check returns how long the client has to wait before trying to log in as the username, zero when it may try now
+func (limiter *Limiter) Check(ctx context.Context, username string, address string) (time.Duration, error) {
+	now := time.Now()
+	var retryAfter time.Duration
+
+	for _, check := range limiter.checks(username, address) {
+		counter, err := limiter.store.Get(ctx, check.key)
+		if err != nil {
+			return 0, err
+		}
+
+		if wait := check.policy.BlockedUntil(counter).Sub(now); wait > retryAfter {
+			retryAfter = wait
+		}
+	}
+
+	return retryAfter, nil
+}
+
+// Fail records a failed login as the username from the address
+func (limiter *Limiter) Fail(ctx context.Context, username string, address string) error {
+	now := time.Now()
+
+	for _, check := range limiter.checks(username, address) {
+		_, err := limiter.store.RecordFailure(ctx, check.key, now.Add(-check.policy.LockoutDuration))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Succeed resets the counter of the username, the address keeps counting so an attacker
+// cannot clear it by logging into an account of their own
+func (limiter *Limiter) Succeed(ctx context.Context, username string) error {
+	return limiter.Unlock(ctx, username)
+}
+
+// Unlock lifts the lockout of the username
+func (limiter *Limiter) Unlock(ctx context.Context, username string) error {
+	return limiter.store.Reset(ctx, usernameKey(username))
+}
+
+type check struct {
+	key    string
+	policy Policy
+}
+
+func (limiter *Limiter) checks(username string, address string) []check {
+	return []check{
+		{key: usernameKey(username), policy: limiter.usernamePolicy},
+		{key: "ip:" + address, policy: limiter.addressPolicy},
+	}
+}
+
+func usernameKey(username string) string {
+	return "user:" + strings.ToLower(username)
+}
@@ -0,0 +1,160 @@
+package lockout
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	mockdb "github.com/VL-037/go-bank/db/mock"
+	db "github.com/VL-037/go-bank/db/sqlc"
+	"github.com/VL-037/go-bank/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+var testPolicy = Policy{
+	DelayAfter:      3,
+	BaseDelay:       time.Second,
+	MaxFailures:     6,
+	LockoutDuration: 15 * time.Minute,
+}
+
+func TestPolicyBlockedUntil(t *testing.T) {
+	lastFailedAt := time.Date(2023, time.March, 1, 10, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		policy   Policy
+		failures int32
+		expected time.Time
+	}{
+		{"No Failures", testPolicy, 0, time.Time{}},
+		{"Below Delay", testPolicy, 2, time.Time{}},
+		{"First Delay", testPolicy, 3, lastFailedAt.Add(time.Second)},
+		{"Doubled Delay", testPolicy, 5, lastFailedAt.Add(4 * time.Second)},
+		{"Locked", testPolicy, 6, lastFailedAt.Add(15 * time.Minute)},
+		{"Still Locked", testPolicy, 9, lastFailedAt.Add(15 * time.Minute)},
+		{"Disabled", Policy{}, 100, time.Time{}},
+		{
+			"Delay Capped At Lockout",
+			Policy{DelayAfter: 1, BaseDelay: time.Minute, MaxFailures: 100, LockoutDuration: 10 * time.Minute},
+			50,
+			lastFailedAt.Add(10 * time.Minute),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			blockedUntil := tc.policy.BlockedUntil(Counter{Failures: tc.failures, LastFailedAt: lastFailedAt})
+			require.Equal(t, tc.expected, blockedUntil)
+		})
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	store := NewMemoryStore()
+	addressPolicy := Policy{MaxFailures: 10, LockoutDuration: time.Hour}
+	limiter := NewLimiter(store, testPolicy, addressPolicy)
+
+	ctx := context.Background()
+	username := util.RandomOwner()
+	address := "10.0.0.1"
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, limiter.Fail(ctx, username, address))
+	}
+	retryAfter, err := limiter.Check(ctx, username, address)
+	require.NoError(t, err)
+	require.Zero(t, retryAfter)
+
+	require.NoError(t, limiter.Fail(ctx, username, address))
+	retryAfter, err = limiter.Check(ctx, username, address)
+	require.NoError(t, err)
+	require.InDelta(t, time.Second, retryAfter, float64(100*time.Millisecond))
+
+	// usernames are counted case-insensitively
+	for i := 0; i < 3; i++ {
+		require.NoError(t, limiter.Fail(ctx, strings.ToUpper(username), address))
+	}
+	retryAfter, err = limiter.Check(ctx, username, "10.0.0.2")
+	require.NoError(t, err)
+	require.InDelta(t, 15*time.Minute, retryAfter, float64(time.Second))
+
+	require.NoError(t, limiter.Unlock(ctx, username))
+	retryAfter, err = limiter.Check(ctx, username, "10.0.0.2")
+	require.NoError(t, err)
+	require.Zero(t, retryAfter)
+
+	// the address is locked regardless of the username
+	for i := 0; i < 4; i++ {
+		require.NoError(t, limiter.Fail(ctx, util.RandomOwner(), address))
+	}
+	retryAfter, err = limiter.Check(ctx, util.RandomOwner(), address)
+	require.NoError(t, err)
+	require.InDelta(t, time.Hour, retryAfter, float64(time.Second))
+
+	// a successful login does not clear the address
+	require.NoError(t, limiter.Succeed(ctx, username))
+	retryAfter, err = limiter.Check(ctx, username, address)
+	require.NoError(t, err)
+	require.InDelta(t, time.Hour, retryAfter, float64(time.Second))
+}
+
+func TestMemoryStoreWindow(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	counter, err := store.RecordFailure(ctx, "user:a", time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, int32(1), counter.Failures)
+
+	counter, err = store.RecordFailure(ctx, "user:a", time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, int32(2), counter.Failures)
+
+	// the last failure is outside the window, the counter restarts
+	counter, err = store.RecordFailure(ctx, "user:a", time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, int32(1), counter.Failures)
+
+	require.NoError(t, store.Reset(ctx, "user:a"))
+	counter, err = store.Get(ctx, "user:a")
+	require.NoError(t, err)
+	require.Zero(t, counter.Failures)
+}
+
+func TestPostgresStore(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	queries := mockdb.NewMockStore(ctrl)
+	store := NewPostgresStore(queries)
+	ctx := context.Background()
+	windowStart := time.Now().Add(-time.Minute)
+	lastFailedAt := time.Now()
+
+	queries.EXPECT().
+		RecordLoginFailure(gomock.Any(), gomock.Eq(db.RecordLoginFailureParams{AttemptKey: "user:a", WindowStart: windowStart})).
+		Times(1).
+		Return(db.LoginAttempt{AttemptKey: "user:a", Failures: 2, LastFailedAt: lastFailedAt}, nil)
+	queries.EXPECT().
+		GetLoginAttempt(gomock.Any(), gomock.Eq("user:b")).
+		Times(1).
+		Return(db.LoginAttempt{}, sql.ErrNoRows)
+	queries.EXPECT().
+		DeleteLoginAttempt(gomock.Any(), gomock.Eq("user:a")).
+		Times(1).
+		Return(nil)
+
+	counter, err := store.RecordFailure(ctx, "user:a", windowStart)
+	require.NoError(t, err)
+	require.Equal(t, Counter{Failures: 2, LastFailedAt: lastFailedAt}, counter)
+
+	counter, err = store.Get(ctx, "user:b")
+	require.NoError(t, err)
+	require.Zero(t, counter)
+
+	require.NoError(t, store.Reset(ctx, "user:a"))
+}
@@ -0,0 +1,47 @@
+package lockout
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps the counters of a single server instance in memory
+type MemoryStore struct {
+	mu       sync.Mutex
+	counters map[string]Counter
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{counters: make(map[string]Counter)}
+}
+
+func (store *MemoryStore) RecordFailure(_ context.Context, key string, windowStart time.Time) (Counter, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	counter := store.counters[key]
+	if counter.LastFailedAt.Before(windowStart) {
+		counter.Failures = 0
+	}
+	counter.Failures++
+	counter.LastFailedAt = time.Now()
+
+	store.counters[key] = counter
+	return counter, nil
+}
+
+func (store *MemoryStore) Get(_ context.Context, key string) (Counter, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	return store.counters[key], nil
+}
+
+func (store *MemoryStore) Reset(_ context.Context, key string) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	delete(store.counters, key)
+	return nil
+}
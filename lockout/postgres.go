@@ -0,0 +1,65 @@
+package lockout
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	db "github.com/VL-037/go-bank/db/sqlc"
+)
+
+// Kinds of counter stores
+const (
+	STORE_POSTGRES = "postgres"
+	STORE_MEMORY   = "memory"
+)
+
+// NewStore creates the counter store selected in the config
+func NewStore(kind string, queries db.Querier) (Store, error) {
+	switch kind {
+	case STORE_POSTGRES:
+		return NewPostgresStore(queries), nil
+	case STORE_MEMORY:
+		return NewMemoryStore(), nil
+	default:
+		return nil, fmt.Errorf("unsupported login attempt store: %q", kind)
+	}
+}
+
+// PostgresStore keeps the counters in the login_attempts table so they are shared by every server instance
+type PostgresStore struct {
+	queries db.Querier
+}
+
+func NewPostgresStore(queries db.Querier) *PostgresStore {
+	return &PostgresStore{queries: queries}
+}
+
+func (store *PostgresStore) RecordFailure(ctx context.Context, key string, windowStart time.Time) (Counter, error) {
+	attempt, err := store.queries.RecordLoginFailure(ctx, db.RecordLoginFailureParams{
+		AttemptKey:  key,
+		WindowStart: windowStart,
+	})
+	if err != nil {
+		return Counter{}, err
+	}
+
+	return Counter{Failures: attempt.Failures, LastFailedAt: attempt.LastFailedAt}, nil
+}
+
+func (store *PostgresStore) Get(ctx context.Context, key string) (Counter, error) {
+	attempt, err := store.queries.GetLoginAttempt(ctx, key)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return Counter{}, nil
+		}
+		return Counter{}, err
+	}
+
+	return Counter{Failures: attempt.Failures, LastFailedAt: attempt.LastFailedAt}, nil
+}
+
+func (store *PostgresStore) Reset(ctx context.Context, key string) error {
+	return store.queries.DeleteLoginAttempt(ctx, key)
+}
@@ -0,0 +1,53 @@
+package mail
+
+import (
+	"fmt"
+	"github.com/VL-037/go-bank/util"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileSender writes every email as an .eml file into a directory, for local development
+type FileSender struct {
+	dir  string
+	from string
+}
+
+func NewFileSender(dir string, from string) (*FileSender, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("cannot create mail directory: %w", err)
+	}
+
+	return &FileSender{dir: dir, from: from}, nil
+}
+
+func (sender *FileSender) Send(message Message) error {
+	now := time.Now()
+	name := fmt.Sprintf("%s-%s.eml", now.UTC().Format("20060102T150405.000000000"), util.RandomString(6))
+
+	return os.WriteFile(filepath.Join(sender.dir, name), message.format(sender.from, now), 0o644)
+}
+
+// LogSender writes every email to a logger instead of delivering it
+type LogSender struct {
+	logger *log.Logger
+	from   string
+}
+
+// NewLogSender creates a sender writing to w, or to the standard logger when w is nil
+func NewLogSender(w io.Writer, from string) *LogSender {
+	logger := log.Default()
+	if w != nil {
+		logger = log.New(w, "", log.LstdFlags)
+	}
+
+	return &LogSender{logger: logger, from: from}
+}
+
+func (sender *LogSender) Send(message Message) error {
+	sender.logger.Printf("mail:\n%s", message.format(sender.from, time.Now()))
+	return nil
+}
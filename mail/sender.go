@@ -0,0 +1,59 @@
+package mail
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/VL-037/go-bank/util"
+	"mime"
+	"strings"
+	"time"
+)
+
+// Kinds of mail senders
+const (
+	SENDER_SMTP = "smtp"
+	SENDER_FILE = "file"
+	SENDER_LOG  = "log"
+)
+
+// Message is a plain text email
+type Message struct {
+	To      []string
+	Subject string
+	Body    string
+}
+
+// Sender delivers emails to users
+type Sender interface {
+	Send(message Message) error
+}
+
+// NewSender creates the sender selected in the config
+func NewSender(config util.Config) (Sender, error) {
+	switch config.MailSender {
+	case SENDER_SMTP:
+		return NewSMTPSender(config.SMTPHost, config.SMTPPort, config.SMTPUsername, config.SMTPPassword, config.MailFrom), nil
+	case SENDER_FILE:
+		return NewFileSender(config.MailFileDir, config.MailFrom)
+	case SENDER_LOG:
+		return NewLogSender(nil, config.MailFrom), nil
+	default:
+		return nil, fmt.Errorf("unsupported mail sender: %q", config.MailSender)
+	}
+}
+
+// format renders the message as an RFC 5322 email
+func (message Message) format(from string, date time.Time) []byte {
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(message.To, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(message.Body, "\r\n", "\n"), "\n", "\r\n"))
+
+	return b.Bytes()
+}
@@ -0,0 +1,165 @@
+package mail
+
+import (
+	"bufio"
+	"bytes"
+	"github.com/VL-037/go-bank/util"
+	"github.com/stretchr/testify/require"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const TEST_FROM = "go-bank <no-reply@go-bank.local>"
+
+func randomMessage() Message {
+	return Message{
+		To:      []string{util.RandomEmail()},
+		Subject: "Verify your email",
+		Body:    "Hello " + util.RandomOwner() + "\nOpen the link to continue.",
+	}
+}
+
+func TestMessageFormat(t *testing.T) {
+	message := randomMessage()
+	date := time.Date(2023, time.March, 1, 10, 0, 0, 0, time.UTC)
+
+	formatted := string(message.format(TEST_FROM, date))
+	headers, body, found := strings.Cut(formatted, "\r\n\r\n")
+	require.True(t, found)
+
+	require.Contains(t, headers, "From: "+TEST_FROM+"\r\n")
+	require.Contains(t, headers, "To: "+message.To[0]+"\r\n")
+	require.Contains(t, headers, "Subject: Verify your email\r\n")
+	require.Contains(t, headers, "Date: Wed, 01 Mar 2023 10:00:00 +0000\r\n")
+	require.Equal(t, strings.ReplaceAll(message.Body, "\n", "\r\n"), body)
+}
+
+func TestFileSender(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	sender, err := NewFileSender(dir, TEST_FROM)
+	require.NoError(t, err)
+
+	message := randomMessage()
+	require.NoError(t, sender.Send(message))
+	require.NoError(t, sender.Send(randomMessage()))
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+
+	var found bool
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		require.NoError(t, err)
+		found = found || strings.Contains(string(content), "To: "+message.To[0])
+	}
+	require.True(t, found)
+}
+
+func TestLogSender(t *testing.T) {
+	var buf bytes.Buffer
+	sender := NewLogSender(&buf, TEST_FROM)
+
+	message := randomMessage()
+	require.NoError(t, sender.Send(message))
+	require.Contains(t, buf.String(), "To: "+message.To[0])
+	require.Contains(t, buf.String(), "Open the link to continue.")
+}
+
+func TestSMTPSender(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	received := make(chan []string, 1)
+	go serveSMTP(listener, received)
+
+	host, port, err := net.SplitHostPort(listener.Addr().String())
+	require.NoError(t, err)
+	portNumber, err := net.LookupPort("tcp", port)
+	require.NoError(t, err)
+
+	message := randomMessage()
+	sender := NewSMTPSender(host, portNumber, "", "", TEST_FROM)
+	require.NoError(t, sender.Send(message))
+
+	select {
+	case lines := <-received:
+		session := strings.Join(lines, "\n")
+		require.Contains(t, session, "MAIL FROM:<no-reply@go-bank.local>")
+		require.Contains(t, session, "RCPT TO:<"+message.To[0]+">")
+		require.Contains(t, session, "Subject: Verify your email")
+	case <-time.After(5 * time.Second):
+		t.Fatal("smtp session did not finish")
+	}
+}
+
+func TestNewSender(t *testing.T) {
+	sender, err := NewSender(util.Config{MailSender: SENDER_LOG, MailFrom: TEST_FROM})
+	require.NoError(t, err)
+	require.IsType(t, &LogSender{}, sender)
+
+	sender, err = NewSender(util.Config{MailSender: SENDER_FILE, MailFileDir: t.TempDir(), MailFrom: TEST_FROM})
+	require.NoError(t, err)
+	require.IsType(t, &FileSender{}, sender)
+
+	sender, err = NewSender(util.Config{MailSender: SENDER_SMTP, SMTPHost: "localhost", SMTPPort: 25, MailFrom: TEST_FROM})
+	require.NoError(t, err)
+	require.IsType(t, &SMTPSender{}, sender)
+
+	_, err = NewSender(util.Config{MailSender: "pigeon"})
+	require.Error(t, err)
+}
+
+// serveSMTP accepts a single session and records every line the client sent
+func serveSMTP(listener net.Listener, received chan<- []string) {
+	conn, err := listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	var lines []string
+	reader := bufio.NewReader(conn)
+	reply := func(line string) {
+		_, _ = conn.Write([]byte(line + "\r\n"))
+	}
+
+	reply("220 localhost ESMTP")
+	inData := false
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			break
+		}
+		line = strings.TrimRight(line, "\r\n")
+		lines = append(lines, line)
+
+		if inData {
+			if line == "." {
+				inData = false
+				reply("250 OK")
+			}
+			continue
+		}
+
+		switch command := strings.ToUpper(strings.SplitN(line, " ", 2)[0]); command {
+		case "EHLO", "HELO":
+			reply("250 localhost")
+		case "DATA":
+			inData = true
+			reply("354 End data with <CR><LF>.<CR><LF>")
+		case "QUIT":
+			reply("221 Bye")
+			received <- lines
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+	received <- lines
+}
@@ -0,0 +1,59 @@
+package mail
+
+import (
+	"fmt"
+	"net"
+	netmail "net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// SMTPSender delivers emails through an SMTP server
+type SMTPSender struct {
+	address  string
+	host     string
+	username string
+	password string
+	from     string
+}
+
+// NewSMTPSender creates a sender for the SMTP server, it authenticates only when a username is set
+func NewSMTPSender(host string, port int, username string, password string, from string) *SMTPSender {
+	return &SMTPSender{
+		address:  net.JoinHostPort(host, strconv.Itoa(port)),
+		host:     host,
+		username: username,
+		password: password,
+		from:     from,
+	}
+}
+
+func (sender *SMTPSender) Send(message Message) error {
+	var auth smtp.Auth
+	if sender.username != "" {
+		auth = smtp.PlainAuth("", sender.username, sender.password, sender.host)
+	}
+
+	envelopeFrom, err := address(sender.from)
+	if err != nil {
+		return err
+	}
+
+	err = smtp.SendMail(sender.address, auth, envelopeFrom, message.To, message.format(sender.from, time.Now()))
+	if err != nil {
+		return fmt.Errorf("failed to send mail: %w", err)
+	}
+
+	return nil
+}
+
+// address returns the bare address of a "Name <address>" sender
+func address(from string) (string, error) {
+	parsed, err := netmail.ParseAddress(from)
+	if err != nil {
+		return "", fmt.Errorf("invalid mail sender address %q: %w", from, err)
+	}
+
+	return parsed.Address, nil
+}
@@ -1,10 +1,13 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"github.com/VL-037/go-bank/api"
 	db "github.com/VL-037/go-bank/db/sqlc"
+	"github.com/VL-037/go-bank/mail"
 	"github.com/VL-037/go-bank/util"
+	"github.com/VL-037/go-bank/worker"
 	_ "github.com/lib/pq"
 	"log"
 )
@@ -21,7 +24,36 @@ func main() {
 	}
 
 	store := db.NewStore(conn)
-	server, err := api.NewServer(config, store)
+
+	interestRates, err := util.ParseCurrencyAmounts(config.InterestRates)
+	if err != nil {
+		log.Fatal("cannot parse interest rates:", err)
+	}
+	if !util.IsSupportedDayCount(config.InterestDayCount) {
+		log.Fatal("unsupported interest day count:", config.InterestDayCount)
+	}
+
+	approvalThresholds, err := util.ParseCurrencyAmounts(config.TransferApprovalThresholds)
+	if err != nil {
+		log.Fatal("cannot parse transfer approval thresholds:", err)
+	}
+
+	runner := worker.NewRunner()
+	runner.Register("scheduled_transfers", config.WorkerInterval, worker.ExecuteScheduledTransfers(store, approvalThresholds))
+	runner.Register("standing_orders", config.WorkerInterval,
+		worker.ExecuteStandingOrders(store, config.StandingOrderMaxRetries, config.StandingOrderRetryInterval, approvalThresholds))
+	runner.Register("expired_holds", config.WorkerInterval, worker.ExpireHolds(store))
+	runner.Register("expired_payment_requests", config.WorkerInterval, worker.ExpirePaymentRequests(store))
+	runner.Register("interest", config.WorkerInterval, worker.AccrueInterest(store, interestRates, config.InterestDayCount))
+	runner.Register("balance_snapshots", config.WorkerInterval, worker.SnapshotBalances(store))
+	runner.Start(context.Background())
+
+	mailer, err := mail.NewSender(config)
+	if err != nil {
+		log.Fatal("cannot create mail sender:", err)
+	}
+
+	server, err := api.NewServer(config, store, mailer)
 	if err != nil {
 		log.Fatal("cannot create server:", err)
 	}
@@ -0,0 +1,299 @@
+package statement
+
+import (
+	"encoding/xml"
+	"fmt"
+	"github.com/VL-037/go-bank/util"
+	"io"
+	"strconv"
+	"time"
+)
+
+const CAMT053_NAMESPACE = "urn:iso:std:iso:20022:tech:xsd:camt.053.001.02"
+
+// Codes of ISO 20022 used in camt.053
+const (
+	CAMT_CREDIT          = "CRDT"
+	CAMT_DEBIT           = "DBIT"
+	CAMT_OPENING_BALANCE = "OPBD"
+	CAMT_CLOSING_BALANCE = "CLBD"
+	CAMT_BOOKED          = "BOOK"
+	CAMT_NOT_PROVIDED    = "NOTPROVIDED"
+	CAMT_TRANSFER_CODE   = "TRANSFER"
+	CAMT_ENTRY_CODE      = "ENTRY"
+	CAMT_DATE_TIME       = "2006-01-02T15:04:05Z07:00"
+)
+
+// Maximum lengths of camt.053 text fields
+const (
+	CAMT_MAX_REFERENCE_LENGTH  = 35
+	CAMT_MAX_REMITTANCE_LENGTH = 140
+)
+
+type camtDocument struct {
+	XMLName xml.Name       `xml:"Document"`
+	Xmlns   string         `xml:"xmlns,attr"`
+	Message camtStatements `xml:"BkToCstmrStmt"`
+}
+
+type camtStatements struct {
+	GroupHeader camtGroupHeader `xml:"GrpHdr"`
+	Statement   camtStatement   `xml:"Stmt"`
+}
+
+type camtGroupHeader struct {
+	MessageID string `xml:"MsgId"`
+	CreatedAt string `xml:"CreDtTm"`
+}
+
+type camtStatement struct {
+	ID        string        `xml:"Id"`
+	CreatedAt string        `xml:"CreDtTm"`
+	Period    camtPeriod    `xml:"FrToDt"`
+	Account   camtAccount   `xml:"Acct"`
+	Balances  []camtBalance `xml:"Bal"`
+	Summary   camtSummary   `xml:"TxsSummry"`
+	Entries   []camtEntry   `xml:"Ntry"`
+}
+
+type camtPeriod struct {
+	From string `xml:"FrDtTm"`
+	To   string `xml:"ToDtTm"`
+}
+
+type camtAccount struct {
+	ID       camtAccountID `xml:"Id"`
+	Currency string        `xml:"Ccy,omitempty"`
+	Owner    *camtParty    `xml:"Ownr,omitempty"`
+}
+
+type camtAccountID struct {
+	Other camtOtherID `xml:"Othr"`
+}
+
+type camtOtherID struct {
+	ID string `xml:"Id"`
+}
+
+type camtParty struct {
+	Name string `xml:"Nm"`
+}
+
+type camtAmount struct {
+	Currency string `xml:"Ccy,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type camtBalance struct {
+	Type        camtBalanceType `xml:"Tp"`
+	Amount      camtAmount      `xml:"Amt"`
+	CreditDebit string          `xml:"CdtDbtInd"`
+	Date        camtDate        `xml:"Dt"`
+}
+
+type camtBalanceType struct {
+	CodeOrProprietary camtCode `xml:"CdOrPrtry"`
+}
+
+type camtCode struct {
+	Code string `xml:"Cd"`
+}
+
+type camtDate struct {
+	Date     string `xml:"Dt,omitempty"`
+	DateTime string `xml:"DtTm,omitempty"`
+}
+
+type camtSummary struct {
+	Total  camtEntryCount `xml:"TtlNtries"`
+	Credit camtEntryCount `xml:"TtlCdtNtries"`
+	Debit  camtEntryCount `xml:"TtlDbtNtries"`
+}
+
+type camtEntryCount struct {
+	Count int    `xml:"NbOfNtries"`
+	Sum   string `xml:"Sum"`
+}
+
+type camtEntry struct {
+	Reference         string              `xml:"NtryRef"`
+	Amount            camtAmount          `xml:"Amt"`
+	CreditDebit       string              `xml:"CdtDbtInd"`
+	Status            string              `xml:"Sts"`
+	BookingDate       camtDate            `xml:"BookgDt"`
+	ValueDate         camtDate            `xml:"ValDt"`
+	ServicerReference string              `xml:"AcctSvcrRef"`
+	TransactionCode   camtTransactionCode `xml:"BkTxCd"`
+	Details           *camtEntryDetails   `xml:"NtryDtls,omitempty"`
+}
+
+type camtTransactionCode struct {
+	Proprietary camtCode `xml:"Prtry"`
+}
+
+type camtEntryDetails struct {
+	Transaction camtTransaction `xml:"TxDtls"`
+}
+
+type camtTransaction struct {
+	References     camtReferences  `xml:"Refs"`
+	RelatedParties camtParties     `xml:"RltdPties"`
+	Remittance     *camtRemittance `xml:"RmtInf,omitempty"`
+}
+
+type camtReferences struct {
+	EndToEndID string `xml:"EndToEndId"`
+}
+
+type camtParties struct {
+	Debtor          *camtParty   `xml:"Dbtr,omitempty"`
+	DebtorAccount   *camtAccount `xml:"DbtrAcct,omitempty"`
+	Creditor        *camtParty   `xml:"Cdtr,omitempty"`
+	CreditorAccount *camtAccount `xml:"CdtrAcct,omitempty"`
+}
+
+type camtRemittance struct {
+	Unstructured string `xml:"Ustrd"`
+}
+
+// WriteCamt053 renders the statement as an ISO 20022 camt.053 bank to customer statement
+func (statement Statement) WriteCamt053(w io.Writer) error {
+	account := statement.Account
+	currency := account.Currency
+	id := statement.ID()
+
+	document := camtDocument{
+		Xmlns: CAMT053_NAMESPACE,
+		Message: camtStatements{
+			GroupHeader: camtGroupHeader{
+				MessageID: id,
+				CreatedAt: statement.CreatedAt.Format(CAMT_DATE_TIME),
+			},
+			Statement: camtStatement{
+				ID:        id,
+				CreatedAt: statement.CreatedAt.Format(CAMT_DATE_TIME),
+				Period: camtPeriod{
+					From: statement.PeriodStart.Format(CAMT_DATE_TIME),
+					To:   statement.PeriodEnd.Add(-time.Second).Format(CAMT_DATE_TIME),
+				},
+				Account: camtAccount{
+					ID:       camtAccountID{Other: camtOtherID{ID: account.Number}},
+					Currency: currency,
+					Owner:    &camtParty{Name: account.Owner},
+				},
+				Balances: []camtBalance{
+					camtBalanceOf(CAMT_OPENING_BALANCE, statement.OpeningBalance, currency, statement.PeriodStart.Format(DATE_LAYOUT)),
+					camtBalanceOf(CAMT_CLOSING_BALANCE, statement.ClosingBalance, currency, statement.LastDay().Format(DATE_LAYOUT)),
+				},
+				Summary: statement.camtSummary(),
+			},
+		},
+	}
+
+	for _, line := range statement.Lines {
+		document.Message.Statement.Entries = append(document.Message.Statement.Entries, camtEntryOf(line, currency))
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(document); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func (statement Statement) camtSummary() camtSummary {
+	var credit, debit camtEntryCount
+	var creditSum, debitSum int64
+	for _, line := range statement.Lines {
+		if line.Amount < 0 {
+			debit.Count++
+			debitSum -= line.Amount
+		} else {
+			credit.Count++
+			creditSum += line.Amount
+		}
+	}
+
+	currency := statement.Account.Currency
+	credit.Sum = util.FormatAmount(creditSum, currency)
+	debit.Sum = util.FormatAmount(debitSum, currency)
+
+	return camtSummary{
+		Total: camtEntryCount{
+			Count: len(statement.Lines),
+			Sum:   util.FormatAmount(creditSum+debitSum, currency),
+		},
+		Credit: credit,
+		Debit:  debit,
+	}
+}
+
+// camtBalanceOf states a balance as an amount with a credit or debit indicator, as camt.053 has no negative amounts
+func camtBalanceOf(code string, balance int64, currency string, date string) camtBalance {
+	creditDebit, amount := camtCreditDebit(balance)
+	return camtBalance{
+		Type:        camtBalanceType{CodeOrProprietary: camtCode{Code: code}},
+		Amount:      camtAmount{Currency: currency, Value: util.FormatAmount(amount, currency)},
+		CreditDebit: creditDebit,
+		Date:        camtDate{Date: date},
+	}
+}
+
+func camtEntryOf(line Line, currency string) camtEntry {
+	creditDebit, amount := camtCreditDebit(line.Amount)
+	entryID := strconv.FormatInt(line.EntryID, 10)
+
+	entry := camtEntry{
+		Reference:         entryID,
+		Amount:            camtAmount{Currency: currency, Value: util.FormatAmount(amount, currency)},
+		CreditDebit:       creditDebit,
+		Status:            CAMT_BOOKED,
+		BookingDate:       camtDate{DateTime: line.Date.UTC().Format(CAMT_DATE_TIME)},
+		ValueDate:         camtDate{Date: line.Date.UTC().Format(DATE_LAYOUT)},
+		ServicerReference: entryID,
+		TransactionCode:   camtTransactionCode{Proprietary: camtCode{Code: CAMT_ENTRY_CODE}},
+	}
+	if line.TransferID == 0 {
+		return entry
+	}
+
+	entry.ServicerReference = fmt.Sprintf("%d/%d", line.TransferID, line.EntryID)
+	entry.TransactionCode.Proprietary.Code = CAMT_TRANSFER_CODE
+
+	transaction := camtTransaction{
+		References: camtReferences{EndToEndID: CAMT_NOT_PROVIDED},
+	}
+	if line.Reference != "" {
+		transaction.References.EndToEndID = truncate(line.Reference, CAMT_MAX_REFERENCE_LENGTH)
+	}
+
+	// the counterparty paid the account on credits and was paid by it on debits
+	party := &camtParty{Name: line.CounterpartyName}
+	partyAccount := &camtAccount{ID: camtAccountID{Other: camtOtherID{ID: line.CounterpartyNumber}}}
+	if creditDebit == CAMT_CREDIT {
+		transaction.RelatedParties = camtParties{Debtor: party, DebtorAccount: partyAccount}
+	} else {
+		transaction.RelatedParties = camtParties{Creditor: party, CreditorAccount: partyAccount}
+	}
+
+	if line.Memo != "" {
+		transaction.Remittance = &camtRemittance{Unstructured: truncate(line.Memo, CAMT_MAX_REMITTANCE_LENGTH)}
+	}
+
+	entry.Details = &camtEntryDetails{Transaction: transaction}
+	return entry
+}
+
+func camtCreditDebit(amount int64) (string, int64) {
+	if amount < 0 {
+		return CAMT_DEBIT, -amount
+	}
+	return CAMT_CREDIT, amount
+}
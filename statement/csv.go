@@ -0,0 +1,46 @@
+package statement
+
+import (
+	"encoding/csv"
+	"github.com/VL-037/go-bank/util"
+	"io"
+	"strconv"
+)
+
+const DATE_LAYOUT = "2006-01-02"
+
+var csvHeader = []string{"date", "entry_id", "description", "amount", "balance"}
+
+// WriteCSV renders the statement as CSV, one row per entry between the opening and closing balance rows
+func (statement Statement) WriteCSV(w io.Writer) error {
+	currency := statement.Account.Currency
+
+	writer := csv.NewWriter(w)
+	rows := [][]string{
+		csvHeader,
+		{statement.PeriodStart.Format(DATE_LAYOUT), "", "Opening balance", "", util.FormatAmount(statement.OpeningBalance, currency)},
+	}
+	for _, line := range statement.Lines {
+		rows = append(rows, []string{
+			line.Date.UTC().Format(DATE_LAYOUT),
+			strconv.FormatInt(line.EntryID, 10),
+			line.Description(),
+			util.FormatAmount(line.Amount, currency),
+			util.FormatAmount(line.Balance, currency),
+		})
+	}
+	rows = append(rows, []string{statement.LastDay().Format(DATE_LAYOUT), "", "Closing balance", "", util.FormatAmount(statement.ClosingBalance, currency)})
+
+	if err := writer.WriteAll(rows); err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+// Description tells whether the line took money out of the account or put money in
+func (line Line) Description() string {
+	if line.Amount < 0 {
+		return "Debit"
+	}
+	return "Credit"
+}
@@ -0,0 +1,140 @@
+package statement
+
+import (
+	"fmt"
+	"github.com/VL-037/go-bank/util"
+	"io"
+	"strings"
+)
+
+// Limits of SWIFT MT940 fields
+const (
+	MT940_MAX_REFERENCE_LENGTH  = 16
+	MT940_NARRATIVE_LINE_LENGTH = 65
+	MT940_MAX_NARRATIVE_LINES   = 6
+	MT940_NO_REFERENCE          = "NONREF"
+	MT940_TRANSFER_TYPE         = "NTRF"
+	MT940_MISCELLANEOUS_TYPE    = "NMSC"
+	MT940_LINE_END              = "\r\n"
+	MT940_SWIFT_CHARACTERS      = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789/-?:().,'+ "
+	MT940_REPLACEMENT_CHARACTER = '.'
+)
+
+// WriteMT940 renders the statement as the text block of a SWIFT MT940 customer statement message
+func (statement Statement) WriteMT940(w io.Writer) error {
+	account := statement.Account
+	currency := account.Currency
+
+	fields := []string{
+		":20:" + statement.PeriodStart.Format("060102") + statement.LastDay().Format("060102"),
+		":25:" + account.Number,
+		":28C:1/1",
+		":60F:" + mt940Balance(statement.OpeningBalance, statement.PeriodStart.Format("060102"), currency),
+	}
+
+	for _, line := range statement.Lines {
+		fields = append(fields, ":61:"+mt940StatementLine(line, currency))
+		if narrative := mt940Narrative(line); narrative != "" {
+			fields = append(fields, ":86:"+narrative)
+		}
+	}
+
+	fields = append(fields,
+		":62F:"+mt940Balance(statement.ClosingBalance, statement.LastDay().Format("060102"), currency),
+		"-",
+	)
+
+	_, err := io.WriteString(w, strings.Join(fields, MT940_LINE_END)+MT940_LINE_END)
+	return err
+}
+
+// mt940Balance formats a balance as debit or credit mark, date, currency and amount
+func mt940Balance(balance int64, date string, currency string) string {
+	mark, amount := mt940CreditDebit(balance)
+	return fmt.Sprintf("%s%s%s%s", mark, date, currency, mt940Amount(amount, currency))
+}
+
+// mt940StatementLine formats an entry as value date, entry date, debit or credit mark, amount,
+// transaction type, reference for the account owner and reference of the bank
+func mt940StatementLine(line Line, currency string) string {
+	mark, amount := mt940CreditDebit(line.Amount)
+	date := line.Date.UTC()
+
+	transactionType := MT940_MISCELLANEOUS_TYPE
+	if line.TransferID != 0 {
+		transactionType = MT940_TRANSFER_TYPE
+	}
+
+	reference := MT940_NO_REFERENCE
+	if line.Reference != "" {
+		reference = truncate(mt940Text(line.Reference), MT940_MAX_REFERENCE_LENGTH)
+	}
+
+	return fmt.Sprintf("%s%s%s%s%s%s//%d", date.Format("060102"), date.Format("0102"), mark, mt940Amount(amount, currency), transactionType, reference, line.EntryID)
+}
+
+// mt940Narrative describes the counterparty and memo of a transfer, wrapped on lines of 65 characters
+func mt940Narrative(line Line) string {
+	if line.TransferID == 0 {
+		return ""
+	}
+
+	direction := "FROM"
+	if line.Amount < 0 {
+		direction = "TO"
+	}
+
+	text := fmt.Sprintf("TRANSFER %d %s %s %s", line.TransferID, direction, line.CounterpartyName, line.CounterpartyNumber)
+	if line.Memo != "" {
+		text += " " + line.Memo
+	}
+	if line.Reference != "" {
+		text += " REF " + line.Reference
+	}
+	text = mt940Text(text)
+
+	var lines []string
+	for len(text) > 0 && len(lines) < MT940_MAX_NARRATIVE_LINES {
+		end := MT940_NARRATIVE_LINE_LENGTH
+		if end > len(text) {
+			end = len(text)
+		}
+		lines = append(lines, text[:end])
+		text = text[end:]
+	}
+
+	// a line starting with a colon or a hyphen would read as a new field or the end of the message
+	for i := 1; i < len(lines); i++ {
+		if lines[i][0] == ':' || lines[i][0] == '-' {
+			lines[i] = string(MT940_REPLACEMENT_CHARACTER) + lines[i][1:]
+		}
+	}
+
+	return strings.Join(lines, MT940_LINE_END)
+}
+
+// mt940Amount formats an amount with a decimal comma, which is mandatory even without decimals
+func mt940Amount(amount int64, currency string) string {
+	formatted := strings.Replace(util.FormatAmount(amount, currency), ".", ",", 1)
+	if !strings.Contains(formatted, ",") {
+		formatted += ","
+	}
+	return formatted
+}
+
+func mt940CreditDebit(amount int64) (string, int64) {
+	if amount < 0 {
+		return "D", -amount
+	}
+	return "C", amount
+}
+
+// mt940Text replaces the characters outside of the SWIFT character set
+func mt940Text(text string) string {
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(MT940_SWIFT_CHARACTERS, r) {
+			return r
+		}
+		return MT940_REPLACEMENT_CHARACTER
+	}, text)
+}
@@ -0,0 +1,176 @@
+package statement
+
+import (
+	"encoding/xml"
+	db "github.com/VL-037/go-bank/db/sqlc"
+	"github.com/VL-037/go-bank/util"
+	"io"
+	"strconv"
+)
+
+// Values of the OFX 2.2 banking messages
+const (
+	OFX_HEADER        = `<?xml version="1.0" encoding="UTF-8" standalone="no"?>` + "\n" + `<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>` + "\n"
+	OFX_BANK_ID       = "GOBANK"
+	OFX_ORGANIZATION  = "go-bank"
+	OFX_LANGUAGE      = "ENG"
+	OFX_SUCCESS       = 0
+	OFX_SEVERITY_INFO = "INFO"
+	OFX_CREDIT        = "CREDIT"
+	OFX_DEBIT         = "DEBIT"
+	OFX_INTEREST      = "INT"
+	OFX_CHECKING      = "CHECKING"
+	OFX_SAVINGS       = "SAVINGS"
+	OFX_DATE_TIME     = "20060102150405.000[+0:UTC]"
+	OFX_MAX_NAME      = 32
+	OFX_MAX_MEMO      = 255
+)
+
+type ofxDocument struct {
+	XMLName xml.Name        `xml:"OFX"`
+	SignOn  ofxSignOn       `xml:"SIGNONMSGSRSV1"`
+	Bank    ofxBankMessages `xml:"BANKMSGSRSV1"`
+}
+
+type ofxSignOn struct {
+	Response ofxSignOnResponse `xml:"SONRS"`
+}
+
+type ofxSignOnResponse struct {
+	Status      ofxStatus      `xml:"STATUS"`
+	ServerTime  string         `xml:"DTSERVER"`
+	Language    string         `xml:"LANGUAGE"`
+	Institution ofxInstitution `xml:"FI"`
+}
+
+type ofxStatus struct {
+	Code     int    `xml:"CODE"`
+	Severity string `xml:"SEVERITY"`
+}
+
+type ofxInstitution struct {
+	Organization string `xml:"ORG"`
+}
+
+type ofxBankMessages struct {
+	Transaction ofxStatementTransaction `xml:"STMTTRNRS"`
+}
+
+type ofxStatementTransaction struct {
+	ID        string               `xml:"TRNUID"`
+	Status    ofxStatus            `xml:"STATUS"`
+	Statement ofxStatementResponse `xml:"STMTRS"`
+}
+
+type ofxStatementResponse struct {
+	Currency      string             `xml:"CURDEF"`
+	Account       ofxBankAccount     `xml:"BANKACCTFROM"`
+	Transactions  ofxTransactionList `xml:"BANKTRANLIST"`
+	LedgerBalance ofxBalance         `xml:"LEDGERBAL"`
+}
+
+type ofxBankAccount struct {
+	BankID string `xml:"BANKID"`
+	ID     string `xml:"ACCTID"`
+	Type   string `xml:"ACCTTYPE"`
+}
+
+type ofxTransactionList struct {
+	Start        string           `xml:"DTSTART"`
+	End          string           `xml:"DTEND"`
+	Transactions []ofxTransaction `xml:"STMTTRN"`
+}
+
+type ofxTransaction struct {
+	Type   string `xml:"TRNTYPE"`
+	Posted string `xml:"DTPOSTED"`
+	Amount string `xml:"TRNAMT"`
+	ID     string `xml:"FITID"`
+	Name   string `xml:"NAME,omitempty"`
+	Memo   string `xml:"MEMO,omitempty"`
+}
+
+type ofxBalance struct {
+	Amount string `xml:"BALAMT"`
+	AsOf   string `xml:"DTASOF"`
+}
+
+// WriteOFX renders the statement as an OFX 2.2 bank statement response.
+// The entry ids are the FITIDs, so importing an overlapping period again skips the known transactions
+func (statement Statement) WriteOFX(w io.Writer) error {
+	account := statement.Account
+	currency := account.Currency
+	status := ofxStatus{Code: OFX_SUCCESS, Severity: OFX_SEVERITY_INFO}
+
+	document := ofxDocument{
+		SignOn: ofxSignOn{Response: ofxSignOnResponse{
+			Status:      status,
+			ServerTime:  statement.CreatedAt.UTC().Format(OFX_DATE_TIME),
+			Language:    OFX_LANGUAGE,
+			Institution: ofxInstitution{Organization: OFX_ORGANIZATION},
+		}},
+		Bank: ofxBankMessages{Transaction: ofxStatementTransaction{
+			ID:     statement.ID(),
+			Status: status,
+			Statement: ofxStatementResponse{
+				Currency: currency,
+				Account: ofxBankAccount{
+					BankID: OFX_BANK_ID,
+					ID:     account.Number,
+					Type:   ofxAccountType(account.Type),
+				},
+				Transactions: ofxTransactionList{
+					Start: statement.PeriodStart.UTC().Format(OFX_DATE_TIME),
+					End:   statement.PeriodEnd.UTC().Format(OFX_DATE_TIME),
+				},
+				LedgerBalance: ofxBalance{
+					Amount: util.FormatAmount(statement.ClosingBalance, currency),
+					AsOf:   statement.PeriodEnd.UTC().Format(OFX_DATE_TIME),
+				},
+			},
+		}},
+	}
+
+	transactions := &document.Bank.Transaction.Statement.Transactions.Transactions
+	for _, line := range statement.Lines {
+		*transactions = append(*transactions, ofxTransactionOf(line, currency))
+	}
+
+	if _, err := io.WriteString(w, OFX_HEADER); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(document); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func ofxTransactionOf(line Line, currency string) ofxTransaction {
+	transactionType := OFX_CREDIT
+	if line.Amount < 0 {
+		transactionType = OFX_DEBIT
+	} else if line.CounterpartyName == db.INTEREST_EXPENSE_OWNER {
+		transactionType = OFX_INTEREST
+	}
+
+	return ofxTransaction{
+		Type:   transactionType,
+		Posted: line.Date.UTC().Format(OFX_DATE_TIME),
+		Amount: util.FormatAmount(line.Amount, currency),
+		ID:     strconv.FormatInt(line.EntryID, 10),
+		Name:   truncate(line.CounterpartyName, OFX_MAX_NAME),
+		Memo:   truncate(line.Memo, OFX_MAX_MEMO),
+	}
+}
+
+func ofxAccountType(accountType string) string {
+	if accountType == util.SAVINGS_ACCOUNT_TYPE {
+		return OFX_SAVINGS
+	}
+	return OFX_CHECKING
+}
@@ -0,0 +1,163 @@
+package statement
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/VL-037/go-bank/util"
+	"io"
+	"strings"
+)
+
+// Layout of the PDF statement: A4 pages in points, 9pt Courier so columns line up
+const (
+	PDF_PAGE_WIDTH     = 595
+	PDF_PAGE_HEIGHT    = 842
+	PDF_MARGIN         = 50
+	PDF_FONT_SIZE      = 9
+	PDF_LEADING        = 12
+	PDF_LINES_PER_PAGE = (PDF_PAGE_HEIGHT - 2*PDF_MARGIN) / PDF_LEADING
+)
+
+// WritePDF renders the statement as a PDF document
+func (statement Statement) WritePDF(w io.Writer) error {
+	return writePDF(w, paginate(statement.textLines()))
+}
+
+// textLines lays the statement out as fixed-width text
+func (statement Statement) textLines() []string {
+	account := statement.Account
+	currency := account.Currency
+	row := "%-10s  %-10s  %-11s  %18s  %18s"
+
+	lines := []string{
+		"Account statement",
+		"",
+		fmt.Sprintf("Account number: %s", account.Number),
+		fmt.Sprintf("Account holder: %s", account.Owner),
+		fmt.Sprintf("Currency:       %s", currency),
+		fmt.Sprintf("Period:         %s to %s", statement.PeriodStart.Format(DATE_LAYOUT), statement.LastDay().Format(DATE_LAYOUT)),
+		"",
+		fmt.Sprintf(row, "Date", "Entry", "Description", "Amount", "Balance"),
+		strings.Repeat("-", 73),
+		fmt.Sprintf(row, statement.PeriodStart.Format(DATE_LAYOUT), "", "Opening", "", util.FormatAmount(statement.OpeningBalance, currency)),
+	}
+	for _, line := range statement.Lines {
+		lines = append(lines, fmt.Sprintf(row,
+			line.Date.UTC().Format(DATE_LAYOUT),
+			fmt.Sprint(line.EntryID),
+			line.Description(),
+			util.FormatAmount(line.Amount, currency),
+			util.FormatAmount(line.Balance, currency),
+		))
+	}
+	lines = append(lines, fmt.Sprintf(row, statement.LastDay().Format(DATE_LAYOUT), "", "Closing", "", util.FormatAmount(statement.ClosingBalance, currency)))
+
+	return lines
+}
+
+// paginate splits the lines into pages, keeping the last line of every page for the page number
+func paginate(lines []string) [][]string {
+	perPage := PDF_LINES_PER_PAGE - 2
+
+	var pages [][]string
+	for start := 0; start == 0 || start < len(lines); start += perPage {
+		end := start + perPage
+		if end > len(lines) {
+			end = len(lines)
+		}
+		pages = append(pages, append([]string{}, lines[start:end]...))
+	}
+
+	for i := range pages {
+		pages[i] = append(pages[i], "", fmt.Sprintf("Page %d of %d", i+1, len(pages)))
+	}
+	return pages
+}
+
+// writePDF writes a PDF 1.4 document with one page per list of lines, using the built-in Courier font.
+// Objects are the catalog (1), the page tree (2), the font (3), then a page and its content stream for every page
+func writePDF(w io.Writer, pages [][]string) error {
+	var buf bytes.Buffer
+	var offsets []int
+
+	beginObject := func() {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n", len(offsets))
+	}
+	endObject := func() {
+		buf.WriteString("endobj\n")
+	}
+
+	// the binary comment marks the file as binary for transfer programs
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+2*i)
+	}
+
+	beginObject()
+	buf.WriteString("<< /Type /Catalog /Pages 2 0 R >>\n")
+	endObject()
+
+	beginObject()
+	fmt.Fprintf(&buf, "<< /Type /Pages /Kids [%s] /Count %d >>\n", strings.Join(kids, " "), len(pages))
+	endObject()
+
+	beginObject()
+	buf.WriteString("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>\n")
+	endObject()
+
+	for i, lines := range pages {
+		content := pageContent(lines)
+
+		beginObject()
+		fmt.Fprintf(&buf, "<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>\n", PDF_PAGE_WIDTH, PDF_PAGE_HEIGHT, 5+2*i)
+		endObject()
+
+		beginObject()
+		fmt.Fprintf(&buf, "<< /Length %d >>\nstream\n%sendstream\n", len(content), content)
+		endObject()
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// pageContent draws the lines top down from the top left margin
+func pageContent(lines []string) string {
+	var content strings.Builder
+	fmt.Fprintf(&content, "BT\n/F1 %d Tf\n%d TL\n%d %d Td\n", PDF_FONT_SIZE, PDF_LEADING, PDF_MARGIN, PDF_PAGE_HEIGHT-PDF_MARGIN)
+	for _, line := range lines {
+		fmt.Fprintf(&content, "(%s) '\n", escapePDFText(line))
+	}
+	content.WriteString("ET\n")
+	return content.String()
+}
+
+// escapePDFText escapes a PDF literal string. Characters outside Latin-1 have no glyph in the
+// standard fonts and are replaced by a question mark
+func escapePDFText(text string) string {
+	var escaped strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			escaped.WriteByte('\\')
+			escaped.WriteRune(r)
+		case r < 0x20 || r > 0xff:
+			escaped.WriteByte('?')
+		case r < 0x80:
+			escaped.WriteRune(r)
+		default:
+			fmt.Fprintf(&escaped, "\\%03o", r)
+		}
+	}
+	return escaped.String()
+}
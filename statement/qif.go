@@ -0,0 +1,39 @@
+package statement
+
+import (
+	"fmt"
+	"github.com/VL-037/go-bank/util"
+	"io"
+	"strings"
+)
+
+const QIF_DATE_LAYOUT = "01/02/2006"
+
+// WriteQIF renders the statement as a QIF bank register. QIF has no transaction id, so the entry id
+// goes in the check number field which budgeting tools compare to skip transactions already imported
+func (statement Statement) WriteQIF(w io.Writer) error {
+	currency := statement.Account.Currency
+
+	var qif strings.Builder
+	qif.WriteString("!Type:Bank\n")
+	for _, line := range statement.Lines {
+		fmt.Fprintf(&qif, "D%s\n", line.Date.UTC().Format(QIF_DATE_LAYOUT))
+		fmt.Fprintf(&qif, "T%s\n", util.FormatAmount(line.Amount, currency))
+		fmt.Fprintf(&qif, "N%d\n", line.EntryID)
+		if line.CounterpartyName != "" {
+			fmt.Fprintf(&qif, "P%s\n", qifText(line.CounterpartyName))
+		}
+		if line.Memo != "" {
+			fmt.Fprintf(&qif, "M%s\n", qifText(line.Memo))
+		}
+		qif.WriteString("^\n")
+	}
+
+	_, err := io.WriteString(w, qif.String())
+	return err
+}
+
+// qifText keeps a field on a single line, as every line of QIF starts a new field
+func qifText(text string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(text)
+}
@@ -0,0 +1,83 @@
+package statement
+
+import (
+	"fmt"
+	db "github.com/VL-037/go-bank/db/sqlc"
+	"time"
+)
+
+// Line is an entry of a statement with the balance of the account right after it.
+// The transfer details are empty for entries that weren't posted by a transfer
+type Line struct {
+	EntryID            int64     `json:"entry_id"`
+	Date               time.Time `json:"date"`
+	Amount             int64     `json:"amount"`
+	Balance            int64     `json:"balance"`
+	TransferID         int64     `json:"transfer_id"`
+	Memo               string    `json:"memo"`
+	Reference          string    `json:"reference"`
+	CounterpartyNumber string    `json:"counterparty_number"`
+	CounterpartyName   string    `json:"counterparty_name"`
+}
+
+// Statement lists the entries of an account over a period, from PeriodStart up to but excluding PeriodEnd
+type Statement struct {
+	Account        db.Account `json:"account"`
+	PeriodStart    time.Time  `json:"period_start"`
+	PeriodEnd      time.Time  `json:"period_end"`
+	OpeningBalance int64      `json:"opening_balance"`
+	ClosingBalance int64      `json:"closing_balance"`
+	Lines          []Line     `json:"lines"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// New builds the statement of an account from its balance at the start of the period
+// and the entries of the period in chronological order
+func New(account db.Account, periodStart, periodEnd time.Time, openingBalance int64, entries []db.ListAccountStatementEntriesRow) Statement {
+	statement := Statement{
+		Account:        account,
+		PeriodStart:    periodStart,
+		PeriodEnd:      periodEnd,
+		OpeningBalance: openingBalance,
+		Lines:          make([]Line, 0, len(entries)),
+		CreatedAt:      time.Now().UTC().Truncate(time.Second),
+	}
+
+	balance := openingBalance
+	for _, entry := range entries {
+		balance += entry.Amount
+		statement.Lines = append(statement.Lines, Line{
+			EntryID:            entry.ID,
+			Date:               entry.CreatedAt,
+			Amount:             entry.Amount,
+			Balance:            balance,
+			TransferID:         entry.TransferID.Int64,
+			Memo:               entry.Description.String,
+			Reference:          entry.ClientReference.String,
+			CounterpartyNumber: entry.CounterpartyNumber.String,
+			CounterpartyName:   entry.CounterpartyOwner.String,
+		})
+	}
+	statement.ClosingBalance = balance
+
+	return statement
+}
+
+// LastDay is the last day covered by the statement
+func (statement Statement) LastDay() time.Time {
+	return statement.PeriodEnd.AddDate(0, 0, -1)
+}
+
+// ID identifies the statement by account and period, e.g. VL200000000001-20230101-20230131
+func (statement Statement) ID() string {
+	return fmt.Sprintf("%s-%s-%s", statement.Account.Number, statement.PeriodStart.Format("20060102"), statement.LastDay().Format("20060102"))
+}
+
+// truncate cuts text to at most max characters
+func truncate(text string, max int) string {
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+	return string(runes[:max])
+}
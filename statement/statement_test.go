@@ -0,0 +1,220 @@
+package statement
+
+import (
+	"bytes"
+	"database/sql"
+	"flag"
+	"fmt"
+	db "github.com/VL-037/go-bank/db/sqlc"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+func testStatement(entryCount int) Statement {
+	account := db.Account{
+		ID:       1,
+		Owner:    "alice",
+		Balance:  0,
+		Currency: "USD",
+		Number:   "VL200000000001",
+		Type:     "checking",
+	}
+
+	periodStart := time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)
+	entries := make([]db.ListAccountStatementEntriesRow, entryCount)
+	for i := range entries {
+		entry := db.ListAccountStatementEntriesRow{
+			ID:                 int64(i + 1),
+			Amount:             2500,
+			CreatedAt:          periodStart.Add(time.Duration(i) * time.Hour),
+			TransferID:         sql.NullInt64{Int64: int64(100 + i), Valid: true},
+			CounterpartyNumber: sql.NullString{String: "VL970000000002", Valid: true},
+			CounterpartyOwner:  sql.NullString{String: "bob", Valid: true},
+		}
+		if i%2 == 1 {
+			entry.Amount = -1000
+			entry.Description = sql.NullString{String: "Rent (January) & utilities", Valid: true}
+			entry.ClientReference = sql.NullString{String: "INV-2023-0001", Valid: true}
+		}
+		entries[i] = entry
+	}
+
+	statement := New(account, periodStart, periodStart.AddDate(0, 1, 0), 10000, entries)
+	statement.CreatedAt = time.Date(2023, time.February, 1, 8, 0, 0, 0, time.UTC)
+	return statement
+}
+
+func TestNew(t *testing.T) {
+	statement := testStatement(3)
+
+	require.Equal(t, int64(10000), statement.OpeningBalance)
+	require.Len(t, statement.Lines, 3)
+	require.Equal(t, int64(12500), statement.Lines[0].Balance)
+	require.Equal(t, int64(11500), statement.Lines[1].Balance)
+	require.Equal(t, int64(14000), statement.Lines[2].Balance)
+	require.Equal(t, int64(14000), statement.ClosingBalance)
+	require.Equal(t, int64(101), statement.Lines[1].TransferID)
+	require.Equal(t, "bob", statement.Lines[1].CounterpartyName)
+	require.Equal(t, "INV-2023-0001", statement.Lines[1].Reference)
+	require.Equal(t, time.Date(2023, time.January, 31, 0, 0, 0, 0, time.UTC), statement.LastDay())
+	require.Equal(t, "VL200000000001-20230101-20230131", statement.ID())
+}
+
+func TestNewWithoutEntries(t *testing.T) {
+	statement := testStatement(0)
+
+	require.Empty(t, statement.Lines)
+	require.Equal(t, statement.OpeningBalance, statement.ClosingBalance)
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, testStatement(2).WriteCSV(&buf))
+
+	expected := "date,entry_id,description,amount,balance\n" +
+		"2023-01-01,,Opening balance,,100.00\n" +
+		"2023-01-01,1,Credit,25.00,125.00\n" +
+		"2023-01-01,2,Debit,-10.00,115.00\n" +
+		"2023-01-31,,Closing balance,,115.00\n"
+	require.Equal(t, expected, buf.String())
+}
+
+func TestWritePDF(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, testStatement(2).WritePDF(&buf))
+
+	document := buf.String()
+	require.True(t, strings.HasPrefix(document, "%PDF-1.4\n"))
+	require.True(t, strings.HasSuffix(document, "%%EOF\n"))
+	require.Contains(t, document, "/Count 1")
+	require.Contains(t, document, "(Account number: VL200000000001) '")
+	require.Contains(t, document, "(Page 1 of 1) '")
+	requireValidXref(t, document)
+}
+
+func TestWritePDFPages(t *testing.T) {
+	statement := testStatement(150)
+
+	var buf bytes.Buffer
+	require.NoError(t, statement.WritePDF(&buf))
+
+	document := buf.String()
+	pageCount := (len(statement.textLines()) + PDF_LINES_PER_PAGE - 3) / (PDF_LINES_PER_PAGE - 2)
+	require.Equal(t, 3, pageCount)
+	require.Contains(t, document, fmt.Sprintf("/Count %d", pageCount))
+	require.Contains(t, document, fmt.Sprintf("(Page %d of %d) '", pageCount, pageCount))
+	requireValidXref(t, document)
+}
+
+// requireValidXref checks that every entry of the cross-reference table points at its object
+func requireValidXref(t *testing.T, document string) {
+	startxref := regexp.MustCompile(`startxref\n(\d+)\n`).FindStringSubmatch(document)
+	require.NotNil(t, startxref)
+	xref, err := strconv.Atoi(startxref[1])
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(document[xref:], "xref\n"))
+
+	offsets := regexp.MustCompile(`(\d{10}) 00000 n \n`).FindAllStringSubmatch(document[xref:], -1)
+	require.NotEmpty(t, offsets)
+	for i, match := range offsets {
+		offset, err := strconv.Atoi(match[1])
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(document[offset:], fmt.Sprintf("%d 0 obj\n", i+1)))
+	}
+}
+
+func TestEscapePDFText(t *testing.T) {
+	require.Equal(t, `a\(b\)c\\d`, escapePDFText(`a(b)c\d`))
+	require.Equal(t, `Jos\351`, escapePDFText("José"))
+	require.Equal(t, "?", escapePDFText("日"))
+}
+
+func TestWriteCamt053(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, testStatement(2).WriteCamt053(&buf))
+	requireGolden(t, "statement.camt053.xml", buf.Bytes())
+}
+
+func TestWriteMT940(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, testStatement(2).WriteMT940(&buf))
+	requireGolden(t, "statement.mt940.txt", buf.Bytes())
+}
+
+func TestWriteOFX(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, testStatement(2).WriteOFX(&buf))
+	requireGolden(t, "statement.ofx", buf.Bytes())
+}
+
+func TestWriteQIF(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, testStatement(2).WriteQIF(&buf))
+	requireGolden(t, "statement.qif", buf.Bytes())
+}
+
+func TestOFXTransactionIDs(t *testing.T) {
+	statement := testStatement(3)
+
+	// a later export overlapping the first one must give the shared entries the same ids
+	overlapping := testStatement(3)
+	overlapping.Lines = overlapping.Lines[1:]
+
+	var first, second bytes.Buffer
+	require.NoError(t, statement.WriteOFX(&first))
+	require.NoError(t, overlapping.WriteOFX(&second))
+
+	fitIDs := regexp.MustCompile(`<FITID>(\d+)</FITID>`)
+	require.Equal(t, fitIDs.FindAllString(first.String(), -1)[1:], fitIDs.FindAllString(second.String(), -1))
+}
+
+func TestOFXInterestTransaction(t *testing.T) {
+	line := Line{EntryID: 1, Amount: 15, TransferID: 2, CounterpartyName: db.INTEREST_EXPENSE_OWNER}
+	require.Equal(t, OFX_INTEREST, ofxTransactionOf(line, "USD").Type)
+
+	line.CounterpartyName = "bob"
+	require.Equal(t, OFX_CREDIT, ofxTransactionOf(line, "USD").Type)
+}
+
+func TestMT940Narrative(t *testing.T) {
+	line := Line{
+		EntryID:            1,
+		Amount:             -1000,
+		TransferID:         7,
+		Memo:               strings.Repeat("x", 32) + "-rent ü",
+		CounterpartyNumber: "VL970000000002",
+		CounterpartyName:   "bob",
+	}
+
+	lines := strings.Split(mt940Narrative(line), MT940_LINE_END)
+	require.Len(t, lines, 2)
+	require.Len(t, lines[0], MT940_NARRATIVE_LINE_LENGTH)
+	require.Equal(t, ".rent .", lines[1])
+	require.Empty(t, mt940Narrative(Line{EntryID: 1, Amount: 1000}))
+}
+
+func TestMT940Amount(t *testing.T) {
+	require.Equal(t, "1234,56", mt940Amount(123456, "USD"))
+	require.Equal(t, "0,05", mt940Amount(5, "EUR"))
+}
+
+// requireGolden compares output with a file of testdata, rewriting the file when run with -update
+func requireGolden(t *testing.T, name string, output []byte) {
+	path := filepath.Join("testdata", name)
+	if *update {
+		require.NoError(t, os.MkdirAll("testdata", 0755))
+		require.NoError(t, os.WriteFile(path, output, 0644))
+	}
+
+	golden, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, string(golden), string(output))
+}
@@ -14,10 +14,25 @@ type JWTMaker struct {
 }
 
 func (maker JWTMaker) CreateToken(username string, duration time.Duration) (string, error) {
+	return maker.CreatePurposeToken(username, PURPOSE_ACCESS, duration)
+}
+
+func (maker JWTMaker) CreatePurposeToken(username string, purpose string, duration time.Duration) (string, error) {
 	payload, err := NewPayload(username, duration)
 	if err != nil {
 		return "", err
 	}
+	payload.Purpose = purpose
+
+	jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, payload)
+	return jwtToken.SignedString([]byte(maker.secretKey))
+}
+
+func (maker JWTMaker) CreateScopedToken(username string, clientID string, scopes []string, duration time.Duration) (string, error) {
+	payload, err := NewScopedPayload(username, clientID, scopes, duration)
+	if err != nil {
+		return "", err
+	}
 
 	jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, payload)
 	return jwtToken.SignedString([]byte(maker.secretKey))
@@ -28,10 +28,53 @@ func TestJWTMaker(t *testing.T) {
 
 	require.NotZero(t, payload.ID)
 	require.Equal(t, username, payload.Username)
+	require.Equal(t, PURPOSE_ACCESS, payload.Purpose)
 	require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
 	require.WithinDuration(t, expiredAt, payload.ExpiredAt, time.Second)
 }
 
+func TestJWTPurposeToken(t *testing.T) {
+	maker, err := NewJWTMaker(util.RandomString(32))
+	require.NoError(t, err)
+
+	username := util.RandomOwner()
+	token, err := maker.CreatePurposeToken(username, PURPOSE_MFA_CHALLENGE, time.Minute)
+	require.NoError(t, err)
+
+	payload, err := maker.VerifyToken(token)
+	require.NoError(t, err)
+	require.Equal(t, username, payload.Username)
+	require.Equal(t, PURPOSE_MFA_CHALLENGE, payload.Purpose)
+}
+
+func TestJWTScopedToken(t *testing.T) {
+	maker, err := NewJWTMaker(util.RandomString(32))
+	require.NoError(t, err)
+
+	username := util.RandomOwner()
+	scopes := []string{SCOPE_READ_ACCOUNTS}
+	token, err := maker.CreateScopedToken(username, "client", scopes, time.Minute)
+	require.NoError(t, err)
+
+	payload, err := maker.VerifyToken(token)
+	require.NoError(t, err)
+	require.Equal(t, username, payload.Username)
+	require.Equal(t, PURPOSE_ACCESS, payload.Purpose)
+	require.Equal(t, "client", payload.ClientID)
+	require.Equal(t, scopes, payload.Scopes)
+	require.True(t, payload.Scoped())
+
+	// a client acting on its own behalf without scopes is still limited to them
+	token, err = maker.CreateScopedToken("", "client", nil, time.Minute)
+	require.NoError(t, err)
+
+	payload, err = maker.VerifyToken(token)
+	require.NoError(t, err)
+	require.Empty(t, payload.Username)
+	require.True(t, payload.Scoped())
+	require.False(t, payload.HasScope(SCOPE_READ_ACCOUNTS))
+}
+
 func TestExpiredJWTToken(t *testing.T) {
 	maker, err := NewJWTMaker(util.RandomString(32))
 	require.NoError(t, err)
@@ -4,5 +4,10 @@ import "time"
 
 type Maker interface {
 	CreateToken(username string, duration time.Duration) (string, error)
+	// CreatePurposeToken creates a token which is not an access token, e.g. an MFA challenge
+	CreatePurposeToken(username string, purpose string, duration time.Duration) (string, error)
+	// CreateScopedToken creates an access token issued to an OAuth client which is limited to the scopes,
+	// the username is empty when the client acts on its own behalf
+	CreateScopedToken(username string, clientID string, scopes []string, duration time.Duration) (string, error)
 	VerifyToken(token string) (*Payload, error)
 }
@@ -13,10 +13,24 @@ type PasetoMaker struct {
 }
 
 func (maker *PasetoMaker) CreateToken(username string, duration time.Duration) (string, error) {
+	return maker.CreatePurposeToken(username, PURPOSE_ACCESS, duration)
+}
+
+func (maker *PasetoMaker) CreatePurposeToken(username string, purpose string, duration time.Duration) (string, error) {
 	payload, err := NewPayload(username, duration)
 	if err != nil {
 		return "", err
 	}
+	payload.Purpose = purpose
+
+	return maker.paseto.Encrypt(maker.symmertriccKey, payload, nil)
+}
+
+func (maker *PasetoMaker) CreateScopedToken(username string, clientID string, scopes []string, duration time.Duration) (string, error) {
+	payload, err := NewScopedPayload(username, clientID, scopes, duration)
+	if err != nil {
+		return "", err
+	}
 
 	return maker.paseto.Encrypt(maker.symmertriccKey, payload, nil)
 }
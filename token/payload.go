@@ -10,15 +10,51 @@ import (
 var (
 	ErrInvalidToken = errors.New("token is invalid")
 	ErrExpiredToken = errors.New("token has expired")
+	// ErrRevokedToken is not returned by VerifyToken, tokens are revoked by the user store
+	ErrRevokedToken = errors.New("token has been revoked")
+)
+
+// Purposes of a token, only access tokens are accepted by the API
+const (
+	PURPOSE_ACCESS        = "access"
+	PURPOSE_MFA_CHALLENGE = "mfa_challenge"
+)
+
+// Scopes limit what API keys and OAuth clients may do, tokens without scopes have full access to the user's data
+const (
+	SCOPE_READ_ACCOUNTS   = "read:accounts"
+	SCOPE_WRITE_TRANSFERS = "write:transfers"
 )
 
 type Payload struct {
 	ID        uuid.UUID
 	Username  string    `json:"username"`
+	Purpose   string    `json:"purpose"`
+	Scopes    []string  `json:"scopes,omitempty"`
+	ClientID  string    `json:"client_id,omitempty"`
 	IssuedAt  time.Time `json:"issued_at"`
 	ExpiredAt time.Time `json:"expired_at"`
 }
 
+// Scoped reports whether the payload is limited to its scopes, tokens of OAuth clients always are
+func (payload *Payload) Scoped() bool {
+	return payload.Scopes != nil || payload.ClientID != ""
+}
+
+// HasScope reports whether the payload grants the scope
+func (payload *Payload) HasScope(scope string) bool {
+	if !payload.Scoped() {
+		return true
+	}
+
+	for _, s := range payload.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
 func (payload *Payload) Valid() error {
 	if time.Now().After(payload.ExpiredAt) {
 		return ErrExpiredToken
@@ -26,6 +62,18 @@ func (payload *Payload) Valid() error {
 	return nil
 }
 
+// NewScopedPayload creates the payload of an access token issued to an OAuth client
+func NewScopedPayload(username string, clientID string, scopes []string, duration time.Duration) (*Payload, error) {
+	payload, err := NewPayload(username, duration)
+	if err != nil {
+		return nil, err
+	}
+
+	payload.Scopes = scopes
+	payload.ClientID = clientID
+	return payload, nil
+}
+
 func NewPayload(username string, duration time.Duration) (*Payload, error) {
 	tokenID, err := uuid.NewRandom()
 	if err != nil {
@@ -36,6 +84,7 @@ func NewPayload(username string, duration time.Duration) (*Payload, error) {
 	payload := &Payload{
 		ID:        tokenID,
 		Username:  username,
+		Purpose:   PURPOSE_ACCESS,
 		IssuedAt:  issuedAt,
 		ExpiredAt: issuedAt.Add(duration),
 	}
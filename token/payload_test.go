@@ -0,0 +1,26 @@
+package token
+
+import (
+	"github.com/VL-037/go-bank/util"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestPayloadHasScope(t *testing.T) {
+	payload, err := NewPayload(util.RandomOwner(), time.Minute)
+	require.NoError(t, err)
+	require.False(t, payload.Scoped())
+	require.True(t, payload.HasScope(SCOPE_READ_ACCOUNTS))
+	require.True(t, payload.HasScope(SCOPE_WRITE_TRANSFERS))
+
+	payload.Scopes = []string{SCOPE_READ_ACCOUNTS}
+	require.True(t, payload.Scoped())
+	require.True(t, payload.HasScope(SCOPE_READ_ACCOUNTS))
+	require.False(t, payload.HasScope(SCOPE_WRITE_TRANSFERS))
+
+	// an empty scope list grants nothing
+	payload.Scopes = []string{}
+	require.True(t, payload.Scoped())
+	require.False(t, payload.HasScope(SCOPE_READ_ACCOUNTS))
+}
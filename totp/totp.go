@@ -0,0 +1,116 @@
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Parameters of the generated codes, they are the defaults of authenticator apps
+const (
+	DIGITS       = 6
+	PERIOD       = 30 * time.Second
+	SECRET_BYTES = 20
+
+	// SKEW is the number of periods before and after the current one still accepted,
+	// it makes up for clock drift and the time it takes to type a code
+	SKEW = 1
+)
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateSecret returns a random base32 encoded secret
+func GenerateSecret() (string, error) {
+	b := make([]byte, SECRET_BYTES)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate secret: %w", err)
+	}
+
+	return encoding.EncodeToString(b), nil
+}
+
+// ProvisioningURI returns the otpauth URI authenticator apps scan from a QR code
+func ProvisioningURI(issuer string, accountName string, secret string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(DIGITS))
+	query.Set("period", fmt.Sprint(int(PERIOD.Seconds())))
+
+	link := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + issuer + ":" + accountName,
+		RawQuery: query.Encode(),
+	}
+	return link.String()
+}
+
+// Step returns the number of periods since the Unix epoch
+func Step(t time.Time) int64 {
+	return t.Unix() / int64(PERIOD.Seconds())
+}
+
+// GenerateCode returns the code of the secret at the given time
+func GenerateCode(secret string, t time.Time) (string, error) {
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return "", err
+	}
+
+	return code(key, uint64(Step(t)), DIGITS), nil
+}
+
+// Validate checks the code against the periods around the given time and returns the step it matched,
+// callers should reject steps that were used before so a code cannot be replayed
+func Validate(secret string, passcode string, t time.Time) (int64, bool) {
+	key, err := decodeSecret(secret)
+	if err != nil || len(passcode) != DIGITS {
+		return 0, false
+	}
+
+	current := Step(t)
+	for step := current - SKEW; step <= current+SKEW; step++ {
+		if hmac.Equal([]byte(code(key, uint64(step), DIGITS)), []byte(passcode)) {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
+
+func decodeSecret(secret string) ([]byte, error) {
+	key, err := encoding.DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+	if err != nil {
+		return nil, fmt.Errorf("invalid secret: %w", err)
+	}
+
+	return key, nil
+}
+
+// code computes the HOTP value (RFC 4226) of the counter
+func code(key []byte, counter uint64, digits int) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	modulo := uint32(1)
+	for i := 0; i < digits; i++ {
+		modulo *= 10
+	}
+
+	return fmt.Sprintf("%0*d", digits, value%modulo)
+}
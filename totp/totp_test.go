@@ -0,0 +1,73 @@
+package totp
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// RFC6238_SECRET is the SHA-1 key of the test vectors in RFC 6238 appendix B
+const RFC6238_SECRET = "12345678901234567890"
+
+func TestCodeRFC6238(t *testing.T) {
+	testCases := []struct {
+		unix     int64
+		expected string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	}
+
+	for _, tc := range testCases {
+		step := Step(time.Unix(tc.unix, 0))
+		require.Equal(t, tc.expected, code([]byte(RFC6238_SECRET), uint64(step), 8))
+	}
+}
+
+func TestGenerateAndValidate(t *testing.T) {
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+	require.Len(t, secret, 32)
+
+	now := time.Now()
+	passcode, err := GenerateCode(secret, now)
+	require.NoError(t, err)
+	require.Len(t, passcode, DIGITS)
+
+	step, ok := Validate(secret, passcode, now)
+	require.True(t, ok)
+	require.Equal(t, Step(now), step)
+
+	step, ok = Validate(secret, passcode, now.Add(PERIOD))
+	require.True(t, ok)
+	require.Equal(t, Step(now), step)
+
+	_, ok = Validate(secret, passcode, now.Add(3*PERIOD))
+	require.False(t, ok)
+
+	_, ok = Validate(secret, "12345", now)
+	require.False(t, ok)
+
+	_, ok = Validate("not base32!", passcode, now)
+	require.False(t, ok)
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri := ProvisioningURI("go-bank", "alice", "JBSWY3DPEHPK3PXP")
+
+	parsed, err := url.Parse(uri)
+	require.NoError(t, err)
+	require.Equal(t, "otpauth", parsed.Scheme)
+	require.Equal(t, "totp", parsed.Host)
+	require.Equal(t, "/go-bank:alice", parsed.Path)
+	require.Equal(t, "JBSWY3DPEHPK3PXP", parsed.Query().Get("secret"))
+	require.Equal(t, "go-bank", parsed.Query().Get("issuer"))
+	require.Equal(t, "6", parsed.Query().Get("digits"))
+	require.Equal(t, "30", parsed.Query().Get("period"))
+}
@@ -0,0 +1,53 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Account numbers look like an IBAN: a prefix, two check digits and a zero padded basic account number
+const (
+	ACCOUNT_NUMBER_PREFIX      = "VL"
+	ACCOUNT_NUMBER_BBAN_LENGTH = 10
+	ACCOUNT_NUMBER_LENGTH      = len(ACCOUNT_NUMBER_PREFIX) + 2 + ACCOUNT_NUMBER_BBAN_LENGTH
+)
+
+// NewAccountNumber generates a random account number with valid check digits
+func NewAccountNumber() string {
+	bban := fmt.Sprintf("%0*d", ACCOUNT_NUMBER_BBAN_LENGTH, RandomInt(0, 9999999999))
+	return ACCOUNT_NUMBER_PREFIX + accountNumberCheckDigits(bban) + bban
+}
+
+// IsValidAccountNumber verifies the format and the mod-97 checksum of an account number
+func IsValidAccountNumber(number string) bool {
+	if len(number) != ACCOUNT_NUMBER_LENGTH || !strings.HasPrefix(number, ACCOUNT_NUMBER_PREFIX) {
+		return false
+	}
+
+	digits := number[len(ACCOUNT_NUMBER_PREFIX):]
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	// like an IBAN, the number is valid when its rearranged form leaves a remainder of 1
+	return mod97(digits[2:]+ACCOUNT_NUMBER_PREFIX+digits[:2]) == 1
+}
+
+func accountNumberCheckDigits(bban string) string {
+	return fmt.Sprintf("%02d", 98-mod97(bban+ACCOUNT_NUMBER_PREFIX+"00"))
+}
+
+// mod97 computes the ISO 7064 remainder of a string of digits and upper case letters, letters counting as 10 to 35
+func mod97(s string) int {
+	remainder := 0
+	for _, c := range s {
+		if c >= 'A' && c <= 'Z' {
+			remainder = (remainder*100 + int(c-'A') + 10) % 97
+		} else {
+			remainder = (remainder*10 + int(c-'0')) % 97
+		}
+	}
+	return remainder
+}
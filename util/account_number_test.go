@@ -0,0 +1,27 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAccountNumber(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		number := NewAccountNumber()
+		require.Len(t, number, ACCOUNT_NUMBER_LENGTH)
+		require.True(t, IsValidAccountNumber(number), number)
+	}
+}
+
+func TestIsValidAccountNumber(t *testing.T) {
+	require.True(t, IsValidAccountNumber("VL200000000001"))
+	require.True(t, IsValidAccountNumber("VL901234567890"))
+
+	require.False(t, IsValidAccountNumber("VL210000000001"))
+	require.False(t, IsValidAccountNumber("VL200000000010"))
+	require.False(t, IsValidAccountNumber("XX200000000001"))
+	require.False(t, IsValidAccountNumber("VL20000000001"))
+	require.False(t, IsValidAccountNumber("VL2000000000A1"))
+	require.False(t, IsValidAccountNumber(""))
+}
@@ -0,0 +1,8 @@
+package util
+
+// Types an account can have, internal accounts belong to the bank itself
+const (
+	CHECKING_ACCOUNT_TYPE = "checking"
+	SAVINGS_ACCOUNT_TYPE  = "savings"
+	INTERNAL_ACCOUNT_TYPE = "internal"
+)
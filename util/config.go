@@ -12,6 +12,79 @@ type Config struct {
 	ServerAddress       string        `mapstructure:"SERVER_ADDRESS"`
 	TokenSymmetricKey   string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
 	AccessTokenDuration time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+	WorkerInterval      time.Duration `mapstructure:"WORKER_INTERVAL"`
+
+	// TrustedProxies lists the addresses or CIDRs, e.g. "10.0.0.0/8,192.168.1.1", whose X-Forwarded-For
+	// header gives the client address. Empty trusts no proxy and uses the address of the connection
+	TrustedProxies string `mapstructure:"TRUSTED_PROXIES"`
+
+	StandingOrderMaxRetries    int32         `mapstructure:"STANDING_ORDER_MAX_RETRIES"`
+	StandingOrderRetryInterval time.Duration `mapstructure:"STANDING_ORDER_RETRY_INTERVAL"`
+
+	// TransferApprovalThresholds lists amounts per currency, e.g. "USD:100000,EUR:100000"
+	TransferApprovalThresholds string `mapstructure:"TRANSFER_APPROVAL_THRESHOLDS"`
+
+	PaymentRequestDuration time.Duration `mapstructure:"PAYMENT_REQUEST_DURATION"`
+
+	// new beneficiaries cannot receive transfers above the thresholds during the cooling-off period, zero disables it
+	BeneficiaryCoolingOffPeriod     time.Duration `mapstructure:"BENEFICIARY_COOLING_OFF_PERIOD"`
+	BeneficiaryCoolingOffThresholds string        `mapstructure:"BENEFICIARY_COOLING_OFF_THRESHOLDS"`
+
+	// InterestRates lists annual rates of savings accounts in basis points per currency, e.g. "USD:150,EUR:100"
+	InterestRates    string `mapstructure:"INTEREST_RATES"`
+	InterestDayCount string `mapstructure:"INTEREST_DAY_COUNT"`
+
+	// MailSender is one of smtp, file or log
+	MailSender   string `mapstructure:"MAIL_SENDER"`
+	MailFrom     string `mapstructure:"MAIL_FROM"`
+	MailFileDir  string `mapstructure:"MAIL_FILE_DIR"`
+	SMTPHost     string `mapstructure:"SMTP_HOST"`
+	SMTPPort     int    `mapstructure:"SMTP_PORT"`
+	SMTPUsername string `mapstructure:"SMTP_USERNAME"`
+	SMTPPassword string `mapstructure:"SMTP_PASSWORD"`
+
+	// EmailVerificationURL is the link sent to users, the id and token are added as query parameters
+	EmailVerificationURL      string        `mapstructure:"EMAIL_VERIFICATION_URL"`
+	EmailVerificationDuration time.Duration `mapstructure:"EMAIL_VERIFICATION_DURATION"`
+
+	// PasswordResetURL is the link sent to users, the id and token are added as query parameters
+	PasswordResetURL      string        `mapstructure:"PASSWORD_RESET_URL"`
+	PasswordResetDuration time.Duration `mapstructure:"PASSWORD_RESET_DURATION"`
+
+	TOTPIssuer           string        `mapstructure:"TOTP_ISSUER"`
+	MFAChallengeDuration time.Duration `mapstructure:"MFA_CHALLENGE_DURATION"`
+	// users with two-factor authentication confirm transfers above the thresholds with a code
+	MFAStepUpThresholds string `mapstructure:"MFA_STEP_UP_THRESHOLDS"`
+
+	PasswordMinLength     int  `mapstructure:"PASSWORD_MIN_LENGTH"`
+	PasswordRequireUpper  bool `mapstructure:"PASSWORD_REQUIRE_UPPER"`
+	PasswordRequireLower  bool `mapstructure:"PASSWORD_REQUIRE_LOWER"`
+	PasswordRequireDigit  bool `mapstructure:"PASSWORD_REQUIRE_DIGIT"`
+	PasswordRequireSymbol bool `mapstructure:"PASSWORD_REQUIRE_SYMBOL"`
+	// BreachedPasswordsDir holds SHA-1 range files named by their 5 character prefix, empty disables the check
+	BreachedPasswordsDir string `mapstructure:"BREACHED_PASSWORDS_DIR"`
+
+	// PasswordHashAlgorithm is bcrypt or argon2id, stored hashes of other algorithms or parameters
+	// are upgraded on the next login. ARGON2_MEMORY is in KiB
+	PasswordHashAlgorithm string `mapstructure:"PASSWORD_HASH_ALGORITHM"`
+	BcryptCost            int    `mapstructure:"BCRYPT_COST"`
+	Argon2Time            uint32 `mapstructure:"ARGON2_TIME"`
+	Argon2Memory          uint32 `mapstructure:"ARGON2_MEMORY"`
+	Argon2Threads         uint8  `mapstructure:"ARGON2_THREADS"`
+
+	// LoginAttemptStore is postgres or memory, memory counters are not shared between server instances
+	LoginAttemptStore string `mapstructure:"LOGIN_ATTEMPT_STORE"`
+	// failed logins of a username are delayed after LOGIN_DELAY_AFTER and locked after LOGIN_MAX_FAILURES,
+	// an address is locked after LOGIN_IP_MAX_FAILURES
+	LoginDelayAfter      int32         `mapstructure:"LOGIN_DELAY_AFTER"`
+	LoginBaseDelay       time.Duration `mapstructure:"LOGIN_BASE_DELAY"`
+	LoginMaxFailures     int32         `mapstructure:"LOGIN_MAX_FAILURES"`
+	LoginIPMaxFailures   int32         `mapstructure:"LOGIN_IP_MAX_FAILURES"`
+	LoginLockoutDuration time.Duration `mapstructure:"LOGIN_LOCKOUT_DURATION"`
+
+	// OAuth clients get access tokens of ACCESS_TOKEN_DURATION, refresh tokens are rotated on every use
+	OAuthAuthorizationCodeDuration time.Duration `mapstructure:"OAUTH_AUTHORIZATION_CODE_DURATION"`
+	OAuthRefreshTokenDuration      time.Duration `mapstructure:"OAUTH_REFRESH_TOKEN_DURATION"`
 }
 
 // LoadConfig reads configuration from file or env
@@ -1,11 +1,24 @@
 package util
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
 const (
 	IDR = "IDR"
 	USD = "USD"
 	EUR = "EUR"
 )
 
+// currencyDecimals is the number of digits of the minor unit of every supported currency, as in ISO 4217
+var currencyDecimals = map[string]int{
+	IDR: 2,
+	USD: 2,
+	EUR: 2,
+}
+
 func IsSupportedCurrency(currency string) bool {
 	switch currency {
 	case IDR, USD, EUR:
@@ -13,3 +26,48 @@ func IsSupportedCurrency(currency string) bool {
 	}
 	return false
 }
+
+// CurrencyDecimals returns the number of digits of the minor unit of a currency
+func CurrencyDecimals(currency string) int {
+	return currencyDecimals[currency]
+}
+
+// FormatAmount formats an amount in minor units as a decimal, e.g. 123456 USD becomes "1234.56"
+func FormatAmount(amount int64, currency string) string {
+	decimals := CurrencyDecimals(currency)
+
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	digits := fmt.Sprintf("%0*d", decimals+1, amount)
+	if decimals == 0 {
+		return sign + digits
+	}
+	return sign + digits[:len(digits)-decimals] + "." + digits[len(digits)-decimals:]
+}
+
+// ParseCurrencyAmounts parses a list like "USD:100000,EUR:100000" into amounts per currency
+func ParseCurrencyAmounts(s string) (map[string]int64, error) {
+	amounts := make(map[string]int64)
+	if strings.TrimSpace(s) == "" {
+		return amounts, nil
+	}
+
+	for _, pair := range strings.Split(s, ",") {
+		currency, value, found := strings.Cut(strings.TrimSpace(pair), ":")
+		if !found || !IsSupportedCurrency(currency) {
+			return nil, fmt.Errorf("invalid currency amount %q", pair)
+		}
+
+		amount, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || amount < 0 {
+			return nil, fmt.Errorf("invalid currency amount %q", pair)
+		}
+		amounts[currency] = amount
+	}
+
+	return amounts, nil
+}
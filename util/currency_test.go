@@ -0,0 +1,29 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCurrencyAmounts(t *testing.T) {
+	amounts, err := ParseCurrencyAmounts("USD:100000, EUR:50000")
+	require.NoError(t, err)
+	require.Equal(t, map[string]int64{USD: 100000, EUR: 50000}, amounts)
+
+	amounts, err = ParseCurrencyAmounts("")
+	require.NoError(t, err)
+	require.Empty(t, amounts)
+
+	for _, s := range []string{"USD", "XXX:100", "USD:abc", "USD:-1"} {
+		_, err = ParseCurrencyAmounts(s)
+		require.Error(t, err, s)
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	require.Equal(t, "1234.56", FormatAmount(123456, USD))
+	require.Equal(t, "-0.05", FormatAmount(-5, EUR))
+	require.Equal(t, "0.00", FormatAmount(0, IDR))
+	require.Equal(t, "10.00", FormatAmount(1000, USD))
+}
@@ -0,0 +1,66 @@
+package util
+
+import (
+	"math"
+	"math/big"
+	"time"
+)
+
+// Day count conventions used to turn an annual interest rate into a daily one
+const (
+	DAY_COUNT_ACT_365 = "ACT/365"
+	DAY_COUNT_ACT_360 = "ACT/360"
+	DAY_COUNT_30_360  = "30/360"
+)
+
+// INTEREST_SCALE is the precision of accrued interest, tracked in millionths of the minor unit
+const INTEREST_SCALE = 1_000_000
+
+func IsSupportedDayCount(convention string) bool {
+	switch convention {
+	case DAY_COUNT_ACT_365, DAY_COUNT_ACT_360, DAY_COUNT_30_360:
+		return true
+	}
+	return false
+}
+
+// DayCount returns the number of days between two dates, the end being excluded, and the length of a year
+// according to the convention. 30/360 is the European variant, where the 31st counts as the 30th
+func DayCount(convention string, start, end time.Time) (days int64, yearDays int64) {
+	switch convention {
+	case DAY_COUNT_30_360:
+		d1, d2 := start.Day(), end.Day()
+		if d1 == 31 {
+			d1 = 30
+		}
+		if d2 == 31 {
+			d2 = 30
+		}
+		days = int64(360*(end.Year()-start.Year()) + 30*(int(end.Month())-int(start.Month())) + d2 - d1)
+		return days, 360
+	case DAY_COUNT_ACT_360:
+		yearDays = 360
+	default:
+		yearDays = 365
+	}
+
+	days = int64(math.Round(end.Sub(start).Hours() / 24))
+	return days, yearDays
+}
+
+// AccrueInterest computes the interest of a balance between two dates for an annual rate in basis points.
+// The result is in millionths of the minor unit and rounded down, nothing is accrued on a negative balance
+func AccrueInterest(balance int64, annualRate int64, convention string, start, end time.Time) int64 {
+	days, yearDays := DayCount(convention, start, end)
+	if balance <= 0 || annualRate <= 0 || days <= 0 {
+		return 0
+	}
+
+	// balance * rate / 10000 * days / yearDays, in big integers as the intermediate product overflows int64
+	interest := big.NewInt(balance)
+	interest.Mul(interest, big.NewInt(annualRate))
+	interest.Mul(interest, big.NewInt(days))
+	interest.Mul(interest, big.NewInt(INTEREST_SCALE))
+	interest.Quo(interest, big.NewInt(10000*yearDays))
+	return interest.Int64()
+}
@@ -0,0 +1,52 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestDayCount(t *testing.T) {
+	testCases := []struct {
+		convention string
+		start      time.Time
+		end        time.Time
+		days       int64
+		yearDays   int64
+	}{
+		{DAY_COUNT_ACT_365, date(2023, time.January, 31), date(2023, time.March, 1), 29, 365},
+		{DAY_COUNT_ACT_360, date(2023, time.January, 31), date(2023, time.March, 1), 29, 360},
+		{DAY_COUNT_30_360, date(2023, time.January, 31), date(2023, time.March, 1), 31, 360},
+		{DAY_COUNT_30_360, date(2023, time.March, 30), date(2023, time.March, 31), 0, 360},
+		{DAY_COUNT_30_360, date(2023, time.December, 1), date(2024, time.January, 1), 30, 360},
+		{DAY_COUNT_ACT_365, date(2024, time.February, 28), date(2024, time.March, 1), 2, 365},
+	}
+
+	for _, tc := range testCases {
+		days, yearDays := DayCount(tc.convention, tc.start, tc.end)
+		require.Equal(t, tc.days, days, "%s %s - %s", tc.convention, tc.start, tc.end)
+		require.Equal(t, tc.yearDays, yearDays)
+	}
+}
+
+func TestAccrueInterest(t *testing.T) {
+	start := date(2023, time.January, 1)
+
+	// 365 days at 5% on 1000.00 is 50.00, in millionths of the minor unit
+	require.Equal(t, int64(5000*INTEREST_SCALE), AccrueInterest(100000, 500, DAY_COUNT_ACT_365, start, start.AddDate(1, 0, 0)))
+
+	// a single day is a fraction of the minor unit, rounded down
+	require.Equal(t, int64(13698630), AccrueInterest(100000, 500, DAY_COUNT_ACT_365, start, start.AddDate(0, 0, 1)))
+	require.Equal(t, int64(13888888), AccrueInterest(100000, 500, DAY_COUNT_ACT_360, start, start.AddDate(0, 0, 1)))
+
+	// balances larger than int64 / scale do not overflow
+	require.Equal(t, int64(1_000_000_000*INTEREST_SCALE), AccrueInterest(10_000_000_000, 1000, DAY_COUNT_30_360, start, start.AddDate(1, 0, 0)))
+
+	require.Zero(t, AccrueInterest(-100000, 500, DAY_COUNT_ACT_365, start, start.AddDate(0, 0, 1)))
+	require.Zero(t, AccrueInterest(100000, 0, DAY_COUNT_ACT_365, start, start.AddDate(0, 0, 1)))
+}
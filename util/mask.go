@@ -0,0 +1,16 @@
+package util
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// MaskName keeps only the first letter of every word of a name, e.g. "John Doe" becomes "J*** D***"
+func MaskName(name string) string {
+	words := strings.Fields(name)
+	for i, word := range words {
+		first, _ := utf8.DecodeRuneInString(word)
+		words[i] = string(first) + "***"
+	}
+	return strings.Join(words, " ")
+}
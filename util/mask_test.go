@@ -0,0 +1,13 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaskName(t *testing.T) {
+	require.Equal(t, "J*** D***", MaskName("John Doe"))
+	require.Equal(t, "É*** Z***", MaskName("  Émile   Zola "))
+	require.Equal(t, "", MaskName(""))
+}
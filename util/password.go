@@ -1,12 +1,93 @@
 package util
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
+	"strings"
 )
 
-func HashPassword(password string) (string, error) {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+const (
+	HASH_BCRYPT   = "bcrypt"
+	HASH_ARGON2ID = "argon2id"
+)
+
+const (
+	ARGON2_TIME        = 1
+	ARGON2_MEMORY      = 64 * 1024
+	ARGON2_THREADS     = 4
+	ARGON2_KEY_LENGTH  = 32
+	ARGON2_SALT_LENGTH = 16
+)
+
+var (
+	ErrMismatchedHashAndPassword = bcrypt.ErrMismatchedHashAndPassword
+	ErrUnknownPasswordHash       = errors.New("unknown password hash format")
+)
+
+// PasswordHasher hashes new passwords with one algorithm and checks hashes of every supported algorithm.
+// Hashes are self-describing: bcrypt hashes start with $2a$, $2b$ or $2y$ and
+// Argon2id hashes use the $argon2id$v=19$m=<memory>,t=<time>,p=<threads>$<salt>$<key> format
+type PasswordHasher interface {
+	HashPassword(password string) (string, error)
+	CheckPassword(hashedPassword string, password string) error
+	// NeedsRehash reports whether the hash was made with another algorithm or outdated parameters
+	NeedsRehash(hashedPassword string) bool
+}
+
+// NewPasswordHasher creates the hasher of the configured algorithm, zero parameters take the defaults
+func NewPasswordHasher(config Config) (PasswordHasher, error) {
+	switch config.PasswordHashAlgorithm {
+	case HASH_BCRYPT, "":
+		return NewBcryptHasher(config.BcryptCost)
+	case HASH_ARGON2ID:
+		return NewArgon2idHasher(config.Argon2Time, config.Argon2Memory, config.Argon2Threads), nil
+	default:
+		return nil, fmt.Errorf("unsupported password hash algorithm %q", config.PasswordHashAlgorithm)
+	}
+}
+
+// CheckPassword compares the password with a hash of any supported algorithm
+func CheckPassword(hashedPassword string, password string) error {
+	switch {
+	case isBcryptHash(hashedPassword):
+		return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+	case strings.HasPrefix(hashedPassword, "$"+HASH_ARGON2ID+"$"):
+		return checkArgon2idPassword(hashedPassword, password)
+	default:
+		return ErrUnknownPasswordHash
+	}
+}
+
+func isBcryptHash(hashedPassword string) bool {
+	return strings.HasPrefix(hashedPassword, "$2a$") ||
+		strings.HasPrefix(hashedPassword, "$2b$") ||
+		strings.HasPrefix(hashedPassword, "$2y$")
+}
+
+// BcryptHasher hashes passwords with bcrypt
+type BcryptHasher struct {
+	cost int
+}
+
+// NewBcryptHasher creates a bcrypt hasher, a zero cost takes bcrypt.DefaultCost
+func NewBcryptHasher(cost int) (*BcryptHasher, error) {
+	if cost == 0 {
+		cost = bcrypt.DefaultCost
+	}
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		return nil, fmt.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+	}
+
+	return &BcryptHasher{cost: cost}, nil
+}
+
+func (hasher *BcryptHasher) HashPassword(password string) (string, error) {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), hasher.cost)
 	if err != nil {
 		return "", fmt.Errorf("failed to hash password: %w", err)
 	}
@@ -14,6 +95,121 @@ func HashPassword(password string) (string, error) {
 	return string(hashedPassword), nil
 }
 
-func CheckPassword(hashedPassword string, password string) error {
-	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+func (hasher *BcryptHasher) CheckPassword(hashedPassword string, password string) error {
+	return CheckPassword(hashedPassword, password)
+}
+
+func (hasher *BcryptHasher) NeedsRehash(hashedPassword string) bool {
+	if !isBcryptHash(hashedPassword) {
+		return true
+	}
+
+	cost, err := bcrypt.Cost([]byte(hashedPassword))
+	return err != nil || cost < hasher.cost
+}
+
+// Argon2idHasher hashes passwords with Argon2id
+type Argon2idHasher struct {
+	params argon2Params
+}
+
+type argon2Params struct {
+	time    uint32
+	memory  uint32
+	threads uint8
+}
+
+// NewArgon2idHasher creates an Argon2id hasher, memory is in KiB and zero parameters take the defaults
+func NewArgon2idHasher(time uint32, memory uint32, threads uint8) *Argon2idHasher {
+	if time == 0 {
+		time = ARGON2_TIME
+	}
+	if memory == 0 {
+		memory = ARGON2_MEMORY
+	}
+	if threads == 0 {
+		threads = ARGON2_THREADS
+	}
+
+	return &Argon2idHasher{params: argon2Params{time: time, memory: memory, threads: threads}}
+}
+
+func (hasher *Argon2idHasher) HashPassword(password string) (string, error) {
+	salt := make([]byte, ARGON2_SALT_LENGTH)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	p := hasher.params
+	key := argon2.IDKey([]byte(password), salt, p.time, p.memory, p.threads, ARGON2_KEY_LENGTH)
+
+	return fmt.Sprintf("$%s$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		HASH_ARGON2ID, argon2.Version, p.memory, p.time, p.threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func (hasher *Argon2idHasher) CheckPassword(hashedPassword string, password string) error {
+	return CheckPassword(hashedPassword, password)
+}
+
+func (hasher *Argon2idHasher) NeedsRehash(hashedPassword string) bool {
+	params, _, key, err := decodeArgon2idHash(hashedPassword)
+	if err != nil {
+		return true
+	}
+
+	return params.time < hasher.params.time ||
+		params.memory < hasher.params.memory ||
+		params.threads != hasher.params.threads ||
+		len(key) != ARGON2_KEY_LENGTH
+}
+
+func checkArgon2idPassword(hashedPassword string, password string) error {
+	params, salt, key, err := decodeArgon2idHash(hashedPassword)
+	if err != nil {
+		return err
+	}
+
+	otherKey := argon2.IDKey([]byte(password), salt, params.time, params.memory, params.threads, uint32(len(key)))
+	if subtle.ConstantTimeCompare(key, otherKey) != 1 {
+		return ErrMismatchedHashAndPassword
+	}
+
+	return nil
+}
+
+func decodeArgon2idHash(hashedPassword string) (params argon2Params, salt []byte, key []byte, err error) {
+	// "", "argon2id", "v=19", "m=65536,t=1,p=4", salt, key
+	parts := strings.Split(hashedPassword, "$")
+	if len(parts) != 6 || parts[1] != HASH_ARGON2ID {
+		err = ErrUnknownPasswordHash
+		return
+	}
+
+	var version int
+	if _, err = fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		err = ErrUnknownPasswordHash
+		return
+	}
+
+	if _, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.time, &params.threads); err != nil {
+		err = ErrUnknownPasswordHash
+		return
+	}
+
+	salt, err = base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		err = ErrUnknownPasswordHash
+		return
+	}
+
+	key, err = base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		err = ErrUnknownPasswordHash
+		return
+	}
+
+	return
 }
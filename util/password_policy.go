@@ -0,0 +1,137 @@
+package util
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// MIN_CONTAINED_LENGTH keeps very short usernames or email parts from rejecting most passwords
+const MIN_CONTAINED_LENGTH = 3
+
+// MAX_PASSWORD_BYTES is the most bcrypt hashes, longer passwords would be cut off or rejected by it
+const MAX_PASSWORD_BYTES = 72
+
+// PasswordPolicy describes the passwords users may choose
+type PasswordPolicy struct {
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	// BreachedPasswordsDir holds range files of breached SHA-1 hashes, e.g. 21BD1.txt with
+	// SUFFIX:COUNT lines, so only the file of the hash prefix is read. Empty disables the check
+	BreachedPasswordsDir string
+}
+
+// PasswordPolicyError lists every rule a password breaks
+type PasswordPolicyError struct {
+	Violations []string
+}
+
+func (err *PasswordPolicyError) Error() string {
+	return strings.Join(err.Violations, "; ")
+}
+
+// Validate checks the password of the user against the policy, the error is a *PasswordPolicyError
+// when the password breaks a rule
+func (policy PasswordPolicy) Validate(password string, username string, email string) error {
+	var violations []string
+
+	if len([]rune(password)) < policy.MinLength {
+		violations = append(violations, fmt.Sprintf("password must be at least %d characters long", policy.MinLength))
+	}
+	if len(password) > MAX_PASSWORD_BYTES {
+		violations = append(violations, fmt.Sprintf("password must be at most %d bytes long", MAX_PASSWORD_BYTES))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case !unicode.IsLetter(r):
+			hasSymbol = true
+		}
+	}
+	if policy.RequireUpper && !hasUpper {
+		violations = append(violations, "password must contain an uppercase letter")
+	}
+	if policy.RequireLower && !hasLower {
+		violations = append(violations, "password must contain a lowercase letter")
+	}
+	if policy.RequireDigit && !hasDigit {
+		violations = append(violations, "password must contain a digit")
+	}
+	if policy.RequireSymbol && !hasSymbol {
+		violations = append(violations, "password must contain a symbol")
+	}
+
+	lowerPassword := strings.ToLower(password)
+	if contains(lowerPassword, username) {
+		violations = append(violations, "password must not contain the username")
+	}
+	localPart, _, _ := strings.Cut(email, "@")
+	if contains(lowerPassword, email) || contains(lowerPassword, localPart) {
+		violations = append(violations, "password must not contain the email address")
+	}
+
+	breached, err := policy.breached(password)
+	if err != nil {
+		return err
+	}
+	if breached {
+		violations = append(violations, "password has appeared in a data breach, choose another one")
+	}
+
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+func contains(lowerPassword string, part string) bool {
+	return len(part) >= MIN_CONTAINED_LENGTH && strings.Contains(lowerPassword, strings.ToLower(part))
+}
+
+// breached looks the SHA-1 of the password up in the range file of its first five hex digits
+func (policy PasswordPolicy) breached(password string) (bool, error) {
+	if policy.BreachedPasswordsDir == "" {
+		return false, nil
+	}
+
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	file, err := os.Open(filepath.Join(policy.BreachedPasswordsDir, hash[:5]+".txt"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, fmt.Errorf("cannot read breached passwords: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		suffix, _, _ := strings.Cut(scanner.Text(), ":")
+		if strings.EqualFold(strings.TrimSpace(suffix), hash[5:]) {
+			return true, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return false, fmt.Errorf("cannot read breached passwords: %w", err)
+	}
+
+	return false, nil
+}
@@ -0,0 +1,106 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordPolicy(t *testing.T) {
+	dir := t.TempDir()
+	// SHA-1 of "password123" is CBFDAC6008F9CAB4083784CBD1874F76618D2A97
+	rangeFile := "0018A45C4D1DEF81644B54AB7F969B88D65:1\nC6008F9CAB4083784CBD1874F76618D2A97:2254650\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "CBFDA.txt"), []byte(rangeFile), 0o644))
+
+	policy := PasswordPolicy{
+		MinLength:            10,
+		RequireUpper:         true,
+		RequireLower:         true,
+		RequireDigit:         true,
+		RequireSymbol:        true,
+		BreachedPasswordsDir: dir,
+	}
+
+	testCases := []struct {
+		name       string
+		password   string
+		violations []string
+	}{
+		{
+			name:     "OK",
+			password: "Correct-Horse-7",
+		},
+		{
+			name:     "Too Short",
+			password: "Aa1-",
+			violations: []string{
+				"password must be at least 10 characters long",
+			},
+		},
+		{
+			name:     "Too Long",
+			password: "Correct-Horse-7-" + strings.Repeat("é", 30),
+			violations: []string{
+				"password must be at most 72 bytes long",
+			},
+		},
+		{
+			name:     "Missing Classes",
+			password: "abcdefghijkl",
+			violations: []string{
+				"password must contain an uppercase letter",
+				"password must contain a digit",
+				"password must contain a symbol",
+			},
+		},
+		{
+			name:     "Contains Username",
+			password: "My-ALICE-Password-1",
+			violations: []string{
+				"password must not contain the username",
+			},
+		},
+		{
+			name:     "Contains Email",
+			password: "Wonderland.Rabbit-1",
+			violations: []string{
+				"password must not contain the email address",
+			},
+		},
+		{
+			name:     "Breached",
+			password: "password123",
+			violations: []string{
+				"password must contain an uppercase letter",
+				"password must contain a symbol",
+				"password has appeared in a data breach, choose another one",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := policy.Validate(tc.password, "alice", "wonderland.rabbit@email.com")
+			if tc.violations == nil {
+				require.NoError(t, err)
+				return
+			}
+
+			var policyErr *PasswordPolicyError
+			require.ErrorAs(t, err, &policyErr)
+			require.Equal(t, tc.violations, policyErr.Violations)
+		})
+	}
+}
+
+func TestPasswordPolicyDefaults(t *testing.T) {
+	// the zero policy accepts anything and short usernames do not count as contained
+	require.NoError(t, PasswordPolicy{}.Validate("ab", "ab", "ab@email.com"))
+
+	// a missing range file means the prefix has no breached hashes
+	policy := PasswordPolicy{BreachedPasswordsDir: t.TempDir()}
+	require.NoError(t, policy.Validate("password123", "alice", "alice@email.com"))
+}
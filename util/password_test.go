@@ -3,13 +3,17 @@ package util
 import (
 	"github.com/stretchr/testify/require"
 	"golang.org/x/crypto/bcrypt"
+	"strings"
 	"testing"
 )
 
 func TestPassword(t *testing.T) {
 	password := RandomString(6)
 
-	hashedPassword1, err := HashPassword(password)
+	hasher, err := NewBcryptHasher(0)
+	require.NoError(t, err)
+
+	hashedPassword1, err := hasher.HashPassword(password)
 	require.NoError(t, err)
 	require.NotEmpty(t, hashedPassword1)
 
@@ -20,8 +24,100 @@ func TestPassword(t *testing.T) {
 	err = CheckPassword(hashedPassword1, wrongPassword)
 	require.EqualError(t, err, bcrypt.ErrMismatchedHashAndPassword.Error())
 
-	hashedPassword2, err := HashPassword(hashedPassword1)
+	hashedPassword2, err := hasher.HashPassword(hashedPassword1)
 	require.NoError(t, err)
 	require.NotEmpty(t, hashedPassword2)
 	require.NotEqual(t, hashedPassword1, hashedPassword2)
 }
+
+func TestBcryptHasher(t *testing.T) {
+	password := RandomString(8)
+
+	hasher, err := NewBcryptHasher(bcrypt.MinCost)
+	require.NoError(t, err)
+
+	hashedPassword, err := hasher.HashPassword(password)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(hashedPassword, "$2a$04$"))
+	require.NoError(t, hasher.CheckPassword(hashedPassword, password))
+	require.ErrorIs(t, hasher.CheckPassword(hashedPassword, RandomString(8)), ErrMismatchedHashAndPassword)
+	require.False(t, hasher.NeedsRehash(hashedPassword))
+
+	strongerHasher, err := NewBcryptHasher(bcrypt.MinCost + 1)
+	require.NoError(t, err)
+	require.True(t, strongerHasher.NeedsRehash(hashedPassword))
+
+	_, err = NewBcryptHasher(bcrypt.MaxCost + 1)
+	require.Error(t, err)
+}
+
+func TestArgon2idHasher(t *testing.T) {
+	password := RandomString(8)
+	hasher := NewArgon2idHasher(1, 1024, 1)
+
+	hashedPassword, err := hasher.HashPassword(password)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(hashedPassword, "$argon2id$v=19$m=1024,t=1,p=1$"))
+	require.NoError(t, hasher.CheckPassword(hashedPassword, password))
+	require.ErrorIs(t, hasher.CheckPassword(hashedPassword, RandomString(8)), ErrMismatchedHashAndPassword)
+	require.False(t, hasher.NeedsRehash(hashedPassword))
+
+	// the salt is random
+	hashedPassword2, err := hasher.HashPassword(password)
+	require.NoError(t, err)
+	require.NotEqual(t, hashedPassword, hashedPassword2)
+
+	require.True(t, NewArgon2idHasher(2, 1024, 1).NeedsRehash(hashedPassword))
+	require.True(t, NewArgon2idHasher(1, 2048, 1).NeedsRehash(hashedPassword))
+	require.True(t, NewArgon2idHasher(1, 1024, 2).NeedsRehash(hashedPassword))
+}
+
+func TestPasswordHasherMigration(t *testing.T) {
+	password := RandomString(8)
+
+	bcryptHasher, err := NewBcryptHasher(0)
+	require.NoError(t, err)
+
+	bcryptHash, err := bcryptHasher.HashPassword(password)
+	require.NoError(t, err)
+
+	// hashes of the previous algorithm are still accepted but flagged for a rehash
+	argon2idHasher := NewArgon2idHasher(1, 1024, 1)
+	require.NoError(t, argon2idHasher.CheckPassword(bcryptHash, password))
+	require.True(t, argon2idHasher.NeedsRehash(bcryptHash))
+
+	argon2idHash, err := argon2idHasher.HashPassword(password)
+	require.NoError(t, err)
+
+	require.NoError(t, bcryptHasher.CheckPassword(argon2idHash, password))
+	require.True(t, bcryptHasher.NeedsRehash(argon2idHash))
+	require.False(t, bcryptHasher.NeedsRehash(bcryptHash))
+}
+
+func TestCheckPasswordUnknownHash(t *testing.T) {
+	testCases := []string{
+		"",
+		RandomString(60),
+		"$argon2id$v=19$m=1024,t=1,p=1$salt",
+		"$argon2id$v=18$m=1024,t=1,p=1$c2FsdHNhbHQ$a2V5",
+		"$argon2id$v=19$m=x,t=1,p=1$c2FsdHNhbHQ$a2V5",
+		"$argon2id$v=19$m=1024,t=1,p=1$!!!$a2V5",
+	}
+
+	for _, hashedPassword := range testCases {
+		require.ErrorIs(t, CheckPassword(hashedPassword, "secret"), ErrUnknownPasswordHash, hashedPassword)
+	}
+}
+
+func TestNewPasswordHasher(t *testing.T) {
+	hasher, err := NewPasswordHasher(Config{})
+	require.NoError(t, err)
+	require.IsType(t, &BcryptHasher{}, hasher)
+
+	hasher, err = NewPasswordHasher(Config{PasswordHashAlgorithm: HASH_ARGON2ID})
+	require.NoError(t, err)
+	require.IsType(t, &Argon2idHasher{}, hasher)
+
+	_, err = NewPasswordHasher(Config{PasswordHashAlgorithm: "md5"})
+	require.Error(t, err)
+}
@@ -0,0 +1,62 @@
+package util
+
+import "time"
+
+const (
+	DAILY   = "daily"
+	WEEKLY  = "weekly"
+	MONTHLY = "monthly"
+)
+
+func IsSupportedFrequency(frequency string) bool {
+	switch frequency {
+	case DAILY, WEEKLY, MONTHLY:
+		return true
+	}
+	return false
+}
+
+// NextOccurrence returns the first occurrence of a recurrence which is strictly after the given time.
+// Occurrences happen at the clock time of startAt, never before startAt.
+// Weekly recurrences repeat on the weekday of startAt, monthly ones on dayOfMonth,
+// falling back to the last day of the month when it is shorter
+func NextOccurrence(frequency string, dayOfMonth int, startAt time.Time, after time.Time) time.Time {
+	if after.Before(startAt) {
+		after = startAt.Add(-time.Nanosecond)
+	}
+	after = after.In(startAt.Location())
+
+	hour, min, sec := startAt.Clock()
+	year, month, day := after.Date()
+	candidate := time.Date(year, month, day, hour, min, sec, startAt.Nanosecond(), startAt.Location())
+
+	switch frequency {
+	case WEEKLY:
+		days := (int(startAt.Weekday()) - int(candidate.Weekday()) + 7) % 7
+		candidate = candidate.AddDate(0, 0, days)
+		if !candidate.After(after) {
+			candidate = candidate.AddDate(0, 0, 7)
+		}
+	case MONTHLY:
+		candidate = dayInMonth(candidate, dayOfMonth)
+		if !candidate.After(after) {
+			nextMonth := time.Date(year, month+1, 1, hour, min, sec, startAt.Nanosecond(), startAt.Location())
+			candidate = dayInMonth(nextMonth, dayOfMonth)
+		}
+	default:
+		if !candidate.After(after) {
+			candidate = candidate.AddDate(0, 0, 1)
+		}
+	}
+
+	return candidate
+}
+
+// dayInMonth moves t to the given day of its month, clamped to the last day of the month
+func dayInMonth(t time.Time, day int) time.Time {
+	lastDay := time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, t.Location()).Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return time.Date(t.Year(), t.Month(), day, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+}
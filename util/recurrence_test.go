@@ -0,0 +1,87 @@
+package util
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestNextOccurrence(t *testing.T) {
+	// Wednesday
+	startAt := time.Date(2023, time.January, 4, 9, 30, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name       string
+		frequency  string
+		dayOfMonth int
+		after      time.Time
+		expected   time.Time
+	}{
+		{
+			name:      "DAILY - First Occurrence Is Start",
+			frequency: DAILY,
+			after:     startAt.Add(-time.Hour),
+			expected:  startAt,
+		},
+		{
+			name:      "DAILY - Next Day",
+			frequency: DAILY,
+			after:     startAt,
+			expected:  time.Date(2023, time.January, 5, 9, 30, 0, 0, time.UTC),
+		},
+		{
+			name:      "DAILY - Same Day Later",
+			frequency: DAILY,
+			after:     time.Date(2023, time.January, 10, 8, 0, 0, 0, time.UTC),
+			expected:  time.Date(2023, time.January, 10, 9, 30, 0, 0, time.UTC),
+		},
+		{
+			name:      "WEEKLY - Same Weekday",
+			frequency: WEEKLY,
+			after:     startAt,
+			expected:  time.Date(2023, time.January, 11, 9, 30, 0, 0, time.UTC),
+		},
+		{
+			name:      "WEEKLY - From Friday",
+			frequency: WEEKLY,
+			after:     time.Date(2023, time.January, 13, 12, 0, 0, 0, time.UTC),
+			expected:  time.Date(2023, time.January, 18, 9, 30, 0, 0, time.UTC),
+		},
+		{
+			name:       "MONTHLY - Later This Month",
+			frequency:  MONTHLY,
+			dayOfMonth: 25,
+			after:      startAt,
+			expected:   time.Date(2023, time.January, 25, 9, 30, 0, 0, time.UTC),
+		},
+		{
+			name:       "MONTHLY - Next Month",
+			frequency:  MONTHLY,
+			dayOfMonth: 1,
+			after:      startAt,
+			expected:   time.Date(2023, time.February, 1, 9, 30, 0, 0, time.UTC),
+		},
+		{
+			name:       "MONTHLY - Clamped To Last Day",
+			frequency:  MONTHLY,
+			dayOfMonth: 31,
+			after:      time.Date(2023, time.January, 31, 10, 0, 0, 0, time.UTC),
+			expected:   time.Date(2023, time.February, 28, 9, 30, 0, 0, time.UTC),
+		},
+		{
+			name:       "MONTHLY - Year Rollover",
+			frequency:  MONTHLY,
+			dayOfMonth: 15,
+			after:      time.Date(2023, time.December, 20, 10, 0, 0, 0, time.UTC),
+			expected:   time.Date(2024, time.January, 15, 9, 30, 0, 0, time.UTC),
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			next := NextOccurrence(tc.frequency, tc.dayOfMonth, startAt, tc.after)
+			require.Equal(t, tc.expected, next)
+		})
+	}
+}
@@ -0,0 +1,8 @@
+package util
+
+// Roles a user can have
+const (
+	DEPOSITOR_ROLE = "depositor"
+	APPROVER_ROLE  = "approver"
+	ADMIN_ROLE     = "admin"
+)
@@ -0,0 +1,27 @@
+package util
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+const SECURE_TOKEN_BYTES = 32
+
+// NewSecureToken returns a random URL-safe token for links sent to users
+func NewSecureToken() (string, error) {
+	b := make([]byte, SECURE_TOKEN_BYTES)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashToken returns the SHA-256 of a token so only its hash has to be stored
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,25 @@
+package util
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecureToken(t *testing.T) {
+	token1, err := NewSecureToken()
+	require.NoError(t, err)
+
+	decoded, err := base64.RawURLEncoding.DecodeString(token1)
+	require.NoError(t, err)
+	require.Len(t, decoded, SECURE_TOKEN_BYTES)
+
+	token2, err := NewSecureToken()
+	require.NoError(t, err)
+	require.NotEqual(t, token1, token2)
+
+	require.Len(t, HashToken(token1), 64)
+	require.Equal(t, HashToken(token1), HashToken(token1))
+	require.NotEqual(t, HashToken(token1), HashToken(token2))
+}
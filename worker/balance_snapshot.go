@@ -0,0 +1,30 @@
+package worker
+
+import (
+	"context"
+	db "github.com/VL-037/go-bank/db/sqlc"
+	"log"
+	"time"
+)
+
+// SnapshotBalances stores the balance every account had at the end of the previous day.
+// Accounts already snapshotted for that day are skipped, so the task can run more often than daily
+func SnapshotBalances(store db.Store) Task {
+	return func(ctx context.Context) error {
+		now := time.Now().UTC()
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+		count, err := store.CreateBalanceSnapshots(ctx, db.CreateBalanceSnapshotsParams{
+			SnapshotDate: today.AddDate(0, 0, -1),
+			EndTime:      today,
+		})
+		if err != nil {
+			return err
+		}
+
+		if count > 0 {
+			log.Printf("%d balance snapshots taken for %s", count, today.AddDate(0, 0, -1).Format("2006-01-02"))
+		}
+		return nil
+	}
+}
@@ -0,0 +1,45 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	mockdb "github.com/VL-037/go-bank/db/mock"
+	db "github.com/VL-037/go-bank/db/sqlc"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestSnapshotBalances(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		CreateBalanceSnapshots(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.CreateBalanceSnapshotsParams) (int64, error) {
+			require.Equal(t, arg.EndTime, arg.EndTime.Truncate(24*time.Hour))
+			require.WithinDuration(t, time.Now(), arg.EndTime, 24*time.Hour)
+			require.Equal(t, arg.EndTime.AddDate(0, 0, -1), arg.SnapshotDate)
+			return 3, nil
+		})
+
+	err := SnapshotBalances(store)(context.Background())
+	require.NoError(t, err)
+}
+
+func TestSnapshotBalancesError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		CreateBalanceSnapshots(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(int64(0), sql.ErrConnDone)
+
+	err := SnapshotBalances(store)(context.Background())
+	require.ErrorIs(t, err, sql.ErrConnDone)
+}
@@ -0,0 +1,25 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	db "github.com/VL-037/go-bank/db/sqlc"
+	"log"
+)
+
+// ExpireHolds releases every active hold whose expiry has passed
+func ExpireHolds(store db.Store) Task {
+	return func(ctx context.Context) error {
+		for {
+			response, err := store.ExpireHoldTx(ctx)
+			if err != nil {
+				if err == sql.ErrNoRows {
+					return nil
+				}
+				return err
+			}
+
+			log.Printf("hold [%d] expired", response.Hold.ID)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	mockdb "github.com/VL-037/go-bank/db/mock"
+	db "github.com/VL-037/go-bank/db/sqlc"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestExpireHolds(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	gomock.InOrder(
+		store.EXPECT().
+			ExpireHoldTx(gomock.Any()).
+			Times(3).
+			Return(db.HoldTxResponse{}, nil),
+		store.EXPECT().
+			ExpireHoldTx(gomock.Any()).
+			Times(1).
+			Return(db.HoldTxResponse{}, sql.ErrNoRows),
+	)
+
+	err := ExpireHolds(store)(context.Background())
+	require.NoError(t, err)
+}
@@ -0,0 +1,36 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	db "github.com/VL-037/go-bank/db/sqlc"
+	"log"
+	"time"
+)
+
+// AccrueInterest accrues the interest of every savings account up to the current day.
+// Accounts already accrued today are skipped, so the task can run more often than daily
+func AccrueInterest(store db.Store, rates map[string]int64, dayCount string) Task {
+	return func(ctx context.Context) error {
+		now := time.Now().UTC()
+		arg := db.AccrueInterestTxParams{
+			Today:    time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC),
+			Rates:    rates,
+			DayCount: dayCount,
+		}
+
+		for {
+			response, err := store.AccrueInterestTx(ctx, arg)
+			if err != nil {
+				if err == sql.ErrNoRows {
+					return nil
+				}
+				return err
+			}
+
+			for _, capitalization := range response.Capitalizations {
+				log.Printf("interest of %d capitalized into account [%d]", capitalization.Transfer.Amount, response.Account.ID)
+			}
+		}
+	}
+}
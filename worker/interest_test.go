@@ -0,0 +1,41 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	mockdb "github.com/VL-037/go-bank/db/mock"
+	db "github.com/VL-037/go-bank/db/sqlc"
+	"github.com/VL-037/go-bank/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestAccrueInterest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	rates := map[string]int64{util.USD: 200}
+
+	store := mockdb.NewMockStore(ctrl)
+	gomock.InOrder(
+		store.EXPECT().
+			AccrueInterestTx(gomock.Any(), gomock.Any()).
+			Times(2).
+			DoAndReturn(func(_ context.Context, arg db.AccrueInterestTxParams) (db.AccrueInterestTxResponse, error) {
+				require.Equal(t, rates, arg.Rates)
+				require.Equal(t, util.DAY_COUNT_ACT_360, arg.DayCount)
+				require.Equal(t, arg.Today, arg.Today.Truncate(24*time.Hour))
+				require.WithinDuration(t, time.Now(), arg.Today, 24*time.Hour)
+				return db.AccrueInterestTxResponse{}, nil
+			}),
+		store.EXPECT().
+			AccrueInterestTx(gomock.Any(), gomock.Any()).
+			Times(1).
+			Return(db.AccrueInterestTxResponse{}, sql.ErrNoRows),
+	)
+
+	err := AccrueInterest(store, rates, util.DAY_COUNT_ACT_360)(context.Background())
+	require.NoError(t, err)
+}
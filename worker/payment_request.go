@@ -0,0 +1,22 @@
+package worker
+
+import (
+	"context"
+	db "github.com/VL-037/go-bank/db/sqlc"
+	"log"
+)
+
+// ExpirePaymentRequests marks every pending payment request whose expiry has passed as expired
+func ExpirePaymentRequests(store db.Store) Task {
+	return func(ctx context.Context) error {
+		count, err := store.ExpirePaymentRequests(ctx)
+		if err != nil {
+			return err
+		}
+
+		if count > 0 {
+			log.Printf("%d payment requests expired", count)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,26 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	mockdb "github.com/VL-037/go-bank/db/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestExpirePaymentRequests(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().ExpirePaymentRequests(gomock.Any()).Times(1).Return(int64(2), nil)
+
+	err := ExpirePaymentRequests(store)(context.Background())
+	require.NoError(t, err)
+
+	store.EXPECT().ExpirePaymentRequests(gomock.Any()).Times(1).Return(int64(0), sql.ErrConnDone)
+
+	err = ExpirePaymentRequests(store)(context.Background())
+	require.ErrorIs(t, err, sql.ErrConnDone)
+}
@@ -0,0 +1,59 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Task is a unit of background work executed periodically by the Runner
+type Task func(ctx context.Context) error
+
+type registeredTask struct {
+	name     string
+	interval time.Duration
+	task     Task
+}
+
+// Runner runs background tasks inside the server process
+type Runner struct {
+	tasks []registeredTask
+}
+
+// NewRunner creates a new Runner without any task
+func NewRunner() *Runner {
+	return &Runner{}
+}
+
+// Register adds a task to be executed every interval
+func (runner *Runner) Register(name string, interval time.Duration, task Task) {
+	runner.tasks = append(runner.tasks, registeredTask{
+		name:     name,
+		interval: interval,
+		task:     task,
+	})
+}
+
+// Start runs every registered task on its own ticker until ctx is cancelled
+func (runner *Runner) Start(ctx context.Context) {
+	for _, t := range runner.tasks {
+		go run(ctx, t)
+	}
+}
+
+func run(ctx context.Context, t registeredTask) {
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := t.task(ctx); err != nil {
+			log.Printf("worker task %s failed: %v", t.name, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	db "github.com/VL-037/go-bank/db/sqlc"
+	"log"
+)
+
+// ExecuteScheduledTransfers executes every scheduled transfer which is due,
+// amounts above the approval threshold of their currency fail instead
+func ExecuteScheduledTransfers(store db.Store, approvalThresholds map[string]int64) Task {
+	return func(ctx context.Context) error {
+		arg := db.ExecuteScheduledTransferTxParams{
+			ApprovalThresholds: approvalThresholds,
+		}
+
+		for {
+			response, err := store.ExecuteScheduledTransferTx(ctx, arg)
+			if err != nil {
+				if err == sql.ErrNoRows {
+					return nil
+				}
+				return err
+			}
+
+			log.Printf("scheduled transfer [%d] %s", response.ScheduledTransfer.ID, response.ScheduledTransfer.Status)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	mockdb "github.com/VL-037/go-bank/db/mock"
+	db "github.com/VL-037/go-bank/db/sqlc"
+	"github.com/VL-037/go-bank/util"
+	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestExecuteScheduledTransfers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	arg := db.ExecuteScheduledTransferTxParams{
+		ApprovalThresholds: map[string]int64{util.USD: 100_000},
+	}
+
+	store := mockdb.NewMockStore(ctrl)
+	gomock.InOrder(
+		store.EXPECT().
+			ExecuteScheduledTransferTx(gomock.Any(), gomock.Eq(arg)).
+			Times(2).
+			Return(db.ExecuteScheduledTransferTxResponse{}, nil),
+		store.EXPECT().
+			ExecuteScheduledTransferTx(gomock.Any(), gomock.Eq(arg)).
+			Times(1).
+			Return(db.ExecuteScheduledTransferTxResponse{}, sql.ErrNoRows),
+	)
+
+	err := ExecuteScheduledTransfers(store, arg.ApprovalThresholds)(context.Background())
+	require.NoError(t, err)
+}
+
+func TestExecuteScheduledTransfersError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		ExecuteScheduledTransferTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.ExecuteScheduledTransferTxResponse{}, sql.ErrConnDone)
+
+	err := ExecuteScheduledTransfers(store, nil)(context.Background())
+	require.ErrorIs(t, err, sql.ErrConnDone)
+}
+
+func TestExecuteScheduledTransfersTransientError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// a deadlock stops the run without failing the scheduled transfer, the next run executes it
+	deadlock := &pq.Error{Code: "40P01", Message: "deadlock detected"}
+	store := mockdb.NewMockStore(ctrl)
+	gomock.InOrder(
+		store.EXPECT().
+			ExecuteScheduledTransferTx(gomock.Any(), gomock.Any()).
+			Times(1).
+			Return(db.ExecuteScheduledTransferTxResponse{}, deadlock),
+		store.EXPECT().
+			ExecuteScheduledTransferTx(gomock.Any(), gomock.Any()).
+			Times(1).
+			Return(db.ExecuteScheduledTransferTxResponse{
+				ScheduledTransfer: db.ScheduledTransfer{Status: db.SCHEDULED_TRANSFER_STATUS_EXECUTED},
+			}, nil),
+		store.EXPECT().
+			ExecuteScheduledTransferTx(gomock.Any(), gomock.Any()).
+			Times(1).
+			Return(db.ExecuteScheduledTransferTxResponse{}, sql.ErrNoRows),
+	)
+
+	task := ExecuteScheduledTransfers(store, nil)
+	require.ErrorIs(t, task(context.Background()), deadlock)
+	require.NoError(t, task(context.Background()))
+}
@@ -0,0 +1,33 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	db "github.com/VL-037/go-bank/db/sqlc"
+	"log"
+	"time"
+)
+
+// ExecuteStandingOrders executes every standing order which is due,
+// occurrences above the approval threshold of their currency are skipped
+func ExecuteStandingOrders(store db.Store, maxRetries int32, retryInterval time.Duration, approvalThresholds map[string]int64) Task {
+	return func(ctx context.Context) error {
+		arg := db.ExecuteStandingOrderTxParams{
+			MaxRetries:         maxRetries,
+			RetryInterval:      retryInterval,
+			ApprovalThresholds: approvalThresholds,
+		}
+
+		for {
+			response, err := store.ExecuteStandingOrderTx(ctx, arg)
+			if err != nil {
+				if err == sql.ErrNoRows {
+					return nil
+				}
+				return err
+			}
+
+			log.Printf("standing order [%d] next execution at %s", response.StandingOrder.ID, response.StandingOrder.NextExecutionAt)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	mockdb "github.com/VL-037/go-bank/db/mock"
+	db "github.com/VL-037/go-bank/db/sqlc"
+	"github.com/VL-037/go-bank/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestExecuteStandingOrders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	arg := db.ExecuteStandingOrderTxParams{
+		MaxRetries:         3,
+		RetryInterval:      time.Hour,
+		ApprovalThresholds: map[string]int64{util.USD: 100_000},
+	}
+
+	store := mockdb.NewMockStore(ctrl)
+	gomock.InOrder(
+		store.EXPECT().
+			ExecuteStandingOrderTx(gomock.Any(), gomock.Eq(arg)).
+			Times(1).
+			Return(db.ExecuteStandingOrderTxResponse{}, nil),
+		store.EXPECT().
+			ExecuteStandingOrderTx(gomock.Any(), gomock.Eq(arg)).
+			Times(1).
+			Return(db.ExecuteStandingOrderTxResponse{}, sql.ErrNoRows),
+	)
+
+	err := ExecuteStandingOrders(store, arg.MaxRetries, arg.RetryInterval, arg.ApprovalThresholds)(context.Background())
+	require.NoError(t, err)
+}